@@ -3,22 +3,53 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"GitSyncer/core/database"
 	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/azuredevops"
+	"GitSyncer/core/provider/bitbucket"
+	"GitSyncer/core/provider/gerrit"
+	"GitSyncer/core/provider/gitea"
+	"GitSyncer/core/provider/github"
+	"GitSyncer/core/provider/gitlab"
+	"GitSyncer/core/provider/local"
+	"GitSyncer/core/provider/plaingit"
+	"GitSyncer/core/provider/plugin"
+	"GitSyncer/core/provider/sourcehut"
 	"GitSyncer/core/service"
 	"GitSyncer/core/store"
+	"GitSyncer/core/webhook"
 )
 
+// nativeMirrorRefresh is how often the status of native mirrors is recorded into sync history.
+const nativeMirrorRefresh = 5 * time.Minute
+
 type App struct {
-	ctx context.Context
-	db  *sql.DB
+	ctx    context.Context
+	cancel context.CancelFunc
+	db     *sql.DB
+
+	Providers            *store.ProviderStore
+	Repositories         *store.RepositoryStore
+	Credentials          *service.CredentialService
+	Detection            *service.DetectionService
+	Webhooks             *service.WebhookService
+	WebhookRegistrations *service.WebhookRegistrationService
+	Mirrors              *service.MirrorService
+	Sync                 *service.SyncService
+	Exports              *service.ExportService
+	SyncQueue            *service.SyncQueue
+	Registry             *provider.ProviderRegistry
+	Transports           *service.ProviderTransports
 
-	Providers    *store.ProviderStore
-	Repositories *store.RepositoryStore
-	Credentials  *service.CredentialService
+	webhookServer *webhook.Server
 }
 
 func NewApp() *App {
@@ -28,7 +59,7 @@ func NewApp() *App {
 // startup is called when the app starts. The context is saved
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
-	a.ctx = ctx
+	a.ctx, a.cancel = context.WithCancel(ctx)
 
 	dbPath, err := database.DefaultDBPath()
 	if err != nil {
@@ -48,12 +79,96 @@ func (a *App) startup(ctx context.Context) {
 	a.Providers = store.NewProviderStore(db)
 	a.Repositories = store.NewRepositoryStore(db)
 
+	if err := a.Repositories.BackfillCanonicalURLs(); err != nil {
+		log.Fatalf("failed to index repository urls: %v", err)
+	}
+
 	credStore := store.NewCredentialStore(db)
 	settingStore := store.NewSettingStore(db)
 	a.Credentials = service.NewCredentialService(db, credStore, settingStore)
+
+	registrationStore := store.NewWebhookRegistrationStore(db)
+
+	a.SyncQueue = service.NewSyncQueue()
+	a.Webhooks = service.NewWebhookService(a.Providers, a.Repositories, store.NewWebhookDeliveryStore(db), registrationStore, settingStore, a.Credentials, a.SyncQueue)
+	a.webhookServer = webhook.NewServer(a.Webhooks)
+
+	if err := a.Webhooks.Prune(); err != nil {
+		log.Printf("failed to prune webhook deliveries: %v", err)
+	}
+
+	if addr, err := a.Webhooks.ListenAddress(); err != nil {
+		log.Printf("failed to read webhook listen address: %v", err)
+	} else if addr != "" {
+		if err := a.webhookServer.Start(addr); err != nil {
+			log.Printf("failed to start webhook receiver: %v", err)
+		}
+	}
+
+	a.Registry = provider.NewProviderRegistry()
+	if err := registerProviders(a.Registry); err != nil {
+		log.Fatalf("failed to register providers: %v", err)
+	}
+
+	// A broken plugin must not keep the app from starting.
+	if dir, err := plugin.DefaultDir(); err != nil {
+		log.Printf("failed to resolve plugins directory: %v", err)
+	} else if _, err := plugin.Load(ctx, a.Registry, dir); err != nil {
+		log.Printf("failed to load plugins: %v", err)
+	}
+
+	a.Detection = service.NewDetectionService(settingStore, store.NewHostMappingStore(db), a.Registry, nil)
+
+	a.Transports = service.NewProviderTransports()
+	connect := service.RegistryConnector(a.Registry, a.Credentials, a.Transports)
+	a.WebhookRegistrations = service.NewWebhookRegistrationService(a.Providers, a.Repositories, registrationStore, settingStore, a.Credentials, connect)
+
+	mirrorStore := store.NewMirrorStore(db)
+	historyStore := store.NewSyncHistoryStore(db)
+	a.Mirrors = service.NewMirrorService(a.Providers, a.Repositories, mirrorStore, store.NewSubmoduleMappingStore(db), historyStore, a.Credentials, connect)
+
+	dataDir := filepath.Join(filepath.Dir(dbPath), "mirrors")
+	a.Sync = service.NewSyncService(a.Providers, a.Repositories, mirrorStore, historyStore, store.NewReleaseAssetChecksumStore(db), a.Credentials, a.Mirrors, connect, dataDir)
+
+	storage := service.RegistryStorageConnector(a.Registry, a.Credentials)
+	exportDir := filepath.Join(filepath.Dir(dbPath), "exports")
+	a.Exports = service.NewExportService(a.Providers, a.Repositories, store.NewDiscussionExportStore(db), a.Credentials, connect, storage, exportDir)
+
+	go a.Sync.Run(a.ctx, a.SyncQueue, nativeMirrorRefresh)
+}
+
+// registerProviders registers all built-in provider factories.
+func registerProviders(r *provider.ProviderRegistry) error {
+	for _, register := range []func(*provider.ProviderRegistry) error{
+		github.Register,
+		gitlab.Register,
+		gitea.Register,
+		bitbucket.Register,
+		azuredevops.Register,
+		gerrit.Register,
+		sourcehut.Register,
+		plaingit.Register,
+		local.Register,
+	} {
+		if err := register(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (a *App) shutdown(ctx context.Context) {
+	if a.cancel != nil {
+		a.cancel()
+	}
+
+	if a.webhookServer != nil {
+		if err := a.webhookServer.Stop(ctx); err != nil {
+			log.Printf("error stopping webhook receiver: %v", err)
+		}
+	}
+
 	if a.Credentials != nil {
 		a.Credentials.Lock()
 	}
@@ -145,3 +260,243 @@ func (a *App) UpdateCredential(id, providerID int64, label, authType, authData s
 func (a *App) DeleteCredential(id int64) error {
 	return a.Credentials.Delete(id)
 }
+
+// GetProviderRateLimits returns the API quota last reported to the provider record providerID.
+func (a *App) GetProviderRateLimits(providerID int64) []provider.RateLimitStatus {
+	return a.Transports.RateLimits(providerID)
+}
+
+// DetectProvider identifies the provider hosting url, probing self-hosted instances once per host.
+func (a *App) DetectProvider(url string) (*provider.ProbeResult, error) {
+	return a.Detection.Detect(a.ctx, url)
+}
+
+// ListHostMappings returns the user-defined host-to-provider mappings.
+func (a *App) ListHostMappings() ([]models.HostMapping, error) {
+	return a.Detection.ListMappings()
+}
+
+// CreateHostMapping adds a mapping from a host pattern to a provider type.
+func (a *App) CreateHostMapping(pattern, providerType, apiURL string) (int64, error) {
+	m := &models.HostMapping{
+		Pattern:      pattern,
+		ProviderType: providerType,
+		APIURL:       apiURL,
+	}
+
+	if err := a.Detection.SaveMapping(m); err != nil {
+		return 0, err
+	}
+
+	return m.ID, nil
+}
+
+// UpdateHostMapping changes an existing host mapping.
+func (a *App) UpdateHostMapping(id int64, pattern, providerType, apiURL string) error {
+	m := &models.HostMapping{
+		ID:           id,
+		Pattern:      pattern,
+		ProviderType: providerType,
+		APIURL:       apiURL,
+	}
+
+	return a.Detection.SaveMapping(m)
+}
+
+// DeleteHostMapping removes a host mapping by ID.
+func (a *App) DeleteHostMapping(id int64) error {
+	return a.Detection.DeleteMapping(id)
+}
+
+// ForgetProbe drops the cached detection result for the host of rawURL, which may also be a bare
+// host name, so the next detection probes the host again.
+func (a *App) ForgetProbe(rawURL string) error {
+	host := provider.ExtractHost(rawURL)
+	if host == "" {
+		host = strings.ToLower(strings.TrimSpace(rawURL))
+	}
+
+	return a.Detection.Forget(host)
+}
+
+// AddRepository tracks a repository of the provider record providerID and registers a webhook
+// for it when the provider supports that and a public webhook URL is configured. A repository
+// already tracked under another URL of the same repository, e.g. its SSH URL, is rejected.
+func (a *App) AddRepository(providerID int64, name, cloneURL string) (int64, error) {
+	if provider.CanonicalURL(cloneURL) != "" {
+		existing, err := a.Repositories.FindByCloneURL(cloneURL)
+		if err == nil {
+			return 0, fmt.Errorf("repository %s is already tracked as %q", provider.RedactURL(cloneURL), existing.Name)
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			return 0, err
+		}
+	}
+
+	repo := &models.Repository{
+		ProviderID: providerID,
+		Name:       name,
+		CloneURL:   cloneURL,
+	}
+
+	if err := a.Repositories.Create(repo); err != nil {
+		return 0, err
+	}
+
+	// A missing hook is repaired by the next reconciliation, so it does not fail the addition.
+	if _, err := a.WebhookRegistrations.Register(a.ctx, repo.ID); err != nil && !errors.Is(err, service.ErrNoPublicURL) {
+		log.Printf("failed to register webhook for repository %d: %v", repo.ID, err)
+	}
+
+	return repo.ID, nil
+}
+
+// RemoveRepository stops tracking a repository, deleting the webhook registered for it.
+func (a *App) RemoveRepository(id int64) error {
+	// A hook that cannot be deleted now is removed by the next reconciliation.
+	if err := a.WebhookRegistrations.Unregister(a.ctx, id); err != nil {
+		log.Printf("failed to delete webhook of repository %d: %v", id, err)
+	}
+
+	return a.Repositories.Delete(id)
+}
+
+// ListProviderSchemas returns the configuration schema of every registered source control
+// provider type, used to render provider setup forms.
+func (a *App) ListProviderSchemas() map[string]provider.ConfigSchema {
+	schemas := make(map[string]provider.ConfigSchema)
+
+	for _, pt := range a.Registry.ListSourceControlProviderTypes() {
+		if schema, ok := a.Registry.GetSourceControlProviderSchema(pt); ok {
+			schemas[string(pt)] = schema
+		}
+	}
+
+	return schemas
+}
+
+// GetWebhookListenAddress returns the configured webhook receiver address, or "" when disabled.
+func (a *App) GetWebhookListenAddress() (string, error) {
+	return a.Webhooks.ListenAddress()
+}
+
+// SetWebhookListenAddress starts the webhook receiver on addr, e.g. ":8765", and remembers it.
+// An empty addr stops the receiver.
+func (a *App) SetWebhookListenAddress(addr string) error {
+	if addr == "" {
+		if err := a.webhookServer.Stop(a.ctx); err != nil {
+			return err
+		}
+	} else if err := a.webhookServer.Start(addr); err != nil {
+		return err
+	}
+
+	return a.Webhooks.SetListenAddress(addr)
+}
+
+// ListWebhookDeliveries returns up to limit recorded webhook deliveries, most recent first.
+func (a *App) ListWebhookDeliveries(limit int) ([]models.WebhookDelivery, error) {
+	return a.Webhooks.ListDeliveries(limit)
+}
+
+// GetWebhookDelivery returns a recorded webhook delivery by ID.
+func (a *App) GetWebhookDelivery(id int64) (*models.WebhookDelivery, error) {
+	return a.Webhooks.GetDelivery(id)
+}
+
+// ReplayWebhookDelivery processes a recorded webhook delivery again.
+func (a *App) ReplayWebhookDelivery(id int64) (*models.WebhookDelivery, error) {
+	return a.Webhooks.Replay(id)
+}
+
+// GetWebhookPublicURL returns the base URL providers deliver webhooks to, or "" when unset.
+func (a *App) GetWebhookPublicURL() (string, error) {
+	return a.WebhookRegistrations.PublicURL()
+}
+
+// SetWebhookPublicURL stores the base URL the webhook receiver is reachable at from the providers.
+// Registered hooks are updated by the next ReconcileWebhooks.
+func (a *App) SetWebhookPublicURL(url string) error {
+	return a.WebhookRegistrations.SetPublicURL(url)
+}
+
+// ListWebhookRegistrations returns the webhooks GitSyncer registered on source repositories.
+func (a *App) ListWebhookRegistrations() ([]models.WebhookRegistration, error) {
+	return a.WebhookRegistrations.List()
+}
+
+// ReconcileWebhooks registers missing webhooks, repairs stale ones and removes those of
+// repositories no longer tracked.
+func (a *App) ReconcileWebhooks() (*service.WebhookReconcileResult, error) {
+	return a.WebhookRegistrations.Reconcile(a.ctx)
+}
+
+// ListMirrors returns the mirrors of a repository.
+func (a *App) ListMirrors(repositoryID int64) ([]models.Mirror, error) {
+	return a.Mirrors.List(repositoryID)
+}
+
+// CreateMirror adds a GitSyncer-driven or native mirror to a repository. Native mirrors are
+// configured on the provider that performs the transfer.
+func (a *App) CreateMirror(m models.Mirror) (int64, error) {
+	if err := a.Mirrors.Create(a.ctx, &m); err != nil {
+		return 0, err
+	}
+
+	return m.ID, nil
+}
+
+// UpdateMirror changes a mirror, reconfiguring its native counterpart. A new direction, target or
+// credential replaces a native mirror. For a pull mirror that DELETES the mirror repository on
+// the target provider, with all its data, and is refused unless deleteTarget is set. A pull mirror
+// cannot be switched to another mode.
+func (a *App) UpdateMirror(m models.Mirror, deleteTarget bool) error {
+	return a.Mirrors.Update(a.ctx, &m, deleteTarget)
+}
+
+// DeleteMirror removes a mirror and its native counterpart. Removing a pull mirror DELETES the
+// mirror repository on the target provider, with all its data, and is refused unless
+// deleteTarget is set.
+func (a *App) DeleteMirror(id int64, deleteTarget bool) error {
+	return a.Mirrors.Delete(a.ctx, id, deleteTarget)
+}
+
+// ListSubmoduleMappings returns where the submodule URLs of a mirror's repository, and of their
+// own submodules, are mirrored.
+func (a *App) ListSubmoduleMappings(mirrorID int64) ([]models.SubmoduleMapping, error) {
+	return a.Mirrors.SubmoduleMappings(mirrorID)
+}
+
+// GetMirrorCloneCommand returns a git command cloning a mirror with its submodules from their
+// mirrors.
+func (a *App) GetMirrorCloneCommand(mirrorID int64) (string, error) {
+	return a.Mirrors.CloneCommand(mirrorID)
+}
+
+// GetNativeMirrorStatus returns the provider's status of a native mirror.
+func (a *App) GetNativeMirrorStatus(id int64) (*provider.NativeMirror, error) {
+	return a.Mirrors.NativeStatus(a.ctx, id)
+}
+
+// SyncRepository queues a repository for syncing to its mirrors. It returns false when the
+// repository was already queued.
+func (a *App) SyncRepository(id int64) bool {
+	return a.SyncQueue.Enqueue(id, service.SyncReasonManual)
+}
+
+// ListSyncHistory returns up to limit sync history entries of a repository, most recent first.
+func (a *App) ListSyncHistory(repositoryID int64, limit int) ([]models.SyncHistory, error) {
+	return a.Sync.History(repositoryID, limit)
+}
+
+// ExportDiscussions archives the issues, pull requests and comments of a repository to the storage
+// provider record storageProviderID, continuing from the previous export to it.
+func (a *App) ExportDiscussions(repositoryID, storageProviderID int64) (*models.DiscussionExport, error) {
+	return a.Exports.Export(a.ctx, repositoryID, storageProviderID)
+}
+
+// ListDiscussionExports returns the discussion exports of a repository, most recent first.
+func (a *App) ListDiscussionExports(repositoryID int64) ([]models.DiscussionExport, error) {
+	return a.Exports.Exports(repositoryID)
+}
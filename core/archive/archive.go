@@ -0,0 +1,137 @@
+// Package archive defines the versioned JSON archive that the discussion history of a repository
+// is exported into: its issues, pull or merge requests, comments, labels and milestones.
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"GitSyncer/core/provider"
+)
+
+// Version is the archive format written by Write. Read accepts this version and older ones.
+const Version = 1
+
+// Archive is one export of a repository's discussions. An incremental archive holds the issues
+// and comments updated since the export it continues from, and all labels and milestones.
+type Archive struct {
+	Version    int    `json:"version"`
+	Provider   string `json:"provider"`
+	Repository string `json:"repository"`
+	CloneURL   string `json:"clone_url"`
+
+	// Since is the time of the export this one continues from, or nil for a full export.
+	Since      *time.Time `json:"since,omitempty"`
+	ExportedAt time.Time  `json:"exported_at"`
+
+	Labels     []provider.Label     `json:"labels"`
+	Milestones []provider.Milestone `json:"milestones"`
+	Issues     []provider.Issue     `json:"issues"`
+	Comments   []provider.Comment   `json:"comments"`
+}
+
+// Write encodes a to w as indented JSON, stamping the current Version. Missing lists are
+// written as empty ones.
+func Write(w io.Writer, a *Archive) error {
+	a.Version = Version
+	a.Labels = orEmpty(a.Labels)
+	a.Milestones = orEmpty(a.Milestones)
+	a.Issues = orEmpty(a.Issues)
+	a.Comments = orEmpty(a.Comments)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(a); err != nil {
+		return fmt.Errorf("archive.Write: %w", err)
+	}
+
+	return nil
+}
+
+// Read decodes an archive from r, rejecting versions newer than Version.
+func Read(r io.Reader) (*Archive, error) {
+	var a Archive
+
+	if err := json.NewDecoder(r).Decode(&a); err != nil {
+		return nil, fmt.Errorf("archive.Read: %w", err)
+	}
+
+	if a.Version < 1 || a.Version > Version {
+		return nil, fmt.Errorf("archive.Read: unsupported version %d", a.Version)
+	}
+
+	return &a, nil
+}
+
+// Merge folds archives, oldest first, into one holding the latest state of every issue and
+// comment, and the labels and milestones of the last archive.
+func Merge(archives ...*Archive) *Archive {
+	if len(archives) == 0 {
+		return nil
+	}
+
+	first, last := archives[0], archives[len(archives)-1]
+
+	merged := &Archive{
+		Version:    Version,
+		Provider:   last.Provider,
+		Repository: last.Repository,
+		CloneURL:   last.CloneURL,
+		Since:      first.Since,
+		ExportedAt: last.ExportedAt,
+		Labels:     last.Labels,
+		Milestones: last.Milestones,
+	}
+
+	type issueKey struct {
+		number      int
+		pullRequest bool
+	}
+
+	type commentKey struct {
+		id          string
+		pullRequest bool
+	}
+
+	issues := make(map[issueKey]int)
+	comments := make(map[commentKey]int)
+
+	for _, a := range archives {
+		for _, issue := range a.Issues {
+			key := issueKey{issue.Number, issue.PullRequest}
+
+			if i, ok := issues[key]; ok {
+				merged.Issues[i] = issue
+				continue
+			}
+
+			issues[key] = len(merged.Issues)
+			merged.Issues = append(merged.Issues, issue)
+		}
+
+		for _, c := range a.Comments {
+			key := commentKey{c.ID, c.PullRequest}
+
+			if i, ok := comments[key]; ok {
+				merged.Comments[i] = c
+				continue
+			}
+
+			comments[key] = len(merged.Comments)
+			merged.Comments = append(merged.Comments, c)
+		}
+	}
+
+	return merged
+}
+
+func orEmpty[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+
+	return items
+}
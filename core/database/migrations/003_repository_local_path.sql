@@ -0,0 +1,7 @@
+-- +goose Up
+
+ALTER TABLE repositories ADD COLUMN local_path TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+
+ALTER TABLE repositories DROP COLUMN local_path;
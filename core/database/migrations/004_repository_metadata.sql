@@ -0,0 +1,15 @@
+-- +goose Up
+
+ALTER TABLE repositories ADD COLUMN owner TEXT NOT NULL DEFAULT '';
+ALTER TABLE repositories ADD COLUMN visibility TEXT NOT NULL DEFAULT '';
+ALTER TABLE repositories ADD COLUMN is_archived INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE repositories ADD COLUMN is_fork INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE repositories ADD COLUMN pushed_at DATETIME;
+
+-- +goose Down
+
+ALTER TABLE repositories DROP COLUMN pushed_at;
+ALTER TABLE repositories DROP COLUMN is_fork;
+ALTER TABLE repositories DROP COLUMN is_archived;
+ALTER TABLE repositories DROP COLUMN visibility;
+ALTER TABLE repositories DROP COLUMN owner;
@@ -0,0 +1,14 @@
+-- +goose Up
+
+CREATE TABLE host_mappings (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    pattern         TEXT    NOT NULL UNIQUE,
+    provider_type   TEXT    NOT NULL,
+    api_url         TEXT    NOT NULL DEFAULT '',
+    created_at      DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at      DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS host_mappings;
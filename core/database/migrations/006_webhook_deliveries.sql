@@ -0,0 +1,26 @@
+-- +goose Up
+
+CREATE TABLE webhook_deliveries (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    provider_id     INTEGER NOT NULL REFERENCES providers(id) ON DELETE CASCADE,
+    repository_id   INTEGER REFERENCES repositories(id) ON DELETE SET NULL,
+    delivery_id     TEXT    NOT NULL DEFAULT '',
+    event           TEXT    NOT NULL DEFAULT '',
+    status          TEXT    NOT NULL,
+    verified        INTEGER NOT NULL DEFAULT 0,
+    error_message   TEXT    NOT NULL DEFAULT '',
+    headers         TEXT    NOT NULL DEFAULT '',
+    payload         TEXT    NOT NULL DEFAULT '',
+    received_at     DATETIME NOT NULL DEFAULT (datetime('now')),
+    processed_at    DATETIME
+);
+
+CREATE INDEX idx_webhook_deliveries_provider_id ON webhook_deliveries(provider_id);
+CREATE INDEX idx_webhook_deliveries_received_at ON webhook_deliveries(received_at);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_received_at;
+DROP INDEX IF EXISTS idx_webhook_deliveries_provider_id;
+
+DROP TABLE IF EXISTS webhook_deliveries;
@@ -0,0 +1,21 @@
+-- +goose Up
+
+CREATE TABLE webhook_registrations (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    provider_id     INTEGER NOT NULL REFERENCES providers(id) ON DELETE CASCADE,
+    repository_id   INTEGER UNIQUE REFERENCES repositories(id) ON DELETE SET NULL,
+    credential_id   INTEGER REFERENCES credentials(id) ON DELETE SET NULL,
+    clone_url       TEXT    NOT NULL,
+    hook_id         TEXT    NOT NULL DEFAULT '',
+    url             TEXT    NOT NULL DEFAULT '',
+    created_at      DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at      DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX idx_webhook_registrations_provider_id ON webhook_registrations(provider_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_webhook_registrations_provider_id;
+
+DROP TABLE IF EXISTS webhook_registrations;
@@ -0,0 +1,33 @@
+-- +goose Up
+
+CREATE TABLE mirrors (
+    id                      INTEGER PRIMARY KEY AUTOINCREMENT,
+    repository_id           INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    mode                    TEXT    NOT NULL DEFAULT 'gitsyncer',
+    direction               TEXT    NOT NULL DEFAULT '',
+    target_provider_id      INTEGER NOT NULL REFERENCES providers(id) ON DELETE CASCADE,
+    target_url              TEXT    NOT NULL,
+    credential_id           INTEGER REFERENCES credentials(id) ON DELETE SET NULL,
+    interval_seconds        INTEGER NOT NULL DEFAULT 0,
+    only_protected_branches INTEGER NOT NULL DEFAULT 0,
+    native_id               TEXT    NOT NULL DEFAULT '',
+    created_at              DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at              DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX idx_mirrors_repository_id ON mirrors(repository_id);
+
+-- History outlives the mirror it was recorded for, so mirror_id is not a foreign key.
+ALTER TABLE sync_history ADD COLUMN mirror_id INTEGER;
+
+CREATE INDEX idx_sync_history_mirror_id ON sync_history(mirror_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_sync_history_mirror_id;
+
+ALTER TABLE sync_history DROP COLUMN mirror_id;
+
+DROP INDEX IF EXISTS idx_mirrors_repository_id;
+
+DROP TABLE IF EXISTS mirrors;
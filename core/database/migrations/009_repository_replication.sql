@@ -0,0 +1,13 @@
+-- +goose Up
+
+ALTER TABLE repositories ADD COLUMN homepage TEXT NOT NULL DEFAULT '';
+ALTER TABLE repositories ADD COLUMN topics TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE mirrors ADD COLUMN target_owner TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+
+ALTER TABLE mirrors DROP COLUMN target_owner;
+
+ALTER TABLE repositories DROP COLUMN topics;
+ALTER TABLE repositories DROP COLUMN homepage;
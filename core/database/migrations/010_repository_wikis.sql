@@ -0,0 +1,9 @@
+-- +goose Up
+
+ALTER TABLE repositories ADD COLUMN has_wiki INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE repositories ADD COLUMN wiki_clone_url TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+
+ALTER TABLE repositories DROP COLUMN wiki_clone_url;
+ALTER TABLE repositories DROP COLUMN has_wiki;
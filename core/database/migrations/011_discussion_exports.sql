@@ -0,0 +1,20 @@
+-- +goose Up
+
+CREATE TABLE discussion_exports (
+    id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+    repository_id       INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    storage_provider_id INTEGER NOT NULL REFERENCES providers(id) ON DELETE CASCADE,
+    path                TEXT    NOT NULL,
+    since               DATETIME,
+    exported_at         DATETIME NOT NULL,
+    issues              INTEGER NOT NULL DEFAULT 0,
+    comments            INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_discussion_exports_repository_id ON discussion_exports(repository_id, storage_provider_id);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_discussion_exports_repository_id;
+
+DROP TABLE IF EXISTS discussion_exports;
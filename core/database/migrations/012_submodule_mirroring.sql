@@ -0,0 +1,19 @@
+-- +goose Up
+
+ALTER TABLE mirrors ADD COLUMN mirror_submodules INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE submodule_mappings (
+    id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+    mirror_id           INTEGER NOT NULL REFERENCES mirrors(id) ON DELETE CASCADE,
+    submodule_mirror_id INTEGER NOT NULL REFERENCES mirrors(id) ON DELETE CASCADE,
+    source_url          TEXT    NOT NULL,
+    target_url          TEXT    NOT NULL,
+    updated_at          DATETIME NOT NULL,
+    UNIQUE(mirror_id, source_url)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS submodule_mappings;
+
+ALTER TABLE mirrors DROP COLUMN mirror_submodules;
@@ -0,0 +1,7 @@
+-- +goose Up
+
+ALTER TABLE providers ADD COLUMN options TEXT NOT NULL DEFAULT '{}';
+
+-- +goose Down
+
+ALTER TABLE providers DROP COLUMN options;
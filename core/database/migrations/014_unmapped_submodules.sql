@@ -0,0 +1,39 @@
+-- +goose Up
+
+-- Submodules GitSyncer does not mirror are recorded without a submodule mirror or target URL.
+CREATE TABLE submodule_mappings_new (
+    id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+    mirror_id           INTEGER NOT NULL REFERENCES mirrors(id) ON DELETE CASCADE,
+    submodule_mirror_id INTEGER REFERENCES mirrors(id) ON DELETE CASCADE,
+    source_url          TEXT    NOT NULL,
+    target_url          TEXT    NOT NULL DEFAULT '',
+    updated_at          DATETIME NOT NULL,
+    UNIQUE(mirror_id, source_url)
+);
+
+INSERT INTO submodule_mappings_new (id, mirror_id, submodule_mirror_id, source_url, target_url, updated_at)
+SELECT id, mirror_id, submodule_mirror_id, source_url, target_url, updated_at FROM submodule_mappings;
+
+DROP TABLE submodule_mappings;
+
+ALTER TABLE submodule_mappings_new RENAME TO submodule_mappings;
+
+-- +goose Down
+
+DELETE FROM submodule_mappings WHERE submodule_mirror_id IS NULL;
+
+CREATE TABLE submodule_mappings_old (
+    id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+    mirror_id           INTEGER NOT NULL REFERENCES mirrors(id) ON DELETE CASCADE,
+    submodule_mirror_id INTEGER NOT NULL REFERENCES mirrors(id) ON DELETE CASCADE,
+    source_url          TEXT    NOT NULL,
+    target_url          TEXT    NOT NULL,
+    updated_at          DATETIME NOT NULL,
+    UNIQUE(mirror_id, source_url)
+);
+
+INSERT INTO submodule_mappings_old SELECT * FROM submodule_mappings;
+
+DROP TABLE submodule_mappings;
+
+ALTER TABLE submodule_mappings_old RENAME TO submodule_mappings;
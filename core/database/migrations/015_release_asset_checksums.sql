@@ -0,0 +1,15 @@
+-- +goose Up
+
+CREATE TABLE release_asset_checksums (
+    provider_id  INTEGER NOT NULL REFERENCES providers(id) ON DELETE CASCADE,
+    asset_id     TEXT    NOT NULL,
+    download_url TEXT    NOT NULL,
+    size         INTEGER NOT NULL,
+    sha256       TEXT    NOT NULL,
+    updated_at   DATETIME NOT NULL,
+    PRIMARY KEY (provider_id, asset_id)
+);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS release_asset_checksums;
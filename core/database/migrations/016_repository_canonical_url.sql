@@ -0,0 +1,13 @@
+-- +goose Up
+
+-- The canonical identity of clone_url (provider.CanonicalURL), '' when it cannot be parsed. It is
+-- computed in Go, so existing rows stay NULL until RepositoryStore.BackfillCanonicalURLs runs.
+ALTER TABLE repositories ADD COLUMN canonical_url TEXT;
+
+CREATE INDEX idx_repositories_canonical_url ON repositories(canonical_url);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_repositories_canonical_url;
+
+ALTER TABLE repositories DROP COLUMN canonical_url;
@@ -0,0 +1,330 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"GitSyncer/core/models"
+)
+
+// Auth holds the credentials passed to git for a single command.
+// Username/Password are sent as HTTP basic auth, BearerToken as an HTTP bearer token
+// and SSHKey is a private key used for SSH remotes.
+type Auth struct {
+	Username    string
+	Password    string
+	BearerToken string
+	SSHKey      string
+}
+
+// CommandError is returned when a git command exits unsuccessfully.
+type CommandError struct {
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("git %s: %v: %s", strings.Join(e.Args, " "), e.Err, strings.TrimSpace(e.Stderr))
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// AuthFromCredential builds git auth from a provider credential.
+// Token and OAuth credentials are sent as the password of tokenUsername.
+func AuthFromCredential(cred *models.Credential, tokenUsername string) (*Auth, error) {
+	if cred == nil {
+		return nil, nil
+	}
+
+	switch cred.AuthType {
+	case models.AuthTypeToken, models.AuthTypeOAuth:
+		return &Auth{Username: tokenUsername, Password: cred.AuthData}, nil
+	case models.AuthTypeSSHKey:
+		return &Auth{SSHKey: cred.AuthData}, nil
+	case models.AuthTypeBasic:
+		username, password, ok := strings.Cut(cred.AuthData, ":")
+		if !ok {
+			return nil, errors.New("git.AuthFromCredential: basic auth data must be username:password")
+		}
+
+		return &Auth{Username: username, Password: password}, nil
+	default:
+		return nil, fmt.Errorf("git.AuthFromCredential: unsupported auth type %q", cred.AuthType)
+	}
+}
+
+// Run executes git with the given arguments in dir and returns its standard output.
+func Run(ctx context.Context, dir string, auth *Auth, args ...string) (string, error) {
+	return RunInput(ctx, dir, auth, nil, args...)
+}
+
+// RunInput is Run with standard input read from input, such as object names for cat-file --batch.
+func RunInput(ctx context.Context, dir string, auth *Auth, input io.Reader, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdin = input
+
+	env, cleanup, err := authEnv(auth)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	cmd.Env = append(os.Environ(), env...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+
+		return "", &CommandError{Args: args, Stderr: stderr.String(), Err: err}
+	}
+
+	return stdout.String(), nil
+}
+
+// IsRepository reports whether path is an existing git repository (bare or not).
+func IsRepository(ctx context.Context, path string) bool {
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+
+	_, err := Run(ctx, path, nil, "rev-parse", "--git-dir")
+
+	return err == nil
+}
+
+// InitBare creates an empty bare repository at path, including missing parent directories.
+func InitBare(ctx context.Context, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("git.InitBare: create parent dir: %w", err)
+	}
+
+	if _, err := Run(ctx, "", nil, "init", "--bare", "--quiet", path); err != nil {
+		return fmt.Errorf("git.InitBare: %w", err)
+	}
+
+	return nil
+}
+
+// CloneMirror creates a bare mirror of remoteURL at destPath.
+// If destPath already holds a repository, its refs are fetched and pruned instead.
+func CloneMirror(ctx context.Context, remoteURL, destPath string, auth *Auth) error {
+	if err := checkRemoteURL(remoteURL); err != nil {
+		return fmt.Errorf("git.CloneMirror: %w", err)
+	}
+
+	if IsRepository(ctx, destPath) {
+		if _, err := Run(ctx, destPath, auth, "remote", "set-url", "--", "origin", remoteURL); err != nil {
+			return fmt.Errorf("git.CloneMirror: %w", err)
+		}
+
+		if _, err := Run(ctx, destPath, auth, "fetch", "--prune", "--tags", "origin"); err != nil {
+			return fmt.Errorf("git.CloneMirror: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0o750); err != nil {
+		return fmt.Errorf("git.CloneMirror: create parent dir: %w", err)
+	}
+
+	if _, err := Run(ctx, "", auth, "clone", "--mirror", "--", remoteURL, destPath); err != nil {
+		return fmt.Errorf("git.CloneMirror: %w", err)
+	}
+
+	return nil
+}
+
+// PushMirror pushes all refs of the repository at localPath to remoteURL, deleting refs missing locally.
+func PushMirror(ctx context.Context, localPath, remoteURL string, auth *Auth) error {
+	if localPath == "" {
+		return errors.New("git.PushMirror: repository has no local path, clone it first")
+	}
+
+	if err := checkRemoteURL(remoteURL); err != nil {
+		return fmt.Errorf("git.PushMirror: %w", err)
+	}
+
+	if _, err := Run(ctx, localPath, auth, "push", "--mirror", "--", remoteURL); err != nil {
+		return fmt.Errorf("git.PushMirror: %w", err)
+	}
+
+	return nil
+}
+
+// checkRemoteURL rejects a remote URL starting with "-", which git could take for an option.
+// Remote URLs can come from untrusted sources such as the .gitmodules of a mirrored repository.
+func checkRemoteURL(remoteURL string) error {
+	if strings.HasPrefix(remoteURL, "-") {
+		return fmt.Errorf("remote url %q must not start with \"-\"", remoteURL)
+	}
+
+	return nil
+}
+
+// SubmoduleURLs returns the URLs of the submodules declared in the top-level .gitmodules of the
+// commits reachable from any ref of the repository at repoPath, each once, in the order found.
+// Relative URLs are returned as written.
+func SubmoduleURLs(ctx context.Context, repoPath string) ([]string, error) {
+	out, err := Run(ctx, repoPath, nil, "rev-list", "--objects", "--all")
+	if err != nil {
+		return nil, fmt.Errorf("git.SubmoduleURLs: %w", err)
+	}
+
+	var (
+		urls []string
+		seen = make(map[string]bool)
+	)
+
+	for _, line := range strings.Split(out, "\n") {
+		oid, name, ok := strings.Cut(line, " ")
+		if !ok || name != ".gitmodules" {
+			continue
+		}
+
+		config, err := Run(ctx, repoPath, nil, "config", "--blob", oid, "--get-regexp", `^submodule\..*\.url$`)
+
+		// git config exits with 1 when no key matches.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("git.SubmoduleURLs: %w", err)
+		}
+
+		for _, entry := range strings.Split(config, "\n") {
+			_, url, ok := strings.Cut(entry, " ")
+			if url = strings.TrimSpace(url); ok && url != "" && !seen[url] {
+				seen[url] = true
+				urls = append(urls, url)
+			}
+		}
+	}
+
+	return urls, nil
+}
+
+// authEnv returns environment variables configuring git to use auth without
+// exposing secrets on the command line. The cleanup function removes any temporary key file.
+func authEnv(auth *Auth) ([]string, func(), error) {
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+	cleanup := func() {}
+
+	if auth == nil {
+		return env, cleanup, nil
+	}
+
+	switch {
+	case auth.BearerToken != "":
+		env = append(env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Bearer "+auth.BearerToken,
+		)
+	case auth.Password != "":
+		basic := base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password))
+		env = append(env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+basic,
+		)
+	}
+
+	if auth.SSHKey != "" {
+		keyPath, removeKey, err := writeKeyFile(auth.SSHKey)
+		if err != nil {
+			return nil, cleanup, err
+		}
+
+		cleanup = removeKey
+		env = append(env, fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %q -o IdentitiesOnly=yes -o StrictHostKeyChecking=accept-new", keyPath))
+	}
+
+	return env, cleanup, nil
+}
+
+// RunSSH runs a command on an SSH host (e.g. gitolite's "info") using the SSH key from auth, if any.
+// destination is "user@host"; port 0 uses the SSH default.
+func RunSSH(ctx context.Context, auth *Auth, destination string, port int, command ...string) (string, error) {
+	args := []string{"-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=accept-new"}
+
+	if port != 0 {
+		args = append(args, "-p", fmt.Sprint(port))
+	}
+
+	if auth != nil && auth.SSHKey != "" {
+		keyPath, removeKey, err := writeKeyFile(auth.SSHKey)
+		if err != nil {
+			return "", err
+		}
+		defer removeKey()
+
+		args = append(args, "-i", keyPath, "-o", "IdentitiesOnly=yes")
+	}
+
+	// "--" keeps a destination starting with "-" from being parsed as an option.
+	args = append(args, "--", destination)
+	args = append(args, command...)
+
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = ctxErr
+		}
+
+		return "", &CommandError{Args: append([]string{"ssh"}, args...), Stderr: stderr.String(), Err: err}
+	}
+
+	return stdout.String(), nil
+}
+
+// writeKeyFile stores a private key in a temporary file readable only by the current user.
+func writeKeyFile(key string) (string, func(), error) {
+	f, err := os.CreateTemp("", "gitsyncer-key-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("git: create ssh key file: %w", err)
+	}
+
+	remove := func() { os.Remove(f.Name()) }
+
+	if !strings.HasSuffix(key, "\n") {
+		key += "\n"
+	}
+
+	_, err = f.WriteString(key)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		remove()
+
+		return "", nil, fmt.Errorf("git: write ssh key file: %w", err)
+	}
+
+	return f.Name(), remove, nil
+}
@@ -0,0 +1,183 @@
+package lfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"GitSyncer/core/git"
+)
+
+const mediaType = "application/vnd.git-lfs+json"
+
+// Batch API operations.
+const (
+	OperationDownload = "download"
+	OperationUpload   = "upload"
+)
+
+// Endpoint is the LFS server of one repository, e.g. https://github.com/octo/hello.git/info/lfs,
+// with the credentials sent to it.
+type Endpoint struct {
+	URL  string
+	Auth *git.Auth
+}
+
+// action is a transfer the server asks for: a request to href carrying header.
+type action struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header,omitempty"`
+}
+
+// batchObject is an object in a batch response. Actions is empty when there is nothing to do,
+// e.g. for an upload of an object the server already has.
+type batchObject struct {
+	Pointer
+	Actions map[string]action `json:"actions,omitempty"`
+	Error   *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// batch asks ep how to perform operation on objects with the basic transfer adapter.
+func batch(ctx context.Context, ep Endpoint, operation string, objects []Pointer) ([]batchObject, error) {
+	body, err := json.Marshal(map[string]any{
+		"operation": operation,
+		"transfers": []string{"basic"},
+		"objects":   objects,
+		"hash_algo": "sha256",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(ep.URL, "/")+"/objects/batch", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", mediaType)
+	req.Header.Set("Content-Type", mediaType)
+	setAuth(req, ep.Auth)
+
+	var resp struct {
+		Objects []batchObject `json:"objects"`
+	}
+
+	if err := do(req, &resp); err != nil {
+		return nil, fmt.Errorf("%s batch: %w", operation, err)
+	}
+
+	if err := checkObjects(objects, resp.Objects); err != nil {
+		return nil, fmt.Errorf("%s batch: %w", operation, err)
+	}
+
+	return resp.Objects, nil
+}
+
+// checkObjects rejects a batch response naming objects that were not requested, with a different
+// size, or with an OID that is not a SHA-256 hash. The OIDs name files in the object cache.
+func checkObjects(requested []Pointer, objects []batchObject) error {
+	sizes := make(map[string]int64, len(requested))
+	for _, p := range requested {
+		sizes[p.OID] = p.Size
+	}
+
+	for _, obj := range objects {
+		size, ok := sizes[obj.OID]
+		if !oidPattern.MatchString(obj.OID) || !ok || size != obj.Size {
+			return fmt.Errorf("server returned unrequested object %q of %d bytes", obj.OID, obj.Size)
+		}
+	}
+
+	return nil
+}
+
+// transfer performs a download, upload or verify action. Credentials of ep are only sent along
+// when the action neither brings its own authorization nor leaves ep's host, so that pre-signed
+// storage URLs are requested as they were handed out.
+func transfer(ctx context.Context, ep Endpoint, a action, method string, body io.Reader, size int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, a.Href, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if size >= 0 {
+		req.ContentLength = size
+	}
+
+	for k, v := range a.Header {
+		req.Header.Set(k, v)
+	}
+
+	if req.Header.Get("Authorization") == "" && sameHost(a.Href, ep.URL) {
+		setAuth(req, ep.Auth)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+
+		return nil, statusError(resp)
+	}
+
+	return resp, nil
+}
+
+// do sends req and decodes a JSON response into out.
+func do(req *http.Request, out any) error {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return statusError(resp)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func statusError(resp *http.Response) error {
+	var body struct {
+		Message string `json:"message"`
+	}
+
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body)
+
+	if body.Message != "" {
+		return fmt.Errorf("%s: %s", resp.Status, body.Message)
+	}
+
+	return fmt.Errorf("%s", resp.Status)
+}
+
+// setAuth sends auth as HTTP credentials, the way git does for the repository itself.
+func setAuth(req *http.Request, auth *git.Auth) {
+	switch {
+	case auth == nil:
+	case auth.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+auth.BearerToken)
+	case auth.Password != "":
+		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(auth.Username+":"+auth.Password)))
+	}
+}
+
+func sameHost(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+
+	return errA == nil && errB == nil && strings.EqualFold(ua.Host, ub.Host)
+}
@@ -0,0 +1,223 @@
+package lfs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// batchSize is the number of objects per batch request, git-lfs's default.
+const batchSize = 100
+
+// Stats summarises a transfer. Objects and Bytes count every object the repository references;
+// the other fields count those that were fetched from the source and stored on the target.
+type Stats struct {
+	Objects         int   `json:"objects"`
+	Bytes           int64 `json:"bytes"`
+	Downloaded      int   `json:"downloaded"`
+	DownloadedBytes int64 `json:"downloaded_bytes"`
+	Uploaded        int   `json:"uploaded"`
+	UploadedBytes   int64 `json:"uploaded_bytes"`
+}
+
+// Transfer copies the objects of pointers that target lacks from source to target. Objects are
+// cached under lfs/objects in the repository at repoPath, where git-lfs keeps them, so later
+// transfers to other targets do not download them again. A failing object does not stop the
+// others; their errors are joined.
+func Transfer(ctx context.Context, repoPath string, pointers []Pointer, source, target Endpoint) (Stats, error) {
+	stats := Stats{Objects: len(pointers)}
+	for _, p := range pointers {
+		stats.Bytes += p.Size
+	}
+
+	var errs []error
+
+	for start := 0; start < len(pointers); start += batchSize {
+		chunk := pointers[start:min(start+batchSize, len(pointers))]
+
+		uploads, err := batch(ctx, target, OperationUpload, chunk)
+		if err != nil {
+			return stats, fmt.Errorf("lfs.Transfer: target: %w", err)
+		}
+
+		var missing []batchObject
+
+		for _, obj := range uploads {
+			if obj.Error != nil {
+				errs = append(errs, fmt.Errorf("object %s: target: %s", obj.OID, obj.Error.Message))
+				continue
+			}
+
+			if _, ok := obj.Actions[OperationUpload]; ok {
+				missing = append(missing, obj)
+			}
+		}
+
+		errs = append(errs, fetch(ctx, repoPath, missing, source, &stats)...)
+
+		for _, obj := range missing {
+			// Objects that could not be downloaded have their error recorded already.
+			if !cached(repoPath, obj.Pointer) {
+				continue
+			}
+
+			if err := upload(ctx, repoPath, obj, target); err != nil {
+				errs = append(errs, fmt.Errorf("object %s: upload: %w", obj.OID, err))
+				continue
+			}
+
+			stats.Uploaded++
+			stats.UploadedBytes += obj.Size
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return stats, fmt.Errorf("lfs.Transfer: %w", err)
+	}
+
+	return stats, nil
+}
+
+// fetch downloads the objects not yet cached in repoPath from source.
+func fetch(ctx context.Context, repoPath string, objects []batchObject, source Endpoint, stats *Stats) []error {
+	var wanted []Pointer
+
+	for _, obj := range objects {
+		if !cached(repoPath, obj.Pointer) {
+			wanted = append(wanted, obj.Pointer)
+		}
+	}
+
+	if len(wanted) == 0 {
+		return nil
+	}
+
+	downloads, err := batch(ctx, source, OperationDownload, wanted)
+	if err != nil {
+		return []error{fmt.Errorf("source: %w", err)}
+	}
+
+	var errs []error
+
+	for _, obj := range downloads {
+		var err error
+
+		a, ok := obj.Actions[OperationDownload]
+
+		switch {
+		case obj.Error != nil:
+			err = errors.New(obj.Error.Message)
+		case !ok:
+			err = errors.New("no download offered")
+		default:
+			err = download(ctx, repoPath, obj.Pointer, a, source)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("object %s: download: %w", obj.OID, err))
+			continue
+		}
+
+		stats.Downloaded++
+		stats.DownloadedBytes += obj.Size
+	}
+
+	return errs
+}
+
+// download stores the object p in the cache, checking its size and hash first.
+func download(ctx context.Context, repoPath string, p Pointer, a action, source Endpoint) error {
+	resp, err := transfer(ctx, source, a, http.MethodGet, nil, -1)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dest := objectPath(repoPath, p.OID)
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), "incomplete-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+
+	n, err := tmp.ReadFrom(io.TeeReader(resp.Body, h))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if n != p.Size || hex.EncodeToString(h.Sum(nil)) != p.OID {
+		return fmt.Errorf("received %d bytes that do not match the pointer", n)
+	}
+
+	return os.Rename(tmp.Name(), dest)
+}
+
+// upload sends the cached object to target and confirms it when the server asks to verify.
+func upload(ctx context.Context, repoPath string, obj batchObject, target Endpoint) error {
+	f, err := os.Open(objectPath(repoPath, obj.OID))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	resp, err := transfer(ctx, target, obj.Actions[OperationUpload], http.MethodPut, f, obj.Size)
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+
+	verify, ok := obj.Actions["verify"]
+	if !ok {
+		return nil
+	}
+
+	body, err := json.Marshal(obj.Pointer)
+	if err != nil {
+		return err
+	}
+
+	if verify.Header == nil {
+		verify.Header = make(map[string]string)
+	}
+
+	verify.Header["Accept"] = mediaType
+	verify.Header["Content-Type"] = mediaType
+
+	resp, err = transfer(ctx, target, verify, http.MethodPost, bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+
+	return resp.Body.Close()
+}
+
+// objectPath is where git-lfs keeps the object oid: lfs/objects/ab/cd/abcd... in the git directory.
+func objectPath(repoPath, oid string) string {
+	return filepath.Join(repoPath, "lfs", "objects", oid[0:2], oid[2:4], oid)
+}
+
+func cached(repoPath string, p Pointer) bool {
+	info, err := os.Stat(objectPath(repoPath, p.OID))
+
+	return err == nil && info.Size() == p.Size
+}
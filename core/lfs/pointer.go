@@ -0,0 +1,189 @@
+// Package lfs mirrors Git LFS objects. It finds the LFS pointers committed to a repository, and
+// copies the objects they reference between LFS servers through the batch API, caching them in
+// the repository the way git-lfs does.
+package lfs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"GitSyncer/core/git"
+)
+
+// maxPointerSize bounds the blobs read as pointer candidates; git-lfs uses the same cutoff.
+const maxPointerSize = 1024
+
+const pointerVersion = "version https://git-lfs.github.com/spec/v1"
+
+var oidPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Pointer identifies an LFS object by its SHA-256 and size in bytes.
+type Pointer struct {
+	OID  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+// ParsePointer parses the contents of a pointer file, reporting whether data is one.
+func ParsePointer(data []byte) (Pointer, bool) {
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) < 3 || lines[0] != pointerVersion {
+		return Pointer{}, false
+	}
+
+	var (
+		p       Pointer
+		hasSize bool
+	)
+
+	for _, line := range lines[1:] {
+		key, value, _ := strings.Cut(line, " ")
+
+		switch key {
+		case "oid":
+			oid, ok := strings.CutPrefix(value, "sha256:")
+			if !ok || !oidPattern.MatchString(oid) {
+				return Pointer{}, false
+			}
+
+			p.OID = oid
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || size < 0 {
+				return Pointer{}, false
+			}
+
+			p.Size, hasSize = size, true
+		}
+	}
+
+	return p, p.OID != "" && hasSize
+}
+
+// Scan returns the pointers committed in any ref of the repository at repoPath, each object
+// once. It returns nil without reading blobs when no .gitattributes in those refs routes files
+// through the LFS filter.
+func Scan(ctx context.Context, repoPath string) ([]Pointer, error) {
+	out, err := git.Run(ctx, repoPath, nil, "rev-list", "--objects", "--all")
+	if err != nil {
+		return nil, fmt.Errorf("lfs.Scan: %w", err)
+	}
+
+	var objects, attributes []string
+
+	for _, line := range strings.Split(out, "\n") {
+		oid, name, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+
+		objects = append(objects, oid)
+
+		if path.Base(name) == ".gitattributes" {
+			attributes = append(attributes, oid)
+		}
+	}
+
+	usesLFS := false
+
+	err = readBlobs(ctx, repoPath, attributes, func(data []byte) {
+		usesLFS = usesLFS || bytes.Contains(data, []byte("filter=lfs"))
+	})
+	if err != nil || !usesLFS {
+		return nil, err
+	}
+
+	candidates, err := smallBlobs(ctx, repoPath, objects)
+	if err != nil {
+		return nil, err
+	}
+
+	var pointers []Pointer
+
+	seen := make(map[string]bool)
+
+	err = readBlobs(ctx, repoPath, candidates, func(data []byte) {
+		if p, ok := ParsePointer(data); ok && !seen[p.OID] {
+			seen[p.OID] = true
+			pointers = append(pointers, p)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pointers, nil
+}
+
+// smallBlobs returns the blobs among objects that are small enough to be pointers.
+func smallBlobs(ctx context.Context, repoPath string, objects []string) ([]string, error) {
+	if len(objects) == 0 {
+		return nil, nil
+	}
+
+	out, err := git.RunInput(ctx, repoPath, nil, strings.NewReader(strings.Join(objects, "\n")+"\n"),
+		"cat-file", "--batch-check=%(objectname) %(objecttype) %(objectsize)")
+	if err != nil {
+		return nil, fmt.Errorf("lfs.Scan: %w", err)
+	}
+
+	var blobs []string
+
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+
+		if size, err := strconv.Atoi(fields[2]); err == nil && size <= maxPointerSize {
+			blobs = append(blobs, fields[0])
+		}
+	}
+
+	return blobs, nil
+}
+
+// readBlobs calls fn with the contents of each blob in oids.
+func readBlobs(ctx context.Context, repoPath string, oids []string, fn func(data []byte)) error {
+	if len(oids) == 0 {
+		return nil
+	}
+
+	out, err := git.RunInput(ctx, repoPath, nil, strings.NewReader(strings.Join(oids, "\n")+"\n"), "cat-file", "--batch")
+	if err != nil {
+		return fmt.Errorf("lfs.Scan: %w", err)
+	}
+
+	r := bufio.NewReader(strings.NewReader(out))
+
+	for {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil
+		}
+
+		// Each entry is "<oid> <type> <size>\n<contents>\n".
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			continue
+		}
+
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return fmt.Errorf("lfs.Scan: unexpected cat-file header %q", header)
+		}
+
+		data := make([]byte, size+1)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return fmt.Errorf("lfs.Scan: read %s: %w", fields[0], err)
+		}
+
+		fn(data[:size])
+	}
+}
@@ -2,8 +2,21 @@ package models
 
 import "time"
 
+// Credential auth types.
+const (
+	AuthTypeToken  = "token"
+	AuthTypeSSHKey = "ssh_key"
+	AuthTypeOAuth  = "oauth"
+	AuthTypeBasic  = "basic"
+
+	// AuthTypeWebhookSecret is the shared secret that signs or accompanies webhook deliveries
+	// from the provider. It is never used to call the provider.
+	AuthTypeWebhookSecret = "webhook_secret"
+)
+
 // Credential represents an authentication credential for a provider.
-// AuthType is one of: "token", "ssh_key", "oauth".
+// AuthType is one of: "token", "ssh_key", "oauth", "basic", "webhook_secret".
+// Basic credentials store AuthData as "username:password".
 type Credential struct {
 	ID         int64     `json:"id"`
 	ProviderID int64     `json:"provider_id"`
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// DiscussionExport records an archive of a repository's issues, pull requests and comments
+// stored at Path on the storage provider record StorageProviderID. Since is the ExportedAt of
+// the export it continues from, or nil for a full export; the next export to the same storage
+// fetches what changed after ExportedAt.
+type DiscussionExport struct {
+	ID                int64      `json:"id"`
+	RepositoryID      int64      `json:"repository_id"`
+	StorageProviderID int64      `json:"storage_provider_id"`
+	Path              string     `json:"path"`
+	Since             *time.Time `json:"since"`
+	ExportedAt        time.Time  `json:"exported_at"`
+	Issues            int        `json:"issues"`
+	Comments          int        `json:"comments"`
+}
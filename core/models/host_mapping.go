@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// HostMapping is a user-defined rule assigning hosts to a provider type, consulted before
+// the built-in host rules. Pattern is an exact host, "*.domain" or "label.*".
+type HostMapping struct {
+	ID           int64     `json:"id"`
+	Pattern      string    `json:"pattern"`
+	ProviderType string    `json:"provider_type"`
+	APIURL       string    `json:"api_url"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
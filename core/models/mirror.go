@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Mirror modes.
+const (
+	// MirrorModeGitSyncer mirrors are cloned and pushed by GitSyncer itself.
+	MirrorModeGitSyncer = "gitsyncer"
+
+	// MirrorModeNative mirrors are push or pull mirrors configured on a provider that transfers
+	// the refs server-side.
+	MirrorModeNative = "native"
+)
+
+// Mirror is a destination a repository is mirrored to. TargetURL is the clone URL of the copy on
+// the provider record TargetProviderID. CredentialID authenticates against the remote end of the
+// transfer: the target for GitSyncer-driven and push mirrors, the source for pull mirrors. When
+// nil, the first credential of that end's provider is used.
+//
+// A GitSyncer-driven mirror with a TargetOwner creates its target on demand: the repository is
+// created under that user, organization or group with the source's name, TargetURL is filled in,
+// and the source's metadata is replicated to it on every sync.
+//
+// A GitSyncer-driven mirror with MirrorSubmodules also mirrors the repositories its synced refs
+// reference as submodules to the same target provider, registering repositories and mirrors for
+// them as needed, and records a SubmoduleMapping from each submodule URL to its mirror.
+//
+// Direction, IntervalSeconds, OnlyProtectedBranches and NativeID apply to native mirrors only.
+// A push mirror is configured on the source repository, a pull mirror is the target repository
+// itself. NativeID is the provider's ID of the mirror.
+type Mirror struct {
+	ID                    int64     `json:"id"`
+	RepositoryID          int64     `json:"repository_id"`
+	Mode                  string    `json:"mode"`
+	Direction             string    `json:"direction"`
+	TargetProviderID      int64     `json:"target_provider_id"`
+	TargetURL             string    `json:"target_url"`
+	TargetOwner           string    `json:"target_owner"`
+	CredentialID          *int64    `json:"credential_id"`
+	IntervalSeconds       int64     `json:"interval_seconds"`
+	OnlyProtectedBranches bool      `json:"only_protected_branches"`
+	MirrorSubmodules      bool      `json:"mirror_submodules"`
+	NativeID              string    `json:"native_id"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
@@ -3,11 +3,14 @@ package models
 import "time"
 
 // Provider represents a git hosting provider (GitHub, GitLab, Gitea, etc.).
+// Options are the provider-specific settings described by its type's configuration schema,
+// such as an Azure DevOps organization; they are validated when the provider is connected.
 type Provider struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Type      string    `json:"type"`
-	BaseURL   string    `json:"base_url"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int64             `json:"id"`
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	BaseURL   string            `json:"base_url"`
+	Options   map[string]string `json:"options"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
 }
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ReleaseAssetChecksum records the SHA-256 of a release asset on the provider record ProviderID
+// that does not report checksums itself, so that its contents are not downloaded again to compare
+// them. It applies while the asset keeps its DownloadURL and Size.
+type ReleaseAssetChecksum struct {
+	ProviderID  int64     `json:"provider_id"`
+	AssetID     string    `json:"asset_id"`
+	DownloadURL string    `json:"download_url"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
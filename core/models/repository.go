@@ -2,15 +2,37 @@ package models
 
 import "time"
 
+// Repository visibility values. Providers without an internal level report only public or private.
+const (
+	VisibilityPublic   = "public"
+	VisibilityInternal = "internal"
+	VisibilityPrivate  = "private"
+)
+
 // Repository represents a registered git repository linked to a provider.
+// LocalPath is the bare mirror clone on disk, set by CloneRepo and used by PushMirror.
+// Owner, Visibility, IsArchived, IsFork and PushedAt are provider metadata used for filtering;
+// they are left zero when the provider does not report them. Homepage and Topics are replicated
+// to mirrors along with the description, default branch and visibility. HasWiki reports a wiki
+// repository next to this one, reachable at WikiCloneURL.
 type Repository struct {
 	ID            int64      `json:"id"`
 	ProviderID    int64      `json:"provider_id"`
 	Name          string     `json:"name"`
 	CloneURL      string     `json:"clone_url"`
 	Description   string     `json:"description"`
+	Homepage      string     `json:"homepage"`
+	Topics        []string   `json:"topics"`
 	IsMirror      bool       `json:"is_mirror"`
+	HasWiki       bool       `json:"has_wiki"`
+	WikiCloneURL  string     `json:"wiki_clone_url"`
 	DefaultBranch string     `json:"default_branch"`
+	LocalPath     string     `json:"local_path"`
+	Owner         string     `json:"owner"`
+	Visibility    string     `json:"visibility"`
+	IsArchived    bool       `json:"is_archived"`
+	IsFork        bool       `json:"is_fork"`
+	PushedAt      *time.Time `json:"pushed_at"`
 	LastSyncedAt  *time.Time `json:"last_synced_at"`
 	CreatedAt     time.Time  `json:"created_at"`
 	UpdatedAt     time.Time  `json:"updated_at"`
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// SubmoduleMapping records that the submodule URL SourceURL, declared in the repository mirrored
+// by MirrorID, is mirrored to TargetURL by the mirror SubmoduleMirrorID. Rewriting SourceURL to
+// TargetURL when cloning the mirror makes it independent of the source's hosts. A submodule
+// GitSyncer does not mirror is recorded unmapped, with no SubmoduleMirrorID and TargetURL.
+type SubmoduleMapping struct {
+	ID                int64     `json:"id"`
+	MirrorID          int64     `json:"mirror_id"`
+	SubmoduleMirrorID *int64    `json:"submodule_mirror_id,omitempty"`
+	SourceURL         string    `json:"source_url"`
+	TargetURL         string    `json:"target_url"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
@@ -2,10 +2,19 @@ package models
 
 import "time"
 
+// Sync history statuses.
+const (
+	SyncStatusRunning = "running"
+	SyncStatusSuccess = "success"
+	SyncStatusFailed  = "failed"
+)
+
 // SyncHistory represents a single sync operation audit log entry.
+// MirrorID is the mirror the entry was recorded for, or nil for entries not tied to one.
 type SyncHistory struct {
 	ID           int64      `json:"id"`
 	RepositoryID int64      `json:"repository_id"`
+	MirrorID     *int64     `json:"mirror_id"`
 	Status       string     `json:"status"`
 	StartedAt    time.Time  `json:"started_at"`
 	FinishedAt   *time.Time `json:"finished_at"`
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Webhook delivery statuses.
+const (
+	// WebhookStatusQueued means a sync was enqueued for the matched repository.
+	WebhookStatusQueued = "queued"
+
+	// WebhookStatusIgnored is an authentic delivery for an event that does not trigger a sync, such as a ping.
+	WebhookStatusIgnored = "ignored"
+
+	// WebhookStatusUnmatched is an authentic push for a repository GitSyncer does not track.
+	WebhookStatusUnmatched = "unmatched"
+
+	// WebhookStatusRejected is a delivery whose signature or token did not verify.
+	WebhookStatusRejected = "rejected"
+
+	// WebhookStatusFailed is a delivery that could not be processed, e.g. while the vault is locked.
+	WebhookStatusFailed = "failed"
+)
+
+// WebhookDelivery records a webhook request received from a provider so it can be inspected
+// and replayed. Headers holds the provider's event, delivery and signature headers as JSON;
+// plain secret tokens are never recorded. Verified is set once the signature has been checked.
+type WebhookDelivery struct {
+	ID           int64      `json:"id"`
+	ProviderID   int64      `json:"provider_id"`
+	RepositoryID *int64     `json:"repository_id"`
+	DeliveryID   string     `json:"delivery_id"`
+	Event        string     `json:"event"`
+	Status       string     `json:"status"`
+	Verified     bool       `json:"verified"`
+	ErrorMessage string     `json:"error_message"`
+	Headers      string     `json:"headers"`
+	Payload      string     `json:"payload"`
+	ReceivedAt   time.Time  `json:"received_at"`
+	ProcessedAt  *time.Time `json:"processed_at"`
+}
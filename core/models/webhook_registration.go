@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// WebhookRegistration records a webhook GitSyncer created on a source repository. Its secret is
+// a credential of type AuthTypeWebhookSecret referenced by CredentialID. RepositoryID becomes nil
+// when the repository is removed, leaving the hook to be deleted by the next reconciliation;
+// CloneURL still addresses the repository on the provider then.
+type WebhookRegistration struct {
+	ID           int64     `json:"id"`
+	ProviderID   int64     `json:"provider_id"`
+	RepositoryID *int64    `json:"repository_id"`
+	CredentialID *int64    `json:"credential_id"`
+	CloneURL     string    `json:"clone_url"`
+	HookID       string    `json:"hook_id"`
+	URL          string    `json:"url"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,335 @@
+package azuredevops
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"GitSyncer/core/git"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/internal/httpapi"
+)
+
+const (
+	// DefaultHost is the Azure DevOps Services host; organizations live at https://dev.azure.com/{org}.
+	DefaultHost = "https://dev.azure.com"
+
+	// OptionOrganization names the organization when cfg.BaseURL does not include it.
+	OptionOrganization = "organization"
+
+	apiVersion = "7.1"
+
+	// gitUsername is ignored by Azure Repos when a PAT is supplied as the password, but must be non-empty.
+	gitUsername = "pat"
+)
+
+// Schema describes the configuration accepted by New. Either the base URL or the organization is required.
+var Schema = provider.ConfigSchema{
+	BaseURL: provider.OptionSpec{
+		Type: provider.OptionTypeURL,
+		Help: "Organization or collection URL, e.g. https://dev.azure.com/contoso. Leave empty to use the organization option.",
+	},
+	Options: []provider.OptionSpec{
+		{Name: OptionOrganization, Type: provider.OptionTypeString, Help: "Organization on dev.azure.com, used when the base URL is empty."},
+	},
+}
+
+var _ provider.SourceControlProvider = (*Provider)(nil)
+
+// Provider implements provider.SourceControlProvider for Azure Repos in Azure DevOps Services
+// and Azure DevOps Server. PATs use the "token" auth type; Microsoft Entra tokens use "oauth".
+type Provider struct {
+	api          *httpapi.Client
+	organization string
+
+	mu   sync.RWMutex
+	cred *models.Credential
+}
+
+// New creates an Azure DevOps provider. cfg.BaseURL is the organization (or on-premises collection) URL,
+// e.g. https://dev.azure.com/contoso or https://contoso.visualstudio.com. If it is empty,
+// the "organization" option is resolved against dev.azure.com.
+func New(cfg provider.ProviderConfig) (provider.SourceControlProvider, error) {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	org := cfg.Options[OptionOrganization]
+
+	if baseURL == "" {
+		if org == "" {
+			return nil, fmt.Errorf("azuredevops.New: base url or %q option is required", OptionOrganization)
+		}
+
+		baseURL = DefaultHost + "/" + url.PathEscape(org)
+	}
+
+	api, err := httpapi.NewClient(provider.ProviderAzureDevOps, baseURL, cfg.Transport)
+	if err != nil {
+		return nil, fmt.Errorf("azuredevops.New: %w", err)
+	}
+
+	if org == "" {
+		org = organizationFromBaseURL(api.BaseURL)
+	}
+
+	p := &Provider{api: api, organization: org}
+	api.Authorize = p.authorize
+
+	return p, nil
+}
+
+// Register adds the Azure DevOps factory to the registry.
+func Register(r *provider.ProviderRegistry) error {
+	if err := r.RegisterSourceControlProviderFactory(provider.ProviderAzureDevOps, New); err != nil {
+		return err
+	}
+
+	return r.RegisterSourceControlProviderSchema(provider.ProviderAzureDevOps, Schema)
+}
+
+// organizationFromBaseURL extracts the organization from dev.azure.com/{org} or {org}.visualstudio.com.
+func organizationFromBaseURL(u *url.URL) string {
+	host := strings.ToLower(u.Hostname())
+
+	if org, ok := strings.CutSuffix(host, ".visualstudio.com"); ok {
+		return org
+	}
+
+	first, _, _ := strings.Cut(strings.Trim(u.Path, "/"), "/")
+
+	return first
+}
+
+// Authenticate validates a PAT or OAuth token by listing the organization's projects.
+func (p *Provider) Authenticate(ctx context.Context, cred *models.Credential) error {
+	if cred == nil || (cred.AuthType != models.AuthTypeToken && cred.AuthType != models.AuthTypeOAuth) {
+		return &provider.AuthError{Provider: provider.ProviderAzureDevOps, Message: "a personal access token or oauth credential is required"}
+	}
+
+	p.mu.Lock()
+	p.cred = cred
+	p.mu.Unlock()
+
+	query := url.Values{"api-version": {apiVersion}, "$top": {"1"}}
+
+	resp, err := p.api.Get(ctx, "/_apis/projects", query, nil)
+	if err == nil && isSignInRedirect(resp) {
+		err = &provider.AuthError{Provider: provider.ProviderAzureDevOps, Message: "credential was not accepted"}
+	}
+
+	if err != nil {
+		p.mu.Lock()
+		p.cred = nil
+		p.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+// isSignInRedirect detects the HTML sign-in page Azure DevOps serves with 203 for invalid PATs.
+func isSignInRedirect(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusNonAuthoritativeInfo ||
+		strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html")
+}
+
+type apiProject struct {
+	Name string `json:"name"`
+}
+
+type apiRepo struct {
+	Name          string `json:"name"`
+	RemoteURL     string `json:"remoteUrl"`
+	DefaultBranch string `json:"defaultBranch"`
+	IsDisabled    bool   `json:"isDisabled"`
+	IsFork        bool   `json:"isFork"`
+	Project       struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Visibility  string `json:"visibility"`
+	} `json:"project"`
+}
+
+// ListRepos enumerates the repositories of every project in the organization. Disabled repositories are skipped.
+func (p *Provider) ListRepos(ctx context.Context) ([]models.Repository, error) {
+	if err := p.requireAuth(); err != nil {
+		return nil, err
+	}
+
+	projects, err := p.listProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []models.Repository
+
+	for _, project := range projects {
+		var page struct {
+			Value []apiRepo `json:"value"`
+		}
+
+		path := "/" + url.PathEscape(project.Name) + "/_apis/git/repositories"
+		if _, err := p.api.Get(ctx, path, url.Values{"api-version": {apiVersion}}, &page); err != nil {
+			return nil, err
+		}
+
+		for _, r := range page.Value {
+			if r.IsDisabled {
+				continue
+			}
+
+			repos = append(repos, models.Repository{
+				Name:          r.Project.Name + "/" + r.Name,
+				CloneURL:      stripUserInfo(r.RemoteURL),
+				Description:   r.Project.Description,
+				DefaultBranch: strings.TrimPrefix(r.DefaultBranch, "refs/heads/"),
+				Owner:         r.Project.Name,
+				Visibility:    strings.ToLower(r.Project.Visibility),
+				IsFork:        r.IsFork,
+			})
+		}
+	}
+
+	return repos, nil
+}
+
+// listProjects follows the x-ms-continuationtoken header across project pages.
+func (p *Provider) listProjects(ctx context.Context) ([]apiProject, error) {
+	var projects []apiProject
+
+	token := ""
+
+	for {
+		query := url.Values{"api-version": {apiVersion}, "$top": {"100"}}
+		if token != "" {
+			query.Set("continuationToken", token)
+		}
+
+		var page struct {
+			Value []apiProject `json:"value"`
+		}
+
+		resp, err := p.api.Get(ctx, "/_apis/projects", query, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		projects = append(projects, page.Value...)
+
+		token = resp.Header.Get("X-Ms-Continuationtoken")
+		if token == "" {
+			return projects, nil
+		}
+	}
+}
+
+func stripUserInfo(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	u.User = nil
+
+	return u.String()
+}
+
+// CloneRepo creates or updates a bare mirror of repo at destPath and records it as repo.LocalPath.
+func (p *Provider) CloneRepo(ctx context.Context, repo *models.Repository, destPath string) error {
+	auth, err := p.gitAuth()
+	if err != nil {
+		return err
+	}
+
+	if err := git.CloneMirror(ctx, repo.CloneURL, destPath, auth); err != nil {
+		return fmt.Errorf("azuredevops: clone %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderAzureDevOps, err))
+	}
+
+	repo.LocalPath = destPath
+
+	return nil
+}
+
+// PushMirror pushes the local mirror of repo to remoteURL in this organization.
+func (p *Provider) PushMirror(ctx context.Context, repo *models.Repository, remoteURL string) error {
+	auth, err := p.gitAuth()
+	if err != nil {
+		return err
+	}
+
+	if err := git.PushMirror(ctx, repo.LocalPath, remoteURL, auth); err != nil {
+		return fmt.Errorf("azuredevops: push mirror %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderAzureDevOps, err))
+	}
+
+	return nil
+}
+
+// ValidateURL reports whether url is an Azure Repos URL of this provider's organization.
+func (p *Provider) ValidateURL(rawURL string) bool {
+	ref, ok := ParseURL(rawURL)
+	if !ok {
+		return provider.ExtractHost(rawURL) == strings.ToLower(p.api.BaseURL.Hostname())
+	}
+
+	return strings.EqualFold(ref.Organization, p.organization)
+}
+
+func (p *Provider) GetProviderType() provider.ProviderType {
+	return provider.ProviderAzureDevOps
+}
+
+func (p *Provider) Capabilities() []provider.SourceControlProviderCapability {
+	return []provider.SourceControlProviderCapability{
+		provider.CapabilityAPI,
+		provider.CapabilityTokenAuth,
+		provider.CapabilityOAuth,
+		provider.CapabilityWebhooks,
+	}
+}
+
+// authorize sends PATs as basic auth with an empty username, as Azure DevOps requires.
+func (p *Provider) authorize(req *http.Request) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred == nil {
+		return
+	}
+
+	if p.cred.AuthType == models.AuthTypeOAuth {
+		req.Header.Set("Authorization", "Bearer "+p.cred.AuthData)
+
+		return
+	}
+
+	req.SetBasicAuth("", p.cred.AuthData)
+}
+
+func (p *Provider) requireAuth() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred == nil {
+		return &provider.AuthError{Provider: provider.ProviderAzureDevOps, Message: "not authenticated"}
+	}
+
+	return nil
+}
+
+func (p *Provider) gitAuth() (*git.Auth, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred == nil {
+		return nil, nil
+	}
+
+	if p.cred.AuthType == models.AuthTypeOAuth {
+		return &git.Auth{BearerToken: p.cred.AuthData}, nil
+	}
+
+	return git.AuthFromCredential(p.cred, gitUsername)
+}
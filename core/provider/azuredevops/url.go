@@ -0,0 +1,71 @@
+package azuredevops
+
+import (
+	"net/url"
+	"strings"
+
+	"GitSyncer/core/provider"
+)
+
+// RepoRef identifies an Azure Repos repository.
+type RepoRef struct {
+	Organization string
+	Project      string
+	Repository   string
+}
+
+// ParseURL parses the Azure Repos URL shapes:
+//
+//	https://dev.azure.com/{org}/{project}/_git/{repo}
+//	https://{org}.visualstudio.com[/DefaultCollection]/{project}/_git/{repo}
+//	git@ssh.dev.azure.com:v3/{org}/{project}/{repo}
+//	{org}@vs-ssh.visualstudio.com:v3/{org}/{project}/{repo}
+func ParseURL(rawURL string) (RepoRef, bool) {
+	host := provider.ExtractHost(rawURL)
+	path := provider.ExtractRepoPath(rawURL)
+
+	if host == "" || path == "" {
+		return RepoRef{}, false
+	}
+
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if unescaped, err := url.PathUnescape(s); err == nil {
+			segments[i] = unescaped
+		}
+	}
+
+	switch {
+	case host == "ssh.dev.azure.com" || host == "vs-ssh.visualstudio.com":
+		// v3/{org}/{project}/{repo}
+		if len(segments) != 4 || segments[0] != "v3" {
+			return RepoRef{}, false
+		}
+
+		return RepoRef{Organization: segments[1], Project: segments[2], Repository: segments[3]}, true
+
+	case host == "dev.azure.com":
+		// {org}/{project}/_git/{repo}
+		if len(segments) != 4 || segments[2] != "_git" {
+			return RepoRef{}, false
+		}
+
+		return RepoRef{Organization: segments[0], Project: segments[1], Repository: segments[3]}, true
+
+	case strings.HasSuffix(host, ".visualstudio.com"):
+		// [DefaultCollection/]{project}/_git/{repo}
+		if len(segments) == 4 && strings.EqualFold(segments[0], "DefaultCollection") {
+			segments = segments[1:]
+		}
+
+		if len(segments) != 3 || segments[1] != "_git" {
+			return RepoRef{}, false
+		}
+
+		org := strings.TrimSuffix(host, ".visualstudio.com")
+
+		return RepoRef{Organization: org, Project: segments[0], Repository: segments[2]}, true
+	}
+
+	return RepoRef{}, false
+}
@@ -0,0 +1,102 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"GitSyncer/core/git"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+)
+
+// Register adds the Bitbucket Cloud and Bitbucket Data Center factories to the registry.
+func Register(r *provider.ProviderRegistry) error {
+	if err := r.RegisterSourceControlProviderFactory(provider.ProviderBitbucketCloud, NewCloud); err != nil {
+		return err
+	}
+
+	if err := r.RegisterSourceControlProviderFactory(provider.ProviderBitbucketDataCenter, NewDataCenter); err != nil {
+		return err
+	}
+
+	if err := r.RegisterSourceControlProviderSchema(provider.ProviderBitbucketCloud, CloudSchema); err != nil {
+		return err
+	}
+
+	return r.RegisterSourceControlProviderSchema(provider.ProviderBitbucketDataCenter, DataCenterSchema)
+}
+
+// session holds the credential shared by both Bitbucket flavours.
+type session struct {
+	pt provider.ProviderType
+
+	mu   sync.RWMutex
+	cred *models.Credential
+}
+
+func (s *session) set(cred *models.Credential) {
+	s.mu.Lock()
+	s.cred = cred
+	s.mu.Unlock()
+}
+
+func (s *session) get() *models.Credential {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.cred
+}
+
+func (s *session) requireAuth() error {
+	if s.get() == nil {
+		return &provider.AuthError{Provider: s.pt, Message: "not authenticated"}
+	}
+
+	return nil
+}
+
+// cloneRepo creates or updates a bare mirror of repo at destPath and records it as repo.LocalPath.
+func (s *session) cloneRepo(ctx context.Context, repo *models.Repository, destPath string, auth *git.Auth) error {
+	if err := git.CloneMirror(ctx, repo.CloneURL, destPath, auth); err != nil {
+		return fmt.Errorf("%s: clone %s: %w", s.pt, repo.Name, provider.ClassifyGitError(s.pt, err))
+	}
+
+	repo.LocalPath = destPath
+
+	return nil
+}
+
+func (s *session) pushMirror(ctx context.Context, repo *models.Repository, remoteURL string, auth *git.Auth) error {
+	if err := git.PushMirror(ctx, repo.LocalPath, remoteURL, auth); err != nil {
+		return fmt.Errorf("%s: push mirror %s: %w", s.pt, repo.Name, provider.ClassifyGitError(s.pt, err))
+	}
+
+	return nil
+}
+
+type cloneLink struct {
+	Href string `json:"href"`
+	Name string `json:"name"`
+}
+
+// httpCloneURL picks the HTTP(S) clone link and strips the embedded username Bitbucket adds to it.
+func httpCloneURL(links []cloneLink) string {
+	for _, l := range links {
+		if l.Name != "https" && l.Name != "http" {
+			continue
+		}
+
+		u, err := url.Parse(l.Href)
+		if err != nil {
+			return l.Href
+		}
+
+		u.User = nil
+
+		return u.String()
+	}
+
+	return ""
+}
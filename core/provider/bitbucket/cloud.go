@@ -0,0 +1,284 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"GitSyncer/core/git"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/internal/httpapi"
+)
+
+const (
+	// DefaultCloudBaseURL is the Bitbucket Cloud REST API.
+	DefaultCloudBaseURL = "https://api.bitbucket.org/2.0"
+
+	// OptionWorkspace limits Bitbucket Cloud listing to a single workspace. It is required
+	// for workspace and repository access tokens, which cannot enumerate workspaces.
+	OptionWorkspace = "workspace"
+
+	// cloudTokenUsername is the git username Bitbucket Cloud expects for access tokens.
+	cloudTokenUsername = "x-token-auth"
+
+	cloudPageLen = 100
+)
+
+// CloudSchema describes the configuration accepted by NewCloud.
+var CloudSchema = provider.ConfigSchema{
+	BaseURL: provider.OptionSpec{
+		Type:    provider.OptionTypeURL,
+		Default: DefaultCloudBaseURL,
+		Help:    "REST API URL.",
+	},
+	Options: []provider.OptionSpec{
+		{Name: OptionWorkspace, Type: provider.OptionTypeString, Help: "List only this workspace. Required for workspace and repository access tokens."},
+	},
+}
+
+var _ provider.SourceControlProvider = (*CloudProvider)(nil)
+
+// CloudProvider implements provider.SourceControlProvider for Bitbucket Cloud.
+// App passwords use the "basic" auth type ("username:app_password"); workspace, project and
+// repository access tokens use "token"; OAuth tokens use "oauth".
+type CloudProvider struct {
+	session
+
+	api       *httpapi.Client
+	workspace string
+}
+
+// NewCloud creates a Bitbucket Cloud provider. An empty cfg.BaseURL targets api.bitbucket.org.
+func NewCloud(cfg provider.ProviderConfig) (provider.SourceControlProvider, error) {
+	baseURL := cfg.BaseURL
+	if baseURL == "" || provider.ExtractHost(baseURL) == "bitbucket.org" {
+		baseURL = DefaultCloudBaseURL
+	}
+
+	api, err := httpapi.NewClient(provider.ProviderBitbucketCloud, baseURL, cfg.Transport)
+	if err != nil {
+		return nil, fmt.Errorf("bitbucket.NewCloud: %w", err)
+	}
+
+	p := &CloudProvider{
+		session:   session{pt: provider.ProviderBitbucketCloud},
+		api:       api,
+		workspace: cfg.Options[OptionWorkspace],
+	}
+	api.Authorize = p.authorize
+
+	return p, nil
+}
+
+// Authenticate validates the credential against /user, or against the configured workspace
+// for access tokens that are not tied to a user.
+func (p *CloudProvider) Authenticate(ctx context.Context, cred *models.Credential) error {
+	if err := checkAuthType(provider.ProviderBitbucketCloud, cred); err != nil {
+		return err
+	}
+
+	p.set(cred)
+
+	path := "/user"
+	if p.workspace != "" {
+		path = "/repositories/" + url.PathEscape(p.workspace)
+	}
+
+	if _, err := p.api.Get(ctx, path, url.Values{"pagelen": {"1"}}, nil); err != nil {
+		p.set(nil)
+
+		return err
+	}
+
+	return nil
+}
+
+type cloudRepo struct {
+	FullName    string `json:"full_name"`
+	Description string `json:"description"`
+	MainBranch  struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+	Links struct {
+		Clone []cloneLink `json:"clone"`
+	} `json:"links"`
+	Workspace struct {
+		Slug string `json:"slug"`
+	} `json:"workspace"`
+	IsPrivate bool `json:"is_private"`
+	Parent    *struct {
+		FullName string `json:"full_name"`
+	} `json:"parent"`
+	UpdatedOn *time.Time `json:"updated_on"`
+}
+
+// ListRepos returns the repositories of every workspace the user can access,
+// or of the configured workspace only.
+func (p *CloudProvider) ListRepos(ctx context.Context) ([]models.Repository, error) {
+	if err := p.requireAuth(); err != nil {
+		return nil, err
+	}
+
+	workspaces := []string{p.workspace}
+
+	if p.workspace == "" {
+		var err error
+
+		workspaces, err = p.listWorkspaces(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var repos []models.Repository
+
+	for _, ws := range workspaces {
+		err := paginateCloud(ctx, p.api, "/repositories/"+url.PathEscape(ws), func(page []cloudRepo) {
+			for _, r := range page {
+				visibility := models.VisibilityPublic
+				if r.IsPrivate {
+					visibility = models.VisibilityPrivate
+				}
+
+				// Bitbucket has no push timestamp; the last update stands in for PushedAt.
+				repos = append(repos, models.Repository{
+					Name:          r.FullName,
+					CloneURL:      httpCloneURL(r.Links.Clone),
+					Description:   r.Description,
+					DefaultBranch: r.MainBranch.Name,
+					Owner:         r.Workspace.Slug,
+					Visibility:    visibility,
+					IsFork:        r.Parent != nil,
+					PushedAt:      r.UpdatedOn,
+				})
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return repos, nil
+}
+
+func (p *CloudProvider) listWorkspaces(ctx context.Context) ([]string, error) {
+	type membership struct {
+		Workspace struct {
+			Slug string `json:"slug"`
+		} `json:"workspace"`
+	}
+
+	var slugs []string
+
+	err := paginateCloud(ctx, p.api, "/user/permissions/workspaces", func(page []membership) {
+		for _, m := range page {
+			slugs = append(slugs, m.Workspace.Slug)
+		}
+	})
+
+	return slugs, err
+}
+
+// paginateCloud follows the "next" links embedded in Bitbucket Cloud paged responses.
+func paginateCloud[T any](ctx context.Context, api *httpapi.Client, path string, fn func([]T)) error {
+	next := path
+	query := url.Values{"pagelen": {fmt.Sprint(cloudPageLen)}}
+
+	for next != "" {
+		var page struct {
+			Values []T    `json:"values"`
+			Next   string `json:"next"`
+		}
+
+		if _, err := api.Get(ctx, next, query, &page); err != nil {
+			return err
+		}
+
+		fn(page.Values)
+
+		next = page.Next
+		query = nil
+	}
+
+	return nil
+}
+
+func (p *CloudProvider) CloneRepo(ctx context.Context, repo *models.Repository, destPath string) error {
+	auth, err := p.gitAuth()
+	if err != nil {
+		return err
+	}
+
+	return p.cloneRepo(ctx, repo, destPath, auth)
+}
+
+func (p *CloudProvider) PushMirror(ctx context.Context, repo *models.Repository, remoteURL string) error {
+	auth, err := p.gitAuth()
+	if err != nil {
+		return err
+	}
+
+	return p.pushMirror(ctx, repo, remoteURL, auth)
+}
+
+// ValidateURL reports whether url points at bitbucket.org.
+func (p *CloudProvider) ValidateURL(rawURL string) bool {
+	return provider.ExtractHost(rawURL) == "bitbucket.org"
+}
+
+func (p *CloudProvider) GetProviderType() provider.ProviderType {
+	return provider.ProviderBitbucketCloud
+}
+
+func (p *CloudProvider) Capabilities() []provider.SourceControlProviderCapability {
+	return []provider.SourceControlProviderCapability{
+		provider.CapabilityAPI,
+		provider.CapabilityTokenAuth,
+		provider.CapabilityOAuth,
+		provider.CapabilityWebhooks,
+	}
+}
+
+func (p *CloudProvider) authorize(req *http.Request) {
+	cred := p.get()
+	if cred == nil {
+		return
+	}
+
+	if cred.AuthType == models.AuthTypeBasic {
+		username, password, _ := strings.Cut(cred.AuthData, ":")
+		req.SetBasicAuth(username, password)
+
+		return
+	}
+
+	req.Header.Set("Authorization", "Bearer "+cred.AuthData)
+}
+
+func (p *CloudProvider) gitAuth() (*git.Auth, error) {
+	return git.AuthFromCredential(p.get(), cloudTokenUsername)
+}
+
+// checkAuthType accepts token, OAuth and "username:password" basic credentials.
+func checkAuthType(pt provider.ProviderType, cred *models.Credential) error {
+	if cred == nil {
+		return &provider.AuthError{Provider: pt, Message: "a credential is required"}
+	}
+
+	switch cred.AuthType {
+	case models.AuthTypeToken, models.AuthTypeOAuth:
+		return nil
+	case models.AuthTypeBasic:
+		if strings.Contains(cred.AuthData, ":") {
+			return nil
+		}
+
+		return &provider.AuthError{Provider: pt, Message: "basic auth data must be username:password"}
+	default:
+		return &provider.AuthError{Provider: pt, Message: "unsupported auth type " + cred.AuthType}
+	}
+}
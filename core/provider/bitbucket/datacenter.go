@@ -0,0 +1,231 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"GitSyncer/core/git"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/internal/httpapi"
+)
+
+const (
+	// OptionProject limits Bitbucket Data Center listing to a single project key.
+	OptionProject = "project"
+
+	dataCenterPageLimit = 100
+)
+
+// DataCenterSchema describes the configuration accepted by NewDataCenter.
+var DataCenterSchema = provider.ConfigSchema{
+	BaseURL: provider.OptionSpec{
+		Type:     provider.OptionTypeURL,
+		Required: true,
+		Help:     "Instance URL, e.g. https://bitbucket.example.com.",
+	},
+	Options: []provider.OptionSpec{
+		{Name: OptionProject, Type: provider.OptionTypeString, Help: "List only this project key."},
+	},
+}
+
+var _ provider.SourceControlProvider = (*DataCenterProvider)(nil)
+
+// DataCenterProvider implements provider.SourceControlProvider for Bitbucket Data Center (and Server).
+// HTTP access tokens use the "token" auth type and are sent as bearer tokens; "basic" credentials
+// are sent as username and password.
+type DataCenterProvider struct {
+	session
+
+	api     *httpapi.Client
+	host    string
+	project string
+}
+
+// NewDataCenter creates a Bitbucket Data Center provider. cfg.BaseURL is the instance URL and is required.
+func NewDataCenter(cfg provider.ProviderConfig) (provider.SourceControlProvider, error) {
+	if cfg.BaseURL == "" {
+		return nil, fmt.Errorf("bitbucket.NewDataCenter: base url is required")
+	}
+
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	baseURL = strings.TrimSuffix(baseURL, "/rest/api/1.0")
+
+	api, err := httpapi.NewClient(provider.ProviderBitbucketDataCenter, baseURL+"/rest/api/1.0", cfg.Transport)
+	if err != nil {
+		return nil, fmt.Errorf("bitbucket.NewDataCenter: %w", err)
+	}
+
+	p := &DataCenterProvider{
+		session: session{pt: provider.ProviderBitbucketDataCenter},
+		api:     api,
+		host:    strings.ToLower(api.BaseURL.Hostname()),
+		project: cfg.Options[OptionProject],
+	}
+	api.Authorize = p.authorize
+
+	return p, nil
+}
+
+// Authenticate validates the credential by listing a single repository; anonymous access is rejected.
+func (p *DataCenterProvider) Authenticate(ctx context.Context, cred *models.Credential) error {
+	if err := checkAuthType(provider.ProviderBitbucketDataCenter, cred); err != nil {
+		return err
+	}
+
+	p.set(cred)
+
+	resp, err := p.api.Get(ctx, p.reposPath(), url.Values{"limit": {"1"}}, nil)
+	if err != nil {
+		p.set(nil)
+
+		return err
+	}
+
+	// Data Center answers anonymous requests on public instances; X-AUSERNAME is only set for a valid login.
+	if resp.Header.Get("X-AUSERNAME") == "" && resp.Header.Get("X-AUSERID") == "" {
+		p.set(nil)
+
+		return &provider.AuthError{Provider: provider.ProviderBitbucketDataCenter, Message: "credential was not accepted"}
+	}
+
+	return nil
+}
+
+func (p *DataCenterProvider) reposPath() string {
+	if p.project != "" {
+		return "/projects/" + url.PathEscape(p.project) + "/repos"
+	}
+
+	return "/repos"
+}
+
+type dataCenterRepo struct {
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+	Project     struct {
+		Key string `json:"key"`
+	} `json:"project"`
+	Links struct {
+		Clone []cloneLink `json:"clone"`
+	} `json:"links"`
+	Public   bool `json:"public"`
+	Archived bool `json:"archived"`
+	Origin   *struct {
+		Slug string `json:"slug"`
+	} `json:"origin"`
+}
+
+// ListRepos returns every repository visible to the credential across all projects,
+// or the repositories of the configured project only.
+func (p *DataCenterProvider) ListRepos(ctx context.Context) ([]models.Repository, error) {
+	if err := p.requireAuth(); err != nil {
+		return nil, err
+	}
+
+	var repos []models.Repository
+
+	start := 0
+
+	for {
+		var page struct {
+			Values        []dataCenterRepo `json:"values"`
+			IsLastPage    bool             `json:"isLastPage"`
+			NextPageStart int              `json:"nextPageStart"`
+		}
+
+		query := url.Values{
+			"limit": {fmt.Sprint(dataCenterPageLimit)},
+			"start": {fmt.Sprint(start)},
+		}
+
+		if _, err := p.api.Get(ctx, p.reposPath(), query, &page); err != nil {
+			return nil, err
+		}
+
+		for _, r := range page.Values {
+			visibility := models.VisibilityPrivate
+			if r.Public {
+				visibility = models.VisibilityPublic
+			}
+
+			repos = append(repos, models.Repository{
+				Name:        r.Project.Key + "/" + r.Slug,
+				CloneURL:    httpCloneURL(r.Links.Clone),
+				Description: r.Description,
+				Owner:       r.Project.Key,
+				Visibility:  visibility,
+				IsArchived:  r.Archived,
+				IsFork:      r.Origin != nil,
+			})
+		}
+
+		if page.IsLastPage || len(page.Values) == 0 {
+			break
+		}
+
+		start = page.NextPageStart
+	}
+
+	return repos, nil
+}
+
+func (p *DataCenterProvider) CloneRepo(ctx context.Context, repo *models.Repository, destPath string) error {
+	return p.cloneRepo(ctx, repo, destPath, p.gitAuth())
+}
+
+func (p *DataCenterProvider) PushMirror(ctx context.Context, repo *models.Repository, remoteURL string) error {
+	return p.pushMirror(ctx, repo, remoteURL, p.gitAuth())
+}
+
+// ValidateURL reports whether url points at this Data Center instance.
+func (p *DataCenterProvider) ValidateURL(rawURL string) bool {
+	return provider.ExtractHost(rawURL) == p.host
+}
+
+func (p *DataCenterProvider) GetProviderType() provider.ProviderType {
+	return provider.ProviderBitbucketDataCenter
+}
+
+func (p *DataCenterProvider) Capabilities() []provider.SourceControlProviderCapability {
+	return []provider.SourceControlProviderCapability{
+		provider.CapabilityAPI,
+		provider.CapabilityTokenAuth,
+		provider.CapabilityWebhooks,
+	}
+}
+
+func (p *DataCenterProvider) authorize(req *http.Request) {
+	cred := p.get()
+	if cred == nil {
+		return
+	}
+
+	if cred.AuthType == models.AuthTypeBasic {
+		username, password, _ := strings.Cut(cred.AuthData, ":")
+		req.SetBasicAuth(username, password)
+
+		return
+	}
+
+	req.Header.Set("Authorization", "Bearer "+cred.AuthData)
+}
+
+// gitAuth sends HTTP access tokens as bearer tokens, which Data Center accepts for git over HTTPS.
+func (p *DataCenterProvider) gitAuth() *git.Auth {
+	cred := p.get()
+	if cred == nil {
+		return nil
+	}
+
+	if cred.AuthType == models.AuthTypeBasic {
+		username, password, _ := strings.Cut(cred.AuthData, ":")
+
+		return &git.Auth{Username: username, Password: password}
+	}
+
+	return &git.Auth{BearerToken: cred.AuthData}
+}
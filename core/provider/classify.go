@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"GitSyncer/core/git"
+)
+
+// ErrorFromStatus maps an HTTP error status returned by a provider API to the matching error
+// type. message is the response body or a summary of it; retryAfter is the server's hint, if
+// any. It returns nil for statuses without a typed error, such as 400 and 422.
+func ErrorFromStatus(pt ProviderType, statusCode int, message string, retryAfter time.Duration) error {
+	msg := strings.ToLower(http.StatusText(statusCode))
+	if msg == "" {
+		msg = fmt.Sprintf("status %d", statusCode)
+	}
+
+	if message != "" {
+		msg += ": " + message
+	}
+
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return &AuthError{Provider: pt, Message: msg}
+	case http.StatusForbidden:
+		return &PermissionDeniedError{Provider: pt, Message: msg}
+	case http.StatusNotFound, http.StatusGone:
+		return &NotFoundError{Provider: pt, Message: msg}
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return &ConflictError{Provider: pt, Message: msg}
+	case http.StatusRequestEntityTooLarge:
+		return &TooLargeError{Provider: pt, Message: msg}
+	case http.StatusTooManyRequests:
+		return &RateLimitError{Provider: pt, RetryAfter: retryAfter, Message: msg}
+	}
+
+	if statusCode >= 500 {
+		return &UnavailableError{Provider: pt, RetryAfter: retryAfter, Message: msg}
+	}
+
+	return nil
+}
+
+// gitFailure maps fragments of git and ssh stderr to an error constructor. Fragments are
+// matched case-insensitively, in order, so more specific entries come first.
+type gitFailure struct {
+	pattern *regexp.Regexp
+	wrap    func(pt ProviderType, err error) error
+}
+
+func gitPattern(fragments ...string) *regexp.Regexp {
+	for i, f := range fragments {
+		fragments[i] = regexp.QuoteMeta(strings.ToLower(f))
+	}
+
+	return regexp.MustCompile(strings.Join(fragments, "|"))
+}
+
+var gitFailures = []gitFailure{
+	{gitPattern(
+		"returned error: 429",
+		"http 429",
+	), func(pt ProviderType, err error) error {
+		return &RateLimitError{Provider: pt, Message: "rate limited", Err: err}
+	}},
+	{gitPattern(
+		"Permission denied (publickey",
+		"Authentication failed",
+		"could not read Username",
+		"could not read Password",
+		"HTTP Basic: Access denied",
+		"Invalid username or password",
+		"returned error: 401",
+		"Host key verification failed",
+	), func(pt ProviderType, err error) error {
+		return &AuthError{Provider: pt, Message: "credentials rejected", Err: err}
+	}},
+	{gitPattern(
+		"Large files detected",
+		"exceeds GitHub's file size limit",
+		"exceeds maximum allowed size",
+		"exceeds the limit",
+		"file is too large",
+		"repository size limit",
+		"returned error: 413",
+		"http 413",
+	), func(pt ProviderType, err error) error {
+		return &TooLargeError{Provider: pt, Message: "size limit exceeded", Err: err}
+	}},
+	{gitPattern(
+		"non-fast-forward",
+		"(fetch first)",
+		"(stale info)",
+		"cannot lock ref",
+		"failed to update ref",
+		"updates were rejected",
+	), func(pt ProviderType, err error) error {
+		return &ConflictError{Provider: pt, Message: "remote rejected the update", Err: err}
+	}},
+	{gitPattern(
+		"Permission denied",
+		"Permission to",
+		"not allowed to push",
+		"not allowed to force push",
+		"protected branch",
+		"pre-receive hook declined",
+		"Write access to repository not granted",
+		"returned error: 403",
+	), func(pt ProviderType, err error) error {
+		return &PermissionDeniedError{Provider: pt, Message: "operation not permitted", Err: err}
+	}},
+	{regexp.MustCompile(strings.Join([]string{
+		`repository '[^']*' not found`,
+		`repository '[^']*' does not exist`,
+		`repository not found`,
+		`does not appear to be a git repository`,
+		`could not be found`,
+		`returned error: 404`,
+	}, "|")), func(pt ProviderType, err error) error {
+		return &NotFoundError{Provider: pt, Message: "repository not found", Err: err}
+	}},
+	{gitPattern(
+		"returned error: 500",
+		"returned error: 502",
+		"returned error: 503",
+		"returned error: 504",
+		"http 502",
+		"http 503",
+		"http 504",
+	), func(pt ProviderType, err error) error {
+		return &UnavailableError{Provider: pt, Message: "server unavailable", Err: err}
+	}},
+	{gitPattern(
+		"Could not resolve host",
+		"Connection refused",
+		"Connection timed out",
+		"Operation timed out",
+		"Network is unreachable",
+		"Failed to connect",
+		"Connection reset",
+		"remote end hung up unexpectedly",
+		"early EOF",
+	), func(pt ProviderType, err error) error {
+		return &NetworkError{Provider: pt, Message: "host unreachable", Err: err}
+	}},
+}
+
+// ClassifyGitError maps a failed git or ssh command to the matching provider error type by
+// inspecting its stderr. Errors that are not git command failures, or whose cause is unclear,
+// are returned unchanged.
+func ClassifyGitError(pt ProviderType, err error) error {
+	var cmdErr *git.CommandError
+	if !errors.As(err, &cmdErr) {
+		return err
+	}
+
+	stderr := strings.ToLower(cmdErr.Stderr)
+
+	for _, f := range gitFailures {
+		if f.pattern.MatchString(stderr) {
+			return f.wrap(pt, err)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,160 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// OptionType is the value type of a provider option. All values are stored as strings.
+type OptionType string
+
+const (
+	OptionTypeString OptionType = "string"
+	OptionTypeBool   OptionType = "bool"
+	OptionTypeInt    OptionType = "int"
+	OptionTypeURL    OptionType = "url"
+
+	// OptionTypePath is an absolute filesystem path or file:// URL.
+	OptionTypePath OptionType = "path"
+
+	// OptionTypeList is a newline- or comma-separated list.
+	OptionTypeList OptionType = "list"
+)
+
+// OptionSpec describes one configuration field accepted by a provider factory.
+type OptionSpec struct {
+	Name     string     `json:"name"`
+	Type     OptionType `json:"type"`
+	Required bool       `json:"required"`
+
+	// Default is applied when the option is missing or empty.
+	Default string `json:"default,omitempty"`
+
+	// Secret options are masked in the UI and must not be logged.
+	Secret bool `json:"secret"`
+
+	Help string `json:"help"`
+}
+
+// ConfigSchema describes the ProviderConfig a factory accepts, so setup forms can be rendered
+// generically and configs validated before the factory runs.
+type ConfigSchema struct {
+	// BaseURL describes ProviderConfig.BaseURL; its Name is always "base_url".
+	BaseURL OptionSpec `json:"base_url"`
+
+	Options []OptionSpec `json:"options"`
+}
+
+// ConfigError reports a ProviderConfig field that does not satisfy the factory's schema.
+type ConfigError struct {
+	Provider ProviderType
+	Field    string
+	Message  string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Provider, e.Field, e.Message)
+}
+
+// Validate checks cfg against the schema and returns a copy with defaults applied and bool
+// options normalised to "true" or "false". Unknown options are rejected. All problems are
+// reported together as ConfigErrors joined with errors.Join.
+func (s ConfigSchema) Validate(cfg ProviderConfig) (ProviderConfig, error) {
+	out := cfg
+	out.Options = maps.Clone(cfg.Options)
+
+	if out.Options == nil {
+		out.Options = map[string]string{}
+	}
+
+	var errs []error
+
+	baseSpec := s.BaseURL
+	baseSpec.Name = "base_url"
+
+	baseURL, err := baseSpec.check(cfg.Type, cfg.BaseURL)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	out.BaseURL = baseURL
+
+	known := make(map[string]bool, len(s.Options))
+
+	for _, spec := range s.Options {
+		known[spec.Name] = true
+
+		value, err := spec.check(cfg.Type, out.Options[spec.Name])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if value == "" {
+			delete(out.Options, spec.Name)
+		} else {
+			out.Options[spec.Name] = value
+		}
+	}
+
+	for name := range cfg.Options {
+		if !known[name] {
+			errs = append(errs, &ConfigError{Provider: cfg.Type, Field: name, Message: "unknown option"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return cfg, errors.Join(errs...)
+	}
+
+	return out, nil
+}
+
+// check validates a single value, returning it with the default applied.
+func (spec OptionSpec) check(pt ProviderType, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		value = spec.Default
+	}
+
+	if value == "" {
+		if spec.Required {
+			return "", &ConfigError{Provider: pt, Field: spec.Name, Message: "is required"}
+		}
+
+		return "", nil
+	}
+
+	fail := func(msg string) (string, error) {
+		return "", &ConfigError{Provider: pt, Field: spec.Name, Message: msg}
+	}
+
+	switch spec.Type {
+	case OptionTypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fail("must be true or false")
+		}
+
+		return strconv.FormatBool(b), nil
+	case OptionTypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fail("must be an integer")
+		}
+	case OptionTypeURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fail("must be an absolute URL")
+		}
+	case OptionTypePath:
+		if _, ok := LocalPath(value); !ok {
+			return fail("must be an absolute path or file:// URL")
+		}
+	}
+
+	return value, nil
+}
@@ -2,62 +2,94 @@ package provider
 
 import (
 	"net/url"
+	"path/filepath"
 	"strings"
 )
 
-// urlPatterns maps hostname substrings to provider types.
-var urlPatterns = []struct {
-	pattern      string
-	providerType ProviderType
-}{
-	{"github.com", ProviderGitHub},
-	{"gitlab.com", ProviderGitLab},
-	{"gitlab.", ProviderGitLab},
-	{"gitea.com", ProviderGitea},
-	{"gitea.", ProviderGitea},
-}
-
-// DetectProviderType attempts to determine the provider type from a repository URL.
-// Returns an empty ProviderType if the provider cannot be determined.
+// DetectProviderType attempts to determine the provider type from a repository URL using
+// the built-in host rules. Returns an empty ProviderType if the provider cannot be determined.
 func DetectProviderType(rawURL string) ProviderType {
-	if rawURL == "" {
+	if _, ok := LocalPath(rawURL); ok {
+		return ProviderLocal
+	}
+
+	host := ExtractHost(rawURL)
+	if host == "" {
 		return ""
 	}
 
-	// Handle SSH URLs (e.g., git@github.com:user/repo.git)
-	if strings.HasPrefix(rawURL, "git@") {
-		host := extractSSHHost(rawURL)
-		return matchHost(host)
+	return matchHost(host)
+}
+
+// ResolveProviderType is DetectProviderType with a fallback to ProviderPlainGit for any URL
+// with a recognisable host, so every git remote can be used as a mirror source or target.
+func ResolveProviderType(rawURL string) ProviderType {
+	if pt := DetectProviderType(rawURL); pt != "" {
+		return pt
 	}
 
-	parsed, err := url.Parse(rawURL)
-	if err != nil {
-		return ""
+	if ExtractHost(rawURL) != "" {
+		return ProviderPlainGit
 	}
 
-	host := strings.ToLower(parsed.Hostname())
+	return ""
+}
 
-	return matchHost(host)
+// LocalPath returns the filesystem path of a file:// URL or an absolute path.
+// Relative paths are rejected because they are ambiguous with scp-style remotes.
+func LocalPath(rawURL string) (string, bool) {
+	if rest, ok := strings.CutPrefix(rawURL, "file://"); ok {
+		u, err := url.Parse(rawURL)
+		if err != nil || (u.Host != "" && u.Host != "localhost") {
+			return "", false
+		}
+
+		path := u.Path
+		if path == "" {
+			path = rest
+		}
+
+		// file:///C:/repos -> C:/repos on Windows.
+		if len(path) > 2 && path[0] == '/' && path[2] == ':' {
+			path = path[1:]
+		}
+
+		return filepath.FromSlash(path), true
+	}
+
+	if filepath.IsAbs(rawURL) {
+		return filepath.Clean(rawURL), true
+	}
+
+	return "", false
 }
 
-// extractSSHHost extracts the hostname from an SSH-style git URL.
-// Input: "git@github.com:user/repo.git" -> "github.com"
-func extractSSHHost(sshURL string) string {
-	after := strings.TrimPrefix(sshURL, "git@")
+// ExtractHost returns the lower-cased hostname of a git URL as parsed by ParseGitURL,
+// or "" if it cannot be determined.
+func ExtractHost(rawURL string) string {
+	g, err := ParseGitURL(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return g.Host
+}
 
-	if idx := strings.Index(after, ":"); idx > 0 {
-		return strings.ToLower(after[:idx])
+// ExtractRepoPath returns the repository path of a git URL without leading slashes or the
+// ".git" suffix, e.g. "owner/repo". Returns "" if it cannot be determined.
+func ExtractRepoPath(rawURL string) string {
+	g, err := ParseGitURL(rawURL)
+	if err != nil {
+		return ""
 	}
 
-	return ""
+	return g.Path
 }
 
-// matchHost matches a hostname against known provider URL patterns.
+// matchHost matches a hostname against the built-in host rules.
 func matchHost(host string) ProviderType {
-	for _, p := range urlPatterns {
-		if strings.Contains(host, p.pattern) {
-			return p.providerType
-		}
+	if rule, ok := MatchHostRule(host, builtinHostRules); ok {
+		return rule.Type
 	}
 
 	return ""
@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"GitSyncer/core/models"
+)
+
+// Label is a label issues and pull requests can carry.
+type Label struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+// Milestone groups issues and pull requests. DueOn is a date like 2006-01-02, or empty.
+type Milestone struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	State       string `json:"state"`
+	DueOn       string `json:"due_on,omitempty"`
+}
+
+// Issue is an issue, or a pull or merge request when PullRequest is set. GitLab numbers issues
+// and merge requests separately, so Number identifies an issue only together with PullRequest.
+type Issue struct {
+	Number      int        `json:"number"`
+	PullRequest bool       `json:"pull_request"`
+	Title       string     `json:"title"`
+	Body        string     `json:"body"`
+	State       string     `json:"state"`
+	Author      string     `json:"author"`
+	Labels      []string   `json:"labels"`
+	Milestone   string     `json:"milestone,omitempty"`
+	Assignees   []string   `json:"assignees"`
+	URL         string     `json:"url"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ClosedAt    *time.Time `json:"closed_at,omitempty"`
+	MergedAt    *time.Time `json:"merged_at,omitempty"`
+}
+
+// Comment is a comment on the issue or pull request IssueNumber. PullRequest is set for comments
+// known to be on a pull or merge request, such as review comments.
+type Comment struct {
+	ID          string    `json:"id"`
+	IssueNumber int       `json:"issue_number"`
+	PullRequest bool      `json:"pull_request"`
+	Author      string    `json:"author"`
+	Body        string    `json:"body"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// DiscussionExporter is implemented by providers that expose the discussion history of a
+// repository through their API: issues, pull or merge requests, their comments, labels and
+// milestones. repo is addressed by its CloneURL.
+type DiscussionExporter interface {
+	ListLabels(ctx context.Context, repo *models.Repository) ([]Label, error)
+	ListMilestones(ctx context.Context, repo *models.Repository) ([]Milestone, error)
+
+	// ListIssues returns the issues and pull requests of repo updated at or after since, or all
+	// of them when since is zero.
+	ListIssues(ctx context.Context, repo *models.Repository, since time.Time) ([]Issue, error)
+
+	// ListComments returns the comments on issues and pull requests of repo updated at or after
+	// since, or all of them when since is zero. Events the provider records as comments, such as
+	// GitLab's system notes, are left out.
+	ListComments(ctx context.Context, repo *models.Repository, since time.Time) ([]Comment, error)
+}
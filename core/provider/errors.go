@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"errors"
 	"fmt"
 	"time"
 )
@@ -62,3 +63,154 @@ func (e *NetworkError) Error() string {
 func (e *NetworkError) Unwrap() error {
 	return e.Err
 }
+
+// NotFoundError is returned when a repository or other resource does not exist or is not
+// visible to the credential.
+type NotFoundError struct {
+	Provider ProviderType
+	Message  string
+	Err      error
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("not found [%s]: %s: %v", e.Provider, e.Message, e.Err)
+	}
+
+	return fmt.Sprintf("not found [%s]: %s", e.Provider, e.Message)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// PermissionDeniedError is returned when the credential is valid but not allowed to perform
+// the operation, e.g. pushing to a protected branch or a repository it cannot write.
+type PermissionDeniedError struct {
+	Provider ProviderType
+	Message  string
+	Err      error
+}
+
+func (e *PermissionDeniedError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("permission denied [%s]: %s: %v", e.Provider, e.Message, e.Err)
+	}
+
+	return fmt.Sprintf("permission denied [%s]: %s", e.Provider, e.Message)
+}
+
+func (e *PermissionDeniedError) Unwrap() error {
+	return e.Err
+}
+
+// ConflictError is returned when the remote state conflicts with the change, such as a
+// rejected non-fast-forward push or a resource that already exists.
+type ConflictError struct {
+	Provider ProviderType
+	Message  string
+	Err      error
+}
+
+func (e *ConflictError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("conflict [%s]: %s: %v", e.Provider, e.Message, e.Err)
+	}
+
+	return fmt.Sprintf("conflict [%s]: %s", e.Provider, e.Message)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// TooLargeError is returned when a repository, file or request exceeds a provider size limit.
+type TooLargeError struct {
+	Provider ProviderType
+	Message  string
+	Err      error
+}
+
+func (e *TooLargeError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("too large [%s]: %s: %v", e.Provider, e.Message, e.Err)
+	}
+
+	return fmt.Sprintf("too large [%s]: %s", e.Provider, e.Message)
+}
+
+func (e *TooLargeError) Unwrap() error {
+	return e.Err
+}
+
+// UnavailableError is returned when the provider answered but is temporarily unable to serve
+// the request, e.g. a 5xx response or maintenance mode. RetryAfter is zero without a hint.
+type UnavailableError struct {
+	Provider   ProviderType
+	RetryAfter time.Duration
+	Message    string
+	Err        error
+}
+
+func (e *UnavailableError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("unavailable [%s]: %s: %v", e.Provider, e.Message, e.Err)
+	}
+
+	return fmt.Sprintf("unavailable [%s]: %s", e.Provider, e.Message)
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable reports whether err is transient, so the same operation may succeed later
+// without anyone intervening: rate limits, network failures and unavailable providers.
+func IsRetryable(err error) bool {
+	var (
+		rateErr        *RateLimitError
+		netErr         *NetworkError
+		unavailableErr *UnavailableError
+	)
+
+	return errors.As(err, &rateErr) || errors.As(err, &netErr) || errors.As(err, &unavailableErr)
+}
+
+// IsPermanent reports whether retrying err is pointless until the configuration, credentials
+// or remote repository change. Errors that are neither permanent nor retryable are unclassified.
+func IsPermanent(err error) bool {
+	var (
+		authErr       *AuthError
+		notFoundErr   *NotFoundError
+		permissionErr *PermissionDeniedError
+		conflictErr   *ConflictError
+		tooLargeErr   *TooLargeError
+	)
+
+	return errors.As(err, &authErr) || errors.As(err, &notFoundErr) || errors.As(err, &permissionErr) ||
+		errors.As(err, &conflictErr) || errors.As(err, &tooLargeErr)
+}
+
+// IsNotFound reports whether err is a NotFoundError.
+func IsNotFound(err error) bool {
+	var notFoundErr *NotFoundError
+
+	return errors.As(err, &notFoundErr)
+}
+
+// RetryDelay returns the wait a RateLimitError or UnavailableError in err asks for, or zero.
+func RetryDelay(err error) time.Duration {
+	var (
+		rateErr        *RateLimitError
+		unavailableErr *UnavailableError
+	)
+
+	switch {
+	case errors.As(err, &rateErr):
+		return rateErr.RetryAfter
+	case errors.As(err, &unavailableErr):
+		return unavailableErr.RetryAfter
+	}
+
+	return 0
+}
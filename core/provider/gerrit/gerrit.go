@@ -0,0 +1,285 @@
+package gerrit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"GitSyncer/core/git"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/internal/httpapi"
+)
+
+const (
+	// OptionPrefix limits ListRepos to projects whose name starts with the given prefix.
+	OptionPrefix = "prefix"
+
+	// xssiPrefix is prepended by Gerrit to every JSON response.
+	xssiPrefix = ")]}'"
+
+	perPage = 100
+)
+
+// systemProjects hold Gerrit's own configuration and are never mirrored.
+var systemProjects = []string{"All-Projects", "All-Users"}
+
+// Schema describes the configuration accepted by New.
+var Schema = provider.ConfigSchema{
+	BaseURL: provider.OptionSpec{
+		Type:     provider.OptionTypeURL,
+		Required: true,
+		Help:     "Instance URL including any context path, e.g. https://review.example.com/r.",
+	},
+	Options: []provider.OptionSpec{
+		{Name: OptionPrefix, Type: provider.OptionTypeString, Help: "List only projects whose name starts with this prefix."},
+	},
+}
+
+var _ provider.SourceControlProvider = (*Provider)(nil)
+
+// Provider implements provider.SourceControlProvider for Gerrit Code Review.
+// Credentials use the "basic" auth type with the account's generated HTTP password;
+// a nil credential gives anonymous read access.
+type Provider struct {
+	api    *httpapi.Client
+	host   string
+	prefix string
+
+	mu   sync.RWMutex
+	cred *models.Credential
+}
+
+// New creates a Gerrit provider. cfg.BaseURL is the instance URL including any context path,
+// e.g. https://review.example.com/r.
+func New(cfg provider.ProviderConfig) (provider.SourceControlProvider, error) {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		return nil, fmt.Errorf("gerrit.New: base url is required")
+	}
+
+	api, err := httpapi.NewClient(provider.ProviderGerrit, baseURL, cfg.Transport)
+	if err != nil {
+		return nil, fmt.Errorf("gerrit.New: %w", err)
+	}
+
+	api.ResponsePrefix = xssiPrefix
+
+	p := &Provider{
+		api:    api,
+		host:   strings.ToLower(api.BaseURL.Hostname()),
+		prefix: cfg.Options[OptionPrefix],
+	}
+	api.Authorize = p.authorize
+
+	return p, nil
+}
+
+// Register adds the Gerrit factory to the registry.
+func Register(r *provider.ProviderRegistry) error {
+	if err := r.RegisterSourceControlProviderFactory(provider.ProviderGerrit, New); err != nil {
+		return err
+	}
+
+	return r.RegisterSourceControlProviderSchema(provider.ProviderGerrit, Schema)
+}
+
+// Authenticate validates a username:http-password credential against /a/accounts/self.
+// A nil credential switches to anonymous access and only checks that the server answers.
+func (p *Provider) Authenticate(ctx context.Context, cred *models.Credential) error {
+	if cred != nil && (cred.AuthType != models.AuthTypeBasic || !strings.Contains(cred.AuthData, ":")) {
+		return &provider.AuthError{Provider: provider.ProviderGerrit, Message: "a basic credential of username:http-password is required"}
+	}
+
+	p.mu.Lock()
+	p.cred = cred
+	p.mu.Unlock()
+
+	path := "/config/server/version"
+	if cred != nil {
+		path = "/a/accounts/self"
+	}
+
+	if _, err := p.api.Get(ctx, path, nil, nil); err != nil {
+		p.mu.Lock()
+		p.cred = nil
+		p.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+type apiProject struct {
+	ID           string `json:"id"`
+	State        string `json:"state"`
+	Description  string `json:"description"`
+	MoreProjects bool   `json:"_more_projects"`
+}
+
+// ListRepos returns every visible project except Gerrit's system projects and hidden ones.
+func (p *Provider) ListRepos(ctx context.Context) ([]models.Repository, error) {
+	var repos []models.Repository
+
+	seen := make(map[string]bool)
+
+	for skip := 0; ; skip += perPage {
+		query := url.Values{
+			"d": {""},
+			"n": {fmt.Sprint(perPage)},
+			"S": {fmt.Sprint(skip)},
+		}
+
+		if p.prefix != "" {
+			query.Set("p", p.prefix)
+		}
+
+		// The response is a map keyed by project name.
+		var page map[string]apiProject
+
+		if _, err := p.api.Get(ctx, p.restPath("/projects/"), query, &page); err != nil {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(page))
+		more := false
+
+		for name, project := range page {
+			more = more || project.MoreProjects
+
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+
+		// A server that ignores S returns the first page again; stop instead of looping forever.
+		if len(names) == 0 {
+			return repos, nil
+		}
+
+		slices.Sort(names)
+
+		for _, name := range names {
+			if slices.Contains(systemProjects, name) || page[name].State == "HIDDEN" {
+				continue
+			}
+
+			repos = append(repos, models.Repository{
+				Name:        name,
+				CloneURL:    p.cloneURL(name),
+				Description: page[name].Description,
+				Owner:       namespace(name),
+				IsArchived:  page[name].State == "READ_ONLY",
+			})
+		}
+
+		// Older servers do not set _more_projects; a full page means there may be more.
+		if !more && len(page) < perPage {
+			return repos, nil
+		}
+	}
+}
+
+// namespace returns the parent path of a project name. Gerrit has no owners, so this stands in for one.
+func namespace(name string) string {
+	if dir := path.Dir(name); dir != "." {
+		return dir
+	}
+
+	return ""
+}
+
+// cloneURL returns the HTTP clone URL, using the /a/ prefix when authenticated so private projects resolve.
+func (p *Provider) cloneURL(name string) string {
+	return p.api.BaseURL.String() + p.restPath("/"+name)
+}
+
+// restPath prefixes path with /a when a credential is set, which Gerrit requires for authenticated calls.
+func (p *Provider) restPath(path string) string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred == nil {
+		return path
+	}
+
+	return "/a" + path
+}
+
+// CloneRepo creates or updates a bare mirror of repo at destPath and records it as repo.LocalPath.
+func (p *Provider) CloneRepo(ctx context.Context, repo *models.Repository, destPath string) error {
+	auth, err := p.gitAuth()
+	if err != nil {
+		return err
+	}
+
+	if err := git.CloneMirror(ctx, repo.CloneURL, destPath, auth); err != nil {
+		return fmt.Errorf("gerrit: clone %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderGerrit, err))
+	}
+
+	repo.LocalPath = destPath
+
+	return nil
+}
+
+// PushMirror pushes the local mirror of repo to remoteURL on this Gerrit instance.
+// The account needs Push and Force Push rights, since the push bypasses code review.
+func (p *Provider) PushMirror(ctx context.Context, repo *models.Repository, remoteURL string) error {
+	auth, err := p.gitAuth()
+	if err != nil {
+		return err
+	}
+
+	if err := git.PushMirror(ctx, repo.LocalPath, remoteURL, auth); err != nil {
+		return fmt.Errorf("gerrit: push mirror %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderGerrit, err))
+	}
+
+	return nil
+}
+
+// ValidateURL reports whether url points at this Gerrit instance.
+func (p *Provider) ValidateURL(rawURL string) bool {
+	return provider.ExtractHost(rawURL) == p.host
+}
+
+func (p *Provider) GetProviderType() provider.ProviderType {
+	return provider.ProviderGerrit
+}
+
+func (p *Provider) Capabilities() []provider.SourceControlProviderCapability {
+	return []provider.SourceControlProviderCapability{
+		provider.CapabilityAPI,
+		provider.CapabilitySSH,
+		provider.CapabilityTokenAuth,
+	}
+}
+
+func (p *Provider) authorize(req *http.Request) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred == nil {
+		return
+	}
+
+	username, password, _ := strings.Cut(p.cred.AuthData, ":")
+	req.SetBasicAuth(username, password)
+}
+
+func (p *Provider) gitAuth() (*git.Auth, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred == nil {
+		return nil, nil
+	}
+
+	return git.AuthFromCredential(p.cred, "")
+}
@@ -0,0 +1,548 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"GitSyncer/core/git"
+	"GitSyncer/core/lfs"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/internal/httpapi"
+)
+
+const (
+	// DefaultBaseURL is the gitea.com instance.
+	DefaultBaseURL = "https://gitea.com"
+
+	// OptionCreateMissing controls whether PushMirror creates a missing destination repository.
+	// Enabled unless set to "false".
+	OptionCreateMissing = "create_missing"
+
+	// OptionPrivate makes repositories created by PushMirror private unless set to "false".
+	OptionPrivate = "private"
+
+	perPage = 50
+)
+
+// Flavour identifies the server implementation behind the Gitea-compatible API.
+type Flavour string
+
+const (
+	FlavourGitea   Flavour = "gitea"
+	FlavourForgejo Flavour = "forgejo"
+)
+
+var (
+	_ provider.SourceControlProvider = (*Provider)(nil)
+	_ provider.RepoLister            = (*Provider)(nil)
+	_ provider.RateLimitReporter     = (*Provider)(nil)
+	_ provider.LFSProvider           = (*Provider)(nil)
+)
+
+// Schema describes the configuration accepted by New.
+var Schema = provider.ConfigSchema{
+	BaseURL: provider.OptionSpec{
+		Type:    provider.OptionTypeURL,
+		Default: DefaultBaseURL,
+		Help:    "Instance URL, e.g. https://gitea.example.com or https://codeberg.org.",
+	},
+	Options: []provider.OptionSpec{
+		{Name: OptionCreateMissing, Type: provider.OptionTypeBool, Default: "true", Help: "Create missing destination repositories when pushing mirrors."},
+		{Name: OptionPrivate, Type: provider.OptionTypeBool, Default: "true", Help: "Make repositories created by mirroring private."},
+	},
+}
+
+// Provider implements provider.SourceControlProvider for Gitea and Forgejo (API v1).
+type Provider struct {
+	api           *httpapi.Client
+	host          string
+	basePath      string
+	createMissing bool
+	private       bool
+
+	mu      sync.RWMutex
+	cred    *models.Credential
+	login   string
+	flavour Flavour
+}
+
+// New creates a Gitea/Forgejo provider. cfg.BaseURL is the instance URL, e.g. https://codeberg.org.
+func New(cfg provider.ProviderConfig) (provider.SourceControlProvider, error) {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	baseURL = strings.TrimSuffix(baseURL, "/api/v1")
+
+	api, err := httpapi.NewClient(provider.ProviderGitea, baseURL+"/api/v1", cfg.Transport)
+	if err != nil {
+		return nil, fmt.Errorf("gitea.New: %w", err)
+	}
+
+	p := &Provider{
+		api:           api,
+		host:          strings.ToLower(api.BaseURL.Hostname()),
+		basePath:      strings.Trim(strings.TrimSuffix(api.BaseURL.Path, "/api/v1"), "/"),
+		createMissing: cfg.Options[OptionCreateMissing] != "false",
+		private:       cfg.Options[OptionPrivate] != "false",
+		flavour:       FlavourGitea,
+	}
+	api.Authorize = p.authorize
+	api.PageQuery = url.Values{"limit": {fmt.Sprint(perPage)}}
+
+	return p, nil
+}
+
+// Register adds the Gitea factory to the registry. Forgejo instances use the same provider type.
+func Register(r *provider.ProviderRegistry) error {
+	if err := r.RegisterSourceControlProviderFactory(provider.ProviderGitea, New); err != nil {
+		return err
+	}
+
+	return r.RegisterSourceControlProviderSchema(provider.ProviderGitea, Schema)
+}
+
+// Authenticate validates a token, OAuth or basic credential and detects the server flavour.
+func (p *Provider) Authenticate(ctx context.Context, cred *models.Credential) error {
+	if cred == nil {
+		return &provider.AuthError{Provider: provider.ProviderGitea, Message: "a credential is required"}
+	}
+
+	switch cred.AuthType {
+	case models.AuthTypeToken, models.AuthTypeOAuth:
+	case models.AuthTypeBasic:
+		if !strings.Contains(cred.AuthData, ":") {
+			return &provider.AuthError{Provider: provider.ProviderGitea, Message: "basic auth data must be username:password"}
+		}
+	default:
+		return &provider.AuthError{Provider: provider.ProviderGitea, Message: "unsupported auth type " + cred.AuthType}
+	}
+
+	p.mu.Lock()
+	p.cred = cred
+	p.mu.Unlock()
+
+	var user struct {
+		Login string `json:"login"`
+	}
+
+	if _, err := p.api.Get(ctx, "/user", nil, &user); err != nil {
+		p.mu.Lock()
+		p.cred = nil
+		p.mu.Unlock()
+
+		return err
+	}
+
+	flavour := p.detectFlavour(ctx)
+
+	p.mu.Lock()
+	p.login = user.Login
+	p.flavour = flavour
+	p.mu.Unlock()
+
+	return nil
+}
+
+// Flavour returns the detected server implementation. It is FlavourGitea until Authenticate succeeds.
+func (p *Provider) Flavour() Flavour {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.flavour
+}
+
+// detectFlavour inspects the version string; Forgejo reports versions like "7.0.0+gitea-1.21.0".
+func (p *Provider) detectFlavour(ctx context.Context) Flavour {
+	var version struct {
+		Version string `json:"version"`
+	}
+
+	if _, err := p.api.Get(ctx, "/version", nil, &version); err != nil {
+		return FlavourGitea
+	}
+
+	if strings.Contains(version.Version, "+gitea-") || strings.Contains(strings.ToLower(version.Version), "forgejo") {
+		return FlavourForgejo
+	}
+
+	return FlavourGitea
+}
+
+type apiRepo struct {
+	FullName      string   `json:"full_name"`
+	CloneURL      string   `json:"clone_url"`
+	Description   string   `json:"description"`
+	Website       string   `json:"website"`
+	Topics        []string `json:"topics"`
+	Mirror        bool     `json:"mirror"`
+	HasWiki       bool     `json:"has_wiki"`
+	DefaultBranch string   `json:"default_branch"`
+	Owner         struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	Private   bool       `json:"private"`
+	Internal  bool       `json:"internal"`
+	Archived  bool       `json:"archived"`
+	Fork      bool       `json:"fork"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// toModel maps a repository. Gitea has no push timestamp, so the last update stands in for PushedAt.
+func (r apiRepo) toModel() models.Repository {
+	visibility := models.VisibilityPublic
+
+	switch {
+	case r.Private:
+		visibility = models.VisibilityPrivate
+	case r.Internal:
+		visibility = models.VisibilityInternal
+	}
+
+	m := models.Repository{
+		Name:          r.FullName,
+		CloneURL:      r.CloneURL,
+		Description:   r.Description,
+		Homepage:      r.Website,
+		Topics:        r.Topics,
+		IsMirror:      r.Mirror,
+		HasWiki:       r.HasWiki,
+		DefaultBranch: r.DefaultBranch,
+		Owner:         r.Owner.Login,
+		Visibility:    visibility,
+		IsArchived:    r.Archived,
+		IsFork:        r.Fork,
+		PushedAt:      r.UpdatedAt,
+	}
+
+	if m.HasWiki {
+		m.WikiCloneURL = provider.WikiCloneURL(m.CloneURL)
+	}
+
+	return m
+}
+
+type apiOrg struct {
+	Username string `json:"username"`
+}
+
+// ListRepos returns the user's repositories and the repositories of every organization the user belongs to.
+func (p *Provider) ListRepos(ctx context.Context) ([]models.Repository, error) {
+	if err := p.requireAuth(); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+
+	var repos []models.Repository
+
+	add := func(page []apiRepo) {
+		for _, r := range page {
+			if seen[r.FullName] {
+				continue
+			}
+
+			seen[r.FullName] = true
+
+			repos = append(repos, r.toModel())
+		}
+	}
+
+	if err := httpapi.Paginate(ctx, p.api, "/user/repos", nil, add); err != nil {
+		return nil, err
+	}
+
+	var orgs []apiOrg
+
+	if err := httpapi.Paginate(ctx, p.api, "/user/orgs", nil, func(page []apiOrg) { orgs = append(orgs, page...) }); err != nil {
+		return nil, err
+	}
+
+	for _, org := range orgs {
+		if err := httpapi.Paginate(ctx, p.api, "/orgs/"+url.PathEscape(org.Username)+"/repos", nil, add); err != nil {
+			return nil, err
+		}
+	}
+
+	return repos, nil
+}
+
+// ListReposWithOptions lists one page of repositories. With an owner, /repos/search filters by
+// owner, visibility, archived and fork server-side and sorts by last update for PushedSince;
+// the cursor is the next page URL. Without an owner, the ListRepos result is filtered client-side.
+func (p *Provider) ListReposWithOptions(ctx context.Context, opts provider.ListReposOptions) (*provider.RepoPage, error) {
+	if opts.Owner == "" {
+		repos, err := p.ListRepos(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return provider.PaginateRepos(repos, opts)
+	}
+
+	if err := p.requireAuth(); err != nil {
+		return nil, err
+	}
+
+	if err := p.api.CheckLink(opts.Cursor); err != nil {
+		return nil, err
+	}
+
+	path, query := opts.Cursor, url.Values(nil)
+
+	if path == "" {
+		var owner struct {
+			ID int64 `json:"id"`
+		}
+
+		if _, err := p.api.Get(ctx, "/users/"+url.PathEscape(opts.Owner), nil, &owner); err != nil {
+			return nil, err
+		}
+
+		path, query = "/repos/search", searchQuery(owner.ID, opts)
+	}
+
+	var search struct {
+		Data []apiRepo `json:"data"`
+	}
+
+	resp, err := p.api.Get(ctx, path, query, &search)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &provider.RepoPage{NextCursor: httpapi.NextLink(resp)}
+
+	for _, r := range search.Data {
+		repo := r.toModel()
+
+		if !opts.PushedSince.IsZero() && repo.PushedAt != nil && repo.PushedAt.Before(opts.PushedSince) {
+			result.NextCursor = ""
+
+			break
+		}
+
+		if opts.Matches(repo) {
+			result.Repos = append(result.Repos, repo)
+		}
+	}
+
+	return result, nil
+}
+
+// searchQuery builds /repos/search parameters for repositories owned by uid.
+func searchQuery(uid int64, opts provider.ListReposOptions) url.Values {
+	query := url.Values{
+		"uid":       {fmt.Sprint(uid)},
+		"exclusive": {"true"},
+		"limit":     {fmt.Sprint(min(opts.Limit(), perPage))},
+	}
+
+	switch opts.Visibility {
+	case models.VisibilityPrivate:
+		query.Set("is_private", "true")
+	case models.VisibilityPublic:
+		query.Set("is_private", "false")
+	}
+
+	if opts.Archived != nil {
+		query.Set("archived", fmt.Sprint(*opts.Archived))
+	}
+
+	if opts.Fork != nil {
+		mode := "source"
+		if *opts.Fork {
+			mode = "fork"
+		}
+
+		query.Set("mode", mode)
+	}
+
+	if !opts.PushedSince.IsZero() {
+		query.Set("sort", "updated")
+		query.Set("order", "desc")
+	}
+
+	return query
+}
+
+// CloneRepo creates or updates a bare mirror of repo at destPath and records it as repo.LocalPath.
+func (p *Provider) CloneRepo(ctx context.Context, repo *models.Repository, destPath string) error {
+	auth, err := p.gitAuth()
+	if err != nil {
+		return err
+	}
+
+	if err := git.CloneMirror(ctx, repo.CloneURL, destPath, auth); err != nil {
+		return fmt.Errorf("gitea: clone %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderGitea, err))
+	}
+
+	repo.LocalPath = destPath
+
+	return nil
+}
+
+// PushMirror pushes the local mirror of repo to remoteURL, first creating the destination
+// repository on this instance if it does not exist and the create_missing option allows it.
+// Wikis are not created that way; Gitea initialises the wiki of an existing repository on push.
+func (p *Provider) PushMirror(ctx context.Context, repo *models.Repository, remoteURL string) error {
+	if p.createMissing && p.ValidateURL(remoteURL) && !provider.IsWikiURL(remoteURL) {
+		if err := p.ensureRepo(ctx, repo, remoteURL); err != nil {
+			return fmt.Errorf("gitea: push mirror %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderGitea, err))
+		}
+	}
+
+	auth, err := p.gitAuth()
+	if err != nil {
+		return err
+	}
+
+	if err := git.PushMirror(ctx, repo.LocalPath, remoteURL, auth); err != nil {
+		return fmt.Errorf("gitea: push mirror %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderGitea, err))
+	}
+
+	return nil
+}
+
+// ensureRepo creates the repository addressed by remoteURL under the user or organization owner if missing.
+func (p *Provider) ensureRepo(ctx context.Context, repo *models.Repository, remoteURL string) error {
+	owner, name, err := p.ownerAndName(remoteURL)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = p.EnsureRepo(ctx, owner, name, provider.RepoMetadata{
+		Description:   repo.Description,
+		DefaultBranch: repo.DefaultBranch,
+	})
+
+	return err
+}
+
+// ownerAndName splits a clone URL on this instance into owner and repository name.
+func (p *Provider) ownerAndName(remoteURL string) (string, string, error) {
+	path := provider.ExtractRepoPath(remoteURL)
+
+	if p.basePath != "" {
+		path = strings.TrimPrefix(strings.TrimPrefix(path, p.basePath), "/")
+	}
+
+	owner, name, ok := strings.Cut(path, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("cannot determine owner/name from %q", remoteURL)
+	}
+
+	return owner, name, nil
+}
+
+// repoPath returns the API path of the repository repo.CloneURL addresses, requiring authentication.
+func (p *Provider) repoPath(repo *models.Repository) (string, error) {
+	if err := p.requireAuth(); err != nil {
+		return "", err
+	}
+
+	owner, name, err := p.ownerAndName(repo.CloneURL)
+	if err != nil {
+		return "", fmt.Errorf("gitea: %w", err)
+	}
+
+	return "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name), nil
+}
+
+// LFSEndpoint returns the LFS server of the repository cloneURL addresses on Gitea,
+// authenticated like git.
+func (p *Provider) LFSEndpoint(cloneURL string) (*lfs.Endpoint, error) {
+	lfsURL, err := provider.LFSURL(cloneURL)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := p.gitAuth()
+	if err != nil {
+		return nil, err
+	}
+
+	return &lfs.Endpoint{URL: lfsURL, Auth: auth}, nil
+}
+
+// ValidateURL reports whether url points at this instance.
+func (p *Provider) ValidateURL(rawURL string) bool {
+	return provider.ExtractHost(rawURL) == p.host
+}
+
+func (p *Provider) GetProviderType() provider.ProviderType {
+	return provider.ProviderGitea
+}
+
+func (p *Provider) Capabilities() []provider.SourceControlProviderCapability {
+	return []provider.SourceControlProviderCapability{
+		provider.CapabilityAPI,
+		provider.CapabilityTokenAuth,
+		provider.CapabilityOAuth,
+		provider.CapabilityWebhooks,
+		provider.CapabilityMirror,
+		provider.CapabilityWiki,
+		provider.CapabilityLFS,
+		provider.CapabilityReleases,
+		provider.CapabilityIssues,
+	}
+}
+
+// RateLimits returns the API quota reported on the most recent responses.
+func (p *Provider) RateLimits() []provider.RateLimitStatus {
+	return p.api.RateLimits()
+}
+
+func (p *Provider) authorize(req *http.Request) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred == nil {
+		return
+	}
+
+	switch p.cred.AuthType {
+	case models.AuthTypeToken:
+		req.Header.Set("Authorization", "token "+p.cred.AuthData)
+	case models.AuthTypeOAuth:
+		req.Header.Set("Authorization", "Bearer "+p.cred.AuthData)
+	case models.AuthTypeBasic:
+		username, password, _ := strings.Cut(p.cred.AuthData, ":")
+		req.SetBasicAuth(username, password)
+	}
+}
+
+func (p *Provider) requireAuth() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred == nil {
+		return &provider.AuthError{Provider: provider.ProviderGitea, Message: "not authenticated"}
+	}
+
+	return nil
+}
+
+// gitAuth sends tokens as the password of the authenticated login, which Gitea and Forgejo both accept.
+func (p *Provider) gitAuth() (*git.Auth, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred == nil {
+		return nil, nil
+	}
+
+	username := p.login
+	if username == "" {
+		username = "git"
+	}
+
+	return git.AuthFromCredential(p.cred, username)
+}
@@ -0,0 +1,187 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+)
+
+var _ provider.MirrorManager = (*Provider)(nil)
+
+// pullMirrorID identifies the single pull mirror a repository can be.
+const pullMirrorID = "pull"
+
+type apiMirrorRepo struct {
+	Mirror         bool       `json:"mirror"`
+	MirrorInterval string     `json:"mirror_interval"`
+	MirrorUpdated  *time.Time `json:"mirror_updated"`
+	OriginalURL    string     `json:"original_url"`
+}
+
+// toNative maps a pull mirror repository. Gitea does not report failed updates through the API,
+// so a mirror that has updated once is reported as ok.
+func (r apiMirrorRepo) toNative() *provider.NativeMirror {
+	interval, _ := time.ParseDuration(r.MirrorInterval)
+
+	m := &provider.NativeMirror{
+		ID:        pullMirrorID,
+		Direction: provider.MirrorPull,
+		RemoteURL: provider.RedactURL(r.OriginalURL),
+		Interval:  interval,
+		Enabled:   interval > 0,
+		Status:    provider.MirrorStatusPending,
+	}
+
+	// Gitea reports the zero time for mirrors that never updated.
+	if r.MirrorUpdated != nil && r.MirrorUpdated.Year() > 1 {
+		m.Status = provider.MirrorStatusOK
+		m.LastUpdateAt = r.MirrorUpdated
+		m.LastSuccessAt = r.MirrorUpdated
+	}
+
+	return m
+}
+
+// MirrorDirections reports pull mirrors: repositories on this instance that fetch from a remote.
+func (p *Provider) MirrorDirections() []string {
+	return []string{provider.MirrorPull}
+}
+
+// ListMirrors returns the repository's pull mirror, or none if it is a regular repository.
+func (p *Provider) ListMirrors(ctx context.Context, repo *models.Repository) ([]provider.NativeMirror, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var r apiMirrorRepo
+
+	if _, err := p.api.Get(ctx, path, nil, &r); err != nil {
+		return nil, err
+	}
+
+	if !r.Mirror {
+		return nil, nil
+	}
+
+	return []provider.NativeMirror{*r.toNative()}, nil
+}
+
+// CreateMirror migrates cfg.RemoteURL into a new pull mirror at the location repo.CloneURL
+// addresses, under the user or organization owner. Mirrors are private unless the private
+// option is false.
+func (p *Provider) CreateMirror(ctx context.Context, repo *models.Repository, cfg provider.NativeMirrorConfig) (*provider.NativeMirror, error) {
+	if cfg.Direction != provider.MirrorPull {
+		return nil, fmt.Errorf("gitea: %s mirror: %w", cfg.Direction, provider.ErrUnsupportedMirror)
+	}
+
+	if cfg.OnlyProtectedBranches {
+		return nil, fmt.Errorf("gitea: pull mirrors cannot be limited to protected branches: %w", provider.ErrUnsupportedMirror)
+	}
+
+	if err := p.requireAuth(); err != nil {
+		return nil, err
+	}
+
+	owner, name, err := p.ownerAndName(repo.CloneURL)
+	if err != nil {
+		return nil, fmt.Errorf("gitea: %w", err)
+	}
+
+	body := map[string]any{
+		"clone_addr":    cfg.RemoteURL,
+		"auth_username": cfg.Username,
+		"auth_password": cfg.Password,
+		"mirror":        true,
+		"repo_owner":    owner,
+		"repo_name":     name,
+		"description":   repo.Description,
+		"private":       p.private,
+		"service":       "git",
+	}
+
+	if cfg.Interval > 0 {
+		body["mirror_interval"] = cfg.Interval.String()
+	}
+
+	var r apiMirrorRepo
+
+	if _, err := p.api.Send(ctx, http.MethodPost, "/repos/migrate", body, &r); err != nil {
+		return nil, fmt.Errorf("gitea: create pull mirror %s/%s: %w", owner, name, err)
+	}
+
+	return r.toNative(), nil
+}
+
+// UpdateMirror changes the pull interval. A zero interval keeps the current one.
+func (p *Provider) UpdateMirror(ctx context.Context, repo *models.Repository, id string, cfg provider.NativeMirrorConfig) (*provider.NativeMirror, error) {
+	path, err := p.mirrorPath(repo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]any{}
+	if cfg.Interval > 0 {
+		body["mirror_interval"] = cfg.Interval.String()
+	}
+
+	var r apiMirrorRepo
+
+	if _, err := p.api.Send(ctx, http.MethodPatch, path, body, &r); err != nil {
+		return nil, fmt.Errorf("gitea: update pull mirror %s: %w", repo.Name, err)
+	}
+
+	return r.toNative(), nil
+}
+
+// DeleteMirror deletes the pull mirror repository itself, since Gitea cannot turn a mirror into
+// a regular repository through the API. Repositories that are not mirrors are left alone.
+// MirrorService only calls it once the deletion was confirmed.
+func (p *Provider) DeleteMirror(ctx context.Context, repo *models.Repository, id string) error {
+	path, err := p.mirrorPath(repo, id)
+	if err != nil {
+		return err
+	}
+
+	var r apiMirrorRepo
+
+	if _, err := p.api.Get(ctx, path, nil, &r); err != nil {
+		return err
+	}
+
+	if !r.Mirror {
+		return &provider.NotFoundError{Provider: provider.ProviderGitea, Message: repo.Name + " is not a pull mirror"}
+	}
+
+	if _, err := p.api.Send(ctx, http.MethodDelete, path, nil, nil); err != nil {
+		return fmt.Errorf("gitea: delete pull mirror %s: %w", repo.Name, err)
+	}
+
+	return nil
+}
+
+// SyncMirror queues a fetch of the pull mirror.
+func (p *Provider) SyncMirror(ctx context.Context, repo *models.Repository, id string) error {
+	path, err := p.mirrorPath(repo, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.api.Send(ctx, http.MethodPost, path+"/mirror-sync", nil, nil); err != nil {
+		return fmt.Errorf("gitea: sync pull mirror %s: %w", repo.Name, err)
+	}
+
+	return nil
+}
+
+func (p *Provider) mirrorPath(repo *models.Repository, id string) (string, error) {
+	if id != pullMirrorID {
+		return "", &provider.NotFoundError{Provider: provider.ProviderGitea, Message: "mirror " + id}
+	}
+
+	return p.repoPath(repo)
+}
@@ -0,0 +1,178 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/internal/httpapi"
+)
+
+var _ provider.ReleaseManager = (*Provider)(nil)
+
+type apiRelease struct {
+	ID         int64      `json:"id"`
+	TagName    string     `json:"tag_name"`
+	Name       string     `json:"name"`
+	Body       string     `json:"body"`
+	Draft      bool       `json:"draft"`
+	Prerelease bool       `json:"prerelease"`
+	Assets     []apiAsset `json:"assets"`
+}
+
+func (r apiRelease) toRelease() *provider.Release {
+	rel := &provider.Release{
+		ID:         strconv.FormatInt(r.ID, 10),
+		TagName:    r.TagName,
+		Name:       r.Name,
+		Body:       r.Body,
+		Draft:      r.Draft,
+		Prerelease: r.Prerelease,
+		Assets:     make([]provider.ReleaseAsset, 0, len(r.Assets)),
+	}
+
+	for _, a := range r.Assets {
+		rel.Assets = append(rel.Assets, *a.toAsset())
+	}
+
+	return rel
+}
+
+// apiAsset is a release attachment. Gitea reports no checksum for attachments.
+type apiAsset struct {
+	ID                 int64  `json:"id"`
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+func (a apiAsset) toAsset() *provider.ReleaseAsset {
+	return &provider.ReleaseAsset{
+		ID:          strconv.FormatInt(a.ID, 10),
+		Name:        a.Name,
+		Size:        a.Size,
+		DownloadURL: a.BrowserDownloadURL,
+	}
+}
+
+// ListReleases returns the repository's releases, drafts included when the credential can write.
+func (p *Provider) ListReleases(ctx context.Context, repo *models.Repository) ([]provider.Release, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []provider.Release
+
+	err = httpapi.Paginate(ctx, p.api, path+"/releases", nil, func(page []apiRelease) {
+		for _, r := range page {
+			releases = append(releases, *r.toRelease())
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return releases, nil
+}
+
+// CreateRelease publishes a release for the existing tag cfg.TagName.
+func (p *Provider) CreateRelease(ctx context.Context, repo *models.Repository, cfg provider.ReleaseConfig) (*provider.Release, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var r apiRelease
+
+	if _, err := p.api.Send(ctx, http.MethodPost, path+"/releases", releaseBody(cfg), &r); err != nil {
+		return nil, fmt.Errorf("gitea: create release %s on %s: %w", cfg.TagName, repo.Name, err)
+	}
+
+	return r.toRelease(), nil
+}
+
+// UpdateRelease replaces the tag, name, notes and prerelease flag of the release id.
+func (p *Provider) UpdateRelease(ctx context.Context, repo *models.Repository, id string, cfg provider.ReleaseConfig) (*provider.Release, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var r apiRelease
+
+	if _, err := p.api.Send(ctx, http.MethodPatch, path+"/releases/"+url.PathEscape(id), releaseBody(cfg), &r); err != nil {
+		return nil, fmt.Errorf("gitea: update release %s on %s: %w", cfg.TagName, repo.Name, err)
+	}
+
+	return r.toRelease(), nil
+}
+
+// UploadReleaseAsset attaches content to the release as a multipart "attachment". Gitea takes
+// the size from the upload itself.
+func (p *Provider) UploadReleaseAsset(ctx context.Context, repo *models.Repository, id, name string, content io.Reader, _ int64) (*provider.ReleaseAsset, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var a apiAsset
+
+	_, err = p.api.Upload(ctx, path+"/releases/"+url.PathEscape(id)+"/assets", url.Values{"name": {name}}, "attachment", name, content, &a)
+	if err != nil {
+		return nil, fmt.Errorf("gitea: upload %s to %s: %w", name, repo.Name, err)
+	}
+
+	return a.toAsset(), nil
+}
+
+// DownloadReleaseAsset streams the attachment from its download URL, authenticated for private repositories.
+func (p *Provider) DownloadReleaseAsset(ctx context.Context, repo *models.Repository, asset provider.ReleaseAsset) (io.ReadCloser, error) {
+	if err := p.requireAuth(); err != nil {
+		return nil, err
+	}
+
+	req, err := p.api.NewRequest(ctx, http.MethodGet, asset.DownloadURL, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/octet-stream")
+
+	resp, err := p.api.Stream(req)
+	if err != nil {
+		return nil, fmt.Errorf("gitea: download %s from %s: %w", asset.Name, repo.Name, err)
+	}
+
+	return resp.Body, nil
+}
+
+// DeleteReleaseAsset removes the attachment from the release id.
+func (p *Provider) DeleteReleaseAsset(ctx context.Context, repo *models.Repository, id string, asset provider.ReleaseAsset) error {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return err
+	}
+
+	path += "/releases/" + url.PathEscape(id) + "/assets/" + url.PathEscape(asset.ID)
+
+	if _, err := p.api.Send(ctx, http.MethodDelete, path, nil, nil); err != nil {
+		return fmt.Errorf("gitea: delete asset %s on %s: %w", asset.Name, repo.Name, err)
+	}
+
+	return nil
+}
+
+func releaseBody(cfg provider.ReleaseConfig) map[string]any {
+	return map[string]any{
+		"tag_name":   cfg.TagName,
+		"name":       cfg.Name,
+		"body":       cfg.Body,
+		"prerelease": cfg.Prerelease,
+	}
+}
@@ -0,0 +1,140 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+)
+
+var _ provider.RepoManager = (*Provider)(nil)
+
+// GetRepo returns the repository repo.CloneURL addresses.
+func (p *Provider) GetRepo(ctx context.Context, repo *models.Repository) (*models.Repository, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var r apiRepo
+
+	if _, err := p.api.Get(ctx, path, nil, &r); err != nil {
+		return nil, err
+	}
+
+	m := r.toModel()
+
+	return &m, nil
+}
+
+// EnsureRepo returns owner/name, creating it under the authenticated user or the organization
+// owner when missing. Gitea names the initial branch after meta.DefaultBranch, and an empty
+// visibility falls back to the private option.
+func (p *Provider) EnsureRepo(ctx context.Context, owner, name string, meta provider.RepoMetadata) (*models.Repository, bool, error) {
+	if err := p.requireAuth(); err != nil {
+		return nil, false, err
+	}
+
+	path := "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name)
+
+	var r apiRepo
+
+	_, err := p.api.Get(ctx, path, nil, &r)
+	if err == nil {
+		m := r.toModel()
+		return &m, false, nil
+	}
+
+	if !provider.IsNotFound(err) {
+		return nil, false, err
+	}
+
+	p.mu.RLock()
+	login := p.login
+	p.mu.RUnlock()
+
+	create := "/orgs/" + url.PathEscape(owner) + "/repos"
+	if strings.EqualFold(owner, login) {
+		create = "/user/repos"
+	}
+
+	private := p.private
+	if meta.Visibility != "" {
+		private = meta.Visibility != models.VisibilityPublic
+	}
+
+	body := map[string]any{
+		"name":        name,
+		"description": meta.Description,
+		"private":     private,
+	}
+
+	if meta.DefaultBranch != "" {
+		body["default_branch"] = meta.DefaultBranch
+	}
+
+	if _, err := p.api.Send(ctx, http.MethodPost, create, body, &r); err != nil {
+		return nil, false, fmt.Errorf("create %s/%s: %w", owner, name, err)
+	}
+
+	// Creation takes no website or topics.
+	if meta.Homepage != "" || len(meta.Topics) > 0 {
+		if err := p.edit(ctx, path, provider.RepoMetadata{Description: meta.Description, Homepage: meta.Homepage, Topics: meta.Topics}); err != nil {
+			return nil, true, err
+		}
+	}
+
+	m := r.toModel()
+	m.Homepage, m.Topics = meta.Homepage, meta.Topics
+
+	return &m, true, nil
+}
+
+// UpdateRepo applies meta to the repository, replacing its topics. Internal visibility is
+// applied as private.
+func (p *Provider) UpdateRepo(ctx context.Context, repo *models.Repository, meta provider.RepoMetadata) error {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return err
+	}
+
+	if err := p.edit(ctx, path, meta); err != nil {
+		return fmt.Errorf("gitea: update %s: %w", repo.Name, err)
+	}
+
+	return nil
+}
+
+func (p *Provider) edit(ctx context.Context, path string, meta provider.RepoMetadata) error {
+	body := map[string]any{
+		"description": meta.Description,
+		"website":     meta.Homepage,
+	}
+
+	if meta.Visibility != "" {
+		body["private"] = meta.Visibility != models.VisibilityPublic
+	}
+
+	if meta.DefaultBranch != "" {
+		body["default_branch"] = meta.DefaultBranch
+	}
+
+	if _, err := p.api.Send(ctx, http.MethodPatch, path, body, nil); err != nil {
+		return err
+	}
+
+	topics := meta.Topics
+	if topics == nil {
+		topics = []string{}
+	}
+
+	if _, err := p.api.Send(ctx, http.MethodPut, path+"/topics", map[string]any{"topics": topics}, nil); err != nil {
+		return fmt.Errorf("set topics: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package github
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/internal/httpapi"
+)
+
+var _ provider.DiscussionExporter = (*Provider)(nil)
+
+type apiUser struct {
+	Login string `json:"login"`
+}
+
+type apiLabel struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+type apiMilestone struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	State       string     `json:"state"`
+	DueOn       *time.Time `json:"due_on"`
+}
+
+type apiIssue struct {
+	Number    int        `json:"number"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	State     string     `json:"state"`
+	User      apiUser    `json:"user"`
+	Labels    []apiLabel `json:"labels"`
+	Assignees []apiUser  `json:"assignees"`
+	Milestone *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+	HTMLURL     string     `json:"html_url"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ClosedAt    *time.Time `json:"closed_at"`
+	PullRequest *struct {
+		MergedAt *time.Time `json:"merged_at"`
+	} `json:"pull_request"`
+}
+
+// toIssue maps an issue. The issues API lists pull requests too, marked by their pull_request field.
+func (i apiIssue) toIssue() provider.Issue {
+	issue := provider.Issue{
+		Number:    i.Number,
+		Title:     i.Title,
+		Body:      i.Body,
+		State:     i.State,
+		Author:    i.User.Login,
+		Labels:    make([]string, 0, len(i.Labels)),
+		Assignees: make([]string, 0, len(i.Assignees)),
+		URL:       i.HTMLURL,
+		CreatedAt: i.CreatedAt,
+		UpdatedAt: i.UpdatedAt,
+		ClosedAt:  i.ClosedAt,
+	}
+
+	for _, l := range i.Labels {
+		issue.Labels = append(issue.Labels, l.Name)
+	}
+
+	for _, a := range i.Assignees {
+		issue.Assignees = append(issue.Assignees, a.Login)
+	}
+
+	if i.Milestone != nil {
+		issue.Milestone = i.Milestone.Title
+	}
+
+	if i.PullRequest != nil {
+		issue.PullRequest = true
+		issue.MergedAt = i.PullRequest.MergedAt
+	}
+
+	return issue
+}
+
+// apiComment is an issue comment, which links its issue, or a review comment, which links its
+// pull request.
+type apiComment struct {
+	ID             int64     `json:"id"`
+	Body           string    `json:"body"`
+	User           apiUser   `json:"user"`
+	IssueURL       string    `json:"issue_url"`
+	PullRequestURL string    `json:"pull_request_url"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (c apiComment) toComment() provider.Comment {
+	parent := c.IssueURL
+	if c.PullRequestURL != "" {
+		parent = c.PullRequestURL
+	}
+
+	number, _ := strconv.Atoi(path.Base(parent))
+
+	return provider.Comment{
+		ID:          strconv.FormatInt(c.ID, 10),
+		IssueNumber: number,
+		PullRequest: c.PullRequestURL != "",
+		Author:      c.User.Login,
+		Body:        c.Body,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+// ListLabels returns the repository's labels.
+func (p *Provider) ListLabels(ctx context.Context, repo *models.Repository) ([]provider.Label, error) {
+	repoPath, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []provider.Label
+
+	err = httpapi.Paginate(ctx, p.api, repoPath+"/labels", nil, func(page []apiLabel) {
+		for _, l := range page {
+			labels = append(labels, provider.Label(l))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+// ListMilestones returns the repository's open and closed milestones.
+func (p *Provider) ListMilestones(ctx context.Context, repo *models.Repository) ([]provider.Milestone, error) {
+	repoPath, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var milestones []provider.Milestone
+
+	err = httpapi.Paginate(ctx, p.api, repoPath+"/milestones", url.Values{"state": {"all"}}, func(page []apiMilestone) {
+		for _, m := range page {
+			milestone := provider.Milestone{Title: m.Title, Description: m.Description, State: m.State}
+			if m.DueOn != nil {
+				milestone.DueOn = m.DueOn.Format(time.DateOnly)
+			}
+
+			milestones = append(milestones, milestone)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return milestones, nil
+}
+
+// ListIssues returns the repository's issues and pull requests updated at or after since,
+// least recently updated first.
+func (p *Provider) ListIssues(ctx context.Context, repo *models.Repository, since time.Time) ([]provider.Issue, error) {
+	repoPath, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []provider.Issue
+
+	err = httpapi.Paginate(ctx, p.api, repoPath+"/issues", sinceQuery(since, url.Values{"state": {"all"}}), func(page []apiIssue) {
+		for _, i := range page {
+			issues = append(issues, i.toIssue())
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return issues, nil
+}
+
+// ListComments returns the issue and pull request comments, then the review comments on pull
+// request diffs, updated at or after since.
+func (p *Provider) ListComments(ctx context.Context, repo *models.Repository, since time.Time) ([]provider.Comment, error) {
+	repoPath, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []provider.Comment
+
+	add := func(page []apiComment) {
+		for _, c := range page {
+			comments = append(comments, c.toComment())
+		}
+	}
+
+	for _, endpoint := range []string{"/issues/comments", "/pulls/comments"} {
+		if err := httpapi.Paginate(ctx, p.api, repoPath+endpoint, sinceQuery(since, nil), add); err != nil {
+			return nil, err
+		}
+	}
+
+	return comments, nil
+}
+
+// sinceQuery adds the parameters listing items by last update, from since on when it is set.
+func sinceQuery(since time.Time, query url.Values) url.Values {
+	if query == nil {
+		query = url.Values{}
+	}
+
+	query.Set("sort", "updated")
+	query.Set("direction", "asc")
+
+	if !since.IsZero() {
+		query.Set("since", since.UTC().Format(time.RFC3339))
+	}
+
+	return query
+}
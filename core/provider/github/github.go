@@ -0,0 +1,430 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"GitSyncer/core/git"
+	"GitSyncer/core/lfs"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/internal/httpapi"
+)
+
+const (
+	// DefaultBaseURL is the public GitHub REST API.
+	DefaultBaseURL = "https://api.github.com"
+
+	// gitUsername is the username GitHub accepts alongside a token for git over HTTPS.
+	gitUsername = "x-access-token"
+
+	perPage = 100
+)
+
+var (
+	_ provider.SourceControlProvider = (*Provider)(nil)
+	_ provider.RepoLister            = (*Provider)(nil)
+	_ provider.RateLimitReporter     = (*Provider)(nil)
+	_ provider.LFSProvider           = (*Provider)(nil)
+)
+
+// Schema describes the configuration accepted by New.
+var Schema = provider.ConfigSchema{
+	BaseURL: provider.OptionSpec{
+		Type:    provider.OptionTypeURL,
+		Default: DefaultBaseURL,
+		Help:    "API URL. For GitHub Enterprise Server use https://{host}/api/v3.",
+	},
+}
+
+// Provider implements provider.SourceControlProvider for GitHub and GitHub Enterprise Server.
+type Provider struct {
+	api *httpapi.Client
+
+	mu    sync.RWMutex
+	cred  *models.Credential
+	login string
+}
+
+// New creates a GitHub provider. An empty cfg.BaseURL or https://github.com targets the public
+// GitHub API; any other host is treated as GitHub Enterprise Server.
+func New(cfg provider.ProviderConfig) (provider.SourceControlProvider, error) {
+	api, err := httpapi.NewClient(provider.ProviderGitHub, apiBaseURL(cfg.BaseURL), cfg.Transport)
+	if err != nil {
+		return nil, fmt.Errorf("github.New: %w", err)
+	}
+
+	p := &Provider{api: api}
+	api.Authorize = p.authorize
+	api.PageQuery = url.Values{"per_page": {fmt.Sprint(perPage)}}
+
+	return p, nil
+}
+
+// Register adds the GitHub factory to the registry.
+func Register(r *provider.ProviderRegistry) error {
+	if err := r.RegisterSourceControlProviderFactory(provider.ProviderGitHub, New); err != nil {
+		return err
+	}
+
+	return r.RegisterSourceControlProviderSchema(provider.ProviderGitHub, Schema)
+}
+
+// Authenticate validates a token or OAuth credential against the /user endpoint.
+func (p *Provider) Authenticate(ctx context.Context, cred *models.Credential) error {
+	if cred == nil || (cred.AuthType != models.AuthTypeToken && cred.AuthType != models.AuthTypeOAuth) {
+		return &provider.AuthError{Provider: provider.ProviderGitHub, Message: "a token or oauth credential is required"}
+	}
+
+	p.mu.Lock()
+	p.cred = cred
+	p.mu.Unlock()
+
+	var user struct {
+		Login string `json:"login"`
+	}
+
+	if _, err := p.api.Get(ctx, "/user", nil, &user); err != nil {
+		p.mu.Lock()
+		p.cred = nil
+		p.mu.Unlock()
+
+		return err
+	}
+
+	p.mu.Lock()
+	p.login = user.Login
+	p.mu.Unlock()
+
+	return nil
+}
+
+type apiRepo struct {
+	FullName      string   `json:"full_name"`
+	CloneURL      string   `json:"clone_url"`
+	Description   string   `json:"description"`
+	Homepage      string   `json:"homepage"`
+	Topics        []string `json:"topics"`
+	MirrorURL     string   `json:"mirror_url"`
+	HasWiki       bool     `json:"has_wiki"`
+	DefaultBranch string   `json:"default_branch"`
+	Owner         struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	Private    bool       `json:"private"`
+	Visibility string     `json:"visibility"`
+	Archived   bool       `json:"archived"`
+	Fork       bool       `json:"fork"`
+	PushedAt   *time.Time `json:"pushed_at"`
+}
+
+// toModel maps a repository. has_wiki only reports that the wiki is enabled; it has no
+// repository until its first page is written.
+func (r apiRepo) toModel() models.Repository {
+	visibility := r.Visibility
+	if visibility == "" {
+		visibility = models.VisibilityPublic
+		if r.Private {
+			visibility = models.VisibilityPrivate
+		}
+	}
+
+	m := models.Repository{
+		Name:          r.FullName,
+		CloneURL:      r.CloneURL,
+		Description:   r.Description,
+		Homepage:      r.Homepage,
+		Topics:        r.Topics,
+		IsMirror:      r.MirrorURL != "",
+		HasWiki:       r.HasWiki,
+		DefaultBranch: r.DefaultBranch,
+		Owner:         r.Owner.Login,
+		Visibility:    visibility,
+		IsArchived:    r.Archived,
+		IsFork:        r.Fork,
+		PushedAt:      r.PushedAt,
+	}
+
+	if m.HasWiki {
+		m.WikiCloneURL = provider.WikiCloneURL(m.CloneURL)
+	}
+
+	return m
+}
+
+// ListRepos returns every repository the token can access: owned, collaborator and organization member repos.
+func (p *Provider) ListRepos(ctx context.Context) ([]models.Repository, error) {
+	if err := p.requireAuth(); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{
+		"affiliation": {"owner,collaborator,organization_member"},
+		"per_page":    {fmt.Sprint(perPage)},
+	}
+
+	var repos []models.Repository
+
+	next := "/user/repos"
+
+	for next != "" {
+		var page []apiRepo
+
+		resp, err := p.api.Get(ctx, next, query, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range page {
+			repos = append(repos, r.toModel())
+		}
+
+		// The next link already carries the query parameters.
+		next = httpapi.NextLink(resp)
+		query = nil
+	}
+
+	return repos, nil
+}
+
+// ListReposWithOptions lists one page of repositories. An owner selects /user/repos (for the
+// authenticated login), /orgs/{owner}/repos or /users/{owner}/repos; visibility, or fork for
+// organizations, is filtered server-side. With PushedSince, results are sorted by push time and
+// the listing stops at the first older repository. Archived is always filtered client-side.
+// The cursor is the next page URL.
+func (p *Provider) ListReposWithOptions(ctx context.Context, opts provider.ListReposOptions) (*provider.RepoPage, error) {
+	if err := p.requireAuth(); err != nil {
+		return nil, err
+	}
+
+	if err := p.api.CheckLink(opts.Cursor); err != nil {
+		return nil, err
+	}
+
+	path, query := opts.Cursor, url.Values(nil)
+
+	if path == "" {
+		path, query = p.listEndpoint(opts)
+	}
+
+	var page []apiRepo
+
+	resp, err := p.api.Get(ctx, path, query, &page)
+	if err != nil && opts.Cursor == "" && strings.HasPrefix(path, "/orgs/") && provider.IsNotFound(err) {
+		// The owner is a user rather than an organization; the users endpoint has no fork or visibility type.
+		path = "/users/" + url.PathEscape(opts.Owner) + "/repos"
+		query.Del("type")
+		resp, err = p.api.Get(ctx, path, query, &page)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &provider.RepoPage{NextCursor: httpapi.NextLink(resp)}
+
+	for _, r := range page {
+		repo := r.toModel()
+
+		if !opts.PushedSince.IsZero() && repo.PushedAt != nil && repo.PushedAt.Before(opts.PushedSince) {
+			result.NextCursor = ""
+
+			break
+		}
+
+		if opts.Matches(repo) {
+			result.Repos = append(result.Repos, repo)
+		}
+	}
+
+	return result, nil
+}
+
+// listEndpoint picks the endpoint and server-side filters for the first page of a listing.
+func (p *Provider) listEndpoint(opts provider.ListReposOptions) (string, url.Values) {
+	query := url.Values{"per_page": {fmt.Sprint(min(opts.Limit(), perPage))}}
+
+	if !opts.PushedSince.IsZero() {
+		query.Set("sort", "pushed")
+		query.Set("direction", "desc")
+	}
+
+	p.mu.RLock()
+	login := p.login
+	p.mu.RUnlock()
+
+	if opts.Owner == "" || strings.EqualFold(opts.Owner, login) {
+		affiliation := "owner,collaborator,organization_member"
+		if opts.Owner != "" {
+			affiliation = "owner"
+		}
+
+		query.Set("affiliation", affiliation)
+
+		if opts.Visibility == models.VisibilityPublic || opts.Visibility == models.VisibilityPrivate {
+			query.Set("visibility", opts.Visibility)
+		}
+
+		return "/user/repos", query
+	}
+
+	switch {
+	case opts.Fork != nil && *opts.Fork:
+		query.Set("type", "forks")
+	case opts.Fork != nil:
+		query.Set("type", "sources")
+	case opts.Visibility == models.VisibilityPublic || opts.Visibility == models.VisibilityPrivate:
+		query.Set("type", opts.Visibility)
+	}
+
+	return "/orgs/" + url.PathEscape(opts.Owner) + "/repos", query
+}
+
+// CloneRepo creates or updates a bare mirror of repo at destPath and records it as repo.LocalPath.
+func (p *Provider) CloneRepo(ctx context.Context, repo *models.Repository, destPath string) error {
+	auth, err := p.gitAuth()
+	if err != nil {
+		return err
+	}
+
+	if err := git.CloneMirror(ctx, repo.CloneURL, destPath, auth); err != nil {
+		return fmt.Errorf("github: clone %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderGitHub, err))
+	}
+
+	repo.LocalPath = destPath
+
+	return nil
+}
+
+// PushMirror pushes the local mirror of repo to remoteURL on GitHub.
+func (p *Provider) PushMirror(ctx context.Context, repo *models.Repository, remoteURL string) error {
+	auth, err := p.gitAuth()
+	if err != nil {
+		return err
+	}
+
+	if err := git.PushMirror(ctx, repo.LocalPath, remoteURL, auth); err != nil {
+		return fmt.Errorf("github: push mirror %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderGitHub, err))
+	}
+
+	return nil
+}
+
+// LFSEndpoint returns the LFS server of the repository cloneURL addresses on GitHub,
+// authenticated like git.
+func (p *Provider) LFSEndpoint(cloneURL string) (*lfs.Endpoint, error) {
+	lfsURL, err := provider.LFSURL(cloneURL)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := p.gitAuth()
+	if err != nil {
+		return nil, err
+	}
+
+	return &lfs.Endpoint{URL: lfsURL, Auth: auth}, nil
+}
+
+// ValidateURL reports whether url points at github.com or at the configured Enterprise host.
+func (p *Provider) ValidateURL(rawURL string) bool {
+	if provider.DetectProviderType(rawURL) == provider.ProviderGitHub {
+		return true
+	}
+
+	host := strings.ToLower(p.api.BaseURL.Hostname())
+
+	return host != "api.github.com" && provider.ExtractHost(rawURL) == host
+}
+
+func (p *Provider) GetProviderType() provider.ProviderType {
+	return provider.ProviderGitHub
+}
+
+func (p *Provider) Capabilities() []provider.SourceControlProviderCapability {
+	return []provider.SourceControlProviderCapability{
+		provider.CapabilityAPI,
+		provider.CapabilityTokenAuth,
+		provider.CapabilityOAuth,
+		provider.CapabilityWebhooks,
+		provider.CapabilityWiki,
+		provider.CapabilityLFS,
+		provider.CapabilityReleases,
+		provider.CapabilityIssues,
+	}
+}
+
+// RateLimits returns the API quota reported on the most recent responses.
+func (p *Provider) RateLimits() []provider.RateLimitStatus {
+	return p.api.RateLimits()
+}
+
+// apiBaseURL resolves the REST API root for an instance URL.
+// GitHub Enterprise Server serves the API under /api/v3 unless an API path is given explicitly.
+func apiBaseURL(baseURL string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	switch provider.ExtractHost(baseURL) {
+	case "", "github.com", "www.github.com", "api.github.com":
+		return DefaultBaseURL
+	}
+
+	if strings.Contains(baseURL, "/api/") {
+		return baseURL
+	}
+
+	return baseURL + "/api/v3"
+}
+
+func (p *Provider) authorize(req *http.Request) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred != nil {
+		req.Header.Set("Authorization", "Bearer "+p.cred.AuthData)
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+}
+
+func (p *Provider) requireAuth() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred == nil {
+		return &provider.AuthError{Provider: provider.ProviderGitHub, Message: "not authenticated"}
+	}
+
+	return nil
+}
+
+// repoPath returns the API path of the repository repo.CloneURL addresses, requiring authentication.
+func (p *Provider) repoPath(repo *models.Repository) (string, error) {
+	if err := p.requireAuth(); err != nil {
+		return "", err
+	}
+
+	g, err := provider.ParseGitURL(repo.CloneURL)
+	if err != nil || g.Owner == "" || g.Name == "" {
+		return "", fmt.Errorf("github: cannot determine owner/name from %q", repo.CloneURL)
+	}
+
+	return "/repos/" + url.PathEscape(g.Owner) + "/" + url.PathEscape(g.Name), nil
+}
+
+func (p *Provider) gitAuth() (*git.Auth, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred == nil {
+		return nil, nil
+	}
+
+	return git.AuthFromCredential(p.cred, gitUsername)
+}
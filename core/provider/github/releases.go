@@ -0,0 +1,222 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/internal/httpapi"
+)
+
+var _ provider.ReleaseManager = (*Provider)(nil)
+
+type apiRelease struct {
+	ID         int64      `json:"id"`
+	TagName    string     `json:"tag_name"`
+	Name       string     `json:"name"`
+	Body       string     `json:"body"`
+	Draft      bool       `json:"draft"`
+	Prerelease bool       `json:"prerelease"`
+	Assets     []apiAsset `json:"assets"`
+}
+
+func (r apiRelease) toRelease() *provider.Release {
+	rel := &provider.Release{
+		ID:         strconv.FormatInt(r.ID, 10),
+		TagName:    r.TagName,
+		Name:       r.Name,
+		Body:       r.Body,
+		Draft:      r.Draft,
+		Prerelease: r.Prerelease,
+		Assets:     make([]provider.ReleaseAsset, 0, len(r.Assets)),
+	}
+
+	for _, a := range r.Assets {
+		rel.Assets = append(rel.Assets, *a.toAsset())
+	}
+
+	return rel
+}
+
+type apiAsset struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	Digest string `json:"digest"`
+	URL    string `json:"url"`
+}
+
+// toAsset maps an asset. Downloads go through the API URL, which works for private repositories.
+func (a apiAsset) toAsset() *provider.ReleaseAsset {
+	sum, _ := strings.CutPrefix(a.Digest, "sha256:")
+
+	return &provider.ReleaseAsset{
+		ID:          strconv.FormatInt(a.ID, 10),
+		Name:        a.Name,
+		Size:        a.Size,
+		SHA256:      sum,
+		DownloadURL: a.URL,
+	}
+}
+
+// ListReleases returns the repository's releases, drafts included when the token can push.
+func (p *Provider) ListReleases(ctx context.Context, repo *models.Repository) ([]provider.Release, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	path += "/releases"
+	query := url.Values{"per_page": {fmt.Sprint(perPage)}}
+
+	var releases []provider.Release
+
+	for path != "" {
+		var page []apiRelease
+
+		resp, err := p.api.Get(ctx, path, query, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range page {
+			releases = append(releases, *r.toRelease())
+		}
+
+		path = httpapi.NextLink(resp)
+		query = nil
+	}
+
+	return releases, nil
+}
+
+// CreateRelease publishes a release for the existing tag cfg.TagName.
+func (p *Provider) CreateRelease(ctx context.Context, repo *models.Repository, cfg provider.ReleaseConfig) (*provider.Release, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var r apiRelease
+
+	if _, err := p.api.Send(ctx, http.MethodPost, path+"/releases", releaseBody(cfg), &r); err != nil {
+		return nil, fmt.Errorf("github: create release %s on %s: %w", cfg.TagName, repo.Name, err)
+	}
+
+	return r.toRelease(), nil
+}
+
+// UpdateRelease replaces the tag, name, notes and prerelease flag of the release id.
+func (p *Provider) UpdateRelease(ctx context.Context, repo *models.Repository, id string, cfg provider.ReleaseConfig) (*provider.Release, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var r apiRelease
+
+	if _, err := p.api.Send(ctx, http.MethodPatch, path+"/releases/"+url.PathEscape(id), releaseBody(cfg), &r); err != nil {
+		return nil, fmt.Errorf("github: update release %s on %s: %w", cfg.TagName, repo.Name, err)
+	}
+
+	return r.toRelease(), nil
+}
+
+// UploadReleaseAsset uploads an asset through the uploads host, uploads.github.com or the
+// Enterprise instance's /api/uploads.
+func (p *Provider) UploadReleaseAsset(ctx context.Context, repo *models.Repository, id, name string, content io.Reader, size int64) (*provider.ReleaseAsset, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	target := p.uploadsURL() + path + "/releases/" + url.PathEscape(id) + "/assets"
+
+	req, err := p.api.NewRequest(ctx, http.MethodPost, target, url.Values{"name": {name}}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = io.NopCloser(content)
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := p.api.Stream(req)
+	if err != nil {
+		return nil, fmt.Errorf("github: upload %s to %s: %w", name, repo.Name, err)
+	}
+	defer resp.Body.Close()
+
+	var a apiAsset
+
+	if err := json.NewDecoder(resp.Body).Decode(&a); err != nil {
+		return nil, fmt.Errorf("github: decode uploaded asset %s: %w", name, err)
+	}
+
+	return a.toAsset(), nil
+}
+
+// DownloadReleaseAsset streams the asset, following GitHub's redirect to its storage.
+func (p *Provider) DownloadReleaseAsset(ctx context.Context, repo *models.Repository, asset provider.ReleaseAsset) (io.ReadCloser, error) {
+	if err := p.requireAuth(); err != nil {
+		return nil, err
+	}
+
+	req, err := p.api.NewRequest(ctx, http.MethodGet, asset.DownloadURL, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/octet-stream")
+
+	resp, err := p.api.Stream(req)
+	if err != nil {
+		return nil, fmt.Errorf("github: download %s from %s: %w", asset.Name, repo.Name, err)
+	}
+
+	return resp.Body, nil
+}
+
+// DeleteReleaseAsset removes the asset; GitHub addresses assets without their release.
+func (p *Provider) DeleteReleaseAsset(ctx context.Context, repo *models.Repository, _ string, asset provider.ReleaseAsset) error {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.api.Send(ctx, http.MethodDelete, path+"/releases/assets/"+url.PathEscape(asset.ID), nil, nil); err != nil {
+		return fmt.Errorf("github: delete asset %s on %s: %w", asset.Name, repo.Name, err)
+	}
+
+	return nil
+}
+
+// uploadsURL returns the root of the uploads API that matches the REST API root.
+func (p *Provider) uploadsURL() string {
+	base := *p.api.BaseURL
+
+	if strings.EqualFold(base.Host, "api.github.com") {
+		base.Host = "uploads.github.com"
+		return base.String()
+	}
+
+	base.Path = strings.TrimSuffix(base.Path, "/v3") + "/uploads"
+
+	return base.String()
+}
+
+func releaseBody(cfg provider.ReleaseConfig) map[string]any {
+	return map[string]any{
+		"tag_name":   cfg.TagName,
+		"name":       cfg.Name,
+		"body":       cfg.Body,
+		"prerelease": cfg.Prerelease,
+	}
+}
@@ -0,0 +1,136 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+)
+
+var _ provider.RepoManager = (*Provider)(nil)
+
+// GetRepo returns the repository repo.CloneURL addresses.
+func (p *Provider) GetRepo(ctx context.Context, repo *models.Repository) (*models.Repository, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var r apiRepo
+
+	if _, err := p.api.Get(ctx, path, nil, &r); err != nil {
+		return nil, err
+	}
+
+	m := r.toModel()
+
+	return &m, nil
+}
+
+// EnsureRepo returns owner/name, creating it without an initial commit under the authenticated
+// user or the organization owner. Internal visibility is only available to organizations on
+// GitHub Enterprise.
+func (p *Provider) EnsureRepo(ctx context.Context, owner, name string, meta provider.RepoMetadata) (*models.Repository, bool, error) {
+	if err := p.requireAuth(); err != nil {
+		return nil, false, err
+	}
+
+	path := "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name)
+
+	var r apiRepo
+
+	_, err := p.api.Get(ctx, path, nil, &r)
+	if err == nil {
+		m := r.toModel()
+		return &m, false, nil
+	}
+
+	if !provider.IsNotFound(err) {
+		return nil, false, err
+	}
+
+	p.mu.RLock()
+	login := p.login
+	p.mu.RUnlock()
+
+	create := "/orgs/" + url.PathEscape(owner) + "/repos"
+	if strings.EqualFold(owner, login) {
+		create = "/user/repos"
+	}
+
+	body := map[string]any{
+		"name":        name,
+		"description": meta.Description,
+		"homepage":    meta.Homepage,
+		"private":     meta.Visibility != models.VisibilityPublic,
+		"auto_init":   false,
+	}
+
+	if meta.Visibility == models.VisibilityInternal && create != "/user/repos" {
+		body["visibility"] = models.VisibilityInternal
+	}
+
+	if _, err := p.api.Send(ctx, http.MethodPost, create, body, &r); err != nil {
+		return nil, false, fmt.Errorf("github: create %s/%s: %w", owner, name, err)
+	}
+
+	if len(meta.Topics) > 0 {
+		if err := p.setTopics(ctx, path, meta.Topics); err != nil {
+			return nil, true, err
+		}
+	}
+
+	m := r.toModel()
+	m.Topics = topicNames(meta.Topics)
+
+	return &m, true, nil
+}
+
+// UpdateRepo applies meta to the repository, replacing its topics.
+func (p *Provider) UpdateRepo(ctx context.Context, repo *models.Repository, meta provider.RepoMetadata) error {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]any{
+		"description": meta.Description,
+		"homepage":    meta.Homepage,
+	}
+
+	if meta.Visibility != "" {
+		body["visibility"] = meta.Visibility
+	}
+
+	if meta.DefaultBranch != "" {
+		body["default_branch"] = meta.DefaultBranch
+	}
+
+	if _, err := p.api.Send(ctx, http.MethodPatch, path, body, nil); err != nil {
+		return fmt.Errorf("github: update %s: %w", repo.Name, err)
+	}
+
+	return p.setTopics(ctx, path, meta.Topics)
+}
+
+func (p *Provider) setTopics(ctx context.Context, path string, topics []string) error {
+	if _, err := p.api.Send(ctx, http.MethodPut, path+"/topics", map[string]any{"names": topicNames(topics)}, nil); err != nil {
+		return fmt.Errorf("github: set topics: %w", err)
+	}
+
+	return nil
+}
+
+// topicNames lowercases topics, which GitHub requires.
+func topicNames(topics []string) []string {
+	names := make([]string, 0, len(topics))
+	for _, t := range topics {
+		names = append(names, strings.ToLower(t))
+	}
+
+	return names
+}
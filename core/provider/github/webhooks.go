@@ -0,0 +1,138 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/internal/httpapi"
+)
+
+var _ provider.WebhookManager = (*Provider)(nil)
+
+// hookEvents are the events GitSyncer subscribes to; create and delete cover new and removed refs.
+var hookEvents = []string{"push", "create", "delete"}
+
+type apiHook struct {
+	ID     int64    `json:"id"`
+	Active bool     `json:"active"`
+	Events []string `json:"events"`
+	Config struct {
+		URL string `json:"url"`
+	} `json:"config"`
+}
+
+func (h apiHook) toWebhook() *provider.Webhook {
+	return &provider.Webhook{
+		ID:     strconv.FormatInt(h.ID, 10),
+		URL:    h.Config.URL,
+		Events: h.Events,
+		Active: h.Active,
+	}
+}
+
+// ListWebhooks returns the repository's webhooks. It requires admin access to the repository.
+func (p *Provider) ListWebhooks(ctx context.Context, repo *models.Repository) ([]provider.Webhook, error) {
+	path, err := p.hooksPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{"per_page": {fmt.Sprint(perPage)}}
+
+	var hooks []provider.Webhook
+
+	for path != "" {
+		var page []apiHook
+
+		resp, err := p.api.Get(ctx, path, query, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, h := range page {
+			hooks = append(hooks, *h.toWebhook())
+		}
+
+		path = httpapi.NextLink(resp)
+		query = nil
+	}
+
+	return hooks, nil
+}
+
+// CreateWebhook adds an active JSON webhook signed with cfg.Secret.
+func (p *Provider) CreateWebhook(ctx context.Context, repo *models.Repository, cfg provider.WebhookConfig) (*provider.Webhook, error) {
+	path, err := p.hooksPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	body := hookBody(cfg)
+	body["name"] = "web"
+
+	var hook apiHook
+
+	if _, err := p.api.Send(ctx, http.MethodPost, path, body, &hook); err != nil {
+		return nil, fmt.Errorf("github: create webhook on %s: %w", repo.Name, err)
+	}
+
+	return hook.toWebhook(), nil
+}
+
+// UpdateWebhook replaces the URL, secret and events of the hook id and reactivates it.
+func (p *Provider) UpdateWebhook(ctx context.Context, repo *models.Repository, id string, cfg provider.WebhookConfig) (*provider.Webhook, error) {
+	path, err := p.hooksPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var hook apiHook
+
+	if _, err := p.api.Send(ctx, http.MethodPatch, path+"/"+url.PathEscape(id), hookBody(cfg), &hook); err != nil {
+		return nil, fmt.Errorf("github: update webhook %s on %s: %w", id, repo.Name, err)
+	}
+
+	return hook.toWebhook(), nil
+}
+
+// DeleteWebhook removes the hook id.
+func (p *Provider) DeleteWebhook(ctx context.Context, repo *models.Repository, id string) error {
+	path, err := p.hooksPath(repo)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.api.Send(ctx, http.MethodDelete, path+"/"+url.PathEscape(id), nil, nil); err != nil {
+		return fmt.Errorf("github: delete webhook %s on %s: %w", id, repo.Name, err)
+	}
+
+	return nil
+}
+
+func hookBody(cfg provider.WebhookConfig) map[string]any {
+	return map[string]any{
+		"active": true,
+		"events": hookEvents,
+		"config": map[string]any{
+			"url":          cfg.URL,
+			"content_type": "json",
+			"secret":       cfg.Secret,
+			"insecure_ssl": "0",
+		},
+	}
+}
+
+// hooksPath returns the hooks endpoint of the repository repo.CloneURL addresses.
+func (p *Provider) hooksPath(repo *models.Repository) (string, error) {
+	path, err := p.repoPath(repo)
+	if err != nil {
+		return "", err
+	}
+
+	return path + "/hooks", nil
+}
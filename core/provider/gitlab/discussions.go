@@ -0,0 +1,221 @@
+package gitlab
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/internal/httpapi"
+)
+
+var _ provider.DiscussionExporter = (*Provider)(nil)
+
+type apiUser struct {
+	Username string `json:"username"`
+}
+
+type apiLabel struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+type apiMilestone struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	State       string `json:"state"`
+	DueDate     string `json:"due_date"`
+}
+
+// apiIssue is an issue or a merge request; merge requests are numbered separately.
+type apiIssue struct {
+	IID         int       `json:"iid"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	State       string    `json:"state"`
+	Author      apiUser   `json:"author"`
+	Labels      []string  `json:"labels"`
+	Assignees   []apiUser `json:"assignees"`
+	Milestone   *struct {
+		Title string `json:"title"`
+	} `json:"milestone"`
+	WebURL    string     `json:"web_url"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+	MergedAt  *time.Time `json:"merged_at"`
+}
+
+func (i apiIssue) toIssue(mergeRequest bool) provider.Issue {
+	issue := provider.Issue{
+		Number:      i.IID,
+		PullRequest: mergeRequest,
+		Title:       i.Title,
+		Body:        i.Description,
+		State:       i.State,
+		Author:      i.Author.Username,
+		Labels:      i.Labels,
+		Assignees:   make([]string, 0, len(i.Assignees)),
+		URL:         i.WebURL,
+		CreatedAt:   i.CreatedAt,
+		UpdatedAt:   i.UpdatedAt,
+		ClosedAt:    i.ClosedAt,
+		MergedAt:    i.MergedAt,
+	}
+
+	if issue.Labels == nil {
+		issue.Labels = []string{}
+	}
+
+	for _, a := range i.Assignees {
+		issue.Assignees = append(issue.Assignees, a.Username)
+	}
+
+	if i.Milestone != nil {
+		issue.Milestone = i.Milestone.Title
+	}
+
+	return issue
+}
+
+type apiNote struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	Author    apiUser   `json:"author"`
+	System    bool      `json:"system"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ListLabels returns the project's labels.
+func (p *Provider) ListLabels(ctx context.Context, repo *models.Repository) ([]provider.Label, error) {
+	projectPath, err := p.projectPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []provider.Label
+
+	err = httpapi.Paginate(ctx, p.api, projectPath+"/labels", nil, func(page []apiLabel) {
+		for _, l := range page {
+			labels = append(labels, provider.Label(l))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+// ListMilestones returns the project's active and closed milestones.
+func (p *Provider) ListMilestones(ctx context.Context, repo *models.Repository) ([]provider.Milestone, error) {
+	projectPath, err := p.projectPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var milestones []provider.Milestone
+
+	err = httpapi.Paginate(ctx, p.api, projectPath+"/milestones", nil, func(page []apiMilestone) {
+		for _, m := range page {
+			milestones = append(milestones, provider.Milestone{Title: m.Title, Description: m.Description, State: m.State, DueOn: m.DueDate})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return milestones, nil
+}
+
+// ListIssues returns the project's issues, then its merge requests, updated at or after since.
+func (p *Provider) ListIssues(ctx context.Context, repo *models.Repository, since time.Time) ([]provider.Issue, error) {
+	projectPath, err := p.projectPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []provider.Issue
+
+	for _, mergeRequests := range []bool{false, true} {
+		endpoint := projectPath + "/issues"
+		if mergeRequests {
+			endpoint = projectPath + "/merge_requests"
+		}
+
+		err := httpapi.Paginate(ctx, p.api, endpoint, updatedQuery(since), func(page []apiIssue) {
+			for _, i := range page {
+				issues = append(issues, i.toIssue(mergeRequests))
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return issues, nil
+}
+
+// ListComments returns the notes updated at or after since on the issues and merge requests
+// updated since then; a new or edited note updates its issue. System notes are left out.
+func (p *Provider) ListComments(ctx context.Context, repo *models.Repository, since time.Time) ([]provider.Comment, error) {
+	issues, err := p.ListIssues(ctx, repo, since)
+	if err != nil {
+		return nil, err
+	}
+
+	projectPath, err := p.projectPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []provider.Comment
+
+	for _, issue := range issues {
+		endpoint := projectPath + "/issues/" + strconv.Itoa(issue.Number) + "/notes"
+		if issue.PullRequest {
+			endpoint = projectPath + "/merge_requests/" + strconv.Itoa(issue.Number) + "/notes"
+		}
+
+		query := url.Values{"order_by": {"updated_at"}, "sort": {"asc"}}
+
+		err := httpapi.Paginate(ctx, p.api, endpoint, query, func(page []apiNote) {
+			for _, n := range page {
+				if n.System || n.UpdatedAt.Before(since) {
+					continue
+				}
+
+				comments = append(comments, provider.Comment{
+					ID:          strconv.FormatInt(n.ID, 10),
+					IssueNumber: issue.Number,
+					PullRequest: issue.PullRequest,
+					Author:      n.Author.Username,
+					Body:        n.Body,
+					CreatedAt:   n.CreatedAt,
+					UpdatedAt:   n.UpdatedAt,
+				})
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return comments, nil
+}
+
+// updatedQuery lists issues or merge requests of any state by last update, from since on when
+// it is set.
+func updatedQuery(since time.Time) url.Values {
+	query := url.Values{"scope": {"all"}, "state": {"all"}, "order_by": {"updated_at"}, "sort": {"asc"}}
+
+	if !since.IsZero() {
+		query.Set("updated_after", since.UTC().Format(time.RFC3339))
+	}
+
+	return query
+}
@@ -0,0 +1,422 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"GitSyncer/core/git"
+	"GitSyncer/core/lfs"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/internal/httpapi"
+)
+
+const (
+	// DefaultBaseURL is the gitlab.com instance.
+	DefaultBaseURL = "https://gitlab.com"
+
+	// OptionGroup limits ListRepos to a group (ID or full path) and its subgroups.
+	OptionGroup = "group"
+
+	// gitUsername is accepted by GitLab for personal, project, group and OAuth tokens over HTTPS.
+	gitUsername = "oauth2"
+
+	perPage = 100
+)
+
+var (
+	_ provider.SourceControlProvider = (*Provider)(nil)
+	_ provider.RepoLister            = (*Provider)(nil)
+	_ provider.RateLimitReporter     = (*Provider)(nil)
+	_ provider.LFSProvider           = (*Provider)(nil)
+)
+
+// Schema describes the configuration accepted by New.
+var Schema = provider.ConfigSchema{
+	BaseURL: provider.OptionSpec{
+		Type:    provider.OptionTypeURL,
+		Default: DefaultBaseURL,
+		Help:    "Instance URL, e.g. https://gitlab.example.com.",
+	},
+	Options: []provider.OptionSpec{
+		{Name: OptionGroup, Type: provider.OptionTypeString, Help: "List only this group (ID or full path) and its subgroups."},
+	},
+}
+
+// Provider implements provider.SourceControlProvider for gitlab.com and self-managed GitLab (API v4).
+type Provider struct {
+	api   *httpapi.Client
+	host  string
+	group string
+
+	mu     sync.RWMutex
+	cred   *models.Credential
+	scopes []string
+}
+
+// New creates a GitLab provider. cfg.BaseURL is the instance URL, e.g. https://gitlab.example.com.
+func New(cfg provider.ProviderConfig) (provider.SourceControlProvider, error) {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	apiURL := baseURL
+	if !strings.HasSuffix(apiURL, "/api/v4") {
+		apiURL += "/api/v4"
+	}
+
+	api, err := httpapi.NewClient(provider.ProviderGitLab, apiURL, cfg.Transport)
+	if err != nil {
+		return nil, fmt.Errorf("gitlab.New: %w", err)
+	}
+
+	p := &Provider{
+		api:   api,
+		host:  strings.ToLower(api.BaseURL.Hostname()),
+		group: cfg.Options[OptionGroup],
+	}
+	api.Authorize = p.authorize
+	api.PageQuery = url.Values{"per_page": {fmt.Sprint(perPage)}}
+
+	return p, nil
+}
+
+// Register adds the GitLab factory to the registry.
+func Register(r *provider.ProviderRegistry) error {
+	if err := r.RegisterSourceControlProviderFactory(provider.ProviderGitLab, New); err != nil {
+		return err
+	}
+
+	return r.RegisterSourceControlProviderSchema(provider.ProviderGitLab, Schema)
+}
+
+// Authenticate validates the credential and records the token scopes.
+// Personal, project and group access tokens use the "token" auth type; OAuth tokens use "oauth".
+func (p *Provider) Authenticate(ctx context.Context, cred *models.Credential) error {
+	if cred == nil || (cred.AuthType != models.AuthTypeToken && cred.AuthType != models.AuthTypeOAuth) {
+		return &provider.AuthError{Provider: provider.ProviderGitLab, Message: "a token or oauth credential is required"}
+	}
+
+	p.mu.Lock()
+	p.cred = cred
+	p.scopes = nil
+	p.mu.Unlock()
+
+	scopes, err := p.fetchScopes(ctx, cred)
+	if err != nil {
+		p.mu.Lock()
+		p.cred = nil
+		p.mu.Unlock()
+
+		return err
+	}
+
+	p.mu.Lock()
+	p.scopes = scopes
+	p.mu.Unlock()
+
+	return nil
+}
+
+// fetchScopes verifies the credential. Access tokens of every kind can introspect themselves
+// via /personal_access_tokens/self; OAuth tokens fall back to /user and are assumed to carry the api scope.
+func (p *Provider) fetchScopes(ctx context.Context, cred *models.Credential) ([]string, error) {
+	if cred.AuthType == models.AuthTypeToken {
+		var token struct {
+			Scopes []string `json:"scopes"`
+			Active bool     `json:"active"`
+		}
+
+		_, err := p.api.Get(ctx, "/personal_access_tokens/self", nil, &token)
+		if err == nil {
+			if !token.Active {
+				return nil, &provider.AuthError{Provider: provider.ProviderGitLab, Message: "token is inactive"}
+			}
+
+			return token.Scopes, nil
+		}
+
+		// Instances older than 15.5 do not expose the endpoint.
+		if !provider.IsNotFound(err) {
+			return nil, err
+		}
+	}
+
+	var user struct {
+		Username string `json:"username"`
+	}
+
+	if _, err := p.api.Get(ctx, "/user", nil, &user); err != nil {
+		return nil, err
+	}
+
+	return []string{"api"}, nil
+}
+
+type apiProject struct {
+	PathWithNamespace string   `json:"path_with_namespace"`
+	HTTPURLToRepo     string   `json:"http_url_to_repo"`
+	Description       string   `json:"description"`
+	Topics            []string `json:"topics"`
+	DefaultBranch     string   `json:"default_branch"`
+	Mirror            bool     `json:"mirror"`
+	WikiEnabled       bool     `json:"wiki_enabled"`
+	Visibility        string   `json:"visibility"`
+	Archived          bool     `json:"archived"`
+	Namespace         struct {
+		FullPath string `json:"full_path"`
+	} `json:"namespace"`
+	ForkedFromProject *struct {
+		ID int64 `json:"id"`
+	} `json:"forked_from_project"`
+	LastActivityAt *time.Time `json:"last_activity_at"`
+}
+
+// toModel maps a project. GitLab has no push timestamp, so last activity stands in for PushedAt.
+func (pr apiProject) toModel() models.Repository {
+	m := models.Repository{
+		Name:          pr.PathWithNamespace,
+		CloneURL:      pr.HTTPURLToRepo,
+		Description:   pr.Description,
+		Topics:        pr.Topics,
+		IsMirror:      pr.Mirror,
+		HasWiki:       pr.WikiEnabled,
+		DefaultBranch: pr.DefaultBranch,
+		Owner:         pr.Namespace.FullPath,
+		Visibility:    pr.Visibility,
+		IsArchived:    pr.Archived,
+		IsFork:        pr.ForkedFromProject != nil,
+		PushedAt:      pr.LastActivityAt,
+	}
+
+	if m.HasWiki {
+		m.WikiCloneURL = provider.WikiCloneURL(m.CloneURL)
+	}
+
+	return m
+}
+
+// ListRepos returns the projects the token is a member of, directly or through groups and subgroups.
+// If the "group" option is set, only that group's projects (including subgroups) are listed.
+func (p *Provider) ListRepos(ctx context.Context) ([]models.Repository, error) {
+	return provider.ListAllRepos(ctx, p, provider.ListReposOptions{})
+}
+
+// ListReposWithOptions lists one page of projects. Owner selects a group (with subgroups), falling
+// back to a user namespace; visibility, archived and pushed-since (as last_activity_after) are
+// filtered server-side, fork client-side. The cursor is the next page URL.
+func (p *Provider) ListReposWithOptions(ctx context.Context, opts provider.ListReposOptions) (*provider.RepoPage, error) {
+	if err := p.requireAuth(); err != nil {
+		return nil, err
+	}
+
+	if err := p.api.CheckLink(opts.Cursor); err != nil {
+		return nil, err
+	}
+
+	path, query := opts.Cursor, url.Values(nil)
+
+	if path == "" {
+		path, query = p.listEndpoint(opts)
+	}
+
+	var page []apiProject
+
+	resp, err := p.api.Get(ctx, path, query, &page)
+	if err != nil && opts.Cursor == "" && opts.Owner != "" && provider.IsNotFound(err) {
+		// The owner is a user namespace rather than a group.
+		query.Del("include_subgroups")
+		resp, err = p.api.Get(ctx, "/users/"+url.PathEscape(opts.Owner)+"/projects", query, &page)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &provider.RepoPage{NextCursor: httpapi.NextLink(resp)}
+
+	for _, pr := range page {
+		if repo := pr.toModel(); opts.Matches(repo) {
+			result.Repos = append(result.Repos, repo)
+		}
+	}
+
+	return result, nil
+}
+
+// listEndpoint picks the endpoint and server-side filters for the first page of a listing.
+func (p *Provider) listEndpoint(opts provider.ListReposOptions) (string, url.Values) {
+	query := url.Values{
+		"per_page": {fmt.Sprint(min(opts.Limit(), perPage))},
+		"order_by": {"id"},
+		"sort":     {"asc"},
+	}
+
+	if opts.Visibility != "" {
+		query.Set("visibility", opts.Visibility)
+	}
+
+	if opts.Archived != nil {
+		query.Set("archived", fmt.Sprint(*opts.Archived))
+	}
+
+	if !opts.PushedSince.IsZero() {
+		query.Set("last_activity_after", opts.PushedSince.UTC().Format(time.RFC3339))
+	}
+
+	group := opts.Owner
+	if group == "" {
+		group = p.group
+	}
+
+	if group != "" {
+		query.Set("include_subgroups", "true")
+
+		return "/groups/" + url.PathEscape(group) + "/projects", query
+	}
+
+	query.Set("membership", "true")
+
+	return "/projects", query
+}
+
+// CloneRepo creates or updates a bare mirror of repo at destPath and records it as repo.LocalPath.
+func (p *Provider) CloneRepo(ctx context.Context, repo *models.Repository, destPath string) error {
+	auth, err := p.gitAuth()
+	if err != nil {
+		return err
+	}
+
+	if err := git.CloneMirror(ctx, repo.CloneURL, destPath, auth); err != nil {
+		return fmt.Errorf("gitlab: clone %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderGitLab, err))
+	}
+
+	repo.LocalPath = destPath
+
+	return nil
+}
+
+// PushMirror pushes the local mirror of repo to remoteURL on this GitLab instance.
+func (p *Provider) PushMirror(ctx context.Context, repo *models.Repository, remoteURL string) error {
+	auth, err := p.gitAuth()
+	if err != nil {
+		return err
+	}
+
+	if err := git.PushMirror(ctx, repo.LocalPath, remoteURL, auth); err != nil {
+		return fmt.Errorf("gitlab: push mirror %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderGitLab, err))
+	}
+
+	return nil
+}
+
+// LFSEndpoint returns the LFS server of the repository cloneURL addresses on GitLab,
+// authenticated like git.
+func (p *Provider) LFSEndpoint(cloneURL string) (*lfs.Endpoint, error) {
+	lfsURL, err := provider.LFSURL(cloneURL)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := p.gitAuth()
+	if err != nil {
+		return nil, err
+	}
+
+	return &lfs.Endpoint{URL: lfsURL, Auth: auth}, nil
+}
+
+// ValidateURL reports whether url points at this GitLab instance.
+func (p *Provider) ValidateURL(rawURL string) bool {
+	return provider.ExtractHost(rawURL) == p.host
+}
+
+func (p *Provider) GetProviderType() provider.ProviderType {
+	return provider.ProviderGitLab
+}
+
+// Capabilities reports webhooks and mirror support only after authenticating with a credential
+// that carries the api scope, since both require write access to project settings.
+func (p *Provider) Capabilities() []provider.SourceControlProviderCapability {
+	caps := []provider.SourceControlProviderCapability{
+		provider.CapabilityAPI,
+		provider.CapabilityTokenAuth,
+		provider.CapabilityOAuth,
+		provider.CapabilityWiki,
+		provider.CapabilityLFS,
+		provider.CapabilityReleases,
+		provider.CapabilityIssues,
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if slices.Contains(p.scopes, "api") {
+		caps = append(caps, provider.CapabilityWebhooks, provider.CapabilityMirror)
+	}
+
+	return caps
+}
+
+// RateLimits returns the API quota reported on the most recent responses.
+func (p *Provider) RateLimits() []provider.RateLimitStatus {
+	return p.api.RateLimits()
+}
+
+func (p *Provider) authorize(req *http.Request) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred == nil {
+		return
+	}
+
+	if p.cred.AuthType == models.AuthTypeOAuth {
+		req.Header.Set("Authorization", "Bearer "+p.cred.AuthData)
+	} else {
+		req.Header.Set("PRIVATE-TOKEN", p.cred.AuthData)
+	}
+}
+
+func (p *Provider) requireAuth() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred == nil {
+		return &provider.AuthError{Provider: provider.ProviderGitLab, Message: "not authenticated"}
+	}
+
+	return nil
+}
+
+// projectPath returns the API path of the project repo.CloneURL addresses, requiring authentication.
+func (p *Provider) projectPath(repo *models.Repository) (string, error) {
+	if err := p.requireAuth(); err != nil {
+		return "", err
+	}
+
+	project := provider.ExtractRepoPath(repo.CloneURL)
+	if project == "" {
+		return "", fmt.Errorf("gitlab: cannot determine project path from %q", repo.CloneURL)
+	}
+
+	return "/projects/" + url.PathEscape(project), nil
+}
+
+func (p *Provider) gitAuth() (*git.Auth, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred == nil {
+		return nil, nil
+	}
+
+	return git.AuthFromCredential(p.cred, gitUsername)
+}
@@ -0,0 +1,185 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/internal/httpapi"
+)
+
+var _ provider.MirrorManager = (*Provider)(nil)
+
+type apiRemoteMirror struct {
+	ID                     int64      `json:"id"`
+	Enabled                bool       `json:"enabled"`
+	URL                    string     `json:"url"`
+	UpdateStatus           string     `json:"update_status"`
+	LastError              string     `json:"last_error"`
+	LastUpdateAt           *time.Time `json:"last_update_at"`
+	LastSuccessfulUpdateAt *time.Time `json:"last_successful_update_at"`
+	OnlyProtectedBranches  bool       `json:"only_protected_branches"`
+}
+
+func (m apiRemoteMirror) toNative() *provider.NativeMirror {
+	status := provider.MirrorStatusPending
+
+	switch m.UpdateStatus {
+	case "finished":
+		status = provider.MirrorStatusOK
+	case "failed", "to_retry":
+		status = provider.MirrorStatusFailed
+	case "started":
+		status = provider.MirrorStatusRunning
+	}
+
+	return &provider.NativeMirror{
+		ID:                    strconv.FormatInt(m.ID, 10),
+		Direction:             provider.MirrorPush,
+		RemoteURL:             provider.RedactURL(m.URL),
+		OnlyProtectedBranches: m.OnlyProtectedBranches,
+		Enabled:               m.Enabled,
+		Status:                status,
+		LastError:             m.LastError,
+		LastUpdateAt:          m.LastUpdateAt,
+		LastSuccessAt:         m.LastSuccessfulUpdateAt,
+	}
+}
+
+// MirrorDirections reports push mirrors only; pull mirroring is a GitLab Premium feature
+// configured through project settings rather than the remote mirrors API.
+func (p *Provider) MirrorDirections() []string {
+	return []string{provider.MirrorPush}
+}
+
+// ListMirrors returns the project's push mirrors.
+func (p *Provider) ListMirrors(ctx context.Context, repo *models.Repository) ([]provider.NativeMirror, error) {
+	path, err := p.mirrorsPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{"per_page": {fmt.Sprint(perPage)}}
+
+	var mirrors []provider.NativeMirror
+
+	for path != "" {
+		var page []apiRemoteMirror
+
+		resp, err := p.api.Get(ctx, path, query, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range page {
+			mirrors = append(mirrors, *m.toNative())
+		}
+
+		path = httpapi.NextLink(resp)
+		query = nil
+	}
+
+	return mirrors, nil
+}
+
+// CreateMirror adds an enabled push mirror to cfg.RemoteURL. GitLab keeps the credentials as
+// part of the mirror URL and masks them in responses.
+func (p *Provider) CreateMirror(ctx context.Context, repo *models.Repository, cfg provider.NativeMirrorConfig) (*provider.NativeMirror, error) {
+	if cfg.Direction != provider.MirrorPush {
+		return nil, fmt.Errorf("gitlab: %s mirror: %w", cfg.Direction, provider.ErrUnsupportedMirror)
+	}
+
+	path, err := p.mirrorsPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := url.Parse(cfg.RemoteURL)
+	if err != nil || (remote.Scheme != "https" && remote.Scheme != "http") {
+		return nil, fmt.Errorf("gitlab: push mirror URL %q must be http(s): %w", provider.RedactURL(cfg.RemoteURL), provider.ErrUnsupportedMirror)
+	}
+
+	if cfg.Username != "" || cfg.Password != "" {
+		remote.User = url.UserPassword(cfg.Username, cfg.Password)
+	}
+
+	body := map[string]any{
+		"url":                     remote.String(),
+		"enabled":                 true,
+		"only_protected_branches": cfg.OnlyProtectedBranches,
+		"keep_divergent_refs":     false,
+	}
+
+	var mirror apiRemoteMirror
+
+	if _, err := p.api.Send(ctx, http.MethodPost, path, body, &mirror); err != nil {
+		return nil, fmt.Errorf("gitlab: create push mirror on %s: %w", repo.Name, err)
+	}
+
+	return mirror.toNative(), nil
+}
+
+// UpdateMirror enables the mirror id and applies cfg.OnlyProtectedBranches.
+func (p *Provider) UpdateMirror(ctx context.Context, repo *models.Repository, id string, cfg provider.NativeMirrorConfig) (*provider.NativeMirror, error) {
+	path, err := p.mirrorsPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]any{
+		"enabled":                 true,
+		"only_protected_branches": cfg.OnlyProtectedBranches,
+	}
+
+	var mirror apiRemoteMirror
+
+	if _, err := p.api.Send(ctx, http.MethodPut, path+"/"+url.PathEscape(id), body, &mirror); err != nil {
+		return nil, fmt.Errorf("gitlab: update push mirror %s on %s: %w", id, repo.Name, err)
+	}
+
+	return mirror.toNative(), nil
+}
+
+// DeleteMirror removes the push mirror id. It requires GitLab 14.10 or later.
+func (p *Provider) DeleteMirror(ctx context.Context, repo *models.Repository, id string) error {
+	path, err := p.mirrorsPath(repo)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.api.Send(ctx, http.MethodDelete, path+"/"+url.PathEscape(id), nil, nil); err != nil {
+		return fmt.Errorf("gitlab: delete push mirror %s on %s: %w", id, repo.Name, err)
+	}
+
+	return nil
+}
+
+// SyncMirror starts an update of the push mirror id. GitLab rate-limits manual updates to one
+// every five minutes per mirror.
+func (p *Provider) SyncMirror(ctx context.Context, repo *models.Repository, id string) error {
+	path, err := p.mirrorsPath(repo)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.api.Send(ctx, http.MethodPost, path+"/"+url.PathEscape(id)+"/sync", nil, nil); err != nil {
+		return fmt.Errorf("gitlab: sync push mirror %s on %s: %w", id, repo.Name, err)
+	}
+
+	return nil
+}
+
+// mirrorsPath returns the remote mirrors endpoint of the project repo.CloneURL addresses.
+func (p *Provider) mirrorsPath(repo *models.Repository) (string, error) {
+	path, err := p.projectPath(repo)
+	if err != nil {
+		return "", err
+	}
+
+	return path + "/remote_mirrors", nil
+}
@@ -0,0 +1,208 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/internal/httpapi"
+)
+
+var _ provider.ReleaseManager = (*Provider)(nil)
+
+// apiRelease is a GitLab release. Releases are addressed by their tag, and their assets are
+// links; files are uploaded to the project and linked. GitLab has no drafts or prereleases.
+type apiRelease struct {
+	TagName     string `json:"tag_name"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Assets      struct {
+		Links []apiLink `json:"links"`
+	} `json:"assets"`
+}
+
+func (r apiRelease) toRelease() *provider.Release {
+	rel := &provider.Release{
+		ID:      r.TagName,
+		TagName: r.TagName,
+		Name:    r.Name,
+		Body:    r.Description,
+		Assets:  make([]provider.ReleaseAsset, 0, len(r.Assets.Links)),
+	}
+
+	for _, l := range r.Assets.Links {
+		rel.Assets = append(rel.Assets, *l.toAsset())
+	}
+
+	return rel
+}
+
+// apiLink is a release asset link. GitLab reports neither size nor checksum for links.
+type apiLink struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+func (l apiLink) toAsset() *provider.ReleaseAsset {
+	return &provider.ReleaseAsset{
+		ID:          strconv.FormatInt(l.ID, 10),
+		Name:        l.Name,
+		DownloadURL: l.URL,
+	}
+}
+
+// ListReleases returns the project's releases with their asset links.
+func (p *Provider) ListReleases(ctx context.Context, repo *models.Repository) ([]provider.Release, error) {
+	path, err := p.projectPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	path += "/releases"
+	query := url.Values{"per_page": {fmt.Sprint(perPage)}}
+
+	var releases []provider.Release
+
+	for path != "" {
+		var page []apiRelease
+
+		resp, err := p.api.Get(ctx, path, query, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range page {
+			releases = append(releases, *r.toRelease())
+		}
+
+		path = httpapi.NextLink(resp)
+		query = nil
+	}
+
+	return releases, nil
+}
+
+// CreateRelease publishes a release for the existing tag cfg.TagName. cfg.Prerelease is ignored.
+func (p *Provider) CreateRelease(ctx context.Context, repo *models.Repository, cfg provider.ReleaseConfig) (*provider.Release, error) {
+	path, err := p.projectPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]any{"tag_name": cfg.TagName, "name": cfg.Name, "description": cfg.Body}
+
+	var r apiRelease
+
+	if _, err := p.api.Send(ctx, http.MethodPost, path+"/releases", body, &r); err != nil {
+		return nil, fmt.Errorf("gitlab: create release %s on %s: %w", cfg.TagName, repo.Name, err)
+	}
+
+	return r.toRelease(), nil
+}
+
+// UpdateRelease replaces the name and notes of the release for the tag id; a release cannot
+// move to another tag.
+func (p *Provider) UpdateRelease(ctx context.Context, repo *models.Repository, id string, cfg provider.ReleaseConfig) (*provider.Release, error) {
+	path, err := p.projectPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]any{"name": cfg.Name, "description": cfg.Body}
+
+	var r apiRelease
+
+	if _, err := p.api.Send(ctx, http.MethodPut, path+"/releases/"+url.PathEscape(id), body, &r); err != nil {
+		return nil, fmt.Errorf("gitlab: update release %s on %s: %w", id, repo.Name, err)
+	}
+
+	return r.toRelease(), nil
+}
+
+// UploadReleaseAsset uploads content to the project and links the file from the release for
+// the tag id.
+func (p *Provider) UploadReleaseAsset(ctx context.Context, repo *models.Repository, id, name string, content io.Reader, _ int64) (*provider.ReleaseAsset, error) {
+	path, err := p.projectPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var upload struct {
+		FullPath string `json:"full_path"`
+	}
+
+	if _, err := p.api.Upload(ctx, path+"/uploads", nil, "file", name, content, &upload); err != nil {
+		return nil, fmt.Errorf("gitlab: upload %s to %s: %w", name, repo.Name, err)
+	}
+
+	body := map[string]any{"name": name, "url": p.webURL() + upload.FullPath}
+
+	var link apiLink
+
+	if _, err := p.api.Send(ctx, http.MethodPost, path+"/releases/"+url.PathEscape(id)+"/assets/links", body, &link); err != nil {
+		return nil, fmt.Errorf("gitlab: link %s on %s: %w", name, repo.Name, err)
+	}
+
+	return link.toAsset(), nil
+}
+
+// DownloadReleaseAsset streams the linked file. Links may point anywhere, so the token is only
+// sent to this instance.
+func (p *Provider) DownloadReleaseAsset(ctx context.Context, repo *models.Repository, asset provider.ReleaseAsset) (io.ReadCloser, error) {
+	if err := p.requireAuth(); err != nil {
+		return nil, err
+	}
+
+	var (
+		req *http.Request
+		err error
+	)
+
+	if p.ValidateURL(asset.DownloadURL) {
+		req, err = p.api.NewRequest(ctx, http.MethodGet, asset.DownloadURL, nil, nil)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, asset.DownloadURL, nil)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/octet-stream")
+
+	resp, err := p.api.Stream(req)
+	if err != nil {
+		return nil, fmt.Errorf("gitlab: download %s from %s: %w", asset.Name, repo.Name, err)
+	}
+
+	return resp.Body, nil
+}
+
+// DeleteReleaseAsset removes the link from the release for the tag id. The uploaded file stays
+// in the project.
+func (p *Provider) DeleteReleaseAsset(ctx context.Context, repo *models.Repository, id string, asset provider.ReleaseAsset) error {
+	path, err := p.projectPath(repo)
+	if err != nil {
+		return err
+	}
+
+	path += "/releases/" + url.PathEscape(id) + "/assets/links/" + url.PathEscape(asset.ID)
+
+	if _, err := p.api.Send(ctx, http.MethodDelete, path, nil, nil); err != nil {
+		return fmt.Errorf("gitlab: delete asset %s on %s: %w", asset.Name, repo.Name, err)
+	}
+
+	return nil
+}
+
+// webURL returns the instance URL that upload paths are relative to.
+func (p *Provider) webURL() string {
+	return strings.TrimSuffix(p.api.BaseURL.String(), "/api/v4")
+}
@@ -0,0 +1,120 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+)
+
+var _ provider.RepoManager = (*Provider)(nil)
+
+// GetRepo returns the project repo.CloneURL addresses. GitLab projects have no homepage.
+func (p *Provider) GetRepo(ctx context.Context, repo *models.Repository) (*models.Repository, error) {
+	path, err := p.projectPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr apiProject
+
+	if _, err := p.api.Get(ctx, path, nil, &pr); err != nil {
+		return nil, err
+	}
+
+	m := pr.toModel()
+
+	return &m, nil
+}
+
+// EnsureRepo returns the project name in the namespace owner, a user or (sub)group path,
+// creating it there when missing. The homepage is not replicated.
+func (p *Provider) EnsureRepo(ctx context.Context, owner, name string, meta provider.RepoMetadata) (*models.Repository, bool, error) {
+	if err := p.requireAuth(); err != nil {
+		return nil, false, err
+	}
+
+	owner = strings.Trim(owner, "/")
+
+	var pr apiProject
+
+	_, err := p.api.Get(ctx, "/projects/"+url.PathEscape(owner+"/"+name), nil, &pr)
+	if err == nil {
+		m := pr.toModel()
+		return &m, false, nil
+	}
+
+	if !provider.IsNotFound(err) {
+		return nil, false, err
+	}
+
+	var namespace struct {
+		ID int64 `json:"id"`
+	}
+
+	if _, err := p.api.Get(ctx, "/namespaces/"+url.PathEscape(owner), nil, &namespace); err != nil {
+		return nil, false, fmt.Errorf("gitlab: namespace %s: %w", owner, err)
+	}
+
+	visibility := meta.Visibility
+	if visibility == "" {
+		visibility = models.VisibilityPrivate
+	}
+
+	body := map[string]any{
+		"name":         name,
+		"path":         name,
+		"namespace_id": namespace.ID,
+		"description":  meta.Description,
+		"visibility":   visibility,
+		"topics":       topicsOrEmpty(meta.Topics),
+	}
+
+	if _, err := p.api.Send(ctx, http.MethodPost, "/projects", body, &pr); err != nil {
+		return nil, false, fmt.Errorf("gitlab: create %s/%s: %w", owner, name, err)
+	}
+
+	m := pr.toModel()
+
+	return &m, true, nil
+}
+
+// UpdateRepo applies meta to the project, replacing its topics.
+func (p *Provider) UpdateRepo(ctx context.Context, repo *models.Repository, meta provider.RepoMetadata) error {
+	path, err := p.projectPath(repo)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]any{
+		"description": meta.Description,
+		"topics":      topicsOrEmpty(meta.Topics),
+	}
+
+	if meta.Visibility != "" {
+		body["visibility"] = meta.Visibility
+	}
+
+	if meta.DefaultBranch != "" {
+		body["default_branch"] = meta.DefaultBranch
+	}
+
+	if _, err := p.api.Send(ctx, http.MethodPut, path, body, nil); err != nil {
+		return fmt.Errorf("gitlab: update %s: %w", repo.Name, err)
+	}
+
+	return nil
+}
+
+// topicsOrEmpty sends no topics as an empty list, which clears them, rather than null.
+func topicsOrEmpty(topics []string) []string {
+	if topics == nil {
+		return []string{}
+	}
+
+	return topics
+}
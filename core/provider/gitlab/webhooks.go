@@ -0,0 +1,140 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/internal/httpapi"
+)
+
+var _ provider.WebhookManager = (*Provider)(nil)
+
+type apiHook struct {
+	ID            int64  `json:"id"`
+	URL           string `json:"url"`
+	PushEvents    bool   `json:"push_events"`
+	TagPushEvents bool   `json:"tag_push_events"`
+
+	// AlertStatus is "executable" unless GitLab disabled the hook after repeated failures.
+	AlertStatus string `json:"alert_status"`
+}
+
+func (h apiHook) toWebhook() *provider.Webhook {
+	var events []string
+
+	if h.PushEvents {
+		events = append(events, "push")
+	}
+
+	if h.TagPushEvents {
+		events = append(events, "tag_push")
+	}
+
+	return &provider.Webhook{
+		ID:     strconv.FormatInt(h.ID, 10),
+		URL:    h.URL,
+		Events: events,
+		Active: h.AlertStatus == "" || h.AlertStatus == "executable",
+	}
+}
+
+// ListWebhooks returns the project's hooks. It requires the Maintainer role and the api scope.
+func (p *Provider) ListWebhooks(ctx context.Context, repo *models.Repository) ([]provider.Webhook, error) {
+	path, err := p.hooksPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{"per_page": {fmt.Sprint(perPage)}}
+
+	var hooks []provider.Webhook
+
+	for path != "" {
+		var page []apiHook
+
+		resp, err := p.api.Get(ctx, path, query, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, h := range page {
+			hooks = append(hooks, *h.toWebhook())
+		}
+
+		path = httpapi.NextLink(resp)
+		query = nil
+	}
+
+	return hooks, nil
+}
+
+// CreateWebhook adds a push and tag push hook that sends cfg.Secret as its token.
+func (p *Provider) CreateWebhook(ctx context.Context, repo *models.Repository, cfg provider.WebhookConfig) (*provider.Webhook, error) {
+	path, err := p.hooksPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var hook apiHook
+
+	if _, err := p.api.Send(ctx, http.MethodPost, path, hookBody(cfg), &hook); err != nil {
+		return nil, fmt.Errorf("gitlab: create webhook on %s: %w", repo.Name, err)
+	}
+
+	return hook.toWebhook(), nil
+}
+
+// UpdateWebhook replaces the URL, token and events of the hook id. Saving a hook re-enables it.
+func (p *Provider) UpdateWebhook(ctx context.Context, repo *models.Repository, id string, cfg provider.WebhookConfig) (*provider.Webhook, error) {
+	path, err := p.hooksPath(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var hook apiHook
+
+	if _, err := p.api.Send(ctx, http.MethodPut, path+"/"+url.PathEscape(id), hookBody(cfg), &hook); err != nil {
+		return nil, fmt.Errorf("gitlab: update webhook %s on %s: %w", id, repo.Name, err)
+	}
+
+	return hook.toWebhook(), nil
+}
+
+// DeleteWebhook removes the hook id.
+func (p *Provider) DeleteWebhook(ctx context.Context, repo *models.Repository, id string) error {
+	path, err := p.hooksPath(repo)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.api.Send(ctx, http.MethodDelete, path+"/"+url.PathEscape(id), nil, nil); err != nil {
+		return fmt.Errorf("gitlab: delete webhook %s on %s: %w", id, repo.Name, err)
+	}
+
+	return nil
+}
+
+func hookBody(cfg provider.WebhookConfig) map[string]any {
+	return map[string]any{
+		"url":                     cfg.URL,
+		"token":                   cfg.Secret,
+		"push_events":             true,
+		"tag_push_events":         true,
+		"enable_ssl_verification": true,
+	}
+}
+
+// hooksPath returns the hooks endpoint of the project repo.CloneURL addresses.
+func (p *Provider) hooksPath(repo *models.Repository) (string, error) {
+	path, err := p.projectPath(repo)
+	if err != nil {
+		return "", err
+	}
+
+	return path + "/hooks", nil
+}
@@ -0,0 +1,277 @@
+package provider
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Transport is the protocol a git remote is reached over.
+type Transport string
+
+const (
+	TransportHTTPS Transport = "https"
+	TransportHTTP  Transport = "http"
+	TransportSSH   Transport = "ssh"
+	TransportGit   Transport = "git"
+	TransportFile  Transport = "file"
+)
+
+// GitURL is a parsed git remote. Passwords and tokens embedded in the URL are discarded.
+type GitURL struct {
+	Transport Transport `json:"transport"`
+
+	// User is the login name from the URL, e.g. "git" in git@github.com:user/repo.git.
+	User string `json:"user,omitempty"`
+
+	// Host is the lower-cased hostname without port. It is empty for file remotes.
+	Host string `json:"host,omitempty"`
+
+	// Port is 0 when the URL does not name one.
+	Port int `json:"port,omitempty"`
+
+	// Path is the repository path without surrounding slashes or the ".git" suffix,
+	// e.g. "group/subgroup/repo". For file remotes it is the absolute slash-separated path.
+	Path string `json:"path"`
+
+	// Owner is the namespace part of Path ("group/subgroup") and Name its last segment ("repo").
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+}
+
+// scpLikePattern matches scp-style remotes such as "git@github.com:user/repo.git",
+// "org@vs-ssh.visualstudio.com:v3/org/project/repo" or "host:repo". The user is optional.
+var scpLikePattern = regexp.MustCompile(`^(?:([^@/]+)@)?(\[[^\]/]+\]|[^@/:\[\]]+):(.*)$`)
+
+// schemeTransports maps URL schemes accepted by git to their transport.
+var schemeTransports = map[string]Transport{
+	"https":   TransportHTTPS,
+	"http":    TransportHTTP,
+	"ssh":     TransportSSH,
+	"git+ssh": TransportSSH,
+	"ssh+git": TransportSSH,
+	"git":     TransportGit,
+}
+
+// ParseGitURL parses the remote forms git accepts: http(s)://, ssh://, git://, scp-style
+// [user@]host:path, file:// URLs and absolute paths.
+func ParseGitURL(rawURL string) (*GitURL, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return nil, fmt.Errorf("provider.ParseGitURL: empty url")
+	}
+
+	if local, ok := LocalPath(rawURL); ok {
+		return newGitURL(TransportFile, "", "", 0, filepath.ToSlash(local)), nil
+	}
+
+	if strings.HasPrefix(rawURL, "file://") {
+		return nil, fmt.Errorf("provider.ParseGitURL: file url with remote host %q", rawURL)
+	}
+
+	if !strings.Contains(rawURL, "://") {
+		m := scpLikePattern.FindStringSubmatch(rawURL)
+
+		// A single-letter host is a Windows drive, which git also treats as a local path.
+		if m == nil || len(m[2]) == 1 || strings.HasPrefix(m[3], "//") {
+			return nil, fmt.Errorf("provider.ParseGitURL: unrecognised url %q", rawURL)
+		}
+
+		host := strings.Trim(m[2], "[]")
+
+		return newGitURL(TransportSSH, m[1], host, 0, m[3]), nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("provider.ParseGitURL: %w", err)
+	}
+
+	transport, ok := schemeTransports[strings.ToLower(u.Scheme)]
+	if !ok {
+		return nil, fmt.Errorf("provider.ParseGitURL: unsupported scheme %q", u.Scheme)
+	}
+
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("provider.ParseGitURL: no host in %q", rawURL)
+	}
+
+	port := 0
+	if p := u.Port(); p != "" {
+		port, err = strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("provider.ParseGitURL: invalid port %q", p)
+		}
+	}
+
+	return newGitURL(transport, u.User.Username(), u.Hostname(), port, u.Path), nil
+}
+
+func newGitURL(transport Transport, user, host string, port int, repoPath string) *GitURL {
+	g := &GitURL{
+		Transport: transport,
+		User:      user,
+		Host:      strings.ToLower(host),
+		Port:      port,
+	}
+
+	if transport == TransportFile {
+		g.Path = strings.TrimSuffix(strings.TrimSuffix(repoPath, "/"), ".git")
+	} else {
+		g.Path = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	}
+
+	owner, name := path.Split(g.Path)
+	g.Owner = strings.Trim(owner, "/")
+	g.Name = name
+
+	return g
+}
+
+// hostAliases maps alternative hostnames of hosted services to the name used in canonical URLs.
+var hostAliases = map[string]string{
+	"www.github.com":          "github.com",
+	"api.github.com":          "github.com",
+	"ssh.github.com":          "github.com",
+	"altssh.gitlab.com":       "gitlab.com",
+	"altssh.bitbucket.org":    "bitbucket.org",
+	"api.bitbucket.org":       "bitbucket.org",
+	"ssh.dev.azure.com":       "dev.azure.com",
+	"vs-ssh.visualstudio.com": "dev.azure.com",
+}
+
+// caseInsensitiveProviders treat repository paths case-insensitively, so their canonical paths are lower-cased.
+var caseInsensitiveProviders = map[ProviderType]bool{
+	ProviderGitHub:              true,
+	ProviderGitLab:              true,
+	ProviderGitea:               true,
+	ProviderBitbucketCloud:      true,
+	ProviderBitbucketDataCenter: true,
+	ProviderAzureDevOps:         true,
+}
+
+// CanonicalHost returns the hostname identifying the service, folding SSH and API aliases
+// (ssh.dev.azure.com, api.github.com, ...) into the web host. It is empty for file remotes.
+func (g *GitURL) CanonicalHost() string {
+	if alias, ok := hostAliases[g.Host]; ok {
+		return alias
+	}
+
+	if strings.HasSuffix(g.Host, ".visualstudio.com") {
+		return "dev.azure.com"
+	}
+
+	return g.Host
+}
+
+// Canonical returns the transport-independent identity of the repository, e.g.
+// "github.com/user/repo". Users, credentials, ports and ".git" are dropped, and provider-specific
+// path variants are folded together: Azure SSH and legacy visualstudio.com paths become
+// "dev.azure.com/{org}/{project}/_git/{repo}", Bitbucket Data Center's "/scm/" and Gerrit's
+// authenticated "/a/" prefixes are removed. File remotes are returned as file:// URLs.
+func (g *GitURL) Canonical() string {
+	if g.Transport == TransportFile {
+		return "file://" + g.Path
+	}
+
+	host := g.CanonicalHost()
+	repoPath := g.Path
+	web := g.Transport == TransportHTTPS || g.Transport == TransportHTTP
+	providerType := matchHost(g.Host)
+
+	switch {
+	case host == "dev.azure.com":
+		repoPath = canonicalAzurePath(g.Host, repoPath)
+	case providerType == ProviderBitbucketDataCenter && web:
+		repoPath = strings.TrimPrefix(repoPath, "scm/")
+	case providerType == ProviderGerrit && web:
+		repoPath = strings.TrimPrefix(repoPath, "a/")
+	}
+
+	if caseInsensitiveProviders[providerType] {
+		repoPath = strings.ToLower(repoPath)
+	}
+
+	if repoPath == "" {
+		return host
+	}
+
+	return host + "/" + repoPath
+}
+
+// canonicalAzurePath rewrites the Azure Repos URL shapes to {org}/{project}/_git/{repo}.
+func canonicalAzurePath(host, repoPath string) string {
+	segments := strings.Split(repoPath, "/")
+
+	switch {
+	case (host == "ssh.dev.azure.com" || host == "vs-ssh.visualstudio.com") && len(segments) == 4 && segments[0] == "v3":
+		segments = []string{segments[1], segments[2], "_git", segments[3]}
+	case strings.HasSuffix(host, ".visualstudio.com") && host != "vs-ssh.visualstudio.com":
+		if len(segments) == 4 && strings.EqualFold(segments[0], "DefaultCollection") {
+			segments = segments[1:]
+		}
+
+		segments = append([]string{strings.TrimSuffix(host, ".visualstudio.com")}, segments...)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// CanonicalURL returns the canonical identity of rawURL (see GitURL.Canonical), or "" if it cannot be parsed.
+func CanonicalURL(rawURL string) string {
+	g, err := ParseGitURL(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return g.Canonical()
+}
+
+// SameHost reports whether two URLs belong to the same service, e.g. a provider's base URL and a clone URL.
+func SameHost(a, b string) bool {
+	ga, err := ParseGitURL(a)
+	if err != nil || ga.Host == "" {
+		return false
+	}
+
+	gb, err := ParseGitURL(b)
+	if err != nil {
+		return false
+	}
+
+	return ga.CanonicalHost() == gb.CanonicalHost()
+}
+
+// ResolveRelativeURL resolves ref against the remote base of the repository it is read from, as
+// git does for submodule URLs: "../lib.git" next to "https://host/org/app.git" becomes
+// "https://host/org/lib.git". A ref not starting with "./" or "../" is returned unchanged.
+func ResolveRelativeURL(base, ref string) string {
+	if !strings.HasPrefix(ref, "./") && !strings.HasPrefix(ref, "../") {
+		return ref
+	}
+
+	if strings.Contains(base, "://") {
+		u, err := url.Parse(base)
+		if err != nil {
+			return ref
+		}
+
+		u.Path = path.Join(u.Path, ref)
+
+		return u.String()
+	}
+
+	if local, ok := LocalPath(base); ok {
+		return filepath.Join(local, filepath.FromSlash(ref))
+	}
+
+	if m := scpLikePattern.FindStringSubmatch(base); m != nil {
+		return strings.TrimSuffix(base, m[3]) + path.Join(m[3], ref)
+	}
+
+	return ref
+}
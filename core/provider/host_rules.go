@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HostRule maps hosts matching Pattern to a provider type. Patterns take three forms:
+//
+//	git.example.com   the exact host
+//	*.example.com     example.com and any host below it
+//	gitlab.*          any host whose first label is "gitlab", the usual self-hosted naming
+type HostRule struct {
+	Pattern string       `json:"pattern"`
+	Type    ProviderType `json:"type"`
+
+	// APIURL is the API root for hosts whose API is not at the default location. It may be empty.
+	APIURL string `json:"api_url,omitempty"`
+}
+
+// builtinHostRules covers public hosting services and common self-hosted naming.
+var builtinHostRules = []HostRule{
+	{Pattern: "*.github.com", Type: ProviderGitHub},
+	{Pattern: "*.ghe.com", Type: ProviderGitHub},
+
+	{Pattern: "*.gitlab.com", Type: ProviderGitLab},
+	{Pattern: "framagit.org", Type: ProviderGitLab},
+	{Pattern: "salsa.debian.org", Type: ProviderGitLab},
+	{Pattern: "invent.kde.org", Type: ProviderGitLab},
+	{Pattern: "gitlab.*", Type: ProviderGitLab},
+
+	{Pattern: "*.gitea.com", Type: ProviderGitea},
+	{Pattern: "codeberg.org", Type: ProviderGitea},
+	{Pattern: "git.disroot.org", Type: ProviderGitea},
+	{Pattern: "gitea.*", Type: ProviderGitea},
+	{Pattern: "forgejo.*", Type: ProviderGitea},
+
+	{Pattern: "*.bitbucket.org", Type: ProviderBitbucketCloud},
+	{Pattern: "bitbucket.*", Type: ProviderBitbucketDataCenter},
+
+	{Pattern: "*.dev.azure.com", Type: ProviderAzureDevOps},
+	{Pattern: "*.visualstudio.com", Type: ProviderAzureDevOps},
+
+	{Pattern: "*.googlesource.com", Type: ProviderGerrit},
+	{Pattern: "review.opendev.org", Type: ProviderGerrit},
+	{Pattern: "gerrit.*", Type: ProviderGerrit},
+
+	{Pattern: "*.sr.ht", Type: ProviderSourceHut},
+}
+
+// BuiltinHostRules returns a copy of the rules used by DetectProviderType.
+func BuiltinHostRules() []HostRule {
+	return append([]HostRule(nil), builtinHostRules...)
+}
+
+// ValidateHostPattern checks that pattern is an exact host, a "*.domain" suffix or a "label.*" prefix.
+func ValidateHostPattern(pattern string) error {
+	name := strings.TrimSuffix(strings.TrimPrefix(pattern, "*."), ".*")
+
+	if name == "" || strings.Contains(name, "*") || strings.ContainsAny(name, "/:@ ") ||
+		strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".") || strings.Contains(name, "..") {
+		return fmt.Errorf("provider.ValidateHostPattern: invalid host pattern %q", pattern)
+	}
+
+	if strings.HasPrefix(pattern, "*.") && strings.HasSuffix(pattern, ".*") {
+		return fmt.Errorf("provider.ValidateHostPattern: invalid host pattern %q", pattern)
+	}
+
+	if strings.HasSuffix(pattern, ".*") && strings.Contains(name, ".") {
+		return fmt.Errorf("provider.ValidateHostPattern: %q: only the first label can precede .*", pattern)
+	}
+
+	return nil
+}
+
+// Matches reports whether host satisfies the rule's pattern. Matching is on whole labels,
+// so "*.github.com" does not match "notgithub.com" or "github.com.evil".
+func (r HostRule) Matches(host string) bool {
+	return r.specificity(strings.ToLower(host)) > 0
+}
+
+// specificity ranks how closely the pattern matches host: exact matches beat suffixes, longer
+// suffixes beat shorter ones, and first-label patterns rank lowest. Zero means no match.
+func (r HostRule) specificity(host string) int {
+	pattern := strings.ToLower(r.Pattern)
+
+	switch {
+	case strings.HasPrefix(pattern, "*."):
+		domain := pattern[2:]
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return 1 + len(domain)
+		}
+	case strings.HasSuffix(pattern, ".*"):
+		if strings.HasPrefix(host, strings.TrimSuffix(pattern, "*")) {
+			return 1
+		}
+	case host == pattern:
+		// Hostnames are at most 253 characters, so this outranks every suffix match.
+		return 1 << 16
+	}
+
+	return 0
+}
+
+// MatchHostRule returns the most specific rule in rules matching host. Rules of equal
+// specificity resolve to the earliest.
+func MatchHostRule(host string, rules []HostRule) (HostRule, bool) {
+	host = strings.ToLower(host)
+
+	var best HostRule
+
+	bestScore := 0
+
+	for _, r := range rules {
+		if score := r.specificity(host); score > bestScore {
+			best, bestScore = r, score
+		}
+	}
+
+	return best, bestScore > 0
+}
+
+// DetectProviderTypeWithRules is DetectProviderType with user-defined rules consulted before the
+// built-in ones. The matching rule is returned so its APIURL can be used; it is zero for local paths.
+func DetectProviderTypeWithRules(rawURL string, rules []HostRule) (HostRule, bool) {
+	if _, ok := LocalPath(rawURL); ok {
+		return HostRule{Type: ProviderLocal}, true
+	}
+
+	host := ExtractHost(rawURL)
+	if host == "" {
+		return HostRule{}, false
+	}
+
+	if rule, ok := MatchHostRule(host, rules); ok {
+		return rule, true
+	}
+
+	return MatchHostRule(host, builtinHostRules)
+}
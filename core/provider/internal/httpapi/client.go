@@ -0,0 +1,351 @@
+package httpapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/ratelimit"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Client is a small JSON REST client shared by the provider implementations.
+type Client struct {
+	Provider  provider.ProviderType
+	BaseURL   *url.URL
+	HTTP      *http.Client
+	Authorize func(req *http.Request)
+
+	// RateLimit is the transport under HTTP. It tracks quota, retries throttled requests and
+	// revalidates cached GET responses.
+	RateLimit *ratelimit.Transport
+
+	// PageQuery is added to the first request of Paginate to set the page size, e.g. per_page=100.
+	PageQuery url.Values
+
+	// ResponsePrefix is stripped from JSON response bodies before decoding,
+	// e.g. the ")]}'" line Gerrit prepends to guard against XSSI.
+	ResponsePrefix string
+}
+
+// NewClient creates a Client for the API rooted at baseURL. A *ratelimit.Transport is used as-is,
+// so clients sharing one share its quota and cache; any other transport is wrapped in a new one.
+func NewClient(pt provider.ProviderType, baseURL string, transport http.RoundTripper) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("httpapi.NewClient: parse base url %q: %w", baseURL, err)
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("httpapi.NewClient: base url %q must be absolute", baseURL)
+	}
+
+	rl, ok := transport.(*ratelimit.Transport)
+	if !ok {
+		rl = ratelimit.New(transport, ratelimit.DefaultPolicy)
+	}
+
+	return &Client{
+		Provider:  pt,
+		BaseURL:   u,
+		HTTP:      &http.Client{Timeout: defaultTimeout, Transport: rl},
+		RateLimit: rl,
+	}, nil
+}
+
+// RateLimits returns the quota last reported by the API, or nil if it is not tracked.
+func (c *Client) RateLimits() []provider.RateLimitStatus {
+	if c.RateLimit == nil {
+		return nil
+	}
+
+	return c.RateLimit.Statuses()
+}
+
+// CheckLink rejects an absolute link whose scheme or host differs from the base URL's, such as a
+// pagination cursor handed back by a caller. Requests to it would carry the client's credentials.
+func (c *Client) CheckLink(link string) error {
+	if !strings.HasPrefix(link, "http://") && !strings.HasPrefix(link, "https://") {
+		return nil
+	}
+
+	u, err := url.Parse(link)
+	if err != nil || u.Scheme != c.BaseURL.Scheme || !strings.EqualFold(u.Host, c.BaseURL.Host) {
+		return fmt.Errorf("httpapi: link %q is not on %s", link, c.BaseURL.Host)
+	}
+
+	return nil
+}
+
+// NewRequest builds a request for path relative to the base URL. A non-nil body is JSON-encoded.
+// An absolute URL (e.g. a pagination link) is used as-is.
+func (c *Client) NewRequest(ctx context.Context, method, path string, query url.Values, body any) (*http.Request, error) {
+	var target string
+
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		target = path
+	} else {
+		target = c.BaseURL.String() + "/" + strings.TrimPrefix(path, "/")
+	}
+
+	if len(query) > 0 {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+
+		target += sep + query.Encode()
+	}
+
+	var reader io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("httpapi: encode request body: %w", err)
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, fmt.Errorf("httpapi: new request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "GitSyncer")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.Authorize != nil {
+		c.Authorize(req)
+	}
+
+	return req, nil
+}
+
+// Do sends req, maps error statuses to provider errors and decodes a JSON response into out (if non-nil).
+func (c *Client) Do(req *http.Request, out any) (*http.Response, error) {
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, &provider.NetworkError{Provider: c.Provider, Message: req.Method + " " + req.URL.Path, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if err := CheckResponse(c.Provider, resp); err != nil {
+		return resp, err
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return resp, nil
+	}
+
+	var body io.Reader = resp.Body
+
+	if c.ResponsePrefix != "" {
+		br := bufio.NewReader(resp.Body)
+
+		if head, err := br.Peek(len(c.ResponsePrefix)); err == nil && string(head) == c.ResponsePrefix {
+			_, _ = br.Discard(len(c.ResponsePrefix))
+		}
+
+		body = br
+	}
+
+	if err := json.NewDecoder(body).Decode(out); err != nil {
+		return resp, fmt.Errorf("httpapi: decode %s %s: %w", req.Method, req.URL.Path, err)
+	}
+
+	return resp, nil
+}
+
+// Stream sends req like Do but returns the response with its body unread, for the caller to
+// close. No overall timeout applies, so large uploads and downloads are bounded by the request's
+// context only.
+func (c *Client) Stream(req *http.Request) (*http.Response, error) {
+	client := *c.HTTP
+	client.Timeout = 0
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, &provider.NetworkError{Provider: c.Provider, Message: req.Method + " " + req.URL.Path, Err: err}
+	}
+
+	if err := CheckResponse(c.Provider, resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Upload POSTs content as the file filename of the multipart form field, streaming it without
+// buffering, and decodes the JSON response into out (if non-nil).
+func (c *Client) Upload(ctx context.Context, path string, query url.Values, field, filename string, content io.Reader, out any) (*http.Response, error) {
+	req, err := c.NewRequest(ctx, http.MethodPost, path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	form := multipart.NewWriter(pw)
+
+	go func() {
+		part, err := form.CreateFormFile(field, filename)
+		if err == nil {
+			_, err = io.Copy(part, content)
+		}
+
+		if err == nil {
+			err = form.Close()
+		}
+
+		pw.CloseWithError(err)
+	}()
+
+	req.Body = pr
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := c.Stream(req)
+	if err != nil {
+		pr.Close()
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return resp, nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp, fmt.Errorf("httpapi: decode %s %s: %w", req.Method, req.URL.Path, err)
+	}
+
+	return resp, nil
+}
+
+// Get is a convenience wrapper for a GET request decoded into out.
+func (c *Client) Get(ctx context.Context, path string, query url.Values, out any) (*http.Response, error) {
+	req, err := c.NewRequest(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req, out)
+}
+
+// Send is a convenience wrapper for a request with a JSON body (if non-nil) decoded into out.
+func (c *Client) Send(ctx context.Context, method, path string, body, out any) (*http.Response, error) {
+	req, err := c.NewRequest(ctx, method, path, nil, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req, out)
+}
+
+// StatusError is returned for unexpected non-2xx responses that do not map to a typed provider error.
+type StatusError struct {
+	Provider   provider.ProviderType
+	StatusCode int
+	Method     string
+	Path       string
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s api [%s %s]: unexpected status %d: %s", e.Provider, e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// IsStatus reports whether err is a StatusError with the given status code.
+func IsStatus(err error, code int) bool {
+	var se *StatusError
+
+	return errors.As(err, &se) && se.StatusCode == code
+}
+
+// CheckResponse maps non-2xx responses to the provider error types via provider.ErrorFromStatus,
+// falling back to StatusError for statuses without one.
+func CheckResponse(pt provider.ProviderType, resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	msg := strings.TrimSpace(string(body))
+
+	// GitHub signals an exhausted quota and secondary rate limits with 403 rather than 429.
+	if ratelimit.Throttled(resp) {
+		return &provider.RateLimitError{Provider: pt, RetryAfter: ratelimit.RetryAfter(resp), Message: msg}
+	}
+
+	if err := provider.ErrorFromStatus(pt, resp.StatusCode, msg, ratelimit.RetryAfter(resp)); err != nil {
+		return err
+	}
+
+	return &StatusError{
+		Provider:   pt,
+		StatusCode: resp.StatusCode,
+		Method:     resp.Request.Method,
+		Path:       resp.Request.URL.Path,
+		Body:       msg,
+	}
+}
+
+// Paginate GETs path with query and follows rel="next" Link headers, passing each decoded page
+// to fn. Next links carry their own query, so query and c.PageQuery are sent with the first
+// request only.
+func Paginate[T any](ctx context.Context, c *Client, path string, query url.Values, fn func([]T)) error {
+	q := url.Values{}
+
+	for k, v := range c.PageQuery {
+		q[k] = v
+	}
+
+	for k, v := range query {
+		q[k] = v
+	}
+
+	for path != "" {
+		var page []T
+
+		resp, err := c.Get(ctx, path, q, &page)
+		if err != nil {
+			return err
+		}
+
+		fn(page)
+
+		path = NextLink(resp)
+		q = nil
+	}
+
+	return nil
+}
+
+var linkNextPattern = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="next"`)
+
+// NextLink returns the rel="next" URL from an RFC 8288 Link header, or "" if there is none.
+func NextLink(resp *http.Response) string {
+	m := linkNextPattern.FindStringSubmatch(resp.Header.Get("Link"))
+	if m == nil {
+		return ""
+	}
+
+	return m[1]
+}
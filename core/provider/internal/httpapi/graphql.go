@@ -0,0 +1,63 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"GitSyncer/core/provider"
+)
+
+// GraphQLError is returned when a GraphQL response carries an "errors" array.
+type GraphQLError struct {
+	Provider provider.ProviderType
+	Messages []string
+}
+
+func (e *GraphQLError) Error() string {
+	return fmt.Sprintf("%s graphql: %s", e.Provider, strings.Join(e.Messages, "; "))
+}
+
+// GraphQL posts query with variables to path and decodes the "data" member of the response into out (if non-nil).
+func (c *Client) GraphQL(ctx context.Context, path, query string, variables map[string]any, out any) error {
+	req, err := c.NewRequest(ctx, http.MethodPost, path, nil, map[string]any{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if _, err := c.Do(req, &envelope); err != nil {
+		return err
+	}
+
+	if len(envelope.Errors) > 0 {
+		gqlErr := &GraphQLError{Provider: c.Provider}
+
+		for _, e := range envelope.Errors {
+			gqlErr.Messages = append(gqlErr.Messages, e.Message)
+		}
+
+		return gqlErr
+	}
+
+	if out == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("httpapi: decode graphql data: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+
+	"GitSyncer/core/lfs"
+)
+
+// LFSProvider is implemented by providers that declare CapabilityLFS. It gives the Git LFS
+// server of a repository on the provider, with the credentials of the current session.
+type LFSProvider interface {
+	LFSEndpoint(cloneURL string) (*lfs.Endpoint, error)
+}
+
+// LFSURL returns the LFS server URL git-lfs uses for cloneURL by default: the repository's
+// HTTP(S) URL with ".git/info/lfs" appended. SSH and git:// remotes map to HTTPS on the same host.
+func LFSURL(cloneURL string) (string, error) {
+	u, err := ParseGitURL(cloneURL)
+	if err != nil {
+		return "", err
+	}
+
+	host := u.Host
+
+	switch u.Transport {
+	case TransportHTTP, TransportHTTPS:
+		if u.Port != 0 {
+			host += ":" + strconv.Itoa(u.Port)
+		}
+	case TransportFile:
+		return "", fmt.Errorf("provider.LFSURL: %s has no LFS server", cloneURL)
+	}
+
+	scheme := "https"
+	if u.Transport == TransportHTTP {
+		scheme = "http"
+	}
+
+	return scheme + "://" + host + "/" + u.Path + ".git/info/lfs", nil
+}
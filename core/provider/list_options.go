@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"GitSyncer/core/models"
+)
+
+// DefaultPageSize is used when ListReposOptions.PageSize is zero.
+const DefaultPageSize = 100
+
+// ListReposOptions narrows a repository listing. Zero values mean "no filter".
+type ListReposOptions struct {
+	// Owner is a user, organization, group, workspace or project key. Nested namespaces
+	// (e.g. GitLab subgroups) match their parent.
+	Owner string `json:"owner,omitempty"`
+
+	// Visibility is one of models.VisibilityPublic, VisibilityInternal or VisibilityPrivate.
+	Visibility string `json:"visibility,omitempty"`
+
+	// Archived and Fork select only archived/non-archived or fork/non-fork repositories when set.
+	Archived *bool `json:"archived,omitempty"`
+	Fork     *bool `json:"fork,omitempty"`
+
+	// PushedSince keeps repositories pushed to at or after this time.
+	PushedSince time.Time `json:"pushed_since"`
+
+	// PageSize is a hint for the number of repositories per page. Pages filtered
+	// client-side may hold fewer.
+	PageSize int `json:"page_size,omitempty"`
+
+	// Cursor continues a listing from RepoPage.NextCursor. It is opaque and provider-specific.
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// RepoPage is one page of a filtered repository listing.
+type RepoPage struct {
+	Repos []models.Repository `json:"repos"`
+
+	// NextCursor is empty on the last page.
+	NextCursor string `json:"next_cursor"`
+}
+
+// RepoLister is implemented by providers that can push ListReposOptions filters down to their API.
+type RepoLister interface {
+	ListReposWithOptions(ctx context.Context, opts ListReposOptions) (*RepoPage, error)
+}
+
+// Matches reports whether repo satisfies every filter in opts. Metadata the provider did not
+// report (empty visibility, nil PushedAt) never matches a filter on that field.
+func (opts ListReposOptions) Matches(repo models.Repository) bool {
+	if opts.Owner != "" {
+		owner := strings.ToLower(repo.Owner)
+		want := strings.ToLower(strings.Trim(opts.Owner, "/"))
+
+		if owner != want && !strings.HasPrefix(owner, want+"/") {
+			return false
+		}
+	}
+
+	if opts.Visibility != "" && !strings.EqualFold(repo.Visibility, opts.Visibility) {
+		return false
+	}
+
+	if opts.Archived != nil && repo.IsArchived != *opts.Archived {
+		return false
+	}
+
+	if opts.Fork != nil && repo.IsFork != *opts.Fork {
+		return false
+	}
+
+	if !opts.PushedSince.IsZero() && (repo.PushedAt == nil || repo.PushedAt.Before(opts.PushedSince)) {
+		return false
+	}
+
+	return true
+}
+
+// Filter returns the repositories in repos that match opts.
+func (opts ListReposOptions) Filter(repos []models.Repository) []models.Repository {
+	var matched []models.Repository
+
+	for _, r := range repos {
+		if opts.Matches(r) {
+			matched = append(matched, r)
+		}
+	}
+
+	return matched
+}
+
+// Limit returns PageSize, or DefaultPageSize when unset.
+func (opts ListReposOptions) Limit() int {
+	if opts.PageSize <= 0 {
+		return DefaultPageSize
+	}
+
+	return opts.PageSize
+}
+
+// ListReposPage lists one page of repositories matching opts. Providers implementing RepoLister
+// filter server-side; for the rest the full ListRepos result is filtered client-side and
+// paginated with an offset cursor.
+func ListReposPage(ctx context.Context, p SourceControlProvider, opts ListReposOptions) (*RepoPage, error) {
+	if lister, ok := p.(RepoLister); ok {
+		return lister.ListReposWithOptions(ctx, opts)
+	}
+
+	repos, err := p.ListRepos(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return PaginateRepos(repos, opts)
+}
+
+// PaginateRepos filters repos client-side and returns the page addressed by the offset cursor in opts.
+func PaginateRepos(repos []models.Repository, opts ListReposOptions) (*RepoPage, error) {
+	offset := 0
+
+	if opts.Cursor != "" {
+		n, err := strconv.Atoi(opts.Cursor)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("provider.PaginateRepos: invalid cursor %q", opts.Cursor)
+		}
+
+		offset = n
+	}
+
+	matched := opts.Filter(repos)
+	if offset >= len(matched) {
+		return &RepoPage{}, nil
+	}
+
+	end := min(offset+opts.Limit(), len(matched))
+	page := &RepoPage{Repos: matched[offset:end]}
+
+	if end < len(matched) {
+		page.NextCursor = strconv.Itoa(end)
+	}
+
+	return page, nil
+}
+
+// ListAllRepos follows ListReposPage cursors and returns every repository matching opts.
+func ListAllRepos(ctx context.Context, p SourceControlProvider, opts ListReposOptions) ([]models.Repository, error) {
+	var repos []models.Repository
+
+	for {
+		page, err := ListReposPage(ctx, p, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		repos = append(repos, page.Repos...)
+
+		if page.NextCursor == "" {
+			return repos, nil
+		}
+
+		opts.Cursor = page.NextCursor
+	}
+}
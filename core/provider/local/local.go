@@ -0,0 +1,285 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"GitSyncer/core/git"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+)
+
+// OptionCreateMissing controls whether PushMirror initialises a missing bare repository under the root.
+// Enabled unless set to "false".
+const OptionCreateMissing = "create_missing"
+
+// defaultDescription is the placeholder git writes to the description file of new repositories.
+const defaultDescription = "Unnamed repository; edit this file 'description' to name the repository."
+
+// Schema describes the configuration accepted by New.
+var Schema = provider.ConfigSchema{
+	BaseURL: provider.OptionSpec{
+		Type:     provider.OptionTypePath,
+		Required: true,
+		Help:     "Root directory of the bare repositories.",
+	},
+	Options: []provider.OptionSpec{
+		{Name: OptionCreateMissing, Type: provider.OptionTypeBool, Default: "true", Help: "Initialise missing bare repositories under the root when pushing mirrors."},
+	},
+}
+
+var _ provider.SourceControlProvider = (*Provider)(nil)
+
+// Provider implements provider.SourceControlProvider for a directory tree of bare repositories,
+// used for air-gapped backups and offline tests. No credentials are involved.
+type Provider struct {
+	root          string
+	createMissing bool
+}
+
+// New creates a local provider. cfg.BaseURL is the root directory as a plain path or file:// URL.
+func New(cfg provider.ProviderConfig) (provider.SourceControlProvider, error) {
+	root, ok := provider.LocalPath(cfg.BaseURL)
+	if !ok {
+		return nil, fmt.Errorf("local.New: base url %q must be an absolute path or file:// URL", cfg.BaseURL)
+	}
+
+	return &Provider{
+		root:          root,
+		createMissing: cfg.Options[OptionCreateMissing] != "false",
+	}, nil
+}
+
+// Register adds the local filesystem factory to the registry.
+func Register(r *provider.ProviderRegistry) error {
+	if err := r.RegisterSourceControlProviderFactory(provider.ProviderLocal, New); err != nil {
+		return err
+	}
+
+	return r.RegisterSourceControlProviderSchema(provider.ProviderLocal, Schema)
+}
+
+// Authenticate ignores cred and checks that the root, if it already exists, is a directory.
+// A missing root is allowed; PushMirror creates it on demand.
+func (p *Provider) Authenticate(ctx context.Context, cred *models.Credential) error {
+	info, err := os.Stat(p.root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("local.Authenticate: %w", err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("local.Authenticate: %s is not a directory", p.root)
+	}
+
+	return nil
+}
+
+// ListRepos walks the root and returns every bare repository below it. Repository names are
+// slash-separated paths relative to the root without the ".git" suffix, and nested repositories are not descended into.
+// A "name.wiki.git" repository next to "name.git" is reported as the latter's wiki rather than on its own.
+func (p *Provider) ListRepos(ctx context.Context) ([]models.Repository, error) {
+	var repos []models.Repository
+
+	err := filepath.WalkDir(p.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == p.root && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		// Working tree metadata is not a mirror source on its own.
+		if d.Name() == ".git" {
+			return fs.SkipDir
+		}
+
+		if !isBareRepository(path) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(p.root, path)
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimSuffix(filepath.ToSlash(rel), ".git")
+		if name == "." {
+			name = filepath.Base(strings.TrimSuffix(path, ".git"))
+		}
+
+		repos = append(repos, models.Repository{
+			Name:          name,
+			CloneURL:      path,
+			Description:   readDescription(path),
+			DefaultBranch: readDefaultBranch(path),
+			LocalPath:     path,
+			Owner:         namespace(name),
+		})
+
+		return fs.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("local.ListRepos: %w", err)
+	}
+
+	return attachWikis(repos), nil
+}
+
+// attachWikis folds wiki repositories into the repositories they sit next to.
+func attachWikis(repos []models.Repository) []models.Repository {
+	parents := make(map[string]*models.Repository, len(repos))
+	for i := range repos {
+		parents[provider.WikiCloneURL(repos[i].CloneURL)] = &repos[i]
+	}
+
+	wikis := make(map[string]bool)
+
+	for _, r := range repos {
+		if parent, ok := parents[r.CloneURL]; ok && provider.IsWikiURL(r.CloneURL) {
+			parent.HasWiki = true
+			parent.WikiCloneURL = r.CloneURL
+			wikis[r.CloneURL] = true
+		}
+	}
+
+	return slices.DeleteFunc(repos, func(r models.Repository) bool { return wikis[r.CloneURL] })
+}
+
+// namespace returns the directory part of a slash-separated repository name.
+func namespace(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[:i]
+	}
+
+	return ""
+}
+
+// isBareRepository reports whether dir has the layout of a bare repository.
+func isBareRepository(dir string) bool {
+	if info, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil || info.IsDir() {
+		return false
+	}
+
+	for _, sub := range []string{"objects", "refs"} {
+		if info, err := os.Stat(filepath.Join(dir, sub)); err != nil || !info.IsDir() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func readDescription(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "description"))
+	if err != nil {
+		return ""
+	}
+
+	desc := strings.TrimSpace(string(data))
+	if desc == defaultDescription {
+		return ""
+	}
+
+	return desc
+}
+
+func readDefaultBranch(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+
+	ref, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "ref: refs/heads/")
+	if !ok {
+		return ""
+	}
+
+	return ref
+}
+
+// CloneRepo creates or updates a bare mirror of repo at destPath and records it as repo.LocalPath.
+func (p *Provider) CloneRepo(ctx context.Context, repo *models.Repository, destPath string) error {
+	if err := git.CloneMirror(ctx, repo.CloneURL, destPath, nil); err != nil {
+		return fmt.Errorf("local: clone %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderLocal, err))
+	}
+
+	repo.LocalPath = destPath
+
+	return nil
+}
+
+// PushMirror pushes the local mirror of repo to remoteURL, initialising an empty bare repository
+// first if it is missing, lies under the root and the create_missing option allows it.
+// A remoteURL without a scheme or host is resolved relative to the root.
+func (p *Provider) PushMirror(ctx context.Context, repo *models.Repository, remoteURL string) error {
+	target, err := p.resolve(remoteURL)
+	if err != nil {
+		return fmt.Errorf("local: push mirror %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderLocal, err))
+	}
+
+	if p.createMissing && p.contains(target) && !isBareRepository(target) {
+		if err := git.InitBare(ctx, target); err != nil {
+			return fmt.Errorf("local: push mirror %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderLocal, err))
+		}
+	}
+
+	if err := git.PushMirror(ctx, repo.LocalPath, target, nil); err != nil {
+		return fmt.Errorf("local: push mirror %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderLocal, err))
+	}
+
+	return nil
+}
+
+func (p *Provider) resolve(remoteURL string) (string, error) {
+	if path, ok := provider.LocalPath(remoteURL); ok {
+		return path, nil
+	}
+
+	if remoteURL == "" || strings.Contains(remoteURL, "://") || provider.ExtractHost(remoteURL) != "" {
+		return "", fmt.Errorf("%q is not a local path", remoteURL)
+	}
+
+	return filepath.Join(p.root, filepath.FromSlash(remoteURL)), nil
+}
+
+// contains reports whether path lies strictly below the root.
+func (p *Provider) contains(path string) bool {
+	rel, err := filepath.Rel(p.root, path)
+
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// ValidateURL reports whether url is a path or file:// URL below the root.
+func (p *Provider) ValidateURL(rawURL string) bool {
+	path, ok := provider.LocalPath(rawURL)
+
+	return ok && p.contains(path)
+}
+
+func (p *Provider) GetProviderType() provider.ProviderType {
+	return provider.ProviderLocal
+}
+
+// Capabilities reports wiki support: wikis are stored as "name.wiki.git" next to "name.git".
+func (p *Provider) Capabilities() []provider.SourceControlProviderCapability {
+	return []provider.SourceControlProviderCapability{provider.CapabilityWiki}
+}
@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"GitSyncer/core/models"
+)
+
+// Native mirror directions.
+const (
+	// MirrorPush mirrors are configured on the source repository and push to the remote.
+	MirrorPush = "push"
+
+	// MirrorPull mirrors are repositories on the target that pull from the remote.
+	MirrorPull = "pull"
+)
+
+// Native mirror statuses, normalised from each provider's own.
+const (
+	MirrorStatusOK      = "ok"
+	MirrorStatusFailed  = "failed"
+	MirrorStatusRunning = "running"
+
+	// MirrorStatusPending is a mirror that has not completed an update yet.
+	MirrorStatusPending = "pending"
+)
+
+// ErrUnsupportedMirror is returned for mirror settings the provider cannot apply, such as a
+// direction it does not mirror in.
+var ErrUnsupportedMirror = errors.New("unsupported mirror configuration")
+
+// NativeMirrorConfig describes a server-side mirror to create or update.
+type NativeMirrorConfig struct {
+	// Direction is MirrorPush or MirrorPull.
+	Direction string `json:"direction"`
+
+	// RemoteURL is the push destination or the pull source.
+	RemoteURL string `json:"remote_url"`
+
+	// Username and Password authenticate against RemoteURL over HTTPS.
+	Username string `json:"-"`
+	Password string `json:"-"`
+
+	// Interval is how often a pull mirror fetches. Zero keeps the provider's default; push
+	// mirrors update on every push instead.
+	Interval time.Duration `json:"interval"`
+
+	// OnlyProtectedBranches limits a push mirror to protected branches.
+	OnlyProtectedBranches bool `json:"only_protected_branches"`
+}
+
+// NativeMirror is a server-side mirror as reported by the provider.
+type NativeMirror struct {
+	ID        string `json:"id"`
+	Direction string `json:"direction"`
+
+	// RemoteURL never carries credentials.
+	RemoteURL             string        `json:"remote_url"`
+	Interval              time.Duration `json:"interval"`
+	OnlyProtectedBranches bool          `json:"only_protected_branches"`
+	Enabled               bool          `json:"enabled"`
+
+	// Status is one of the MirrorStatus values and describes the most recent update.
+	Status        string     `json:"status"`
+	LastError     string     `json:"last_error"`
+	LastUpdateAt  *time.Time `json:"last_update_at"`
+	LastSuccessAt *time.Time `json:"last_success_at"`
+}
+
+// MirrorManager is implemented by providers advertising CapabilityMirror that can configure
+// mirrors on the server. repo is always the repository on this provider: the source of a push
+// mirror or the repository that is (or becomes) a pull mirror. URLs of mirrors cannot be
+// changed by UpdateMirror; delete and create the mirror instead.
+type MirrorManager interface {
+	// MirrorDirections returns the directions the provider supports.
+	MirrorDirections() []string
+
+	ListMirrors(ctx context.Context, repo *models.Repository) ([]NativeMirror, error)
+	CreateMirror(ctx context.Context, repo *models.Repository, cfg NativeMirrorConfig) (*NativeMirror, error)
+	UpdateMirror(ctx context.Context, repo *models.Repository, id string, cfg NativeMirrorConfig) (*NativeMirror, error)
+	DeleteMirror(ctx context.Context, repo *models.Repository, id string) error
+
+	// SyncMirror asks the provider to update the mirror now. The update runs asynchronously.
+	SyncMirror(ctx context.Context, repo *models.Repository, id string) error
+}
+
+// RedactURL returns rawURL without any user information, for display and logs.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+
+	u.User = nil
+
+	return u.String()
+}
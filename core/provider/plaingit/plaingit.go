@@ -0,0 +1,308 @@
+package plaingit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"GitSyncer/core/git"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+)
+
+const (
+	// OptionRepos is a newline- or comma-separated list of clone URLs returned by ListRepos.
+	OptionRepos = "repos"
+
+	// OptionGitolite enables listing through gitolite's "info" command on cfg.BaseURL when set to "true".
+	OptionGitolite = "gitolite"
+
+	// OptionUsername is the HTTPS username sent alongside "token" credentials. Defaults to "git".
+	OptionUsername = "username"
+)
+
+// Schema describes the configuration accepted by New.
+var Schema = provider.ConfigSchema{
+	BaseURL: provider.OptionSpec{
+		Type: provider.OptionTypeString,
+		Help: "gitolite SSH address (e.g. ssh://git@host:2222 or git@host) when gitolite is enabled; otherwise informational.",
+	},
+	Options: []provider.OptionSpec{
+		{Name: OptionRepos, Type: provider.OptionTypeList, Help: "Clone URLs to mirror, one per line or comma-separated."},
+		{Name: OptionGitolite, Type: provider.OptionTypeBool, Default: "false", Help: "List repositories with gitolite's info command."},
+		{Name: OptionUsername, Type: provider.OptionTypeString, Default: "git", Help: "HTTPS username sent with token credentials."},
+	},
+}
+
+var _ provider.SourceControlProvider = (*Provider)(nil)
+
+// Provider implements provider.SourceControlProvider for git servers without an API
+// (cgit, gitolite, plain SSH or HTTPS hosts). Repositories come from a configured list
+// or from gitolite; cloning and pushing use the git transport only.
+type Provider struct {
+	baseURL  string
+	repos    []string
+	gitolite bool
+	username string
+
+	mu   sync.RWMutex
+	cred *models.Credential
+}
+
+// New creates a plain git provider. cfg.BaseURL is the gitolite SSH address (e.g. ssh://git@host:2222
+// or git@host) when the gitolite option is set, and is otherwise only informational.
+func New(cfg provider.ProviderConfig) (provider.SourceControlProvider, error) {
+	p := &Provider{
+		baseURL:  strings.TrimSuffix(cfg.BaseURL, "/"),
+		repos:    splitList(cfg.Options[OptionRepos]),
+		gitolite: cfg.Options[OptionGitolite] == "true",
+		username: cfg.Options[OptionUsername],
+	}
+
+	if p.username == "" {
+		p.username = "git"
+	}
+
+	if p.gitolite {
+		if _, _, err := sshDestination(p.baseURL); err != nil {
+			return nil, fmt.Errorf("plaingit.New: gitolite: %w", err)
+		}
+	}
+
+	return p, nil
+}
+
+// Register adds the plain git factory to the registry.
+func Register(r *provider.ProviderRegistry) error {
+	if err := r.RegisterSourceControlProviderFactory(provider.ProviderPlainGit, New); err != nil {
+		return err
+	}
+
+	return r.RegisterSourceControlProviderSchema(provider.ProviderPlainGit, Schema)
+}
+
+func splitList(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == ',' })
+
+	list := make([]string, 0, len(fields))
+
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			list = append(list, f)
+		}
+	}
+
+	return list
+}
+
+// Authenticate stores the credential and verifies it with a cheap round trip:
+// gitolite's "info" command or "git ls-remote" against the first configured repository.
+// A nil credential is allowed for anonymous access.
+func (p *Provider) Authenticate(ctx context.Context, cred *models.Credential) error {
+	p.mu.Lock()
+	p.cred = cred
+	p.mu.Unlock()
+
+	auth, err := p.gitAuth()
+	if err != nil {
+		return &provider.AuthError{Provider: provider.ProviderPlainGit, Message: "invalid credential", Err: err}
+	}
+
+	switch {
+	case p.gitolite:
+		_, err = p.gitoliteInfo(ctx, auth)
+	case len(p.repos) > 0:
+		_, err = git.Run(ctx, "", auth, "ls-remote", "--heads", p.repos[0])
+	}
+
+	if err != nil {
+		p.mu.Lock()
+		p.cred = nil
+		p.mu.Unlock()
+
+		return provider.ClassifyGitError(provider.ProviderPlainGit, err)
+	}
+
+	return nil
+}
+
+// ListRepos returns the configured repository list, or the repositories gitolite reports as readable.
+func (p *Provider) ListRepos(ctx context.Context) ([]models.Repository, error) {
+	if !p.gitolite {
+		repos := make([]models.Repository, 0, len(p.repos))
+
+		for _, u := range p.repos {
+			repos = append(repos, models.Repository{Name: provider.ExtractRepoPath(u), CloneURL: u})
+		}
+
+		return repos, nil
+	}
+
+	auth, err := p.gitAuth()
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := p.gitoliteInfo(ctx, auth)
+	if err != nil {
+		return nil, provider.ClassifyGitError(provider.ProviderPlainGit, err)
+	}
+
+	var repos []models.Repository
+
+	for _, name := range parseGitoliteInfo(out) {
+		repos = append(repos, models.Repository{Name: name, CloneURL: p.gitoliteCloneURL(name)})
+	}
+
+	return repos, nil
+}
+
+func (p *Provider) gitoliteInfo(ctx context.Context, auth *git.Auth) (string, error) {
+	dest, port, err := sshDestination(p.baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	return git.RunSSH(ctx, auth, dest, port, "info")
+}
+
+// parseGitoliteInfo extracts readable repository names from "info" output, skipping
+// the greeting line and wildcard repository patterns.
+//
+//	hello alice, this is git@host running gitolite3 v3.6.12 on git 2.39.5
+//
+//	 R W	tools/build
+//	 R W C	users/CREATOR/..*
+func parseGitoliteInfo(out string) []string {
+	var names []string
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+
+	for scanner.Scan() {
+		perms, name, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok || !strings.Contains(perms, "R") {
+			continue
+		}
+
+		name = strings.TrimSpace(name)
+		if name == "" || strings.ContainsAny(name, "*?[]^$\\") || strings.Contains(name, "CREATOR") {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// gitoliteCloneURL builds a clone URL for name in the same form as the configured base URL.
+func (p *Provider) gitoliteCloneURL(name string) string {
+	if strings.HasPrefix(p.baseURL, "ssh://") {
+		return p.baseURL + "/" + name + ".git"
+	}
+
+	return p.baseURL + ":" + name + ".git"
+}
+
+// sshDestination converts ssh://user@host:port or user@host into an ssh destination and port.
+// A destination starting with "-" is rejected, as ssh would take it for an option.
+func sshDestination(baseURL string) (string, int, error) {
+	if baseURL == "" {
+		return "", 0, errors.New("base url is required")
+	}
+
+	if strings.HasPrefix(baseURL, "-") {
+		return "", 0, fmt.Errorf("%q must not start with \"-\"", baseURL)
+	}
+
+	if !strings.Contains(baseURL, "://") {
+		if !strings.Contains(baseURL, "@") {
+			return "", 0, fmt.Errorf("%q is not a user@host address", baseURL)
+		}
+
+		return strings.TrimSuffix(baseURL, ":"), 0, nil
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", 0, fmt.Errorf("parse %q: %w", baseURL, err)
+	}
+
+	if u.Scheme != "ssh" || u.User == nil {
+		return "", 0, fmt.Errorf("%q must be an ssh://user@host URL", baseURL)
+	}
+
+	if strings.HasPrefix(u.User.Username(), "-") {
+		return "", 0, fmt.Errorf("user in %q must not start with \"-\"", baseURL)
+	}
+
+	port := 0
+
+	if u.Port() != "" {
+		if port, err = strconv.Atoi(u.Port()); err != nil {
+			return "", 0, fmt.Errorf("invalid port in %q", baseURL)
+		}
+	}
+
+	return u.User.Username() + "@" + u.Hostname(), port, nil
+}
+
+// CloneRepo creates or updates a bare mirror of repo at destPath and records it as repo.LocalPath.
+func (p *Provider) CloneRepo(ctx context.Context, repo *models.Repository, destPath string) error {
+	auth, err := p.gitAuth()
+	if err != nil {
+		return err
+	}
+
+	if err := git.CloneMirror(ctx, repo.CloneURL, destPath, auth); err != nil {
+		return fmt.Errorf("plaingit: clone %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderPlainGit, err))
+	}
+
+	repo.LocalPath = destPath
+
+	return nil
+}
+
+// PushMirror pushes the local mirror of repo to remoteURL. The remote repository must already exist.
+func (p *Provider) PushMirror(ctx context.Context, repo *models.Repository, remoteURL string) error {
+	auth, err := p.gitAuth()
+	if err != nil {
+		return err
+	}
+
+	if err := git.PushMirror(ctx, repo.LocalPath, remoteURL, auth); err != nil {
+		return fmt.Errorf("plaingit: push mirror %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderPlainGit, err))
+	}
+
+	return nil
+}
+
+// ValidateURL accepts any URL with a recognisable host, since plain git places no constraints on it.
+func (p *Provider) ValidateURL(rawURL string) bool {
+	return provider.ExtractHost(rawURL) != ""
+}
+
+func (p *Provider) GetProviderType() provider.ProviderType {
+	return provider.ProviderPlainGit
+}
+
+// Capabilities reports transport-level support only: there is no API, so no webhooks,
+// native mirrors or OAuth. Tokens are sent as HTTPS basic auth passwords.
+func (p *Provider) Capabilities() []provider.SourceControlProviderCapability {
+	return []provider.SourceControlProviderCapability{
+		provider.CapabilitySSH,
+		provider.CapabilityTokenAuth,
+	}
+}
+
+func (p *Provider) gitAuth() (*git.Auth, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return git.AuthFromCredential(p.cred, p.username)
+}
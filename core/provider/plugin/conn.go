@@ -0,0 +1,229 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"GitSyncer/core/provider"
+)
+
+// shutdownGrace is how long a plugin may take to exit after shutdown before it is killed.
+const shutdownGrace = 5 * time.Second
+
+// maxMessageSize bounds a single protocol message.
+const maxMessageSize = 64 << 20
+
+// errClosed is returned for calls on a plugin process that has exited or been closed.
+var errClosed = errors.New("plugin process closed")
+
+// conn is the host side of a plugin process.
+type conn struct {
+	pt   provider.ProviderType
+	cmd  *exec.Cmd
+	in   io.WriteCloser
+	done chan struct{}
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan *message
+	err     error
+
+	closeOnce sync.Once
+}
+
+// start launches the plugin executable at path.
+func start(path string, pt provider.ProviderType) (*conn, error) {
+	cmd := exec.Command(path)
+
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin.start: %w", err)
+	}
+
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin.start: %w", err)
+	}
+
+	cmd.Stderr = &stderrLogger{prefix: "plugin " + filepath.Base(path) + ": "}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("plugin.start: %w", err)
+	}
+
+	c := &conn{
+		pt:      pt,
+		cmd:     cmd,
+		in:      in,
+		done:    make(chan struct{}),
+		pending: make(map[int64]chan *message),
+	}
+
+	go c.read(out)
+
+	return c, nil
+}
+
+// read dispatches responses until the plugin's stdout closes, then fails all pending calls.
+func (c *conn) read(out io.Reader) {
+	scanner := bufio.NewScanner(out)
+	scanner.Buffer(make([]byte, 64<<10), maxMessageSize)
+
+	for scanner.Scan() {
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil || msg.ID == nil || msg.Method != "" {
+			// Not a response to us; plugins may not send requests or notifications to the host.
+			continue
+		}
+
+		c.mu.Lock()
+		ch, ok := c.pending[*msg.ID]
+		delete(c.pending, *msg.ID)
+		c.mu.Unlock()
+
+		if ok {
+			ch <- &msg
+		}
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = errClosed
+	}
+
+	c.mu.Lock()
+	c.err = err
+	for id, ch := range c.pending {
+		close(ch)
+		delete(c.pending, id)
+	}
+	c.mu.Unlock()
+
+	// Reap the process so it does not linger as a zombie.
+	c.cmd.Wait()
+	close(c.done)
+}
+
+// call sends a request and decodes the result into result, which may be nil. If ctx ends
+// first, a cancel notification is sent and ctx's error returned.
+func (c *conn) call(ctx context.Context, method string, params, result any) error {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return fmt.Errorf("plugin %s: %s: %w", c.pt, method, c.err)
+	}
+
+	c.nextID++
+	id := c.nextID
+	ch := make(chan *message, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	if err := c.send(&id, method, params); err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+
+		return fmt.Errorf("plugin %s: %s: %w", c.pt, method, err)
+	}
+
+	select {
+	case msg, ok := <-ch:
+		if !ok {
+			return fmt.Errorf("plugin %s: %s: %w", c.pt, method, errClosed)
+		}
+
+		if msg.Error != nil {
+			return toError(c.pt, msg.Error)
+		}
+
+		if result == nil || len(msg.Result) == 0 {
+			return nil
+		}
+
+		if err := json.Unmarshal(msg.Result, result); err != nil {
+			return fmt.Errorf("plugin %s: %s: decode result: %w", c.pt, method, err)
+		}
+
+		return nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+
+		c.send(nil, MethodCancel, cancelParams{ID: id})
+
+		return ctx.Err()
+	}
+}
+
+// send writes one message. A nil id makes it a notification.
+func (c *conn) send(id *int64, method string, params any) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(message{JSONRPC: "2.0", ID: id, Method: method, Params: raw})
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_, err = c.in.Write(append(data, '\n'))
+
+	return err
+}
+
+// Close asks the plugin to shut down and kills it if it has not exited within shutdownGrace.
+func (c *conn) Close() error {
+	c.closeOnce.Do(func() {
+		c.send(nil, MethodShutdown, struct{}{})
+		c.in.Close()
+
+		select {
+		case <-c.done:
+		case <-time.After(shutdownGrace):
+			c.cmd.Process.Kill()
+			<-c.done
+		}
+	})
+
+	return nil
+}
+
+// stderrLogger logs each line a plugin writes to stderr.
+type stderrLogger struct {
+	prefix string
+	buf    []byte
+}
+
+func (l *stderrLogger) Write(p []byte) (int, error) {
+	l.buf = append(l.buf, p...)
+
+	for {
+		line, rest, ok := bytes.Cut(l.buf, []byte{'\n'})
+		if !ok {
+			break
+		}
+
+		log.Print(l.prefix + string(line))
+		l.buf = rest
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,149 @@
+// Package plugin runs providers as external executables. GitSyncer starts each executable
+// found in the plugins directory, reads its Manifest over the stdio protocol described in
+// protocol.go, and registers factories that launch one plugin process per provider instance.
+// Plugins written in Go can use Serve to implement the protocol around native providers.
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"GitSyncer/core/provider"
+)
+
+// DefaultDir returns the plugins directory next to the database, e.g. ~/.config/GitSyncer/plugins.
+func DefaultDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("plugin.DefaultDir: %w", err)
+	}
+
+	return filepath.Join(configDir, "GitSyncer", "plugins"), nil
+}
+
+// Discover performs the handshake with every executable in dir and returns the manifests of
+// those that answered. A missing dir yields no plugins; broken plugins are reported in the
+// joined error without stopping discovery of the others.
+func Discover(ctx context.Context, dir string) ([]Manifest, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("plugin.Discover: %w", err)
+	}
+
+	var (
+		manifests []Manifest
+		errs      []error
+	)
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if !isExecutable(path) {
+			continue
+		}
+
+		m, err := Inspect(ctx, path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		manifests = append(manifests, *m)
+	}
+
+	return manifests, errors.Join(errs...)
+}
+
+// Inspect starts the plugin at path, reads its manifest and stops it again.
+func Inspect(ctx context.Context, path string) (*Manifest, error) {
+	c, err := start(path, "")
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", filepath.Base(path), err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	m, err := readManifest(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", filepath.Base(path), err)
+	}
+
+	m.Path = path
+
+	return m, nil
+}
+
+// Register adds factories, and schemas when the plugin declares them, for each kind m serves.
+// It fails if a native provider or another plugin already uses the type.
+func Register(r *provider.ProviderRegistry, m Manifest) error {
+	if m.hasKind(KindSourceControl) {
+		if err := r.RegisterSourceControlProviderFactory(m.Type, newSourceControlFactory(m)); err != nil {
+			return fmt.Errorf("plugin.Register(%s): %w", m.Path, err)
+		}
+
+		if m.SourceControlSchema != nil {
+			if err := r.RegisterSourceControlProviderSchema(m.Type, *m.SourceControlSchema); err != nil {
+				return fmt.Errorf("plugin.Register(%s): %w", m.Path, err)
+			}
+		}
+	}
+
+	if m.hasKind(KindStorage) {
+		if err := r.RegisterStorageProviderFactory(m.Type, newStorageFactory(m)); err != nil {
+			return fmt.Errorf("plugin.Register(%s): %w", m.Path, err)
+		}
+
+		if m.StorageSchema != nil {
+			if err := r.RegisterStorageProviderSchema(m.Type, *m.StorageSchema); err != nil {
+				return fmt.Errorf("plugin.Register(%s): %w", m.Path, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Load discovers the plugins in dir and registers each one. It returns the manifests that
+// were registered and the joined errors of those that were not.
+func Load(ctx context.Context, r *provider.ProviderRegistry, dir string) ([]Manifest, error) {
+	manifests, err := Discover(ctx, dir)
+
+	errs := []error{err}
+	loaded := make([]Manifest, 0, len(manifests))
+
+	for _, m := range manifests {
+		if err := Register(r, m); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		loaded = append(loaded, m)
+	}
+
+	return loaded, errors.Join(errs...)
+}
+
+// isExecutable reports whether path is a regular file the host can run.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+
+	if runtime.GOOS == "windows" {
+		return slices.Contains([]string{".exe", ".bat", ".cmd"}, strings.ToLower(filepath.Ext(path)))
+	}
+
+	return info.Mode().Perm()&0o111 != 0
+}
@@ -0,0 +1,228 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+)
+
+// ProtocolVersion is the plugin protocol spoken by this build. The host sends it in the
+// handshake and refuses plugins answering with a different version.
+const ProtocolVersion = 1
+
+// Kinds of provider a plugin can serve.
+const (
+	KindSourceControl = "source_control"
+	KindStorage       = "storage"
+)
+
+// Protocol methods. Messages are JSON-RPC 2.0 objects, one per line, exchanged over the
+// plugin's stdin and stdout. Anything the plugin writes to stderr is logged by the host.
+const (
+	// MethodHandshake negotiates the protocol version and returns the plugin's Manifest.
+	MethodHandshake = "handshake"
+
+	// MethodInitialize passes the provider kind and its validated ProviderConfig. It is the
+	// first call after the handshake; each provider instance runs in its own process.
+	MethodInitialize = "initialize"
+
+	// MethodShutdown is a notification asking the plugin to exit. Plugins must also exit when stdin closes.
+	MethodShutdown = "shutdown"
+
+	// MethodCancel is a notification cancelling the in-flight request with the given id.
+	MethodCancel = "$/cancel"
+
+	MethodAuthenticate = "authenticate"
+	MethodListRepos    = "list_repos"
+	MethodCloneRepo    = "clone_repo"
+	MethodPushMirror   = "push_mirror"
+	MethodValidateURL  = "validate_url"
+	MethodCapabilities = "capabilities"
+
+	MethodUpload   = "upload"
+	MethodDownload = "download"
+	MethodList     = "list"
+	MethodDelete   = "delete"
+	MethodGetQuota = "get_quota"
+)
+
+// Error codes. The standard JSON-RPC codes are used for protocol errors; the rest carry the
+// provider error types across the process boundary.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+
+	CodeAuth        = -32001
+	CodeRateLimit   = -32002
+	CodeNetwork     = -32003
+	CodeUnsupported = -32004
+
+	CodeNotFound         = -32005
+	CodePermissionDenied = -32006
+	CodeConflict         = -32007
+	CodeTooLarge         = -32008
+	CodeUnavailable      = -32009
+)
+
+// Manifest is the plugin's answer to the handshake.
+type Manifest struct {
+	ProtocolVersion int                   `json:"protocol_version"`
+	Type            provider.ProviderType `json:"type"`
+
+	// Kinds lists KindSourceControl and/or KindStorage.
+	Kinds []string `json:"kinds"`
+
+	SourceControlSchema *provider.ConfigSchema `json:"source_control_schema,omitempty"`
+	StorageSchema       *provider.ConfigSchema `json:"storage_schema,omitempty"`
+
+	// Path is the executable the manifest was read from. It is set by the host.
+	Path string `json:"-"`
+}
+
+func (m Manifest) hasKind(kind string) bool {
+	for _, k := range m.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// RPCError is a JSON-RPC error object.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+
+	// RetryAfter accompanies CodeRateLimit and CodeUnavailable, in seconds.
+	RetryAfter float64 `json:"retry_after,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("plugin error %d: %s", e.Code, e.Message)
+}
+
+type handshakeParams struct {
+	ProtocolVersion int `json:"protocol_version"`
+}
+
+type initializeParams struct {
+	Kind   string                  `json:"kind"`
+	Config provider.ProviderConfig `json:"config"`
+}
+
+type cancelParams struct {
+	ID int64 `json:"id"`
+}
+
+type authenticateParams struct {
+	Credential *models.Credential `json:"credential"`
+}
+
+type cloneRepoParams struct {
+	Repo     *models.Repository `json:"repo"`
+	DestPath string             `json:"dest_path"`
+}
+
+type pushMirrorParams struct {
+	Repo      *models.Repository `json:"repo"`
+	RemoteURL string             `json:"remote_url"`
+}
+
+type validateURLParams struct {
+	URL string `json:"url"`
+}
+
+type transferParams struct {
+	LocalPath  string `json:"local_path"`
+	RemotePath string `json:"remote_path"`
+}
+
+type listParams struct {
+	Prefix string `json:"prefix"`
+}
+
+type deleteParams struct {
+	RemotePath string `json:"remote_path"`
+}
+
+// toError converts an RPCError received from a plugin into the matching provider error type.
+func toError(pt provider.ProviderType, e *RPCError) error {
+	switch e.Code {
+	case CodeAuth:
+		return &provider.AuthError{Provider: pt, Message: e.Message}
+	case CodeRateLimit:
+		return &provider.RateLimitError{Provider: pt, Message: e.Message, RetryAfter: time.Duration(e.RetryAfter * float64(time.Second))}
+	case CodeNetwork:
+		return &provider.NetworkError{Provider: pt, Message: e.Message}
+	case CodeNotFound:
+		return &provider.NotFoundError{Provider: pt, Message: e.Message}
+	case CodePermissionDenied:
+		return &provider.PermissionDeniedError{Provider: pt, Message: e.Message}
+	case CodeConflict:
+		return &provider.ConflictError{Provider: pt, Message: e.Message}
+	case CodeTooLarge:
+		return &provider.TooLargeError{Provider: pt, Message: e.Message}
+	case CodeUnavailable:
+		return &provider.UnavailableError{Provider: pt, Message: e.Message, RetryAfter: time.Duration(e.RetryAfter * float64(time.Second))}
+	}
+
+	return e
+}
+
+// fromError converts an error returned by a provider into an RPCError for the wire.
+func fromError(err error) *RPCError {
+	var (
+		rpcErr         *RPCError
+		authErr        *provider.AuthError
+		rateErr        *provider.RateLimitError
+		netErr         *provider.NetworkError
+		notFoundErr    *provider.NotFoundError
+		permissionErr  *provider.PermissionDeniedError
+		conflictErr    *provider.ConflictError
+		tooLargeErr    *provider.TooLargeError
+		unavailableErr *provider.UnavailableError
+		parseErr       *json.SyntaxError
+	)
+
+	switch {
+	case errors.As(err, &rpcErr):
+		return rpcErr
+	case errors.As(err, &authErr):
+		return &RPCError{Code: CodeAuth, Message: authErr.Message}
+	case errors.As(err, &rateErr):
+		return &RPCError{Code: CodeRateLimit, Message: rateErr.Message, RetryAfter: rateErr.RetryAfter.Seconds()}
+	case errors.As(err, &netErr):
+		return &RPCError{Code: CodeNetwork, Message: netErr.Message}
+	case errors.As(err, &notFoundErr):
+		return &RPCError{Code: CodeNotFound, Message: notFoundErr.Message}
+	case errors.As(err, &permissionErr):
+		return &RPCError{Code: CodePermissionDenied, Message: permissionErr.Message}
+	case errors.As(err, &conflictErr):
+		return &RPCError{Code: CodeConflict, Message: conflictErr.Message}
+	case errors.As(err, &tooLargeErr):
+		return &RPCError{Code: CodeTooLarge, Message: tooLargeErr.Message}
+	case errors.As(err, &unavailableErr):
+		return &RPCError{Code: CodeUnavailable, Message: unavailableErr.Message, RetryAfter: unavailableErr.RetryAfter.Seconds()}
+	case errors.As(err, &parseErr):
+		return &RPCError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+
+	return &RPCError{Code: CodeInternalError, Message: err.Error()}
+}
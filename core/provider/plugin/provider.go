@@ -0,0 +1,225 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+)
+
+// callTimeout bounds startup calls and the interface methods that take no context.
+const callTimeout = 10 * time.Second
+
+var (
+	_ provider.SourceControlProvider = (*SourceControl)(nil)
+	_ provider.StorageProvider       = (*Storage)(nil)
+)
+
+// SourceControl is a provider.SourceControlProvider backed by a plugin process, which callers
+// stop with Close. A provider garbage collected unclosed is stopped only as a safeguard.
+type SourceControl struct {
+	conn         *conn
+	pt           provider.ProviderType
+	capabilities []provider.SourceControlProviderCapability
+}
+
+// Storage is a provider.StorageProvider backed by a plugin process, which callers stop with
+// Close. A provider garbage collected unclosed is stopped only as a safeguard.
+type Storage struct {
+	conn *conn
+	pt   provider.ProviderType
+}
+
+// launch starts a plugin process for one provider instance and initializes it with cfg.
+func launch(m Manifest, kind string, cfg provider.ProviderConfig) (*conn, error) {
+	c, err := start(m.Path, m.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	if err := handshake(ctx, c, m.Type); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	if err := c.call(ctx, MethodInitialize, initializeParams{Kind: kind, Config: cfg}, nil); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func newSourceControlFactory(m Manifest) provider.SourceControlProviderFactory {
+	return func(cfg provider.ProviderConfig) (provider.SourceControlProvider, error) {
+		c, err := launch(m, KindSourceControl, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+		defer cancel()
+
+		// Capabilities are fixed for an instance, so they are fetched once.
+		var caps []provider.SourceControlProviderCapability
+		if err := c.call(ctx, MethodCapabilities, struct{}{}, &caps); err != nil {
+			c.Close()
+			return nil, err
+		}
+
+		p := &SourceControl{conn: c, pt: m.Type, capabilities: caps}
+		runtime.AddCleanup(p, func(c *conn) { c.Close() }, c)
+
+		return p, nil
+	}
+}
+
+func newStorageFactory(m Manifest) provider.StorageProviderFactory {
+	return func(cfg provider.ProviderConfig) (provider.StorageProvider, error) {
+		c, err := launch(m, KindStorage, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		p := &Storage{conn: c, pt: m.Type}
+		runtime.AddCleanup(p, func(c *conn) { c.Close() }, c)
+
+		return p, nil
+	}
+}
+
+func (p *SourceControl) Authenticate(ctx context.Context, cred *models.Credential) error {
+	return p.conn.call(ctx, MethodAuthenticate, authenticateParams{Credential: cred}, nil)
+}
+
+func (p *SourceControl) ListRepos(ctx context.Context) ([]models.Repository, error) {
+	var repos []models.Repository
+	if err := p.conn.call(ctx, MethodListRepos, struct{}{}, &repos); err != nil {
+		return nil, err
+	}
+
+	return repos, nil
+}
+
+// CloneRepo asks the plugin to mirror repo into destPath and copies back the repository it
+// returns, so the plugin can set LocalPath and other fields as a native provider would.
+func (p *SourceControl) CloneRepo(ctx context.Context, repo *models.Repository, destPath string) error {
+	var updated models.Repository
+	if err := p.conn.call(ctx, MethodCloneRepo, cloneRepoParams{Repo: repo, DestPath: destPath}, &updated); err != nil {
+		return err
+	}
+
+	*repo = updated
+
+	return nil
+}
+
+func (p *SourceControl) PushMirror(ctx context.Context, repo *models.Repository, remoteURL string) error {
+	return p.conn.call(ctx, MethodPushMirror, pushMirrorParams{Repo: repo, RemoteURL: remoteURL}, nil)
+}
+
+// ValidateURL reports false when the plugin does not answer within callTimeout.
+func (p *SourceControl) ValidateURL(rawURL string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	var ok bool
+	if err := p.conn.call(ctx, MethodValidateURL, validateURLParams{URL: rawURL}, &ok); err != nil {
+		return false
+	}
+
+	return ok
+}
+
+func (p *SourceControl) GetProviderType() provider.ProviderType {
+	return p.pt
+}
+
+func (p *SourceControl) Capabilities() []provider.SourceControlProviderCapability {
+	return p.capabilities
+}
+
+// Close stops the plugin process.
+func (p *SourceControl) Close() error {
+	return p.conn.Close()
+}
+
+func (p *Storage) Authenticate(ctx context.Context, cred *models.Credential) error {
+	return p.conn.call(ctx, MethodAuthenticate, authenticateParams{Credential: cred}, nil)
+}
+
+func (p *Storage) Upload(ctx context.Context, localPath string, remotePath string) error {
+	return p.conn.call(ctx, MethodUpload, transferParams{LocalPath: localPath, RemotePath: remotePath}, nil)
+}
+
+func (p *Storage) Download(ctx context.Context, remotePath string, localPath string) error {
+	return p.conn.call(ctx, MethodDownload, transferParams{LocalPath: localPath, RemotePath: remotePath}, nil)
+}
+
+func (p *Storage) List(ctx context.Context, prefix string) ([]provider.StorageObject, error) {
+	var objects []provider.StorageObject
+	if err := p.conn.call(ctx, MethodList, listParams{Prefix: prefix}, &objects); err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+func (p *Storage) Delete(ctx context.Context, remotePath string) error {
+	return p.conn.call(ctx, MethodDelete, deleteParams{RemotePath: remotePath}, nil)
+}
+
+func (p *Storage) GetQuota(ctx context.Context) (*provider.QuotaInfo, error) {
+	var quota provider.QuotaInfo
+	if err := p.conn.call(ctx, MethodGetQuota, struct{}{}, &quota); err != nil {
+		return nil, err
+	}
+
+	return &quota, nil
+}
+
+// Close stops the plugin process.
+func (p *Storage) Close() error {
+	return p.conn.Close()
+}
+
+// handshake checks the protocol version and, when want is set, that the plugin still serves that type.
+func handshake(ctx context.Context, c *conn, want provider.ProviderType) error {
+	m, err := readManifest(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	if want != "" && m.Type != want {
+		return fmt.Errorf("plugin.handshake: plugin now serves %q, expected %q", m.Type, want)
+	}
+
+	return nil
+}
+
+func readManifest(ctx context.Context, c *conn) (*Manifest, error) {
+	var m Manifest
+	if err := c.call(ctx, MethodHandshake, handshakeParams{ProtocolVersion: ProtocolVersion}, &m); err != nil {
+		return nil, fmt.Errorf("plugin.handshake: %w", err)
+	}
+
+	if m.ProtocolVersion != ProtocolVersion {
+		return nil, fmt.Errorf("plugin.handshake: unsupported protocol version %d, want %d", m.ProtocolVersion, ProtocolVersion)
+	}
+
+	if m.Type == "" {
+		return nil, fmt.Errorf("plugin.handshake: no provider type")
+	}
+
+	if !m.hasKind(KindSourceControl) && !m.hasKind(KindStorage) {
+		return nil, fmt.Errorf("plugin.handshake: %s serves no known provider kind", m.Type)
+	}
+
+	return &m, nil
+}
@@ -0,0 +1,352 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"GitSyncer/core/provider"
+)
+
+// Plugin describes the providers a Go plugin serves. At least one factory must be set.
+type Plugin struct {
+	Type provider.ProviderType
+
+	SourceControl       provider.SourceControlProviderFactory
+	SourceControlSchema *provider.ConfigSchema
+
+	Storage       provider.StorageProviderFactory
+	StorageSchema *provider.ConfigSchema
+}
+
+func (p Plugin) manifest() Manifest {
+	m := Manifest{
+		ProtocolVersion:     ProtocolVersion,
+		Type:                p.Type,
+		SourceControlSchema: p.SourceControlSchema,
+		StorageSchema:       p.StorageSchema,
+	}
+
+	if p.SourceControl != nil {
+		m.Kinds = append(m.Kinds, KindSourceControl)
+	}
+
+	if p.Storage != nil {
+		m.Kinds = append(m.Kinds, KindStorage)
+	}
+
+	return m
+}
+
+// server is the plugin side of the protocol for one process.
+type server struct {
+	plugin Plugin
+	out    io.Writer
+
+	writeMu sync.Mutex
+
+	mu            sync.Mutex
+	sourceControl provider.SourceControlProvider
+	storage       provider.StorageProvider
+	inflight      map[int64]context.CancelFunc
+	wg            sync.WaitGroup
+}
+
+// Serve runs the plugin protocol on in and out, normally os.Stdin and os.Stdout, until in is
+// closed or the host sends shutdown. Requests run concurrently and honour $/cancel.
+func Serve(p Plugin, in io.Reader, out io.Writer) error {
+	if p.Type == "" || (p.SourceControl == nil && p.Storage == nil) {
+		return fmt.Errorf("plugin.Serve: a type and at least one factory are required")
+	}
+
+	s := &server{plugin: p, out: out, inflight: make(map[int64]context.CancelFunc)}
+	defer s.wg.Wait()
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64<<10), maxMessageSize)
+
+	for scanner.Scan() {
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			s.reply(nil, nil, &RPCError{Code: CodeParseError, Message: err.Error()})
+			continue
+		}
+
+		switch msg.Method {
+		case MethodShutdown:
+			s.cancelAll()
+			return nil
+		case MethodCancel:
+			var params cancelParams
+			if json.Unmarshal(msg.Params, &params) == nil {
+				s.cancel(params.ID)
+			}
+
+			continue
+		}
+
+		if msg.ID == nil {
+			continue
+		}
+
+		// Handshake and initialize set up state every later call depends on, so they run in order.
+		if msg.Method == MethodHandshake || msg.Method == MethodInitialize {
+			result, err := s.handle(context.Background(), &msg)
+			s.reply(msg.ID, result, err)
+
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		s.mu.Lock()
+		s.inflight[*msg.ID] = cancel
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+
+		go func() {
+			defer s.wg.Done()
+
+			result, err := s.handle(ctx, &msg)
+			s.cancel(*msg.ID)
+			s.reply(msg.ID, result, err)
+		}()
+	}
+
+	s.cancelAll()
+
+	return scanner.Err()
+}
+
+func (s *server) cancel(id int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cancel, ok := s.inflight[id]; ok {
+		cancel()
+		delete(s.inflight, id)
+	}
+}
+
+func (s *server) cancelAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, cancel := range s.inflight {
+		cancel()
+		delete(s.inflight, id)
+	}
+}
+
+func (s *server) reply(id *int64, result any, err error) {
+	msg := message{JSONRPC: "2.0", ID: id}
+
+	if err != nil {
+		msg.Error = fromError(err)
+	} else {
+		raw, merr := json.Marshal(result)
+		if merr != nil {
+			msg.Error = &RPCError{Code: CodeInternalError, Message: merr.Error()}
+		} else {
+			msg.Result = raw
+		}
+	}
+
+	data, _ := json.Marshal(msg)
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.out.Write(append(data, '\n'))
+}
+
+func decodeParams(msg *message, v any) error {
+	if len(msg.Params) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(msg.Params, v); err != nil {
+		return &RPCError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+
+	return nil
+}
+
+func (s *server) handle(ctx context.Context, msg *message) (any, error) {
+	switch msg.Method {
+	case MethodHandshake:
+		var params handshakeParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+
+		if params.ProtocolVersion != ProtocolVersion {
+			return nil, &RPCError{Code: CodeUnsupported, Message: fmt.Sprintf("protocol version %d is not supported", params.ProtocolVersion)}
+		}
+
+		return s.plugin.manifest(), nil
+
+	case MethodInitialize:
+		var params initializeParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+
+		return struct{}{}, s.initialize(params)
+	}
+
+	s.mu.Lock()
+	sc, st := s.sourceControl, s.storage
+	s.mu.Unlock()
+
+	if sc != nil {
+		if result, ok, err := handleSourceControl(ctx, sc, msg); ok {
+			return result, err
+		}
+	}
+
+	if st != nil {
+		if result, ok, err := handleStorage(ctx, st, msg); ok {
+			return result, err
+		}
+	}
+
+	if sc == nil && st == nil {
+		return nil, &RPCError{Code: CodeInvalidRequest, Message: "not initialized"}
+	}
+
+	return nil, &RPCError{Code: CodeMethodNotFound, Message: "unknown method " + msg.Method}
+}
+
+func (s *server) initialize(params initializeParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.sourceControl != nil || s.storage != nil {
+		return &RPCError{Code: CodeInvalidRequest, Message: "already initialized"}
+	}
+
+	var err error
+
+	switch {
+	case params.Kind == KindSourceControl && s.plugin.SourceControl != nil:
+		s.sourceControl, err = s.plugin.SourceControl(params.Config)
+	case params.Kind == KindStorage && s.plugin.Storage != nil:
+		s.storage, err = s.plugin.Storage(params.Config)
+	default:
+		return &RPCError{Code: CodeUnsupported, Message: "kind " + params.Kind + " is not served"}
+	}
+
+	return err
+}
+
+// handleSourceControl dispatches a SourceControlProvider method. ok is false for other methods.
+func handleSourceControl(ctx context.Context, p provider.SourceControlProvider, msg *message) (result any, ok bool, err error) {
+	switch msg.Method {
+	case MethodAuthenticate:
+		var params authenticateParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, true, err
+		}
+
+		return struct{}{}, true, p.Authenticate(ctx, params.Credential)
+
+	case MethodListRepos:
+		repos, err := p.ListRepos(ctx)
+
+		return repos, true, err
+
+	case MethodCloneRepo:
+		var params cloneRepoParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, true, err
+		}
+
+		if params.Repo == nil {
+			return nil, true, &RPCError{Code: CodeInvalidParams, Message: "repo is required"}
+		}
+
+		err := p.CloneRepo(ctx, params.Repo, params.DestPath)
+
+		return params.Repo, true, err
+
+	case MethodPushMirror:
+		var params pushMirrorParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, true, err
+		}
+
+		if params.Repo == nil {
+			return nil, true, &RPCError{Code: CodeInvalidParams, Message: "repo is required"}
+		}
+
+		return struct{}{}, true, p.PushMirror(ctx, params.Repo, params.RemoteURL)
+
+	case MethodValidateURL:
+		var params validateURLParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, true, err
+		}
+
+		return p.ValidateURL(params.URL), true, nil
+
+	case MethodCapabilities:
+		return p.Capabilities(), true, nil
+	}
+
+	return nil, false, nil
+}
+
+// handleStorage dispatches a StorageProvider method. ok is false for other methods.
+func handleStorage(ctx context.Context, p provider.StorageProvider, msg *message) (result any, ok bool, err error) {
+	switch msg.Method {
+	case MethodAuthenticate:
+		var params authenticateParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, true, err
+		}
+
+		return struct{}{}, true, p.Authenticate(ctx, params.Credential)
+
+	case MethodUpload, MethodDownload:
+		var params transferParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, true, err
+		}
+
+		if msg.Method == MethodUpload {
+			return struct{}{}, true, p.Upload(ctx, params.LocalPath, params.RemotePath)
+		}
+
+		return struct{}{}, true, p.Download(ctx, params.RemotePath, params.LocalPath)
+
+	case MethodList:
+		var params listParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, true, err
+		}
+
+		objects, err := p.List(ctx, params.Prefix)
+
+		return objects, true, err
+
+	case MethodDelete:
+		var params deleteParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, true, err
+		}
+
+		return struct{}{}, true, p.Delete(ctx, params.RemotePath)
+
+	case MethodGetQuota:
+		quota, err := p.GetQuota(ctx)
+
+		return quota, true, err
+	}
+
+	return nil, false, nil
+}
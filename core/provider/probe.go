@@ -0,0 +1,240 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DefaultProbeTimeout bounds a whole ProbeProvider call when the context has no earlier deadline.
+const DefaultProbeTimeout = 5 * time.Second
+
+// ErrProbeUnreachable is returned by ProbeProvider when no endpoint answered at all, because the
+// host is unreachable or the probe ran out of time. Unlike a nil result it says nothing about the host.
+var ErrProbeUnreachable = errors.New("provider host did not answer")
+
+// ProbeResult describes a provider detected by probing its API.
+type ProbeResult struct {
+	Type ProviderType `json:"type"`
+
+	// BaseURL is the instance URL to use as ProviderConfig.BaseURL.
+	BaseURL string `json:"base_url"`
+
+	// APIURL is the root of the API that answered the probe.
+	APIURL string `json:"api_url"`
+
+	// Version is the server version, when the endpoint reports one.
+	Version string `json:"version,omitempty"`
+}
+
+// apiProbe checks one well-known endpoint. match inspects a response and returns the
+// server version and whether the endpoint identifies the provider.
+type apiProbe struct {
+	providerType ProviderType
+	apiPath      string
+	endpoint     string
+	match        func(status int, body []byte) (string, bool)
+}
+
+// apiProbes are ordered by preference when several endpoints answer.
+var apiProbes = []apiProbe{
+	{ProviderGitLab, "/api/v4", "/version", matchGitLabVersion},
+	{ProviderGitea, "/api/v1", "/version", matchVersionField},
+	{ProviderGitHub, "/api/v3", "/meta", matchGitHubMeta},
+	{ProviderBitbucketDataCenter, "/rest/api/1.0", "/application-properties", matchBitbucketProperties},
+	{ProviderGerrit, "", "/config/server/version", matchGerritVersion},
+}
+
+// ProbeProvider identifies the provider behind rawURL by querying well-known API endpoints
+// on its host concurrently. SSH and scp-style URLs are probed over HTTPS. It returns nil
+// and no error when the host answers but no endpoint identifies a provider, and
+// ErrProbeUnreachable when nothing answers.
+func ProbeProvider(ctx context.Context, client *http.Client, rawURL string) (*ProbeResult, error) {
+	baseURL, err := probeBaseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultProbeTimeout)
+	defer cancel()
+
+	results := make([]*ProbeResult, len(apiProbes))
+	answered := make([]bool, len(apiProbes))
+
+	var wg sync.WaitGroup
+
+	for i, probe := range apiProbes {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results[i], answered[i] = probe.run(ctx, client, baseURL)
+		}()
+	}
+
+	wg.Wait()
+
+	for _, r := range results {
+		if r != nil {
+			return r, nil
+		}
+	}
+
+	// A cancelled caller is an error; running out of probe time just means nothing answered.
+	if err := ctx.Err(); errors.Is(err, context.Canceled) {
+		return nil, err
+	}
+
+	for _, ok := range answered {
+		if ok {
+			return nil, nil
+		}
+	}
+
+	return nil, fmt.Errorf("provider.ProbeProvider(%s): %w", baseURL, ErrProbeUnreachable)
+}
+
+// run queries the probe endpoint. answered reports whether the server sent any response.
+func (p apiProbe) run(ctx context.Context, client *http.Client, baseURL string) (result *ProbeResult, answered bool) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+p.apiPath+p.endpoint, nil)
+	if err != nil {
+		return nil, false
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "GitSyncer")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false
+	}
+	defer resp.Body.Close()
+
+	// A body cut short, e.g. by the probe deadline, counts as no answer.
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, false
+	}
+
+	version, ok := p.match(resp.StatusCode, body)
+	if !ok {
+		return nil, true
+	}
+
+	return &ProbeResult{
+		Type:    p.providerType,
+		BaseURL: baseURL,
+		APIURL:  baseURL + p.apiPath,
+		Version: version,
+	}, true
+}
+
+// probeBaseURL reduces a repository URL to the scheme and host of its web instance.
+func probeBaseURL(rawURL string) (string, error) {
+	host := ExtractHost(rawURL)
+	if host == "" {
+		return "", fmt.Errorf("provider.ProbeProvider: no host in %q", rawURL)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return u.Scheme + "://" + u.Host, nil
+	}
+
+	// SSH ports say nothing about the web port.
+	return "https://" + host, nil
+}
+
+func decodeJSON(body []byte, v any) bool {
+	return json.Unmarshal(body, v) == nil
+}
+
+// matchGitLabVersion accepts /api/v4/version, which answers 401 with GitLab's own message without a token.
+func matchGitLabVersion(status int, body []byte) (string, bool) {
+	var v struct {
+		Version  string `json:"version"`
+		Revision string `json:"revision"`
+		Message  string `json:"message"`
+	}
+
+	if !decodeJSON(body, &v) {
+		return "", false
+	}
+
+	switch status {
+	case http.StatusOK:
+		return v.Version, v.Version != "" && v.Revision != ""
+	case http.StatusUnauthorized:
+		return "", v.Message == "401 Unauthorized"
+	}
+
+	return "", false
+}
+
+// matchVersionField accepts Gitea and Forgejo's unauthenticated /api/v1/version.
+func matchVersionField(status int, body []byte) (string, bool) {
+	var v struct {
+		Version string `json:"version"`
+	}
+
+	if status != http.StatusOK || !decodeJSON(body, &v) || v.Version == "" {
+		return "", false
+	}
+
+	return v.Version, true
+}
+
+// matchGitHubMeta accepts GitHub Enterprise Server's /api/v3/meta.
+func matchGitHubMeta(status int, body []byte) (string, bool) {
+	var v struct {
+		InstalledVersion                 string `json:"installed_version"`
+		VerifiablePasswordAuthentication *bool  `json:"verifiable_password_authentication"`
+	}
+
+	if status != http.StatusOK || !decodeJSON(body, &v) {
+		return "", false
+	}
+
+	return v.InstalledVersion, v.InstalledVersion != "" || v.VerifiablePasswordAuthentication != nil
+}
+
+// matchBitbucketProperties accepts Bitbucket Data Center's /rest/api/1.0/application-properties.
+func matchBitbucketProperties(status int, body []byte) (string, bool) {
+	var v struct {
+		Version     string `json:"version"`
+		DisplayName string `json:"displayName"`
+	}
+
+	if status != http.StatusOK || !decodeJSON(body, &v) {
+		return "", false
+	}
+
+	return v.Version, v.DisplayName == "Bitbucket"
+}
+
+// matchGerritVersion accepts Gerrit's /config/server/version, a JSON string behind the ")]}'" prefix.
+func matchGerritVersion(status int, body []byte) (string, bool) {
+	rest, ok := bytes.CutPrefix(body, []byte(")]}'"))
+	if status != http.StatusOK || !ok {
+		return "", false
+	}
+
+	var version string
+	if !decodeJSON(bytes.TrimSpace(rest), &version) {
+		return "", false
+	}
+
+	return version, true
+}
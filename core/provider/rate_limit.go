@@ -0,0 +1,21 @@
+package provider
+
+import "time"
+
+// RateLimitStatus is the API quota a provider reported on its most recent response.
+type RateLimitStatus struct {
+	// Resource names the quota bucket when the provider has several, e.g. GitHub's "core",
+	// "search" and "graphql". It is empty for providers with a single quota.
+	Resource  string    `json:"resource,omitempty"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RateLimitReporter is implemented by API-backed providers that track their remaining quota.
+type RateLimitReporter interface {
+	// RateLimits returns the last known status of each quota bucket, sorted by resource.
+	// It is empty until the provider has made a request that returned rate-limit headers.
+	RateLimits() []RateLimitStatus
+}
@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	// maxCacheEntries bounds the conditional-request cache of one Transport.
+	maxCacheEntries = 512
+
+	// maxCacheBody is the largest response body kept for revalidation.
+	maxCacheBody = 1 << 20
+)
+
+// credentialHeaders are mixed into cache keys so responses are never shared between credentials.
+var credentialHeaders = []string{"Authorization", "PRIVATE-TOKEN", "Cookie"}
+
+type cacheEntry struct {
+	key          string
+	etag         string
+	lastModified string
+	header       http.Header
+	body         []byte
+}
+
+// cache is a small LRU of GET responses that carry validators, used to send conditional requests.
+type cache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+func newCache() *cache {
+	return &cache{entries: make(map[string]*list.Element), order: list.New()}
+}
+
+// cacheKey identifies a response by URL, the representation asked for and a digest of the credentials.
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+
+	for _, name := range credentialHeaders {
+		h.Write([]byte(name + ":" + req.Header.Get(name) + "\n"))
+	}
+
+	return strings.Join([]string{req.URL.String(), req.Header.Get("Accept"), hex.EncodeToString(h.Sum(nil))}, " ")
+}
+
+func (c *cache) get(key string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+
+	return el.Value.(*cacheEntry), true
+}
+
+func (c *cache) put(entry *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[entry.key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+
+		return
+	}
+
+	c.entries[entry.key] = c.order.PushFront(entry)
+
+	for c.order.Len() > maxCacheEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (c *cache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.order.Remove(el)
+		delete(c.entries, key)
+	}
+}
+
+// cacheable reports whether a response may be stored for revalidation.
+func cacheable(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	if resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "" {
+		return false
+	}
+
+	return !strings.Contains(strings.ToLower(resp.Header.Get("Cache-Control")), "no-store")
+}
@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"GitSyncer/core/provider"
+)
+
+// headerSet names the headers one provider family uses to report its quota.
+type headerSet struct {
+	limit, remaining, reset, resource string
+}
+
+var headerSets = []headerSet{
+	// GitHub and Gitea.
+	{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "X-RateLimit-Resource"},
+	// GitLab.
+	{"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", ""},
+}
+
+// resetDeltaCutoff separates reset values given as seconds from now (IETF RateLimit draft)
+// from those given as a Unix epoch (GitHub, GitLab, Gitea).
+const resetDeltaCutoff = 1_000_000_000
+
+// ParseStatus reads the quota from GitHub, GitLab or Gitea rate-limit headers.
+// ok is false when h carries none of them.
+func ParseStatus(h http.Header, now time.Time) (status provider.RateLimitStatus, ok bool) {
+	for _, set := range headerSets {
+		remaining, err := strconv.Atoi(h.Get(set.remaining))
+		if err != nil {
+			continue
+		}
+
+		status = provider.RateLimitStatus{Remaining: remaining, UpdatedAt: now}
+		status.Limit, _ = strconv.Atoi(h.Get(set.limit))
+		status.Reset = parseReset(h.Get(set.reset), now)
+
+		if set.resource != "" {
+			status.Resource = h.Get(set.resource)
+		}
+
+		// GitLab also sends the reset as an HTTP date.
+		if status.Reset.IsZero() {
+			if t, err := http.ParseTime(h.Get("RateLimit-ResetTime")); err == nil {
+				status.Reset = t
+			}
+		}
+
+		return status, true
+	}
+
+	return provider.RateLimitStatus{}, false
+}
+
+func parseReset(v string, now time.Time) time.Time {
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return time.Time{}
+	}
+
+	if n < resetDeltaCutoff {
+		return now.Add(time.Duration(n) * time.Second)
+	}
+
+	return time.Unix(n, 0)
+}
+
+// Exhausted reports whether resp carries a zero remaining-quota header.
+func Exhausted(resp *http.Response) bool {
+	status, ok := ParseStatus(resp.Header, time.Now())
+
+	return ok && status.Remaining == 0
+}
+
+// Throttled reports whether a 403 response signals rate limiting rather than missing permissions.
+// GitHub answers an exhausted quota and its secondary rate limits with 403 and either a
+// Retry-After or a zero remaining-quota header.
+func Throttled(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusForbidden && (resp.Header.Get("Retry-After") != "" || Exhausted(resp))
+}
+
+// RetryAfter derives the wait time from Retry-After or a rate-limit reset header. It is zero
+// when the response gives no hint.
+func RetryAfter(resp *http.Response) time.Duration {
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(secs) * time.Second
+		}
+
+		if t, err := http.ParseTime(v); err == nil {
+			return max(time.Until(t).Round(time.Second), 0)
+		}
+	}
+
+	if status, ok := ParseStatus(resp.Header, time.Now()); ok && !status.Reset.IsZero() {
+		if d := time.Until(status.Reset).Round(time.Second); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,307 @@
+// Package ratelimit provides the HTTP transport shared by the API-backed providers. It records
+// the quota reported in GitHub, GitLab and Gitea rate-limit headers, waits or backs off when a
+// request is throttled, and revalidates GET responses with If-None-Match or If-Modified-Since
+// so that unchanged resources do not cost quota.
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"GitSyncer/core/provider"
+)
+
+// Policy controls how a Transport reacts to throttled responses.
+type Policy struct {
+	// MaxRetries is how many times a throttled request is retried. Zero returns the first
+	// throttled response as-is.
+	MaxRetries int
+
+	// MaxWait is the longest the transport sleeps before a retry. A response asking for a longer
+	// wait is returned as-is, which the API client reports as a provider.RateLimitError.
+	MaxWait time.Duration
+
+	// BaseBackoff and MaxBackoff bound the exponential backoff used when the server gives no hint.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// DefaultPolicy rides out short throttling and leaves long waits, such as an exhausted hourly
+// quota, to the caller.
+var DefaultPolicy = Policy{
+	MaxRetries:  3,
+	MaxWait:     15 * time.Second,
+	BaseBackoff: time.Second,
+	MaxBackoff:  15 * time.Second,
+}
+
+// Transport is an http.RoundTripper that tracks quota, retries throttled requests according to
+// its Policy and sends conditional GET requests. One Transport serves one provider record,
+// shared by the provider instances created for it.
+type Transport struct {
+	// Base performs the requests. http.DefaultTransport is used when nil.
+	Base   http.RoundTripper
+	Policy Policy
+
+	cache *cache
+
+	mu       sync.Mutex
+	statuses map[string]provider.RateLimitStatus
+}
+
+// New creates a Transport over base with the given policy.
+func New(base http.RoundTripper, policy Policy) *Transport {
+	return &Transport{
+		Base:     base,
+		Policy:   policy,
+		cache:    newCache(),
+		statuses: make(map[string]provider.RateLimitStatus),
+	}
+}
+
+// Statuses returns the last quota reported for each resource, sorted by resource.
+func (t *Transport) Statuses() []provider.RateLimitStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	statuses := make([]provider.RateLimitStatus, 0, len(t.statuses))
+	for _, s := range t.statuses {
+		statuses = append(statuses, s)
+	}
+
+	slices.SortFunc(statuses, func(a, b provider.RateLimitStatus) int {
+		return strings.Compare(a.Resource, b.Resource)
+	})
+
+	return statuses
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		key   string
+		entry *cacheEntry
+	)
+
+	// Requests that already carry validators are the caller's business.
+	conditional := req.Method == http.MethodGet && req.Header.Get("If-None-Match") == "" && req.Header.Get("If-Modified-Since") == ""
+	if conditional {
+		key = cacheKey(req)
+		entry, _ = t.cache.get(key)
+	}
+
+	for attempt := 0; ; attempt++ {
+		out, err := prepare(req, entry, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.base().RoundTrip(out)
+		if err != nil {
+			return nil, err
+		}
+
+		t.record(resp)
+
+		if wait, ok := t.retryWait(req, resp, attempt); ok {
+			drain(resp)
+
+			if err := sleep(req.Context(), wait); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if conditional {
+			return t.revalidate(key, entry, resp)
+		}
+
+		return resp, nil
+	}
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+
+	return http.DefaultTransport
+}
+
+// prepare returns the request to send for attempt, adding validators from entry. The caller's
+// request is never modified; retries get a fresh copy of the body.
+func prepare(req *http.Request, entry *cacheEntry, attempt int) (*http.Request, error) {
+	if entry == nil && attempt == 0 {
+		return req, nil
+	}
+
+	out := req.Clone(req.Context())
+
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+
+		out.Body = body
+	}
+
+	if entry != nil {
+		if entry.etag != "" {
+			out.Header.Set("If-None-Match", entry.etag)
+		}
+
+		if entry.lastModified != "" {
+			out.Header.Set("If-Modified-Since", entry.lastModified)
+		}
+	}
+
+	return out, nil
+}
+
+func (t *Transport) record(resp *http.Response) {
+	status, ok := ParseStatus(resp.Header, time.Now())
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	t.statuses[status.Resource] = status
+	t.mu.Unlock()
+}
+
+// retryWait reports whether resp is a throttled response worth retrying and how long to wait first.
+func (t *Transport) retryWait(req *http.Request, resp *http.Response, attempt int) (time.Duration, bool) {
+	hinted := resp.Header.Get("Retry-After") != ""
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case Throttled(resp):
+	case resp.StatusCode == http.StatusServiceUnavailable && hinted:
+	default:
+		return 0, false
+	}
+
+	if attempt >= t.Policy.MaxRetries {
+		return 0, false
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return 0, false
+	}
+
+	wait := RetryAfter(resp)
+	if wait == 0 {
+		wait = t.backoff(attempt)
+	}
+
+	if wait > t.Policy.MaxWait {
+		return 0, false
+	}
+
+	if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) < wait {
+		return 0, false
+	}
+
+	return wait, true
+}
+
+// backoff doubles BaseBackoff per attempt up to MaxBackoff, with jitter over the upper half.
+func (t *Transport) backoff(attempt int) time.Duration {
+	d := t.Policy.BaseBackoff << attempt
+	if d <= 0 || d > t.Policy.MaxBackoff {
+		d = t.Policy.MaxBackoff
+	}
+
+	if d <= 1 {
+		return d
+	}
+
+	return d/2 + rand.N(d/2)
+}
+
+// revalidate turns a 304 into the cached response and stores fresh responses that carry validators.
+func (t *Transport) revalidate(key string, entry *cacheEntry, resp *http.Response) (*http.Response, error) {
+	if entry != nil && resp.StatusCode == http.StatusNotModified {
+		drain(resp)
+
+		header := entry.header.Clone()
+		for k, v := range resp.Header {
+			header[k] = v
+		}
+
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(entry.body)),
+			ContentLength: int64(len(entry.body)),
+			Request:       resp.Request,
+		}, nil
+	}
+
+	if !cacheable(resp) {
+		if resp.StatusCode == http.StatusOK {
+			t.cache.remove(key)
+		}
+
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCacheBody+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	if len(body) > maxCacheBody {
+		t.cache.remove(key)
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+
+		return resp, nil
+	}
+
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.cache.put(&cacheEntry{
+		key:          key,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		header:       resp.Header.Clone(),
+		body:         body,
+	})
+
+	return resp, nil
+}
+
+// drain discards a response that will not be returned so its connection can be reused.
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -10,12 +10,16 @@ type ProviderRegistry struct {
 	mu                     sync.RWMutex
 	sourceControlProviders map[ProviderType]SourceControlProviderFactory
 	storeProviders         map[ProviderType]StorageProviderFactory
+	sourceControlSchemas   map[ProviderType]ConfigSchema
+	storageSchemas         map[ProviderType]ConfigSchema
 }
 
 func NewProviderRegistry() *ProviderRegistry {
 	return &ProviderRegistry{
 		sourceControlProviders: make(map[ProviderType]SourceControlProviderFactory),
 		storeProviders:         make(map[ProviderType]StorageProviderFactory),
+		sourceControlSchemas:   make(map[ProviderType]ConfigSchema),
+		storageSchemas:         make(map[ProviderType]ConfigSchema),
 	}
 }
 
@@ -47,6 +51,35 @@ func (r *ProviderRegistry) RegisterStorageProviderFactory(pt ProviderType, facto
 	return nil
 }
 
+// RegisterSourceControlProviderSchema registers the configuration schema of an already registered
+// source control factory. Factories without a schema receive their config unvalidated.
+func (r *ProviderRegistry) RegisterSourceControlProviderSchema(pt ProviderType, schema ConfigSchema) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.sourceControlProviders[pt]; !exists {
+		return fmt.Errorf("source control provider not found: %s", pt)
+	}
+
+	r.sourceControlSchemas[pt] = schema
+
+	return nil
+}
+
+// RegisterStorageProviderSchema registers the configuration schema of an already registered storage factory.
+func (r *ProviderRegistry) RegisterStorageProviderSchema(pt ProviderType, schema ConfigSchema) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.storeProviders[pt]; !exists {
+		return fmt.Errorf("storage provider not found: %s", pt)
+	}
+
+	r.storageSchemas[pt] = schema
+
+	return nil
+}
+
 // GetSourceControlProviderFactory returns the SourceControlProviderFactory for the given provider type.
 func (r *ProviderRegistry) GetSourceControlProviderFactory(pt ProviderType) (SourceControlProviderFactory, error) {
 	r.mu.RLock()
@@ -98,3 +131,57 @@ func (r *ProviderRegistry) ListStorageProviderTypes() []ProviderType {
 
 	return types
 }
+
+// GetSourceControlProviderSchema returns the configuration schema for the given source control provider type.
+func (r *ProviderRegistry) GetSourceControlProviderSchema(pt ProviderType) (ConfigSchema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schema, ok := r.sourceControlSchemas[pt]
+
+	return schema, ok
+}
+
+// GetStorageProviderSchema returns the configuration schema for the given storage provider type.
+func (r *ProviderRegistry) GetStorageProviderSchema(pt ProviderType) (ConfigSchema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	schema, ok := r.storageSchemas[pt]
+
+	return schema, ok
+}
+
+// NewSourceControlProvider validates cfg against the schema registered for cfg.Type, if any,
+// and creates the provider from the validated config.
+func (r *ProviderRegistry) NewSourceControlProvider(cfg ProviderConfig) (SourceControlProvider, error) {
+	factory, err := r.GetSourceControlProviderFactory(cfg.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if schema, ok := r.GetSourceControlProviderSchema(cfg.Type); ok {
+		if cfg, err = schema.Validate(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	return factory(cfg)
+}
+
+// NewStorageProvider validates cfg against the schema registered for cfg.Type, if any,
+// and creates the provider from the validated config.
+func (r *ProviderRegistry) NewStorageProvider(cfg ProviderConfig) (StorageProvider, error) {
+	factory, err := r.GetStorageProviderFactory(cfg.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if schema, ok := r.GetStorageProviderSchema(cfg.Type); ok {
+		if cfg, err = schema.Validate(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	return factory(cfg)
+}
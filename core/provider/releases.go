@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"context"
+	"io"
+
+	"GitSyncer/core/models"
+)
+
+// Release is a release published for a tag, with the files attached to it.
+type Release struct {
+	// ID is the provider's identifier for the release, formatted as a string. GitLab identifies
+	// releases by their tag.
+	ID         string         `json:"id"`
+	TagName    string         `json:"tag_name"`
+	Name       string         `json:"name"`
+	Body       string         `json:"body"`
+	Draft      bool           `json:"draft"`
+	Prerelease bool           `json:"prerelease"`
+	Assets     []ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset is a file attached to a release.
+type ReleaseAsset struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+
+	// Size is 0 when the provider does not report it.
+	Size int64 `json:"size"`
+
+	// SHA256 is the hex digest of the contents when the provider reports one.
+	SHA256 string `json:"sha256,omitempty"`
+
+	// DownloadURL is where DownloadReleaseAsset fetches the contents from.
+	DownloadURL string `json:"download_url"`
+}
+
+// ReleaseConfig describes a release to create or update. The tag must already exist.
+type ReleaseConfig struct {
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Body       string `json:"body"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+// ConfigOf returns the replicable fields of rel.
+func ConfigOf(rel *Release) ReleaseConfig {
+	return ReleaseConfig{TagName: rel.TagName, Name: rel.Name, Body: rel.Body, Prerelease: rel.Prerelease}
+}
+
+// ReleaseChanged reports whether current, a release on a provider of type pt, differs from cfg.
+// GitLab has no prereleases, so the flag is not compared there.
+func ReleaseChanged(pt ProviderType, current *Release, cfg ReleaseConfig) bool {
+	if current.Name != cfg.Name || current.Body != cfg.Body {
+		return true
+	}
+
+	return pt != ProviderGitLab && current.Prerelease != cfg.Prerelease
+}
+
+// ReleaseManager is implemented by providers that manage releases and their assets through
+// their API. repo is addressed by its CloneURL.
+type ReleaseManager interface {
+	// ListReleases returns the releases of repo with their assets, drafts included where the
+	// credential can see them.
+	ListReleases(ctx context.Context, repo *models.Repository) ([]Release, error)
+
+	CreateRelease(ctx context.Context, repo *models.Repository, cfg ReleaseConfig) (*Release, error)
+	UpdateRelease(ctx context.Context, repo *models.Repository, id string, cfg ReleaseConfig) (*Release, error)
+
+	// UploadReleaseAsset attaches size bytes read from content to the release id as name.
+	UploadReleaseAsset(ctx context.Context, repo *models.Repository, id, name string, content io.Reader, size int64) (*ReleaseAsset, error)
+
+	// DownloadReleaseAsset returns the contents of asset; the caller closes it.
+	DownloadReleaseAsset(ctx context.Context, repo *models.Repository, asset ReleaseAsset) (io.ReadCloser, error)
+
+	// DeleteReleaseAsset removes asset from the release id, so that a changed file can be
+	// uploaded under the same name.
+	DeleteReleaseAsset(ctx context.Context, repo *models.Repository, id string, asset ReleaseAsset) error
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"context"
+	"slices"
+
+	"GitSyncer/core/models"
+)
+
+// RepoMetadata is the descriptive metadata replicated from a source repository to its mirror.
+type RepoMetadata struct {
+	Description string   `json:"description"`
+	Homepage    string   `json:"homepage"`
+	Topics      []string `json:"topics"`
+
+	// Visibility is one of the models.Visibility values. Empty leaves the visibility alone, or
+	// creates a private repository.
+	Visibility string `json:"visibility"`
+
+	// DefaultBranch is applied by UpdateRepo only, since an empty repository has no branches.
+	DefaultBranch string `json:"default_branch"`
+}
+
+// MetadataOf returns the replicable metadata of repo.
+func MetadataOf(repo *models.Repository) RepoMetadata {
+	return RepoMetadata{
+		Description:   repo.Description,
+		Homepage:      repo.Homepage,
+		Topics:        slices.Clone(repo.Topics),
+		Visibility:    repo.Visibility,
+		DefaultBranch: repo.DefaultBranch,
+	}
+}
+
+// RepoManager is implemented by providers that can read, create and edit repositories through
+// their API. Metadata the provider has no field for is ignored.
+type RepoManager interface {
+	// GetRepo returns the repository repo.CloneURL addresses with its current metadata.
+	GetRepo(ctx context.Context, repo *models.Repository) (*models.Repository, error)
+
+	// EnsureRepo returns the repository name under owner (a user, organization or group path),
+	// creating it with meta when it does not exist. created reports whether it was created.
+	// Existing repositories are returned unchanged.
+	EnsureRepo(ctx context.Context, owner, name string, meta RepoMetadata) (repo *models.Repository, created bool, err error)
+
+	// UpdateRepo brings the metadata of the repository repo.CloneURL addresses in line with meta.
+	UpdateRepo(ctx context.Context, repo *models.Repository, meta RepoMetadata) error
+}
@@ -10,11 +10,28 @@ import (
 type SourceControlProviderCapability string
 
 const (
+	CapabilityAPI       SourceControlProviderCapability = "api"
 	CapabilityWebhooks  SourceControlProviderCapability = "webhooks"
 	CapabilitySSH       SourceControlProviderCapability = "ssh"
 	CapabilityOAuth     SourceControlProviderCapability = "oauth"
 	CapabilityTokenAuth SourceControlProviderCapability = "token_auth"
 	CapabilityMirror    SourceControlProviderCapability = "mirror"
+
+	// CapabilityWiki means the provider hosts repository wikis as git repositories at
+	// WikiCloneURL, so PushMirror can mirror a wiki to it.
+	CapabilityWiki SourceControlProviderCapability = "wiki"
+
+	// CapabilityLFS means the provider serves Git LFS objects through the batch API, and
+	// implements LFSProvider.
+	CapabilityLFS SourceControlProviderCapability = "lfs"
+
+	// CapabilityReleases means the provider publishes releases with downloadable assets, and
+	// implements ReleaseManager.
+	CapabilityReleases SourceControlProviderCapability = "releases"
+
+	// CapabilityIssues means the provider exposes issues, pull requests and their comments, and
+	// implements DiscussionExporter.
+	CapabilityIssues SourceControlProviderCapability = "issues"
 )
 
 // SourceControlProvider defines the interface for interacting with a source control provider.
@@ -0,0 +1,313 @@
+package sourcehut
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"GitSyncer/core/git"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/internal/httpapi"
+)
+
+const (
+	// DefaultBaseURL is the hosted git.sr.ht service.
+	DefaultBaseURL = "https://git.sr.ht"
+
+	// OptionOwner lists another user's public repositories (e.g. "~sircmpwn") instead of the token owner's.
+	OptionOwner = "owner"
+
+	graphQLPath = "/query"
+)
+
+// Schema describes the configuration accepted by New.
+var Schema = provider.ConfigSchema{
+	BaseURL: provider.OptionSpec{
+		Type:    provider.OptionTypeURL,
+		Default: DefaultBaseURL,
+		Help:    "git service URL.",
+	},
+	Options: []provider.OptionSpec{
+		{Name: OptionOwner, Type: provider.OptionTypeString, Help: "List this user's public repositories (e.g. ~sircmpwn) instead of the token owner's."},
+	},
+}
+
+var _ provider.SourceControlProvider = (*Provider)(nil)
+
+// Provider implements provider.SourceControlProvider for SourceHut's git service via its GraphQL API.
+// Personal access tokens use the "token" auth type; OAuth 2.0 bearer tokens use "oauth".
+//
+// git.sr.ht serves HTTPS read-only, so pushing requires an SSH remote (git@git.sr.ht:~user/repo)
+// and an "ssh_key" credential on the provider doing the push.
+type Provider struct {
+	api   *httpapi.Client
+	host  string
+	owner string
+
+	mu   sync.RWMutex
+	cred *models.Credential
+}
+
+// New creates a SourceHut provider. cfg.BaseURL is the git service URL, e.g. https://git.sr.ht.
+func New(cfg provider.ProviderConfig) (provider.SourceControlProvider, error) {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	api, err := httpapi.NewClient(provider.ProviderSourceHut, strings.TrimSuffix(baseURL, graphQLPath), cfg.Transport)
+	if err != nil {
+		return nil, fmt.Errorf("sourcehut.New: %w", err)
+	}
+
+	owner := cfg.Options[OptionOwner]
+	if owner != "" && !strings.HasPrefix(owner, "~") {
+		owner = "~" + owner
+	}
+
+	p := &Provider{
+		api:   api,
+		host:  strings.ToLower(api.BaseURL.Hostname()),
+		owner: owner,
+	}
+	api.Authorize = p.authorize
+
+	return p, nil
+}
+
+// Register adds the SourceHut factory to the registry.
+func Register(r *provider.ProviderRegistry) error {
+	if err := r.RegisterSourceControlProviderFactory(provider.ProviderSourceHut, New); err != nil {
+		return err
+	}
+
+	return r.RegisterSourceControlProviderSchema(provider.ProviderSourceHut, Schema)
+}
+
+// Authenticate validates an API token by querying the current user. An "ssh_key" credential
+// is stored without an API call; it only enables git over SSH.
+func (p *Provider) Authenticate(ctx context.Context, cred *models.Credential) error {
+	if cred == nil {
+		return &provider.AuthError{Provider: provider.ProviderSourceHut, Message: "a credential is required"}
+	}
+
+	switch cred.AuthType {
+	case models.AuthTypeToken, models.AuthTypeOAuth, models.AuthTypeSSHKey:
+	default:
+		return &provider.AuthError{Provider: provider.ProviderSourceHut, Message: "unsupported auth type " + cred.AuthType}
+	}
+
+	p.mu.Lock()
+	p.cred = cred
+	p.mu.Unlock()
+
+	if cred.AuthType == models.AuthTypeSSHKey {
+		return nil
+	}
+
+	var data struct {
+		Me struct {
+			CanonicalName string `json:"canonicalName"`
+		} `json:"me"`
+	}
+
+	if err := p.api.GraphQL(ctx, graphQLPath, `query { me { canonicalName } }`, nil, &data); err != nil {
+		p.mu.Lock()
+		p.cred = nil
+		p.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+const repositoryFields = `results { name description visibility updated HEAD { name } owner { canonicalName } } cursor`
+
+var (
+	ownRepositoriesQuery  = `query($cursor: Cursor) { repositories(cursor: $cursor) { ` + repositoryFields + ` } }`
+	userRepositoriesQuery = `query($username: String!, $cursor: Cursor) { user(username: $username) { repositories(cursor: $cursor) { ` + repositoryFields + ` } } }`
+)
+
+type apiRepositoryPage struct {
+	Results []struct {
+		Name        string     `json:"name"`
+		Description string     `json:"description"`
+		Visibility  string     `json:"visibility"`
+		Updated     *time.Time `json:"updated"`
+		HEAD        *struct {
+			Name string `json:"name"`
+		} `json:"HEAD"`
+		Owner struct {
+			CanonicalName string `json:"canonicalName"`
+		} `json:"owner"`
+	} `json:"results"`
+	Cursor *string `json:"cursor"`
+}
+
+// ListRepos returns the token owner's repositories, or the public repositories of the "owner" option,
+// following the GraphQL cursor until it is exhausted.
+func (p *Provider) ListRepos(ctx context.Context) ([]models.Repository, error) {
+	if err := p.requireAPI(); err != nil {
+		return nil, err
+	}
+
+	var repos []models.Repository
+
+	var cursor *string
+
+	for {
+		page, err := p.repositoryPage(ctx, cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range page.Results {
+			// Unlisted repositories can be cloned by anyone with the URL.
+			visibility := models.VisibilityPublic
+			if r.Visibility == "PRIVATE" {
+				visibility = models.VisibilityPrivate
+			}
+
+			repo := models.Repository{
+				Name:        r.Owner.CanonicalName + "/" + r.Name,
+				CloneURL:    p.api.BaseURL.String() + "/" + r.Owner.CanonicalName + "/" + r.Name,
+				Description: r.Description,
+				Owner:       r.Owner.CanonicalName,
+				Visibility:  visibility,
+				PushedAt:    r.Updated,
+			}
+
+			if r.HEAD != nil {
+				repo.DefaultBranch = strings.TrimPrefix(r.HEAD.Name, "refs/heads/")
+			}
+
+			repos = append(repos, repo)
+		}
+
+		if page.Cursor == nil || *page.Cursor == "" {
+			return repos, nil
+		}
+
+		cursor = page.Cursor
+	}
+}
+
+func (p *Provider) repositoryPage(ctx context.Context, cursor *string) (*apiRepositoryPage, error) {
+	if p.owner == "" {
+		var data struct {
+			Repositories apiRepositoryPage `json:"repositories"`
+		}
+
+		if err := p.api.GraphQL(ctx, graphQLPath, ownRepositoriesQuery, map[string]any{"cursor": cursor}, &data); err != nil {
+			return nil, err
+		}
+
+		return &data.Repositories, nil
+	}
+
+	var data struct {
+		User *struct {
+			Repositories apiRepositoryPage `json:"repositories"`
+		} `json:"user"`
+	}
+
+	vars := map[string]any{"username": strings.TrimPrefix(p.owner, "~"), "cursor": cursor}
+	if err := p.api.GraphQL(ctx, graphQLPath, userRepositoriesQuery, vars, &data); err != nil {
+		return nil, err
+	}
+
+	if data.User == nil {
+		return nil, fmt.Errorf("sourcehut: user %s not found", p.owner)
+	}
+
+	return &data.User.Repositories, nil
+}
+
+// CloneRepo creates or updates a bare mirror of repo at destPath and records it as repo.LocalPath.
+func (p *Provider) CloneRepo(ctx context.Context, repo *models.Repository, destPath string) error {
+	auth, err := p.gitAuth()
+	if err != nil {
+		return err
+	}
+
+	if err := git.CloneMirror(ctx, repo.CloneURL, destPath, auth); err != nil {
+		return fmt.Errorf("sourcehut: clone %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderSourceHut, err))
+	}
+
+	repo.LocalPath = destPath
+
+	return nil
+}
+
+// PushMirror pushes the local mirror of repo to remoteURL, which must be an SSH remote.
+func (p *Provider) PushMirror(ctx context.Context, repo *models.Repository, remoteURL string) error {
+	auth, err := p.gitAuth()
+	if err != nil {
+		return err
+	}
+
+	if err := git.PushMirror(ctx, repo.LocalPath, remoteURL, auth); err != nil {
+		return fmt.Errorf("sourcehut: push mirror %s: %w", repo.Name, provider.ClassifyGitError(provider.ProviderSourceHut, err))
+	}
+
+	return nil
+}
+
+// ValidateURL reports whether url points at this SourceHut git service.
+func (p *Provider) ValidateURL(rawURL string) bool {
+	return provider.ExtractHost(rawURL) == p.host
+}
+
+func (p *Provider) GetProviderType() provider.ProviderType {
+	return provider.ProviderSourceHut
+}
+
+func (p *Provider) Capabilities() []provider.SourceControlProviderCapability {
+	return []provider.SourceControlProviderCapability{
+		provider.CapabilityAPI,
+		provider.CapabilitySSH,
+		provider.CapabilityTokenAuth,
+		provider.CapabilityOAuth,
+		provider.CapabilityWebhooks,
+	}
+}
+
+func (p *Provider) authorize(req *http.Request) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred == nil || p.cred.AuthType == models.AuthTypeSSHKey {
+		return
+	}
+
+	req.Header.Set("Authorization", "Bearer "+p.cred.AuthData)
+}
+
+// requireAPI checks for an API token; SSH keys authenticate git only.
+func (p *Provider) requireAPI() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred == nil || p.cred.AuthType == models.AuthTypeSSHKey {
+		return &provider.AuthError{Provider: provider.ProviderSourceHut, Message: "an api token is required"}
+	}
+
+	return nil
+}
+
+// gitAuth only passes SSH keys to git: tokens are not accepted over HTTPS.
+func (p *Provider) gitAuth() (*git.Auth, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.cred == nil || p.cred.AuthType != models.AuthTypeSSHKey {
+		return nil, nil
+	}
+
+	return git.AuthFromCredential(p.cred, "")
+}
@@ -1,6 +1,9 @@
 package provider
 
-import "time"
+import (
+	"net/http"
+	"time"
+)
 
 // ProviderType identifies a git hosting or storage provider.
 type ProviderType string
@@ -9,6 +12,18 @@ const (
 	ProviderGitHub ProviderType = "github"
 	ProviderGitLab ProviderType = "gitlab"
 	ProviderGitea  ProviderType = "gitea"
+
+	ProviderBitbucketCloud      ProviderType = "bitbucket_cloud"
+	ProviderBitbucketDataCenter ProviderType = "bitbucket_datacenter"
+	ProviderAzureDevOps         ProviderType = "azure_devops"
+	ProviderGerrit              ProviderType = "gerrit"
+	ProviderSourceHut           ProviderType = "sourcehut"
+
+	// ProviderPlainGit is any git server reachable over SSH or HTTPS without a REST API.
+	ProviderPlainGit ProviderType = "git"
+
+	// ProviderLocal is a directory of bare repositories on the local filesystem.
+	ProviderLocal ProviderType = "local"
 )
 
 // ProviderConfig holds configuration for initializing a provider.
@@ -16,6 +31,10 @@ type ProviderConfig struct {
 	Type    ProviderType      `json:"type"`
 	BaseURL string            `json:"base_url"`
 	Options map[string]string `json:"options,omitempty"`
+
+	// Transport, if set, carries the provider's API requests. Connectors pass the same one to
+	// every instance of a provider record so that its quota and revalidation cache persist.
+	Transport http.RoundTripper `json:"-"`
 }
 
 // StorageObject represents a file or object in a storage provider.
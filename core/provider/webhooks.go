@@ -0,0 +1,34 @@
+package provider
+
+import (
+	"context"
+
+	"GitSyncer/core/models"
+)
+
+// Webhook is a repository webhook as registered on the provider.
+type Webhook struct {
+	// ID is the provider's identifier for the hook, formatted as a string.
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+}
+
+// WebhookConfig describes a webhook to create or update. Hooks subscribe to the provider's push
+// events, including tag pushes and branch creation and deletion where those are separate events.
+type WebhookConfig struct {
+	URL string `json:"url"`
+
+	// Secret signs deliveries (GitHub, Gitea) or is sent with them as a token (GitLab).
+	Secret string `json:"-"`
+}
+
+// WebhookManager is implemented by providers advertising CapabilityWebhooks that can manage
+// repository webhooks through their API. repo is addressed by its CloneURL.
+type WebhookManager interface {
+	ListWebhooks(ctx context.Context, repo *models.Repository) ([]Webhook, error)
+	CreateWebhook(ctx context.Context, repo *models.Repository, cfg WebhookConfig) (*Webhook, error)
+	UpdateWebhook(ctx context.Context, repo *models.Repository, id string, cfg WebhookConfig) (*Webhook, error)
+	DeleteWebhook(ctx context.Context, repo *models.Repository, id string) error
+}
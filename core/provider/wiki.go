@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"strings"
+
+	"GitSyncer/core/models"
+)
+
+// wikiSuffix is appended to a repository's path to name its wiki repository.
+const wikiSuffix = ".wiki"
+
+// WikiCloneURL returns the clone URL of the wiki of the repository cloneURL addresses. GitHub,
+// GitLab and Gitea keep a wiki as a separate repository next to its parent, "repo.wiki.git" for
+// "repo.git"; local providers store wikis the same way.
+func WikiCloneURL(cloneURL string) string {
+	base := strings.TrimSuffix(strings.TrimRight(cloneURL, "/"), ".git")
+
+	return base + wikiSuffix + ".git"
+}
+
+// IsWikiURL reports whether cloneURL addresses a wiki repository.
+func IsWikiURL(cloneURL string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(strings.TrimRight(cloneURL, "/"), ".git"), wikiSuffix)
+}
+
+// WikiOf returns the wiki of repo as a repository of its own, which CloneRepo and PushMirror
+// transfer like any other, or nil when repo has no wiki.
+func WikiOf(repo *models.Repository) *models.Repository {
+	if !repo.HasWiki {
+		return nil
+	}
+
+	cloneURL := repo.WikiCloneURL
+	if cloneURL == "" {
+		cloneURL = WikiCloneURL(repo.CloneURL)
+	}
+
+	return &models.Repository{
+		ID:         repo.ID,
+		ProviderID: repo.ProviderID,
+		Name:       repo.Name + wikiSuffix,
+		CloneURL:   cloneURL,
+		Owner:      repo.Owner,
+		Visibility: repo.Visibility,
+	}
+}
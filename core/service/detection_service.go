@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/store"
+)
+
+const settingProbePrefix = "provider_probe:"
+
+// probeCacheTTL is how long a cached probe outcome is trusted before the host is probed again.
+const probeCacheTTL = 7 * 24 * time.Hour
+
+// DetectionService resolves the provider behind a repository URL. User-defined host mappings
+// are consulted first, then the built-in host rules; other hosts are probed and the outcome,
+// including "nothing found", is cached in settings for probeCacheTTL. Hosts that did not
+// answer at all are not cached.
+type DetectionService struct {
+	settingStore *store.SettingStore
+	mappingStore *store.HostMappingStore
+	registry     *provider.ProviderRegistry
+	client       *http.Client
+}
+
+// cachedProbe is the settings representation of a probe outcome. Result is nil when nothing answered.
+type cachedProbe struct {
+	Result   *provider.ProbeResult `json:"result"`
+	ProbedAt time.Time             `json:"probed_at"`
+}
+
+// NewDetectionService creates a DetectionService whose host mappings may name the source control
+// provider types registered in registry. A nil client uses http.DefaultClient.
+func NewDetectionService(
+	settingStore *store.SettingStore,
+	mappingStore *store.HostMappingStore,
+	registry *provider.ProviderRegistry,
+	client *http.Client,
+) *DetectionService {
+	return &DetectionService{settingStore: settingStore, mappingStore: mappingStore, registry: registry, client: client}
+}
+
+// Detect returns the provider type and API location for rawURL. Hosts matched by a mapping or
+// built-in rule are not probed and carry only the mapping's API URL. Hosts that cannot be
+// identified resolve to provider.ProviderPlainGit so any git remote stays usable.
+func (s *DetectionService) Detect(ctx context.Context, rawURL string) (*provider.ProbeResult, error) {
+	rules, err := s.hostRules()
+	if err != nil {
+		return nil, err
+	}
+
+	if rule, ok := provider.DetectProviderTypeWithRules(rawURL, rules); ok {
+		return &provider.ProbeResult{Type: rule.Type, APIURL: rule.APIURL}, nil
+	}
+
+	host := provider.ExtractHost(rawURL)
+	if host == "" {
+		return nil, fmt.Errorf("DetectionService.Detect: no host in %q", rawURL)
+	}
+
+	cached, err := s.cached(host)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached != nil {
+		return resolved(cached.Result, rawURL), nil
+	}
+
+	result, err := provider.ProbeProvider(ctx, s.client, rawURL)
+	if errors.Is(err, provider.ErrProbeUnreachable) {
+		// An offline or slow host may well be identifiable later.
+		return resolved(nil, rawURL), nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("DetectionService.Detect: %w", err)
+	}
+
+	data, err := json.Marshal(cachedProbe{Result: result, ProbedAt: time.Now().UTC()})
+	if err != nil {
+		return nil, fmt.Errorf("DetectionService.Detect: encode: %w", err)
+	}
+
+	if err := s.settingStore.Set(settingProbePrefix+host, string(data)); err != nil {
+		return nil, err
+	}
+
+	return resolved(result, rawURL), nil
+}
+
+// resolved returns result, or a provider.ResolveProviderType fallback when no probe identified rawURL.
+func resolved(result *provider.ProbeResult, rawURL string) *provider.ProbeResult {
+	if result != nil {
+		return result
+	}
+
+	return &provider.ProbeResult{Type: provider.ResolveProviderType(rawURL)}
+}
+
+// hostRules converts the stored host mappings into provider.HostRules.
+func (s *DetectionService) hostRules() ([]provider.HostRule, error) {
+	mappings, err := s.mappingStore.List()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]provider.HostRule, len(mappings))
+	for i, m := range mappings {
+		rules[i] = provider.HostRule{Pattern: m.Pattern, Type: provider.ProviderType(m.ProviderType), APIURL: m.APIURL}
+	}
+
+	return rules, nil
+}
+
+// ListMappings returns the user-defined host mappings.
+func (s *DetectionService) ListMappings() ([]models.HostMapping, error) {
+	return s.mappingStore.List()
+}
+
+// SaveMapping validates m and creates it, or updates it when m.ID is set.
+func (s *DetectionService) SaveMapping(m *models.HostMapping) error {
+	m.Pattern = strings.ToLower(strings.TrimSpace(m.Pattern))
+
+	if err := provider.ValidateHostPattern(m.Pattern); err != nil {
+		return fmt.Errorf("DetectionService.SaveMapping: %w", err)
+	}
+
+	if m.ProviderType == "" {
+		return fmt.Errorf("DetectionService.SaveMapping: provider type is required")
+	}
+
+	if _, err := s.registry.GetSourceControlProviderFactory(provider.ProviderType(m.ProviderType)); err != nil {
+		return fmt.Errorf("DetectionService.SaveMapping: unknown provider type %q", m.ProviderType)
+	}
+
+	if m.APIURL != "" {
+		if u, err := url.Parse(m.APIURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("DetectionService.SaveMapping: invalid api url %q", m.APIURL)
+		}
+	}
+
+	if m.ID == 0 {
+		return s.mappingStore.Create(m)
+	}
+
+	return s.mappingStore.Update(m)
+}
+
+// DeleteMapping removes a host mapping by ID.
+func (s *DetectionService) DeleteMapping(id int64) error {
+	return s.mappingStore.Delete(id)
+}
+
+// Forget drops the cached probe outcome for host so the next Detect probes it again.
+func (s *DetectionService) Forget(host string) error {
+	return s.settingStore.Delete(settingProbePrefix + host)
+}
+
+func (s *DetectionService) cached(host string) (*cachedProbe, error) {
+	value, err := s.settingStore.Get(settingProbePrefix + host)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var c cachedProbe
+	if err := json.Unmarshal([]byte(value), &c); err != nil {
+		// A corrupt entry is treated as a cache miss and overwritten.
+		return nil, nil
+	}
+
+	if time.Since(c.ProbedAt) > probeCacheTTL {
+		return nil, nil
+	}
+
+	return &c, nil
+}
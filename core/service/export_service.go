@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"time"
+
+	"GitSyncer/core/archive"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/store"
+)
+
+// ExportService exports the discussion history of repositories, their issues, pull requests,
+// comments, labels and milestones, into archives kept on storage providers. Archives are written
+// to tempDir before they are uploaded.
+type ExportService struct {
+	providerStore *store.ProviderStore
+	repoStore     *store.RepositoryStore
+	exportStore   *store.DiscussionExportStore
+	credentials   *CredentialService
+	connect       ProviderConnector
+	storage       StorageConnector
+	tempDir       string
+}
+
+// NewExportService creates an ExportService that reads discussions through connect, uploads
+// archives through storage and builds them under tempDir.
+func NewExportService(
+	providerStore *store.ProviderStore,
+	repoStore *store.RepositoryStore,
+	exportStore *store.DiscussionExportStore,
+	credentials *CredentialService,
+	connect ProviderConnector,
+	storage StorageConnector,
+	tempDir string,
+) *ExportService {
+	return &ExportService{
+		providerStore: providerStore,
+		repoStore:     repoStore,
+		exportStore:   exportStore,
+		credentials:   credentials,
+		connect:       connect,
+		storage:       storage,
+		tempDir:       tempDir,
+	}
+}
+
+// Exports returns the exports of a repository, most recent first.
+func (s *ExportService) Exports(repositoryID int64) ([]models.DiscussionExport, error) {
+	return s.exportStore.ListByRepository(repositoryID)
+}
+
+// Export archives the discussions of a repository to the storage provider record
+// storageProviderID, at discussions/<repository>/<time>.json. The first export to a storage is
+// full; later ones hold the issues and comments updated since the previous one, which the
+// archives' Since records. The export time is taken before fetching, so whatever changes while
+// fetching is exported again next time rather than missed.
+func (s *ExportService) Export(ctx context.Context, repositoryID, storageProviderID int64) (*models.DiscussionExport, error) {
+	if s.credentials.IsLocked() {
+		return nil, ErrLocked
+	}
+
+	repo, err := s.repoStore.GetByID(repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := s.providerStore.GetByID(repo.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	scp, err := s.connect(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	defer closeProvider(scp)
+
+	exporter, ok := scp.(provider.DiscussionExporter)
+	if !ok || !slices.Contains(scp.Capabilities(), provider.CapabilityIssues) {
+		return nil, fmt.Errorf("ExportService.Export(%d): provider %s cannot export discussions", repositoryID, scp.GetProviderType())
+	}
+
+	storageRecord, err := s.providerStore.GetByID(storageProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	storage, err := s.storage(ctx, storageRecord)
+	if err != nil {
+		return nil, err
+	}
+	defer closeProvider(storage)
+
+	var since *time.Time
+
+	last, err := s.exportStore.Latest(repositoryID, storageProviderID)
+	switch {
+	case err == nil:
+		since = &last.ExportedAt
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	a := &archive.Archive{
+		Provider:   source.Type,
+		Repository: repo.Name,
+		CloneURL:   repo.CloneURL,
+		Since:      since,
+		ExportedAt: time.Now().UTC(),
+	}
+
+	if err := fetchDiscussions(ctx, exporter, repo, a); err != nil {
+		return nil, fmt.Errorf("ExportService.Export(%d): %w", repositoryID, err)
+	}
+
+	e := &models.DiscussionExport{
+		RepositoryID:      repositoryID,
+		StorageProviderID: storageProviderID,
+		Path:              path.Join("discussions", repo.Name, a.ExportedAt.Format("20060102T150405.000000000Z")+".json"),
+		Since:             since,
+		ExportedAt:        a.ExportedAt,
+		Issues:            len(a.Issues),
+		Comments:          len(a.Comments),
+	}
+
+	if err := s.upload(ctx, storage, a, e.Path); err != nil {
+		return nil, fmt.Errorf("ExportService.Export(%d): %w", repositoryID, err)
+	}
+
+	if err := s.exportStore.Create(e); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// fetchDiscussions fills a with the labels and milestones of repo, and the issues and comments
+// updated since a.Since.
+func fetchDiscussions(ctx context.Context, exporter provider.DiscussionExporter, repo *models.Repository, a *archive.Archive) error {
+	var (
+		since time.Time
+		err   error
+	)
+
+	if a.Since != nil {
+		since = *a.Since
+	}
+
+	if a.Labels, err = exporter.ListLabels(ctx, repo); err != nil {
+		return fmt.Errorf("labels: %w", err)
+	}
+
+	if a.Milestones, err = exporter.ListMilestones(ctx, repo); err != nil {
+		return fmt.Errorf("milestones: %w", err)
+	}
+
+	if a.Issues, err = exporter.ListIssues(ctx, repo, since); err != nil {
+		return fmt.Errorf("issues: %w", err)
+	}
+
+	if a.Comments, err = exporter.ListComments(ctx, repo, since); err != nil {
+		return fmt.Errorf("comments: %w", err)
+	}
+
+	return nil
+}
+
+// upload writes a to a temporary file and uploads it to remotePath.
+func (s *ExportService) upload(ctx context.Context, storage provider.StorageProvider, a *archive.Archive, remotePath string) error {
+	if err := os.MkdirAll(s.tempDir, 0o750); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(s.tempDir, "discussions-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	err = archive.Write(f, a)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return storage.Upload(ctx, f.Name(), remotePath)
+}
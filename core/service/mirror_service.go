@@ -0,0 +1,653 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"GitSyncer/core/git"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/store"
+)
+
+// ErrPullMirrorDeletion is returned when removing or replacing a native pull mirror was not
+// confirmed. A pull mirror is the repository on the target provider, and some providers, such as
+// Gitea, can only remove it by deleting that repository with everything it holds.
+var ErrPullMirrorDeletion = errors.New("removing a pull mirror deletes its repository on the target provider")
+
+// tokenUsernames are the usernames providers expect alongside a token in HTTPS remote URLs.
+// Other providers accept any non-empty username.
+var tokenUsernames = map[provider.ProviderType]string{
+	provider.ProviderGitHub:         "x-access-token",
+	provider.ProviderGitLab:         "oauth2",
+	provider.ProviderBitbucketCloud: "x-token-auth",
+	provider.ProviderAzureDevOps:    "pat",
+}
+
+// MirrorService manages the mirrors of repositories. GitSyncer-driven mirrors are only records
+// here and are transferred by SyncService; native mirrors are also configured on the provider
+// that performs the transfer, which must advertise provider.CapabilityMirror and implement
+// provider.MirrorManager. Native mirrors need an unlocked vault to pass credentials on.
+type MirrorService struct {
+	providerStore *store.ProviderStore
+	repoStore     *store.RepositoryStore
+	mirrorStore   *store.MirrorStore
+	mappingStore  *store.SubmoduleMappingStore
+	historyStore  *store.SyncHistoryStore
+	credentials   *CredentialService
+	connect       ProviderConnector
+}
+
+// NewMirrorService creates a MirrorService that reaches providers through connect.
+func NewMirrorService(
+	providerStore *store.ProviderStore,
+	repoStore *store.RepositoryStore,
+	mirrorStore *store.MirrorStore,
+	mappingStore *store.SubmoduleMappingStore,
+	historyStore *store.SyncHistoryStore,
+	credentials *CredentialService,
+	connect ProviderConnector,
+) *MirrorService {
+	return &MirrorService{
+		providerStore: providerStore,
+		repoStore:     repoStore,
+		mirrorStore:   mirrorStore,
+		mappingStore:  mappingStore,
+		historyStore:  historyStore,
+		credentials:   credentials,
+		connect:       connect,
+	}
+}
+
+// List returns the mirrors of a repository.
+func (s *MirrorService) List(repositoryID int64) ([]models.Mirror, error) {
+	return s.mirrorStore.ListByRepository(repositoryID)
+}
+
+// Create adds a mirror, configuring it on the provider first when it is native.
+func (s *MirrorService) Create(ctx context.Context, m *models.Mirror) error {
+	repo, err := s.validate(m)
+	if err != nil {
+		return fmt.Errorf("MirrorService.Create: %w", err)
+	}
+
+	if m.Mode == models.MirrorModeNative {
+		if err := s.createNative(ctx, m, repo); err != nil {
+			return fmt.Errorf("MirrorService.Create: %w", err)
+		}
+	}
+
+	if err := s.mirrorStore.Create(m); err != nil {
+		if m.Mode == models.MirrorModeNative {
+			// Do not leave behind a native mirror nothing tracks.
+			_ = s.deleteNative(ctx, m, repo)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Update changes a mirror. Interval and protected-branch changes are applied to a native mirror
+// in place; a new direction, target or credential replaces it. Replacing a native pull mirror
+// deletes its repository on the target and fails with ErrPullMirrorDeletion unless deleteTarget
+// confirms that. A native pull mirror cannot change mode, which would leave no repository behind.
+func (s *MirrorService) Update(ctx context.Context, m *models.Mirror, deleteTarget bool) error {
+	existing, err := s.mirrorStore.GetByID(m.ID)
+	if err != nil {
+		return err
+	}
+
+	m.RepositoryID = existing.RepositoryID
+
+	repo, err := s.validate(m)
+	if err != nil {
+		return fmt.Errorf("MirrorService.Update(%d): %w", m.ID, err)
+	}
+
+	m.NativeID = existing.NativeID
+
+	switch {
+	case existing.Mode == models.MirrorModeNative && m.Mode == models.MirrorModeNative && sameNativeMirror(existing, m):
+		end, err := s.nativeEnd(ctx, m, repo)
+		if err == nil {
+			_, err = end.manager.UpdateMirror(ctx, end.repo, m.NativeID, provider.NativeMirrorConfig{
+				Direction:             m.Direction,
+				Interval:              time.Duration(m.IntervalSeconds) * time.Second,
+				OnlyProtectedBranches: m.OnlyProtectedBranches,
+			})
+			end.close()
+		}
+
+		if err != nil {
+			return fmt.Errorf("MirrorService.Update(%d): %w", m.ID, err)
+		}
+	default:
+		if isPullMirror(existing) && m.Mode != models.MirrorModeNative {
+			return fmt.Errorf("MirrorService.Update(%d): a pull mirror cannot change mode; delete it and create a new mirror", m.ID)
+		}
+
+		if isPullMirror(existing) && !deleteTarget {
+			return fmt.Errorf("MirrorService.Update(%d): %w", m.ID, ErrPullMirrorDeletion)
+		}
+
+		if existing.Mode == models.MirrorModeNative {
+			if err := s.deleteNative(ctx, existing, repo); err != nil {
+				return fmt.Errorf("MirrorService.Update(%d): %w", m.ID, err)
+			}
+
+			m.NativeID = ""
+		}
+
+		if m.Mode == models.MirrorModeNative {
+			if err := s.createNative(ctx, m, repo); err != nil {
+				// The old native mirror is gone, so record the mirror without one.
+				_ = s.mirrorStore.Update(m)
+
+				return fmt.Errorf("MirrorService.Update(%d): %w", m.ID, err)
+			}
+		}
+	}
+
+	return s.mirrorStore.Update(m)
+}
+
+// Delete removes a mirror, deleting its native counterpart from the provider. A native mirror
+// already deleted on the provider is not an error. Deleting a native pull mirror deletes its
+// repository on the target and fails with ErrPullMirrorDeletion unless deleteTarget confirms that.
+func (s *MirrorService) Delete(ctx context.Context, id int64, deleteTarget bool) error {
+	m, err := s.mirrorStore.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if isPullMirror(m) && !deleteTarget {
+		return fmt.Errorf("MirrorService.Delete(%d): %w", id, ErrPullMirrorDeletion)
+	}
+
+	if m.Mode == models.MirrorModeNative {
+		repo, err := s.repoStore.GetByID(m.RepositoryID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.deleteNative(ctx, m, repo); err != nil {
+			return fmt.Errorf("MirrorService.Delete(%d): %w", id, err)
+		}
+	}
+
+	return s.mirrorStore.Delete(id)
+}
+
+// NativeStatus returns the provider's view of a native mirror.
+func (s *MirrorService) NativeStatus(ctx context.Context, id int64) (*provider.NativeMirror, error) {
+	m, err := s.mirrorStore.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := s.nativeStatus(ctx, m)
+	if err != nil {
+		return nil, fmt.Errorf("MirrorService.NativeStatus(%d): %w", id, err)
+	}
+
+	return status, nil
+}
+
+// Trigger asks the provider to update a native mirror now. The outcome is recorded by a later
+// RecordStatus once the provider reports it.
+func (s *MirrorService) Trigger(ctx context.Context, m *models.Mirror) error {
+	if m.Mode != models.MirrorModeNative {
+		return fmt.Errorf("MirrorService.Trigger(%d): not a native mirror", m.ID)
+	}
+
+	repo, err := s.repoStore.GetByID(m.RepositoryID)
+	if err != nil {
+		return err
+	}
+
+	end, err := s.nativeEnd(ctx, m, repo)
+	if err == nil {
+		err = end.manager.SyncMirror(ctx, end.repo, m.NativeID)
+		end.close()
+	}
+
+	if err != nil {
+		return fmt.Errorf("MirrorService.Trigger(%d): %w", m.ID, err)
+	}
+
+	return nil
+}
+
+// RecordStatus reflects the latest completed update of a native mirror into sync_history. An
+// update is recorded once; mirrors that are still running or never updated record nothing. It
+// returns the new entry, or nil when there was nothing to record.
+func (s *MirrorService) RecordStatus(ctx context.Context, m *models.Mirror) (*models.SyncHistory, error) {
+	status, err := s.nativeStatus(ctx, m)
+	if err != nil {
+		return nil, fmt.Errorf("MirrorService.RecordStatus(%d): %w", m.ID, err)
+	}
+
+	var result string
+
+	switch status.Status {
+	case provider.MirrorStatusOK:
+		result = models.SyncStatusSuccess
+	case provider.MirrorStatusFailed:
+		result = models.SyncStatusFailed
+	default:
+		return nil, nil
+	}
+
+	if status.LastUpdateAt == nil {
+		return nil, nil
+	}
+
+	latest, err := s.historyStore.LatestByMirror(m.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if latest != nil && latest.Status == result && latest.FinishedAt != nil && latest.FinishedAt.Equal(*status.LastUpdateAt) {
+		return nil, nil
+	}
+
+	details, err := json.Marshal(SyncDetails{
+		Mode:         models.MirrorModeNative,
+		Direction:    m.Direction,
+		Target:       provider.RedactURL(m.TargetURL),
+		NativeStatus: status.Status,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updatedAt := status.LastUpdateAt.UTC()
+
+	h := &models.SyncHistory{
+		RepositoryID: m.RepositoryID,
+		MirrorID:     &m.ID,
+		Status:       result,
+		StartedAt:    updatedAt,
+		FinishedAt:   &updatedAt,
+		ErrorMessage: status.LastError,
+		Details:      string(details),
+	}
+
+	if err := s.historyStore.Create(h); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// RecordAllStatuses calls RecordStatus for every native mirror, returning the joined errors.
+func (s *MirrorService) RecordAllStatuses(ctx context.Context) error {
+	if s.credentials.IsLocked() {
+		return ErrLocked
+	}
+
+	mirrors, err := s.mirrorStore.List()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for i := range mirrors {
+		if mirrors[i].Mode != models.MirrorModeNative {
+			continue
+		}
+
+		if _, err := s.RecordStatus(ctx, &mirrors[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SubmoduleMappings returns where the submodule URLs of a mirror's repository are mirrored, and
+// those of their own submodules, each source URL once. Unmapped submodules are included.
+func (s *MirrorService) SubmoduleMappings(mirrorID int64) ([]models.SubmoduleMapping, error) {
+	var (
+		mappings []models.SubmoduleMapping
+		seen     = make(map[string]bool)
+		visited  = map[int64]bool{mirrorID: true}
+		queue    = []int64{mirrorID}
+	)
+
+	for len(queue) > 0 {
+		direct, err := s.mappingStore.ListByMirror(queue[0])
+		if err != nil {
+			return nil, fmt.Errorf("MirrorService.SubmoduleMappings(%d): %w", mirrorID, err)
+		}
+
+		queue = queue[1:]
+
+		for _, m := range direct {
+			if !seen[m.SourceURL] {
+				seen[m.SourceURL] = true
+				mappings = append(mappings, m)
+			}
+
+			if m.SubmoduleMirrorID != nil && !visited[*m.SubmoduleMirrorID] {
+				visited[*m.SubmoduleMirrorID] = true
+				queue = append(queue, *m.SubmoduleMirrorID)
+			}
+		}
+	}
+
+	return mappings, nil
+}
+
+// CloneCommand returns a git command line cloning a mirror's target with its submodules, rewriting
+// each mapped submodule URL to its mirror. Unmapped submodules are still cloned from their source,
+// as are those whose URL carries user information: insteadOf matches by prefix, so a rewrite
+// would have to repeat it and a redacted one would not match.
+func (s *MirrorService) CloneCommand(mirrorID int64) (string, error) {
+	m, err := s.mirrorStore.GetByID(mirrorID)
+	if err != nil {
+		return "", err
+	}
+
+	if m.Mode != models.MirrorModeGitSyncer || m.TargetURL == "" {
+		return "", fmt.Errorf("MirrorService.CloneCommand(%d): mirror has no target to clone yet", mirrorID)
+	}
+
+	mappings, err := s.SubmoduleMappings(mirrorID)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"git"}
+
+	for _, mapping := range mappings {
+		source := provider.RedactURL(mapping.SourceURL)
+		if mapping.SubmoduleMirrorID == nil || source != mapping.SourceURL {
+			continue
+		}
+
+		args = append(args, "-c", "url."+provider.RedactURL(mapping.TargetURL)+".insteadOf="+source)
+	}
+
+	args = append(args, "clone", "--recurse-submodules", provider.RedactURL(m.TargetURL))
+
+	for i, arg := range args {
+		args[i] = shellQuote(arg)
+	}
+
+	return strings.Join(args, " "), nil
+}
+
+// validate checks a mirror against its repository and target provider and returns the repository.
+func (s *MirrorService) validate(m *models.Mirror) (*models.Repository, error) {
+	repo, err := s.repoStore.GetByID(m.RepositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.providerStore.GetByID(m.TargetProviderID); err != nil {
+		return nil, err
+	}
+
+	switch m.Mode {
+	case "", models.MirrorModeGitSyncer:
+		m.Mode = models.MirrorModeGitSyncer
+		m.Direction, m.IntervalSeconds, m.OnlyProtectedBranches, m.NativeID = "", 0, false, ""
+
+		if m.TargetURL == "" && m.TargetOwner == "" {
+			return nil, errors.New("a target URL or owner is required")
+		}
+	case models.MirrorModeNative:
+		m.TargetOwner, m.MirrorSubmodules = "", false
+
+		if m.TargetURL == "" {
+			return nil, errors.New("target URL is required")
+		}
+
+		if m.Direction != provider.MirrorPush && m.Direction != provider.MirrorPull {
+			return nil, fmt.Errorf("native mirror direction must be %q or %q", provider.MirrorPush, provider.MirrorPull)
+		}
+
+		if m.IntervalSeconds < 0 {
+			return nil, errors.New("mirror interval cannot be negative")
+		}
+	default:
+		return nil, fmt.Errorf("unknown mirror mode %q", m.Mode)
+	}
+
+	return repo, nil
+}
+
+// isPullMirror reports whether m is a native pull mirror, which is the target repository itself.
+func isPullMirror(m *models.Mirror) bool {
+	return m.Mode == models.MirrorModeNative && m.Direction == provider.MirrorPull
+}
+
+// sameNativeMirror reports whether b can be applied to the native mirror of a by UpdateMirror.
+func sameNativeMirror(a, b *models.Mirror) bool {
+	return a.NativeID != "" &&
+		a.Direction == b.Direction &&
+		a.TargetProviderID == b.TargetProviderID &&
+		a.TargetURL == b.TargetURL &&
+		sameID(a.CredentialID, b.CredentialID)
+}
+
+func sameID(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+func (s *MirrorService) createNative(ctx context.Context, m *models.Mirror, repo *models.Repository) error {
+	end, err := s.nativeEnd(ctx, m, repo)
+	if err != nil {
+		return err
+	}
+	defer end.close()
+
+	cfg := provider.NativeMirrorConfig{
+		Direction:             m.Direction,
+		RemoteURL:             end.remoteURL,
+		Interval:              time.Duration(m.IntervalSeconds) * time.Second,
+		OnlyProtectedBranches: m.OnlyProtectedBranches,
+	}
+
+	cfg.Username, cfg.Password, err = s.remoteCredentials(m, end.remote)
+	if err != nil {
+		return err
+	}
+
+	native, err := end.manager.CreateMirror(ctx, end.repo, cfg)
+	if err != nil {
+		return err
+	}
+
+	m.NativeID = native.ID
+
+	return nil
+}
+
+func (s *MirrorService) deleteNative(ctx context.Context, m *models.Mirror, repo *models.Repository) error {
+	if m.NativeID == "" {
+		return nil
+	}
+
+	end, err := s.nativeEnd(ctx, m, repo)
+	if err != nil {
+		return err
+	}
+	defer end.close()
+
+	if err := end.manager.DeleteMirror(ctx, end.repo, m.NativeID); err != nil && !provider.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *MirrorService) nativeStatus(ctx context.Context, m *models.Mirror) (*provider.NativeMirror, error) {
+	if m.Mode != models.MirrorModeNative {
+		return nil, errors.New("not a native mirror")
+	}
+
+	repo, err := s.repoStore.GetByID(m.RepositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := s.nativeEnd(ctx, m, repo)
+	if err != nil {
+		return nil, err
+	}
+	defer end.close()
+
+	mirrors, err := end.manager.ListMirrors(ctx, end.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	i := slices.IndexFunc(mirrors, func(n provider.NativeMirror) bool { return n.ID == m.NativeID })
+	if i < 0 {
+		return nil, &provider.NotFoundError{Provider: end.hostType, Message: "native mirror " + m.NativeID}
+	}
+
+	return &mirrors[i], nil
+}
+
+// nativeMirrorEnd is the provider hosting a native mirror and the remote it transfers from or to.
+type nativeMirrorEnd struct {
+	manager  provider.MirrorManager
+	hostType provider.ProviderType
+
+	// repo is the repository the mirror is configured on.
+	repo *models.Repository
+
+	remoteURL string
+	remote    *models.Provider
+}
+
+// close releases the connection to the provider hosting the mirror.
+func (e *nativeMirrorEnd) close() {
+	closeProvider(e.manager)
+}
+
+// nativeEnd resolves where a native mirror lives: a push mirror on the source repository pushing
+// to the target, a pull mirror on the target repository pulling from the source. Callers release
+// the returned end with close.
+func (s *MirrorService) nativeEnd(ctx context.Context, m *models.Mirror, repo *models.Repository) (*nativeMirrorEnd, error) {
+	target, err := s.providerStore.GetByID(m.TargetProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := s.providerStore.GetByID(repo.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	end := &nativeMirrorEnd{}
+	host := source
+
+	switch m.Direction {
+	case provider.MirrorPush:
+		end.repo, end.remoteURL, end.remote = repo, m.TargetURL, target
+	case provider.MirrorPull:
+		host = target
+		end.repo = &models.Repository{
+			ProviderID:  target.ID,
+			Name:        repo.Name,
+			CloneURL:    m.TargetURL,
+			Description: repo.Description,
+		}
+		end.remoteURL, end.remote = repo.CloneURL, source
+	default:
+		return nil, fmt.Errorf("native mirror direction %q: %w", m.Direction, provider.ErrUnsupportedMirror)
+	}
+
+	scp, err := s.connect(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	manager, ok := scp.(provider.MirrorManager)
+	if !ok || !slices.Contains(scp.Capabilities(), provider.CapabilityMirror) {
+		closeProvider(scp)
+		return nil, fmt.Errorf("provider %s cannot host native mirrors: %w", host.Name, provider.ErrUnsupportedMirror)
+	}
+
+	if !slices.Contains(manager.MirrorDirections(), m.Direction) {
+		closeProvider(scp)
+		return nil, fmt.Errorf("provider %s cannot host %s mirrors: %w", host.Name, m.Direction, provider.ErrUnsupportedMirror)
+	}
+
+	end.manager, end.hostType = manager, scp.GetProviderType()
+
+	return end, nil
+}
+
+// remoteCredentials returns the HTTPS username and password the native mirror authenticates
+// against the remote with: the mirror's own credential, or the first one of the remote provider.
+func (s *MirrorService) remoteCredentials(m *models.Mirror, remote *models.Provider) (string, string, error) {
+	var cred *models.Credential
+
+	if m.CredentialID != nil {
+		c, err := s.credentials.GetByID(*m.CredentialID)
+		if err != nil {
+			return "", "", err
+		}
+
+		cred = c
+	} else {
+		creds, err := s.credentials.GetByProviderID(remote.ID)
+		if err != nil {
+			return "", "", err
+		}
+
+		for i := range creds {
+			if creds[i].AuthType != models.AuthTypeWebhookSecret {
+				cred = &creds[i]
+				break
+			}
+		}
+	}
+
+	if cred == nil {
+		return "", "", nil
+	}
+
+	username := tokenUsernames[provider.ProviderType(remote.Type)]
+	if username == "" {
+		username = "git"
+	}
+
+	auth, err := git.AuthFromCredential(cred, username)
+	if err != nil {
+		return "", "", err
+	}
+
+	if auth.SSHKey != "" {
+		return "", "", fmt.Errorf("SSH keys cannot authenticate native mirrors: %w", provider.ErrUnsupportedMirror)
+	}
+
+	return auth.Username, auth.Password, nil
+}
+
+// shellQuote quotes arg for POSIX shells when it holds anything but plain path characters.
+func shellQuote(arg string) string {
+	if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:@+=,") == "" {
+		return arg
+	}
+
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/ratelimit"
+)
+
+// ProviderConnector returns an authenticated provider for a provider record. Providers that
+// hold resources, such as a plugin process, implement io.Closer; callers release them with
+// closeProvider once done.
+type ProviderConnector func(ctx context.Context, p *models.Provider) (provider.SourceControlProvider, error)
+
+// StorageConnector returns an authenticated storage provider for a provider record. Like a
+// ProviderConnector's, its providers are released with closeProvider.
+type StorageConnector func(ctx context.Context, p *models.Provider) (provider.StorageProvider, error)
+
+// ProviderTransports keeps one API transport per provider record. Connected providers are
+// short-lived, so the quota and revalidation cache of their transport would otherwise be lost
+// after every sync.
+type ProviderTransports struct {
+	mu         sync.Mutex
+	transports map[int64]*ratelimit.Transport
+}
+
+// NewProviderTransports creates an empty ProviderTransports.
+func NewProviderTransports() *ProviderTransports {
+	return &ProviderTransports{transports: make(map[int64]*ratelimit.Transport)}
+}
+
+// transport returns the transport of the provider record providerID, creating it on first use.
+func (t *ProviderTransports) transport(providerID int64) *ratelimit.Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rl, ok := t.transports[providerID]
+	if !ok {
+		rl = ratelimit.New(nil, ratelimit.DefaultPolicy)
+		t.transports[providerID] = rl
+	}
+
+	return rl
+}
+
+// RateLimits returns the quota last reported to the provider record providerID, sorted by
+// resource. It is empty until a provider for the record has made an API request.
+func (t *ProviderTransports) RateLimits(providerID int64) []provider.RateLimitStatus {
+	t.mu.Lock()
+	rl, ok := t.transports[providerID]
+	t.mu.Unlock()
+
+	if !ok {
+		return []provider.RateLimitStatus{}
+	}
+
+	return rl.Statuses()
+}
+
+// RegistryConnector creates providers from registry with the base URL and options of the
+// provider record, and authenticates them with its first credential that is not a webhook
+// secret. Providers without such a credential are returned unauthenticated. The providers of
+// one record share its transport in transports.
+func RegistryConnector(registry *provider.ProviderRegistry, credentials *CredentialService, transports *ProviderTransports) ProviderConnector {
+	return func(ctx context.Context, p *models.Provider) (provider.SourceControlProvider, error) {
+		scp, err := registry.NewSourceControlProvider(provider.ProviderConfig{
+			Type:      provider.ProviderType(p.Type),
+			BaseURL:   p.BaseURL,
+			Options:   p.Options,
+			Transport: transports.transport(p.ID),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("connect provider %d: %w", p.ID, err)
+		}
+
+		if err := authenticate(ctx, credentials, p, scp.Authenticate); err != nil {
+			closeProvider(scp)
+			return nil, err
+		}
+
+		return scp, nil
+	}
+}
+
+// RegistryStorageConnector creates storage providers from registry, authenticating them like
+// RegistryConnector does.
+func RegistryStorageConnector(registry *provider.ProviderRegistry, credentials *CredentialService) StorageConnector {
+	return func(ctx context.Context, p *models.Provider) (provider.StorageProvider, error) {
+		sp, err := registry.NewStorageProvider(provider.ProviderConfig{
+			Type:    provider.ProviderType(p.Type),
+			BaseURL: p.BaseURL,
+			Options: p.Options,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("connect storage provider %d: %w", p.ID, err)
+		}
+
+		if err := authenticate(ctx, credentials, p, sp.Authenticate); err != nil {
+			closeProvider(sp)
+			return nil, err
+		}
+
+		return sp, nil
+	}
+}
+
+// authenticate calls auth with the first credential of the provider record p that is not a
+// webhook secret, if there is one.
+func authenticate(ctx context.Context, credentials *CredentialService, p *models.Provider, auth func(context.Context, *models.Credential) error) error {
+	creds, err := credentials.GetByProviderID(p.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range creds {
+		if creds[i].AuthType == models.AuthTypeWebhookSecret {
+			continue
+		}
+
+		if err := auth(ctx, &creds[i]); err != nil {
+			return fmt.Errorf("connect provider %d: %w", p.ID, err)
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// closeProvider releases the resources of a connected provider, or of a capability interface
+// asserted from one, if it holds any. A nil provider is ignored.
+func closeProvider(p any) {
+	if c, ok := p.(io.Closer); ok {
+		c.Close()
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Sync request reasons.
+const (
+	SyncReasonWebhook  = "webhook"
+	SyncReasonSchedule = "schedule"
+	SyncReasonManual   = "manual"
+)
+
+// SyncRequest asks for one repository to be synced. Attempt counts the retries of a request
+// whose sync failed with a transient error.
+type SyncRequest struct {
+	RepositoryID int64     `json:"repository_id"`
+	Reason       string    `json:"reason"`
+	RequestedAt  time.Time `json:"requested_at"`
+	Attempt      int       `json:"attempt,omitempty"`
+}
+
+// SyncQueue is the in-memory queue of repositories waiting to be synced. A repository is
+// queued at most once: requesting it again while it is pending keeps its original place.
+type SyncQueue struct {
+	mu      sync.Mutex
+	order   []int64
+	pending map[int64]SyncRequest
+	ready   chan struct{}
+}
+
+// NewSyncQueue creates an empty SyncQueue.
+func NewSyncQueue() *SyncQueue {
+	return &SyncQueue{
+		pending: make(map[int64]SyncRequest),
+		ready:   make(chan struct{}, 1),
+	}
+}
+
+// Enqueue adds repositoryID to the queue. It returns false when the repository was already pending.
+func (q *SyncQueue) Enqueue(repositoryID int64, reason string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.add(SyncRequest{RepositoryID: repositoryID, Reason: reason, RequestedAt: time.Now().UTC()})
+}
+
+// Retry queues req again after delay with its Attempt incremented, unless the repository has
+// been queued again in the meantime.
+func (q *SyncQueue) Retry(req SyncRequest, delay time.Duration) {
+	req.Attempt++
+
+	time.AfterFunc(delay, func() {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		q.add(req)
+	})
+}
+
+// add queues req unless its repository is pending. q.mu must be held.
+func (q *SyncQueue) add(req SyncRequest) bool {
+	if _, ok := q.pending[req.RepositoryID]; ok {
+		return false
+	}
+
+	q.pending[req.RepositoryID] = req
+	q.order = append(q.order, req.RepositoryID)
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+
+	return true
+}
+
+// Next removes and returns the oldest request, waiting until one is queued or ctx ends.
+func (q *SyncQueue) Next(ctx context.Context) (SyncRequest, error) {
+	for {
+		q.mu.Lock()
+		if len(q.order) > 0 {
+			id := q.order[0]
+			q.order = q.order[1:]
+
+			req := q.pending[id]
+			delete(q.pending, id)
+
+			// Wake another waiter if more work remains.
+			if len(q.order) > 0 {
+				select {
+				case q.ready <- struct{}{}:
+				default:
+				}
+			}
+
+			q.mu.Unlock()
+
+			return req, nil
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-q.ready:
+		case <-ctx.Done():
+			return SyncRequest{}, ctx.Err()
+		}
+	}
+}
+
+// Pending returns the queued requests, oldest first.
+func (q *SyncQueue) Pending() []SyncRequest {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	requests := make([]SyncRequest, 0, len(q.order))
+	for _, id := range q.order {
+		requests = append(requests, q.pending[id])
+	}
+
+	return requests
+}
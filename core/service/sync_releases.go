@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/store"
+)
+
+// ReleaseStats counts the published releases of the source and what replicating them changed on
+// the target.
+type ReleaseStats struct {
+	Releases       int   `json:"releases"`
+	Created        int   `json:"created"`
+	Updated        int   `json:"updated"`
+	AssetsUploaded int   `json:"assets_uploaded"`
+	UploadedBytes  int64 `json:"uploaded_bytes"`
+}
+
+// releaseSource holds the published releases of a repository on its provider. The files of their
+// assets are downloaded into dir at most once per sync, however many targets need them. Checksums
+// computed for assets whose provider reports none are kept in checksums for later syncs.
+type releaseSource struct {
+	repo      *models.Repository
+	manager   provider.ReleaseManager
+	releases  []provider.Release
+	dir       string
+	files     map[string]assetFile
+	checksums *store.ReleaseAssetChecksumStore
+}
+
+// assetFile is a downloaded asset.
+type assetFile struct {
+	path   string
+	size   int64
+	sha256 string
+}
+
+// sourceReleases lists the published releases of repo, or returns nil when its provider does not
+// manage releases. Drafts are left out; their tags may not exist yet.
+func (s *SyncService) sourceReleases(ctx context.Context, repo *models.Repository, dir string, source func() (provider.SourceControlProvider, error)) (*releaseSource, error) {
+	scp, err := source()
+	if err != nil {
+		return nil, err
+	}
+
+	manager, ok := scp.(provider.ReleaseManager)
+	if !ok || !slices.Contains(scp.Capabilities(), provider.CapabilityReleases) {
+		return nil, nil
+	}
+
+	releases, err := manager.ListReleases(ctx, repo)
+	if err != nil {
+		return nil, fmt.Errorf("list releases: %w", err)
+	}
+
+	releases = slices.DeleteFunc(releases, func(r provider.Release) bool { return r.Draft })
+
+	return &releaseSource{
+		repo:      repo,
+		manager:   manager,
+		releases:  releases,
+		dir:       dir,
+		files:     make(map[string]assetFile),
+		checksums: s.checksums,
+	}, nil
+}
+
+// pushReleases replicates the releases of the source to the mirror's target scp, when both
+// providers manage releases, and reports what changed in details. Releases are matched by tag
+// name and assets by name and checksum, so a repeated sync changes nothing. A failing release
+// does not stop the others; their errors are joined.
+func (s *SyncService) pushReleases(
+	ctx context.Context,
+	repo *models.Repository,
+	m *models.Mirror,
+	scp provider.SourceControlProvider,
+	details *SyncDetails,
+	sourceReleases func() (*releaseSource, error),
+) error {
+	manager, ok := scp.(provider.ReleaseManager)
+	if !ok || !slices.Contains(scp.Capabilities(), provider.CapabilityReleases) {
+		return nil
+	}
+
+	source, err := sourceReleases()
+	if err != nil || source == nil || len(source.releases) == 0 {
+		return err
+	}
+
+	targetRepo := &models.Repository{ProviderID: m.TargetProviderID, Name: repo.Name, CloneURL: m.TargetURL}
+
+	existing, err := manager.ListReleases(ctx, targetRepo)
+	if err != nil {
+		return fmt.Errorf("list target releases: %w", err)
+	}
+
+	byTag := make(map[string]*provider.Release, len(existing))
+	for i := range existing {
+		byTag[existing[i].TagName] = &existing[i]
+	}
+
+	stats := &ReleaseStats{Releases: len(source.releases)}
+	details.Releases = stats
+
+	var errs []error
+
+	for i := range source.releases {
+		rel := &source.releases[i]
+
+		if err := s.pushRelease(ctx, source, rel, scp.GetProviderType(), manager, targetRepo, byTag[rel.TagName], stats); err != nil {
+			errs = append(errs, fmt.Errorf("release %s: %w", rel.TagName, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// pushRelease creates or updates the target's release for the tag of rel, then uploads the
+// assets it lacks or holds with other contents.
+func (s *SyncService) pushRelease(
+	ctx context.Context,
+	source *releaseSource,
+	rel *provider.Release,
+	pt provider.ProviderType,
+	manager provider.ReleaseManager,
+	targetRepo *models.Repository,
+	current *provider.Release,
+	stats *ReleaseStats,
+) error {
+	cfg := provider.ConfigOf(rel)
+
+	switch {
+	case current == nil:
+		created, err := manager.CreateRelease(ctx, targetRepo, cfg)
+		if err != nil {
+			return err
+		}
+
+		current = created
+		stats.Created++
+	case provider.ReleaseChanged(pt, current, cfg):
+		assets := current.Assets
+
+		updated, err := manager.UpdateRelease(ctx, targetRepo, current.ID, cfg)
+		if err != nil {
+			return err
+		}
+
+		// Not every provider returns the assets with an updated release.
+		current = updated
+		current.Assets = assets
+		stats.Updated++
+	}
+
+	have := make(map[string]provider.ReleaseAsset, len(current.Assets))
+	for _, a := range current.Assets {
+		have[a.Name] = a
+	}
+
+	var errs []error
+
+	for _, asset := range rel.Assets {
+		if err := source.pushAsset(ctx, rel, asset, manager, targetRepo, current.ID, have, stats); err != nil {
+			errs = append(errs, fmt.Errorf("asset %s: %w", asset.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// pushAsset uploads asset to the target release id unless an asset of the same name with the
+// same contents is attached to it already. One with other contents is replaced.
+func (src *releaseSource) pushAsset(
+	ctx context.Context,
+	rel *provider.Release,
+	asset provider.ReleaseAsset,
+	manager provider.ReleaseManager,
+	targetRepo *models.Repository,
+	id string,
+	have map[string]provider.ReleaseAsset,
+	stats *ReleaseStats,
+) error {
+	if existing, ok := have[asset.Name]; ok {
+		same, err := src.matches(ctx, rel, asset, manager, targetRepo, existing)
+		if err != nil || same {
+			return err
+		}
+
+		if err := manager.DeleteReleaseAsset(ctx, targetRepo, id, existing); err != nil {
+			return err
+		}
+	}
+
+	file, err := src.file(ctx, rel, asset)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(file.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	uploaded, err := manager.UploadReleaseAsset(ctx, targetRepo, id, asset.Name, f, file.size)
+	if err != nil {
+		return err
+	}
+
+	stats.AssetsUploaded++
+	stats.UploadedBytes += file.size
+
+	return src.remember(targetRepo.ProviderID, *uploaded, file.size, file.sha256)
+}
+
+// matches reports whether existing, an asset on the target, has the contents of the source's
+// asset. Sizes are compared first, then checksums the providers report or that were recorded for
+// the assets before; only checksums still missing are computed from the downloaded files.
+func (src *releaseSource) matches(
+	ctx context.Context,
+	rel *provider.Release,
+	asset provider.ReleaseAsset,
+	manager provider.ReleaseManager,
+	targetRepo *models.Repository,
+	existing provider.ReleaseAsset,
+) (bool, error) {
+	if asset.Size > 0 && existing.Size > 0 && asset.Size != existing.Size {
+		return false, nil
+	}
+
+	ours, err := src.checksum(src.repo.ProviderID, asset)
+	if err != nil {
+		return false, err
+	}
+
+	if ours == "" {
+		file, err := src.file(ctx, rel, asset)
+		if err != nil {
+			return false, err
+		}
+
+		ours = file.sha256
+	}
+
+	theirs, err := src.checksum(targetRepo.ProviderID, existing)
+	if err != nil {
+		return false, err
+	}
+
+	if theirs == "" {
+		r, err := manager.DownloadReleaseAsset(ctx, targetRepo, existing)
+		if err != nil {
+			return false, err
+		}
+		defer r.Close()
+
+		h := sha256.New()
+
+		n, err := io.Copy(h, r)
+		if err != nil {
+			return false, err
+		}
+
+		theirs = hex.EncodeToString(h.Sum(nil))
+
+		if err := src.remember(targetRepo.ProviderID, existing, n, theirs); err != nil {
+			return false, err
+		}
+	}
+
+	return strings.EqualFold(ours, theirs), nil
+}
+
+// checksum returns the checksum asset of the provider record providerID reports, or the one
+// recorded for it while its download URL and size are unchanged, or "".
+func (src *releaseSource) checksum(providerID int64, asset provider.ReleaseAsset) (string, error) {
+	if asset.SHA256 != "" {
+		return asset.SHA256, nil
+	}
+
+	c, err := src.checksums.Get(providerID, asset.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if c.DownloadURL != asset.DownloadURL || (asset.Size > 0 && c.Size != asset.Size) {
+		return "", nil
+	}
+
+	return c.SHA256, nil
+}
+
+// remember records the checksum of the size bytes of asset on the provider record providerID,
+// unless the provider reports checksums itself.
+func (src *releaseSource) remember(providerID int64, asset provider.ReleaseAsset, size int64, sum string) error {
+	if asset.SHA256 != "" {
+		return nil
+	}
+
+	return src.checksums.Upsert(&models.ReleaseAssetChecksum{
+		ProviderID:  providerID,
+		AssetID:     asset.ID,
+		DownloadURL: asset.DownloadURL,
+		Size:        size,
+		SHA256:      sum,
+	})
+}
+
+// file downloads the source's asset of rel once and returns the downloaded file.
+func (src *releaseSource) file(ctx context.Context, rel *provider.Release, asset provider.ReleaseAsset) (assetFile, error) {
+	key := rel.ID + "/" + asset.ID
+	if file, ok := src.files[key]; ok {
+		return file, nil
+	}
+
+	if err := os.MkdirAll(src.dir, 0o750); err != nil {
+		return assetFile{}, err
+	}
+
+	r, err := src.manager.DownloadReleaseAsset(ctx, src.repo, asset)
+	if err != nil {
+		return assetFile{}, err
+	}
+	defer r.Close()
+
+	f, err := os.CreateTemp(src.dir, "asset-*")
+	if err != nil {
+		return assetFile{}, err
+	}
+
+	h := sha256.New()
+
+	n, err := io.Copy(io.MultiWriter(f, h), r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return assetFile{}, err
+	}
+
+	file := assetFile{path: f.Name(), size: n, sha256: hex.EncodeToString(h.Sum(nil))}
+
+	if asset.SHA256 != "" && !strings.EqualFold(asset.SHA256, file.sha256) {
+		return assetFile{}, fmt.Errorf("downloaded %d bytes that do not match the reported checksum", n)
+	}
+
+	if err := src.remember(src.repo.ProviderID, asset, file.size, file.sha256); err != nil {
+		return assetFile{}, err
+	}
+
+	src.files[key] = file
+
+	return file, nil
+}
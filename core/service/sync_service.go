@@ -0,0 +1,629 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"GitSyncer/core/git"
+	"GitSyncer/core/lfs"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/store"
+)
+
+// SyncDetails is the JSON stored in the details of a sync_history entry.
+type SyncDetails struct {
+	Mode      string `json:"mode"`
+	Direction string `json:"direction,omitempty"`
+
+	// Target is the mirror's target URL without credentials.
+	Target string `json:"target"`
+
+	// NativeStatus is the provider's status of a native mirror.
+	NativeStatus string `json:"native_status,omitempty"`
+
+	// Wiki marks the entry of the repository's wiki, which is synced after the repository itself.
+	Wiki bool `json:"wiki,omitempty"`
+
+	// LFS counts the LFS objects the synced refs reference and those copied to the target. It is
+	// set when both providers serve LFS and the repository uses it.
+	LFS *lfs.Stats `json:"lfs,omitempty"`
+
+	// Releases counts the source's releases and those created, updated or given assets on the
+	// target. It is set when both providers manage releases and the source has any.
+	Releases *ReleaseStats `json:"releases,omitempty"`
+
+	// Created reports that the target repository was created by this sync, MetadataReplicated
+	// that the source's metadata was applied to it.
+	Created            bool `json:"created,omitempty"`
+	MetadataReplicated bool `json:"metadata_replicated,omitempty"`
+
+	// Permanent marks a failure that retrying will not fix until the mirror's configuration,
+	// credentials or target change.
+	Permanent bool `json:"permanent,omitempty"`
+}
+
+const (
+	// maxSyncRetries bounds how often Run retries a sync failing with a transient error.
+	maxSyncRetries = 3
+
+	// syncRetryDelay is the wait before the first retry when the error does not ask for one;
+	// it doubles with every attempt.
+	syncRetryDelay = time.Minute
+)
+
+// SyncService transfers repositories to their mirrors. GitSyncer-driven mirrors are cloned into
+// dataDir once per sync and pushed to each target; native mirrors are asked to update on the
+// provider, and their outcome is recorded by MirrorService.RecordStatus.
+type SyncService struct {
+	providerStore *store.ProviderStore
+	repoStore     *store.RepositoryStore
+	mirrorStore   *store.MirrorStore
+	historyStore  *store.SyncHistoryStore
+	checksums     *store.ReleaseAssetChecksumStore
+	credentials   *CredentialService
+	mirrors       *MirrorService
+	connect       ProviderConnector
+	dataDir       string
+}
+
+// NewSyncService creates a SyncService that keeps local mirror clones under dataDir.
+func NewSyncService(
+	providerStore *store.ProviderStore,
+	repoStore *store.RepositoryStore,
+	mirrorStore *store.MirrorStore,
+	historyStore *store.SyncHistoryStore,
+	checksums *store.ReleaseAssetChecksumStore,
+	credentials *CredentialService,
+	mirrors *MirrorService,
+	connect ProviderConnector,
+	dataDir string,
+) *SyncService {
+	return &SyncService{
+		providerStore: providerStore,
+		repoStore:     repoStore,
+		mirrorStore:   mirrorStore,
+		historyStore:  historyStore,
+		checksums:     checksums,
+		credentials:   credentials,
+		mirrors:       mirrors,
+		connect:       connect,
+		dataDir:       dataDir,
+	}
+}
+
+// History returns up to limit sync_history entries of a repository, most recent first.
+func (s *SyncService) History(repositoryID int64, limit int) ([]models.SyncHistory, error) {
+	return s.historyStore.ListByRepository(repositoryID, limit)
+}
+
+// Sync transfers a repository to all of its mirrors, recording one sync_history entry per
+// GitSyncer-driven mirror and per native mirror that could not be triggered. The wiki of a
+// repository that has one gets an entry of its own for each GitSyncer-driven mirror whose target
+// hosts wikis. LFS objects are copied along when both providers serve LFS, and releases with their
+// assets when both manage releases. Mirrors with MirrorSubmodules then have the repositories the
+// synced refs reference as submodules mirrored and synced too. A failing mirror does not stop the
+// others; their errors are joined.
+func (s *SyncService) Sync(ctx context.Context, repositoryID int64) error {
+	if s.credentials.IsLocked() {
+		return ErrLocked
+	}
+
+	return s.sync(ctx, repositoryID, make(map[int64]bool))
+}
+
+// sync is Sync for a repository not in visited, which collects the repositories synced in this
+// run so that submodules referencing each other are synced once.
+func (s *SyncService) sync(ctx context.Context, repositoryID int64, visited map[int64]bool) error {
+	visited[repositoryID] = true
+
+	repo, err := s.repoStore.GetByID(repositoryID)
+	if err != nil {
+		return err
+	}
+
+	mirrors, err := s.mirrorStore.ListByRepository(repositoryID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		errs           []error
+		pushed         bool
+		withSubmodules []*models.Mirror
+	)
+
+	// The source provider is connected once per run and released when the run ends.
+	var connected provider.SourceControlProvider
+
+	source := sync.OnceValues(func() (provider.SourceControlProvider, error) {
+		scp, err := s.source(ctx, repo)
+		connected = scp
+
+		return scp, err
+	})
+	defer func() { closeProvider(connected) }()
+
+	// The source and its wiki are cloned, and its metadata, LFS objects, releases and submodules
+	// looked up, at most once per run. Release assets are downloaded into a directory removed
+	// afterwards.
+	clone := sync.OnceValue(func() error { return s.clone(ctx, repo, source) })
+	cloneWiki := sync.OnceValues(func() (*models.Repository, error) { return s.cloneWiki(ctx, repo, source) })
+	metadata := sync.OnceValues(func() (provider.RepoMetadata, error) { return s.metadata(ctx, repo, source) })
+	sourceLFS := sync.OnceValues(func() (*lfs.Endpoint, error) { return s.sourceLFS(ctx, repo, source) })
+	lfsObjects := sync.OnceValues(func() ([]lfs.Pointer, error) { return lfs.Scan(ctx, repo.LocalPath) })
+	submoduleURLs := sync.OnceValues(func() ([]string, error) { return git.SubmoduleURLs(ctx, repo.LocalPath) })
+
+	assetDir := filepath.Join(s.dataDir, fmt.Sprintf("%d.releases", repo.ID))
+	defer os.RemoveAll(assetDir)
+
+	sourceReleases := sync.OnceValues(func() (*releaseSource, error) { return s.sourceReleases(ctx, repo, assetDir, source) })
+
+	for i := range mirrors {
+		m := &mirrors[i]
+
+		if m.Mode == models.MirrorModeNative {
+			if err := s.mirrors.Trigger(ctx, m); err != nil {
+				errs = append(errs, s.recordFailure(m, err))
+			}
+
+			continue
+		}
+
+		h, details, err := s.start(m, false)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		var target provider.SourceControlProvider
+
+		err = clone()
+		if err == nil {
+			target, err = s.target(ctx, m)
+		}
+
+		if err == nil {
+			err = s.push(ctx, repo, m, target, details, metadata)
+		}
+
+		if err == nil {
+			err = s.pushLFS(ctx, repo, m, target, details, sourceLFS, lfsObjects)
+		}
+
+		if err == nil {
+			err = s.pushReleases(ctx, repo, m, target, details, sourceReleases)
+		}
+
+		if finishErr := s.finish(h, details, err); finishErr != nil {
+			errs = append(errs, finishErr)
+		}
+
+		if err != nil {
+			closeProvider(target)
+			errs = append(errs, fmt.Errorf("mirror %d: %w", m.ID, err))
+
+			continue
+		}
+
+		pushed = true
+
+		if m.MirrorSubmodules {
+			withSubmodules = append(withSubmodules, m)
+		}
+
+		// Whether the source has a wiki is refreshed along with its metadata, so that repositories
+		// added by URL, which do not know yet, have their wiki mirrored too.
+		if _, err := metadata(); err != nil {
+			errs = append(errs, fmt.Errorf("mirror %d wiki: %w", m.ID, err))
+		} else if repo.HasWiki && slices.Contains(target.Capabilities(), provider.CapabilityWiki) {
+			if err := s.pushWiki(ctx, m, target, cloneWiki); err != nil {
+				errs = append(errs, fmt.Errorf("mirror %d wiki: %w", m.ID, err))
+			}
+		}
+
+		closeProvider(target)
+	}
+
+	if pushed {
+		// Also persists LocalPath and any metadata refreshed from the source.
+		now := time.Now().UTC()
+		repo.LastSyncedAt = &now
+
+		if err := s.repoStore.Update(repo); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, m := range withSubmodules {
+		if err := s.syncSubmodules(ctx, repo, m, submoduleURLs, visited); err != nil {
+			errs = append(errs, fmt.Errorf("mirror %d submodules: %w", m.ID, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("SyncService.Sync(%d): %w", repositoryID, err)
+	}
+
+	return nil
+}
+
+// Run syncs the repositories taken from queue until ctx ends, and every refresh records the
+// status of native mirrors. Failures are logged and transient ones retried; requests taken while
+// the vault is locked fail.
+func (s *SyncService) Run(ctx context.Context, queue *SyncQueue, refresh time.Duration) {
+	go func() {
+		ticker := time.NewTicker(refresh)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if s.credentials.IsLocked() {
+					continue
+				}
+
+				if err := s.mirrors.RecordAllStatuses(ctx); err != nil {
+					log.Printf("failed to record native mirror status: %v", err)
+				}
+			}
+		}
+	}()
+
+	for {
+		req, err := queue.Next(ctx)
+		if err != nil {
+			return
+		}
+
+		if err := s.Sync(ctx, req.RepositoryID); err != nil {
+			s.failed(queue, req, err)
+		}
+	}
+}
+
+// failed logs the failed sync of req. A sync that failed with a transient error is queued again
+// after the delay the provider asked for, or an exponential backoff, up to maxSyncRetries times;
+// one with a permanent error is reported as needing attention and not retried.
+func (s *SyncService) failed(queue *SyncQueue, req SyncRequest, err error) {
+	switch {
+	case provider.IsPermanent(err):
+		log.Printf("%s sync of repository %d failed and needs attention: %v", req.Reason, req.RepositoryID, err)
+	case provider.IsRetryable(err) && req.Attempt < maxSyncRetries:
+		delay := provider.RetryDelay(err)
+		if delay <= 0 {
+			delay = syncRetryDelay << req.Attempt
+		}
+
+		log.Printf("%s sync of repository %d failed, retrying in %s: %v", req.Reason, req.RepositoryID, delay, err)
+		queue.Retry(req, delay)
+	default:
+		log.Printf("%s sync of repository %d failed: %v", req.Reason, req.RepositoryID, err)
+	}
+}
+
+// source connects to the provider of repo.
+func (s *SyncService) source(ctx context.Context, repo *models.Repository) (provider.SourceControlProvider, error) {
+	p, err := s.providerStore.GetByID(repo.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.connect(ctx, p)
+}
+
+// clone fetches the source repository into its local mirror clone, setting repo.LocalPath.
+func (s *SyncService) clone(ctx context.Context, repo *models.Repository, source func() (provider.SourceControlProvider, error)) error {
+	scp, err := source()
+	if err != nil {
+		return err
+	}
+
+	return scp.CloneRepo(ctx, repo, filepath.Join(s.dataDir, fmt.Sprintf("%d.git", repo.ID)))
+}
+
+// cloneWiki fetches the wiki of repo into its local mirror clone and returns it.
+func (s *SyncService) cloneWiki(ctx context.Context, repo *models.Repository, source func() (provider.SourceControlProvider, error)) (*models.Repository, error) {
+	scp, err := source()
+	if err != nil {
+		return nil, err
+	}
+
+	wiki := provider.WikiOf(repo)
+
+	if err := scp.CloneRepo(ctx, wiki, filepath.Join(s.dataDir, fmt.Sprintf("%d.wiki.git", repo.ID))); err != nil {
+		return nil, err
+	}
+
+	return wiki, nil
+}
+
+// metadata refreshes the stored metadata of repo, including whether it has a wiki, from its
+// provider, when the provider can report it, and returns the metadata to replicate.
+func (s *SyncService) metadata(ctx context.Context, repo *models.Repository, source func() (provider.SourceControlProvider, error)) (provider.RepoMetadata, error) {
+	scp, err := source()
+	if err != nil {
+		return provider.RepoMetadata{}, err
+	}
+
+	if manager, ok := scp.(provider.RepoManager); ok {
+		current, err := manager.GetRepo(ctx, repo)
+		if err != nil {
+			return provider.RepoMetadata{}, err
+		}
+
+		repo.Description, repo.Homepage, repo.Topics = current.Description, current.Homepage, current.Topics
+		repo.Visibility, repo.DefaultBranch = current.Visibility, current.DefaultBranch
+		repo.HasWiki, repo.WikiCloneURL = current.HasWiki, current.WikiCloneURL
+	}
+
+	return provider.MetadataOf(repo), nil
+}
+
+// sourceLFS returns the LFS server of repo, or nil when its provider does not serve LFS.
+func (s *SyncService) sourceLFS(ctx context.Context, repo *models.Repository, source func() (provider.SourceControlProvider, error)) (*lfs.Endpoint, error) {
+	scp, err := source()
+	if err != nil {
+		return nil, err
+	}
+
+	server, ok := scp.(provider.LFSProvider)
+	if !ok || !slices.Contains(scp.Capabilities(), provider.CapabilityLFS) {
+		return nil, nil
+	}
+
+	return server.LFSEndpoint(repo.CloneURL)
+}
+
+// target connects to the mirror's target provider, authenticating with the mirror's credential
+// when it has one.
+func (s *SyncService) target(ctx context.Context, m *models.Mirror) (provider.SourceControlProvider, error) {
+	target, err := s.providerStore.GetByID(m.TargetProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var cred *models.Credential
+
+	if m.CredentialID != nil {
+		if cred, err = s.credentials.GetByID(*m.CredentialID); err != nil {
+			return nil, err
+		}
+	}
+
+	scp, err := s.connect(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if cred != nil {
+		if err := scp.Authenticate(ctx, cred); err != nil {
+			closeProvider(scp)
+			return nil, err
+		}
+	}
+
+	return scp, nil
+}
+
+// push pushes the local mirror clone of repo to the mirror's target scp. A mirror with a target
+// owner has its target created first and its metadata replicated after the push, once the
+// default branch exists.
+func (s *SyncService) push(
+	ctx context.Context,
+	repo *models.Repository,
+	m *models.Mirror,
+	scp provider.SourceControlProvider,
+	details *SyncDetails,
+	metadata func() (provider.RepoMetadata, error),
+) error {
+	var (
+		err     error
+		manager provider.RepoManager
+		meta    provider.RepoMetadata
+	)
+
+	if m.TargetOwner != "" {
+		var ok bool
+		if manager, ok = scp.(provider.RepoManager); !ok {
+			return fmt.Errorf("provider %s cannot create repositories", scp.GetProviderType())
+		}
+
+		if meta, err = metadata(); err != nil {
+			return err
+		}
+
+		created, isNew, err := manager.EnsureRepo(ctx, m.TargetOwner, path.Base(repo.Name), meta)
+		if err != nil {
+			return err
+		}
+
+		details.Created = isNew
+
+		if created.CloneURL != m.TargetURL {
+			m.TargetURL = created.CloneURL
+			details.Target = provider.RedactURL(m.TargetURL)
+
+			if err := s.mirrorStore.Update(m); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := scp.PushMirror(ctx, repo, m.TargetURL); err != nil {
+		return err
+	}
+
+	if manager != nil {
+		targetRepo := &models.Repository{ProviderID: m.TargetProviderID, Name: repo.Name, CloneURL: m.TargetURL}
+
+		if err := manager.UpdateRepo(ctx, targetRepo, meta); err != nil {
+			return fmt.Errorf("replicate metadata: %w", err)
+		}
+
+		details.MetadataReplicated = true
+	}
+
+	return nil
+}
+
+// pushLFS copies the LFS objects the local mirror clone of repo references from the source's LFS
+// server to the target's, when both providers serve LFS, and reports the transfer in details.
+func (s *SyncService) pushLFS(
+	ctx context.Context,
+	repo *models.Repository,
+	m *models.Mirror,
+	scp provider.SourceControlProvider,
+	details *SyncDetails,
+	sourceLFS func() (*lfs.Endpoint, error),
+	lfsObjects func() ([]lfs.Pointer, error),
+) error {
+	server, ok := scp.(provider.LFSProvider)
+	if !ok || !slices.Contains(scp.Capabilities(), provider.CapabilityLFS) {
+		return nil
+	}
+
+	source, err := sourceLFS()
+	if err != nil || source == nil {
+		return err
+	}
+
+	objects, err := lfsObjects()
+	if err != nil || len(objects) == 0 {
+		return err
+	}
+
+	target, err := server.LFSEndpoint(m.TargetURL)
+	if err != nil {
+		return err
+	}
+
+	stats, err := lfs.Transfer(ctx, repo.LocalPath, objects, *source, *target)
+	details.LFS = &stats
+
+	return err
+}
+
+// pushWiki pushes the local mirror clone of the repository's wiki to the wiki of the mirror's
+// target scp under an entry of its own. A wiki that is enabled but was never written has no
+// repository, and is skipped without an entry.
+func (s *SyncService) pushWiki(
+	ctx context.Context,
+	m *models.Mirror,
+	scp provider.SourceControlProvider,
+	cloneWiki func() (*models.Repository, error),
+) error {
+	wiki, err := cloneWiki()
+	if provider.IsNotFound(err) {
+		return nil
+	}
+
+	h, details, startErr := s.start(m, true)
+	if startErr != nil {
+		return errors.Join(err, startErr)
+	}
+
+	if err == nil {
+		err = scp.PushMirror(ctx, wiki, provider.WikiCloneURL(m.TargetURL))
+	}
+
+	if finishErr := s.finish(h, details, err); finishErr != nil {
+		return errors.Join(err, finishErr)
+	}
+
+	return err
+}
+
+// start records a running sync_history entry for a GitSyncer-driven mirror, or for its wiki, and
+// returns it with the details to fill in while syncing.
+func (s *SyncService) start(m *models.Mirror, wiki bool) (*models.SyncHistory, *SyncDetails, error) {
+	targetURL := m.TargetURL
+	if wiki {
+		targetURL = provider.WikiCloneURL(targetURL)
+	}
+
+	details := &SyncDetails{Mode: m.Mode, Target: provider.RedactURL(targetURL), Wiki: wiki}
+
+	data, err := json.Marshal(details)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h := &models.SyncHistory{
+		RepositoryID: m.RepositoryID,
+		MirrorID:     &m.ID,
+		Status:       models.SyncStatusRunning,
+		StartedAt:    time.Now().UTC(),
+		Details:      string(data),
+	}
+
+	if err := s.historyStore.Create(h); err != nil {
+		return nil, nil, err
+	}
+
+	return h, details, nil
+}
+
+// finish records the outcome syncErr and the final details of a running entry.
+func (s *SyncService) finish(h *models.SyncHistory, details *SyncDetails, syncErr error) error {
+	details.Permanent = provider.IsPermanent(syncErr)
+
+	data, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	h.FinishedAt = &now
+	h.Status = models.SyncStatusSuccess
+	h.Details = string(data)
+
+	if syncErr != nil {
+		h.Status = models.SyncStatusFailed
+		h.ErrorMessage = syncErr.Error()
+	}
+
+	return s.historyStore.Update(h)
+}
+
+// recordFailure records a native mirror that could not be triggered and returns err.
+func (s *SyncService) recordFailure(m *models.Mirror, err error) error {
+	details, jsonErr := json.Marshal(SyncDetails{
+		Mode:      m.Mode,
+		Direction: m.Direction,
+		Target:    provider.RedactURL(m.TargetURL),
+		Permanent: provider.IsPermanent(err),
+	})
+	if jsonErr != nil {
+		return errors.Join(err, jsonErr)
+	}
+
+	now := time.Now().UTC()
+
+	h := &models.SyncHistory{
+		RepositoryID: m.RepositoryID,
+		MirrorID:     &m.ID,
+		Status:       models.SyncStatusFailed,
+		StartedAt:    now,
+		FinishedAt:   &now,
+		ErrorMessage: err.Error(),
+		Details:      string(details),
+	}
+
+	if createErr := s.historyStore.Create(h); createErr != nil {
+		return errors.Join(err, createErr)
+	}
+
+	return err
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+)
+
+// syncSubmodules makes sure the repositories the local mirror clone of repo references as
+// submodules are mirrored to the target provider of m, syncs them, and records where each
+// submodule URL is mirrored. Relative submodule URLs are resolved against the clone URL of repo.
+// Repositories in visited were synced in this run already and are not synced again.
+func (s *SyncService) syncSubmodules(
+	ctx context.Context,
+	repo *models.Repository,
+	m *models.Mirror,
+	submoduleURLs func() ([]string, error),
+	visited map[int64]bool,
+) error {
+	urls, err := submoduleURLs()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, rawURL := range urls {
+		sourceURL := provider.ResolveRelativeURL(repo.CloneURL, rawURL)
+
+		if err := s.syncSubmodule(ctx, repo, m, sourceURL, visited); err != nil {
+			errs = append(errs, fmt.Errorf("submodule %s: %w", provider.RedactURL(sourceURL), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// syncSubmodule mirrors the submodule at sourceURL alongside the mirror m of its superproject
+// parent and records the mapping once the submodule's mirror has a target. A submodule that is
+// not mirrored is recorded unmapped.
+func (s *SyncService) syncSubmodule(
+	ctx context.Context,
+	parent *models.Repository,
+	m *models.Mirror,
+	sourceURL string,
+	visited map[int64]bool,
+) error {
+	sub, err := s.submoduleRepo(ctx, parent, sourceURL)
+	if err != nil {
+		return err
+	}
+
+	if sub == nil {
+		return s.mirrors.mappingStore.Upsert(&models.SubmoduleMapping{MirrorID: m.ID, SourceURL: sourceURL})
+	}
+
+	subMirror, err := s.submoduleMirror(ctx, m, sub)
+	if err != nil {
+		return err
+	}
+
+	var syncErr error
+
+	if !visited[sub.ID] {
+		syncErr = s.sync(ctx, sub.ID, visited)
+
+		// A mirror with a target owner gets its target URL on its first sync.
+		if subMirror, err = s.mirrorStore.GetByID(subMirror.ID); err != nil {
+			return errors.Join(syncErr, err)
+		}
+	}
+
+	if subMirror.TargetURL == "" {
+		return errors.Join(syncErr, fmt.Errorf("mirror %d has no target yet", subMirror.ID))
+	}
+
+	mapping := &models.SubmoduleMapping{
+		MirrorID:          m.ID,
+		SubmoduleMirrorID: &subMirror.ID,
+		SourceURL:         sourceURL,
+		TargetURL:         subMirror.TargetURL,
+	}
+
+	return errors.Join(syncErr, s.mirrors.mappingStore.Upsert(mapping))
+}
+
+// submoduleRepo returns the repository cloned from sourceURL. One that is not tracked yet is
+// registered under the first provider record on its host that serves its URL, and so gets that
+// record's credentials, trying the provider of the superproject parent first, but only when it lives on the parent's host: a submodule elsewhere,
+// or a local path of a parent that is not local itself, is left alone and nil returned.
+func (s *SyncService) submoduleRepo(ctx context.Context, parent *models.Repository, sourceURL string) (*models.Repository, error) {
+	existing, err := s.repoStore.FindByCloneURL(sourceURL)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return existing, err
+	}
+
+	parentProvider, err := s.providerStore.GetByID(parent.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := provider.ParseGitURL(sourceURL)
+	if err != nil || sub.Host != provider.ExtractHost(parent.CloneURL) {
+		return nil, nil
+	}
+
+	if sub.Transport == provider.TransportFile && parentProvider.Type != string(provider.ProviderLocal) {
+		return nil, nil
+	}
+
+	providers, err := s.providerStore.FindByURL(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(providers, func(a, b models.Provider) int {
+		switch {
+		case a.ID == parent.ProviderID:
+			return -1
+		case b.ID == parent.ProviderID:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	for i := range providers {
+		scp, err := s.connect(ctx, &providers[i])
+		if err != nil {
+			continue
+		}
+
+		serves := scp.ValidateURL(sourceURL)
+		closeProvider(scp)
+
+		if !serves {
+			continue
+		}
+
+		sub := &models.Repository{ProviderID: providers[i].ID, Name: repoName(sourceURL), CloneURL: sourceURL}
+
+		if err := s.repoStore.Create(sub); err != nil {
+			return nil, err
+		}
+
+		return sub, nil
+	}
+
+	return nil, nil
+}
+
+// submoduleMirror returns the GitSyncer-driven mirror of sub to the target provider of parent.
+// When there is none, one is created like parent: under its target owner, or else next to its
+// target URL, named after sub.
+func (s *SyncService) submoduleMirror(ctx context.Context, parent *models.Mirror, sub *models.Repository) (*models.Mirror, error) {
+	mirrors, err := s.mirrorStore.ListByRepository(sub.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range mirrors {
+		if mirrors[i].Mode == models.MirrorModeGitSyncer && mirrors[i].TargetProviderID == parent.TargetProviderID {
+			return &mirrors[i], nil
+		}
+	}
+
+	m := &models.Mirror{
+		RepositoryID:     sub.ID,
+		Mode:             models.MirrorModeGitSyncer,
+		TargetProviderID: parent.TargetProviderID,
+		TargetOwner:      parent.TargetOwner,
+		CredentialID:     parent.CredentialID,
+		MirrorSubmodules: true,
+	}
+
+	if m.TargetOwner == "" {
+		name := path.Base(sub.Name)
+		if strings.HasSuffix(parent.TargetURL, ".git") {
+			name += ".git"
+		}
+
+		m.TargetURL = provider.ResolveRelativeURL(parent.TargetURL, "../"+name)
+	}
+
+	if err := s.mirrors.Create(ctx, m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// repoName names a repository registered from its clone URL: by its path on a hosted service, by
+// its directory name on disk.
+func repoName(cloneURL string) string {
+	g, err := provider.ParseGitURL(cloneURL)
+	if err != nil {
+		return cloneURL
+	}
+
+	if g.Transport == provider.TransportFile {
+		return g.Name
+	}
+
+	return g.Path
+}
@@ -0,0 +1,453 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/store"
+	"GitSyncer/core/webhook"
+)
+
+const (
+	settingWebhookPublicURL = "webhook_public_url"
+
+	// webhookSecretBytes is the size of a generated per-repository secret before hex encoding.
+	webhookSecretBytes = 32
+)
+
+// ErrNoPublicURL is returned when registering a webhook before the receiver's public URL is set.
+var ErrNoPublicURL = errors.New("webhook public URL is not configured")
+
+// WebhookReconcileResult summarises a reconciliation of registered webhooks.
+type WebhookReconcileResult struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Deleted   int `json:"deleted"`
+	Unchanged int `json:"unchanged"`
+
+	// Errors holds one message per repository or registration that could not be reconciled.
+	Errors []string `json:"errors"`
+}
+
+// Outcomes of ensuring a single webhook.
+const (
+	hookCreated   = "created"
+	hookUpdated   = "updated"
+	hookUnchanged = "unchanged"
+)
+
+// WebhookRegistrationService creates, repairs and removes webhooks on source repositories so
+// that pushes reach the receiver. Each hook gets its own generated secret, stored encrypted as a
+// credential of type models.AuthTypeWebhookSecret, so every operation needs an unlocked vault.
+// Only providers the receiver understands and whose provider advertises
+// provider.CapabilityWebhooks and implements provider.WebhookManager are managed.
+type WebhookRegistrationService struct {
+	providerStore     *store.ProviderStore
+	repoStore         *store.RepositoryStore
+	registrationStore *store.WebhookRegistrationStore
+	settingStore      *store.SettingStore
+	credentials       *CredentialService
+	connect           ProviderConnector
+}
+
+// NewWebhookRegistrationService creates a WebhookRegistrationService that reaches providers through connect.
+func NewWebhookRegistrationService(
+	providerStore *store.ProviderStore,
+	repoStore *store.RepositoryStore,
+	registrationStore *store.WebhookRegistrationStore,
+	settingStore *store.SettingStore,
+	credentials *CredentialService,
+	connect ProviderConnector,
+) *WebhookRegistrationService {
+	return &WebhookRegistrationService{
+		providerStore:     providerStore,
+		repoStore:         repoStore,
+		registrationStore: registrationStore,
+		settingStore:      settingStore,
+		credentials:       credentials,
+		connect:           connect,
+	}
+}
+
+// PublicURL returns the base URL providers deliver webhooks to, or "" when it is not configured.
+func (s *WebhookRegistrationService) PublicURL() (string, error) {
+	u, err := s.settingStore.Get(settingWebhookPublicURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+
+	return u, err
+}
+
+// SetPublicURL stores the base URL the receiver is reachable at from the providers, e.g.
+// https://sync.example.com. Existing hooks pick it up on the next reconciliation.
+func (s *WebhookRegistrationService) SetPublicURL(rawURL string) error {
+	rawURL = strings.TrimSuffix(strings.TrimSpace(rawURL), "/")
+
+	if rawURL == "" {
+		return s.settingStore.Delete(settingWebhookPublicURL)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("WebhookRegistrationService.SetPublicURL: %w", err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("WebhookRegistrationService.SetPublicURL: %q is not an absolute http(s) URL", rawURL)
+	}
+
+	return s.settingStore.Set(settingWebhookPublicURL, rawURL)
+}
+
+// List returns every webhook registration, including those awaiting removal.
+func (s *WebhookRegistrationService) List() ([]models.WebhookRegistration, error) {
+	return s.registrationStore.List()
+}
+
+// Register makes sure the repository has an active webhook pointing at the receiver, creating
+// it with a new secret if needed. It returns nil without error when the repository's provider
+// cannot manage webhooks.
+func (s *WebhookRegistrationService) Register(ctx context.Context, repositoryID int64) (*models.WebhookRegistration, error) {
+	publicURL, err := s.PublicURL()
+	if err != nil {
+		return nil, err
+	}
+
+	if publicURL == "" {
+		return nil, ErrNoPublicURL
+	}
+
+	repo, err := s.repoStore.GetByID(repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := s.manager(ctx, repo.ProviderID)
+	if err != nil || m == nil {
+		return nil, err
+	}
+	defer closeProvider(m)
+
+	reg, err := s.registrationStore.GetByRepositoryID(repositoryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		reg = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	_, reg, err = s.ensure(ctx, m, repo, reg, webhook.ReceiverURL(publicURL, repo.ProviderID))
+	if err != nil {
+		return nil, fmt.Errorf("WebhookRegistrationService.Register(%d): %w", repositoryID, err)
+	}
+
+	return reg, nil
+}
+
+// Unregister deletes the repository's webhook from the provider along with its secret. It is a
+// no-op for repositories without a registration.
+func (s *WebhookRegistrationService) Unregister(ctx context.Context, repositoryID int64) error {
+	reg, err := s.registrationStore.GetByRepositoryID(repositoryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	m, err := s.manager(ctx, reg.ProviderID)
+	if err != nil {
+		return fmt.Errorf("WebhookRegistrationService.Unregister(%d): %w", repositoryID, err)
+	}
+	defer closeProvider(m)
+
+	if err := s.remove(ctx, m, reg); err != nil {
+		return fmt.Errorf("WebhookRegistrationService.Unregister(%d): %w", repositoryID, err)
+	}
+
+	return nil
+}
+
+// Reconcile removes the hooks of repositories that are no longer tracked, then registers or
+// repairs a hook for every tracked repository: missing hooks are recreated and hooks with a
+// stale URL or that the provider disabled are updated. Without a public URL only removals are
+// reconciled. Failures for single repositories are collected in the result.
+func (s *WebhookRegistrationService) Reconcile(ctx context.Context) (*WebhookReconcileResult, error) {
+	if s.credentials.IsLocked() {
+		return nil, ErrLocked
+	}
+
+	registrations, err := s.registrationStore.List()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WebhookReconcileResult{}
+	managers := make(map[int64]managerResult)
+	defer closeManagers(managers)
+	byRepo := make(map[int64]*models.WebhookRegistration)
+
+	for i := range registrations {
+		reg := &registrations[i]
+
+		if reg.RepositoryID != nil {
+			byRepo[*reg.RepositoryID] = reg
+			continue
+		}
+
+		m, err := s.cachedManager(ctx, managers, reg.ProviderID)
+		if err == nil {
+			err = s.remove(ctx, m, reg)
+		}
+
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", reg.CloneURL, err))
+			continue
+		}
+
+		result.Deleted++
+	}
+
+	publicURL, err := s.PublicURL()
+	if err != nil || publicURL == "" {
+		return result, err
+	}
+
+	repos, err := s.repoStore.List()
+	if err != nil {
+		return result, err
+	}
+
+	for i := range repos {
+		repo := &repos[i]
+
+		m, err := s.cachedManager(ctx, managers, repo.ProviderID)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", repo.Name, err))
+			continue
+		}
+
+		if m == nil {
+			continue
+		}
+
+		outcome, _, err := s.ensure(ctx, m, repo, byRepo[repo.ID], webhook.ReceiverURL(publicURL, repo.ProviderID))
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", repo.Name, err))
+			continue
+		}
+
+		switch outcome {
+		case hookCreated:
+			result.Created++
+		case hookUpdated:
+			result.Updated++
+		default:
+			result.Unchanged++
+		}
+	}
+
+	return result, nil
+}
+
+// ensure brings the hook of repo in line with hookURL, creating reg when it is nil, and reports
+// what it had to do.
+func (s *WebhookRegistrationService) ensure(
+	ctx context.Context,
+	m provider.WebhookManager,
+	repo *models.Repository,
+	reg *models.WebhookRegistration,
+	hookURL string,
+) (string, *models.WebhookRegistration, error) {
+	if reg == nil {
+		reg = &models.WebhookRegistration{ProviderID: repo.ProviderID, RepositoryID: &repo.ID}
+	}
+
+	secret, generated, err := s.secret(reg, repo)
+	if err != nil {
+		return "", nil, err
+	}
+
+	outcome, err := s.ensureHook(ctx, m, repo, reg, provider.WebhookConfig{URL: hookURL, Secret: secret}, generated)
+	if err == nil {
+		reg.CloneURL, reg.URL = repo.CloneURL, hookURL
+
+		if reg.ID == 0 {
+			err = s.registrationStore.Create(reg)
+		} else if outcome != hookUnchanged {
+			err = s.registrationStore.Update(reg)
+		}
+	}
+
+	if err != nil {
+		if generated != nil {
+			// Do not leave behind a secret no hook uses.
+			_ = s.credentials.Delete(*generated)
+		}
+
+		return "", nil, err
+	}
+
+	return outcome, reg, nil
+}
+
+// ensureHook creates the hook when the provider no longer has it and updates it when its URL
+// or secret changed or the provider disabled it. On success reg.HookID names the hook.
+func (s *WebhookRegistrationService) ensureHook(
+	ctx context.Context,
+	m provider.WebhookManager,
+	repo *models.Repository,
+	reg *models.WebhookRegistration,
+	cfg provider.WebhookConfig,
+	newSecret *int64,
+) (string, error) {
+	var existing *provider.Webhook
+
+	if reg.HookID != "" {
+		hooks, err := m.ListWebhooks(ctx, repo)
+		if err != nil {
+			return "", err
+		}
+
+		if i := slices.IndexFunc(hooks, func(h provider.Webhook) bool { return h.ID == reg.HookID }); i >= 0 {
+			existing = &hooks[i]
+		}
+	}
+
+	switch {
+	case existing == nil:
+		hook, err := m.CreateWebhook(ctx, repo, cfg)
+		if err != nil {
+			return "", err
+		}
+
+		reg.HookID = hook.ID
+
+		return hookCreated, nil
+	case existing.URL != cfg.URL || !existing.Active || newSecret != nil || reg.CloneURL != repo.CloneURL:
+		if _, err := m.UpdateWebhook(ctx, repo, existing.ID, cfg); err != nil {
+			return "", err
+		}
+
+		return hookUpdated, nil
+	}
+
+	return hookUnchanged, nil
+}
+
+// secret returns the registration's secret, generating and storing a new one when it has none
+// or its credential was deleted. generated is the ID of a newly stored credential.
+func (s *WebhookRegistrationService) secret(reg *models.WebhookRegistration, repo *models.Repository) (secret string, generated *int64, err error) {
+	if reg.CredentialID != nil {
+		cred, err := s.credentials.GetByID(*reg.CredentialID)
+		if err == nil {
+			return cred.AuthData, nil, nil
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			return "", nil, err
+		}
+	}
+
+	buf := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, fmt.Errorf("generate webhook secret: %w", err)
+	}
+
+	secret = hex.EncodeToString(buf)
+
+	cred := &models.Credential{
+		ProviderID: repo.ProviderID,
+		Label:      "webhook: " + repo.Name,
+		AuthType:   models.AuthTypeWebhookSecret,
+		AuthData:   secret,
+	}
+
+	if err := s.credentials.Store(cred); err != nil {
+		return "", nil, err
+	}
+
+	reg.CredentialID = &cred.ID
+
+	return secret, &cred.ID, nil
+}
+
+// remove deletes the registration's hook, if m can still manage it, then its secret and record.
+// A hook already deleted on the provider is not an error.
+func (s *WebhookRegistrationService) remove(ctx context.Context, m provider.WebhookManager, reg *models.WebhookRegistration) error {
+	if m != nil && reg.HookID != "" {
+		repo := &models.Repository{ProviderID: reg.ProviderID, Name: reg.CloneURL, CloneURL: reg.CloneURL}
+
+		if err := m.DeleteWebhook(ctx, repo, reg.HookID); err != nil && !provider.IsNotFound(err) {
+			return err
+		}
+	}
+
+	if reg.CredentialID != nil {
+		if err := s.credentials.Delete(*reg.CredentialID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	}
+
+	return s.registrationStore.Delete(reg.ID)
+}
+
+type managerResult struct {
+	manager provider.WebhookManager
+	err     error
+}
+
+// cachedManager is manager memoised in cache, so a reconciliation connects to each provider once.
+func (s *WebhookRegistrationService) cachedManager(ctx context.Context, cache map[int64]managerResult, providerID int64) (provider.WebhookManager, error) {
+	r, ok := cache[providerID]
+	if !ok {
+		r.manager, r.err = s.manager(ctx, providerID)
+		cache[providerID] = r
+	}
+
+	return r.manager, r.err
+}
+
+// closeManagers releases the providers connected by cachedManager.
+func closeManagers(cache map[int64]managerResult) {
+	for _, r := range cache {
+		closeProvider(r.manager)
+	}
+}
+
+// manager connects to the provider record providerID and returns it as a WebhookManager, or nil
+// if its hooks cannot be managed or would not be understood by the receiver. Callers release a
+// returned manager with closeProvider.
+func (s *WebhookRegistrationService) manager(ctx context.Context, providerID int64) (provider.WebhookManager, error) {
+	p, err := s.providerStore.GetByID(providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !webhook.Supported(provider.ProviderType(p.Type)) {
+		return nil, nil
+	}
+
+	scp, err := s.connect(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := scp.(provider.WebhookManager)
+	if !ok || !slices.Contains(scp.Capabilities(), provider.CapabilityWebhooks) {
+		closeProvider(scp)
+		return nil, nil
+	}
+
+	return m, nil
+}
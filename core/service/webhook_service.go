@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/store"
+	"GitSyncer/core/webhook"
+)
+
+const (
+	settingWebhookListenAddr = "webhook_listen_addr"
+
+	// webhookRetention is how long deliveries are kept for inspection and replay.
+	webhookRetention = 30 * 24 * time.Hour
+
+	// Deliveries that did not verify may come from anyone, so only the most recent
+	// maxUnverifiedDeliveries are kept, and their payload only up to maxUnverifiedPayload bytes.
+	maxUnverifiedDeliveries = 200
+	maxUnverifiedPayload    = 16 << 10
+)
+
+// errPayloadNotKept is returned when replaying an unverified delivery whose payload was too large to keep.
+var errPayloadNotKept = errors.New("the payload of this unverified delivery was not kept")
+
+var _ webhook.Handler = (*WebhookService)(nil)
+
+// WebhookService verifies and records webhook deliveries and enqueues a sync for the repository
+// each push concerns. Webhook secrets are credentials of type models.AuthTypeWebhookSecret on
+// the provider, so deliveries fail with a retryable status while the vault is locked. Secrets of
+// hooks registered by WebhookRegistrationService are accepted only for their own repository.
+type WebhookService struct {
+	providerStore     *store.ProviderStore
+	repoStore         *store.RepositoryStore
+	deliveryStore     *store.WebhookDeliveryStore
+	registrationStore *store.WebhookRegistrationStore
+	settingStore      *store.SettingStore
+	credentials       *CredentialService
+	queue             *SyncQueue
+}
+
+// NewWebhookService creates a WebhookService that enqueues syncs on queue.
+func NewWebhookService(
+	providerStore *store.ProviderStore,
+	repoStore *store.RepositoryStore,
+	deliveryStore *store.WebhookDeliveryStore,
+	registrationStore *store.WebhookRegistrationStore,
+	settingStore *store.SettingStore,
+	credentials *CredentialService,
+	queue *SyncQueue,
+) *WebhookService {
+	return &WebhookService{
+		providerStore:     providerStore,
+		repoStore:         repoStore,
+		deliveryStore:     deliveryStore,
+		registrationStore: registrationStore,
+		settingStore:      settingStore,
+		credentials:       credentials,
+		queue:             queue,
+	}
+}
+
+// HandleWebhook verifies, processes and records a delivery for the provider record providerID.
+// A delivery that was rejected or could not be verified is recorded without a payload larger
+// than maxUnverifiedPayload, and evicts the oldest such deliveries beyond maxUnverifiedDeliveries.
+func (s *WebhookService) HandleWebhook(ctx context.Context, providerID int64, header http.Header, body []byte) (*models.WebhookDelivery, error) {
+	pt, err := s.providerType(providerID)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookService.HandleWebhook: %w", err)
+	}
+
+	name, deliveryID := webhook.EventName(pt, header)
+
+	headers, err := json.Marshal(webhook.RecordedHeaders(header))
+	if err != nil {
+		return nil, fmt.Errorf("WebhookService.HandleWebhook: encode headers: %w", err)
+	}
+
+	d := &models.WebhookDelivery{
+		ProviderID: providerID,
+		DeliveryID: deliveryID,
+		Event:      name,
+		Headers:    string(headers),
+		Payload:    string(body),
+	}
+
+	s.process(d, pt, header, body)
+
+	if !d.Verified && len(body) > maxUnverifiedPayload {
+		d.Payload = ""
+	}
+
+	if err := s.deliveryStore.Create(d); err != nil {
+		return nil, err
+	}
+
+	if !d.Verified {
+		if _, err := s.deliveryStore.DeleteUnverifiedBeyond(maxUnverifiedDeliveries); err != nil {
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// Replay processes a recorded delivery again and stores the new outcome. Deliveries that were
+// never verified are verified again first; GitLab ones cannot be, as their token is not recorded.
+func (s *WebhookService) Replay(id int64) (*models.WebhookDelivery, error) {
+	d, err := s.deliveryStore.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	pt, err := s.providerType(d.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookService.Replay(%d): %w", id, err)
+	}
+
+	if !d.Verified && d.Payload == "" {
+		return nil, fmt.Errorf("WebhookService.Replay(%d): %w", id, errPayloadNotKept)
+	}
+
+	var header http.Header
+	if err := json.Unmarshal([]byte(d.Headers), &header); err != nil {
+		return nil, fmt.Errorf("WebhookService.Replay(%d): decode headers: %w", id, err)
+	}
+
+	d.RepositoryID = nil
+	d.ErrorMessage = ""
+
+	s.process(d, pt, header, []byte(d.Payload))
+
+	if err := s.deliveryStore.Update(d); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// ListDeliveries returns up to limit deliveries, most recent first.
+func (s *WebhookService) ListDeliveries(limit int) ([]models.WebhookDelivery, error) {
+	return s.deliveryStore.List(limit)
+}
+
+// GetDelivery returns a recorded delivery by ID.
+func (s *WebhookService) GetDelivery(id int64) (*models.WebhookDelivery, error) {
+	return s.deliveryStore.GetByID(id)
+}
+
+// Prune removes deliveries older than the retention period.
+func (s *WebhookService) Prune() error {
+	_, err := s.deliveryStore.DeleteReceivedBefore(time.Now().Add(-webhookRetention))
+	return err
+}
+
+// ListenAddress returns the configured listener address, or "" when the receiver is disabled.
+func (s *WebhookService) ListenAddress() (string, error) {
+	addr, err := s.settingStore.Get(settingWebhookListenAddr)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+
+	return addr, err
+}
+
+// SetListenAddress stores the listener address, e.g. ":8765". An empty addr disables the receiver.
+func (s *WebhookService) SetListenAddress(addr string) error {
+	addr = strings.TrimSpace(addr)
+
+	if addr == "" {
+		return s.settingStore.Delete(settingWebhookListenAddr)
+	}
+
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return fmt.Errorf("WebhookService.SetListenAddress: %w", err)
+	}
+
+	return s.settingStore.Set(settingWebhookListenAddr, addr)
+}
+
+func (s *WebhookService) providerType(providerID int64) (provider.ProviderType, error) {
+	p, err := s.providerStore.GetByID(providerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("provider %d: %w", providerID, webhook.ErrUnknownProvider)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	pt := provider.ProviderType(p.Type)
+	if !webhook.Supported(pt) {
+		return "", fmt.Errorf("provider %d (%s): %w", providerID, pt, webhook.ErrUnsupportedProvider)
+	}
+
+	return pt, nil
+}
+
+// process verifies d if needed, then matches it to a repository and enqueues a sync, recording
+// the outcome in d. The payload is parsed before verification because the repository it names
+// decides which registered hook secret applies; nothing in it is acted on until it verifies.
+func (s *WebhookService) process(d *models.WebhookDelivery, pt provider.ProviderType, header http.Header, body []byte) {
+	now := time.Now().UTC()
+	d.ProcessedAt = &now
+
+	ev, parseErr := webhook.Parse(pt, header, body)
+
+	var repo *models.Repository
+
+	if parseErr == nil {
+		var err error
+
+		if repo, err = s.findRepository(ev.CloneURLs); err != nil {
+			d.Status, d.ErrorMessage = models.WebhookStatusFailed, err.Error()
+			return
+		}
+	}
+
+	if !d.Verified {
+		secrets, err := s.secrets(d.ProviderID, repo)
+		if err != nil {
+			d.Status, d.ErrorMessage = models.WebhookStatusFailed, err.Error()
+			return
+		}
+
+		if err := webhook.Verify(pt, header, body, secrets); err != nil {
+			d.Status, d.ErrorMessage = models.WebhookStatusRejected, err.Error()
+			return
+		}
+
+		d.Verified = true
+	}
+
+	if parseErr != nil {
+		d.Status, d.ErrorMessage = models.WebhookStatusFailed, parseErr.Error()
+		return
+	}
+
+	if ev.Kind != webhook.EventPush {
+		d.Status = models.WebhookStatusIgnored
+		return
+	}
+
+	if repo == nil {
+		d.Status = models.WebhookStatusUnmatched
+		d.ErrorMessage = "no tracked repository matches " + strings.Join(ev.CloneURLs, ", ")
+
+		return
+	}
+
+	d.RepositoryID = &repo.ID
+	d.Status = models.WebhookStatusQueued
+	s.queue.Enqueue(repo.ID, SyncReasonWebhook)
+}
+
+// secrets returns the webhook secrets a delivery for the provider may be signed with: the
+// provider-wide secrets and, when repo is not nil, the secret of the hook registered for it.
+func (s *WebhookService) secrets(providerID int64, repo *models.Repository) ([]string, error) {
+	creds, err := s.credentials.GetByProviderID(providerID)
+	if err != nil {
+		return nil, err
+	}
+
+	registrations, err := s.registrationStore.List()
+	if err != nil {
+		return nil, err
+	}
+
+	// Registered hook secrets, mapped to whether they belong to repo.
+	hookSecrets := make(map[int64]bool)
+
+	for _, r := range registrations {
+		if r.CredentialID != nil {
+			hookSecrets[*r.CredentialID] = repo != nil && r.RepositoryID != nil && *r.RepositoryID == repo.ID
+		}
+	}
+
+	var secrets []string
+
+	for _, c := range creds {
+		if c.AuthType != models.AuthTypeWebhookSecret {
+			continue
+		}
+
+		if own, registered := hookSecrets[c.ID]; registered && !own {
+			continue
+		}
+
+		secrets = append(secrets, c.AuthData)
+	}
+
+	return secrets, nil
+}
+
+// findRepository returns the tracked repository any of urls addresses, or nil.
+func (s *WebhookService) findRepository(urls []string) (*models.Repository, error) {
+	for _, u := range urls {
+		if provider.CanonicalURL(u) == "" {
+			continue
+		}
+
+		repo, err := s.repoStore.FindByCloneURL(u)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return repo, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+
+	"GitSyncer/core/models"
+)
+
+type DiscussionExportStore struct {
+	db *sql.DB
+}
+
+func NewDiscussionExportStore(db *sql.DB) *DiscussionExportStore {
+	return &DiscussionExportStore{db: db}
+}
+
+const discussionExportColumns = `id, repository_id, storage_provider_id, path, since, exported_at, issues, comments`
+
+func (s *DiscussionExportStore) Create(e *models.DiscussionExport) error {
+	result, err := s.db.Exec(
+		`INSERT INTO discussion_exports (repository_id, storage_provider_id, path, since, exported_at, issues, comments)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		e.RepositoryID, e.StorageProviderID, e.Path, e.Since, e.ExportedAt, e.Issues, e.Comments,
+	)
+	if err != nil {
+		return fmt.Errorf("DiscussionExportStore.Create: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("DiscussionExportStore.Create: last insert id: %w", err)
+	}
+
+	e.ID = id
+
+	return nil
+}
+
+// ListByRepository returns the exports of a repository, most recent first.
+func (s *DiscussionExportStore) ListByRepository(repositoryID int64) ([]models.DiscussionExport, error) {
+	rows, err := s.db.Query(
+		`SELECT `+discussionExportColumns+` FROM discussion_exports WHERE repository_id = ? ORDER BY exported_at DESC, id DESC`,
+		repositoryID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("DiscussionExportStore.ListByRepository(%d): %w", repositoryID, err)
+	}
+	defer rows.Close()
+
+	var exports []models.DiscussionExport
+
+	for rows.Next() {
+		e, err := scanDiscussionExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("DiscussionExportStore.ListByRepository(%d): scan: %w", repositoryID, err)
+		}
+
+		exports = append(exports, *e)
+	}
+
+	return exports, rows.Err()
+}
+
+// Latest returns the most recent export of a repository to a storage provider, or
+// sql.ErrNoRows when there is none.
+func (s *DiscussionExportStore) Latest(repositoryID, storageProviderID int64) (*models.DiscussionExport, error) {
+	e, err := scanDiscussionExport(s.db.QueryRow(
+		`SELECT `+discussionExportColumns+` FROM discussion_exports WHERE repository_id = ? AND storage_provider_id = ?
+		 ORDER BY exported_at DESC, id DESC LIMIT 1`,
+		repositoryID, storageProviderID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("DiscussionExportStore.Latest(%d, %d): %w", repositoryID, storageProviderID, err)
+	}
+
+	return e, nil
+}
+
+func scanDiscussionExport(row rowScanner) (*models.DiscussionExport, error) {
+	e := &models.DiscussionExport{}
+
+	var since sql.NullTime
+
+	err := row.Scan(&e.ID, &e.RepositoryID, &e.StorageProviderID, &e.Path, &since, &e.ExportedAt, &e.Issues, &e.Comments)
+	if err != nil {
+		return nil, err
+	}
+
+	if since.Valid {
+		e.Since = &since.Time
+	}
+
+	return e, nil
+}
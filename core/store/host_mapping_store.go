@@ -0,0 +1,124 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"GitSyncer/core/models"
+)
+
+type HostMappingStore struct {
+	db *sql.DB
+}
+
+func NewHostMappingStore(db *sql.DB) *HostMappingStore {
+	return &HostMappingStore{db: db}
+}
+
+func (s *HostMappingStore) Create(m *models.HostMapping) error {
+	now := time.Now().UTC()
+
+	result, err := s.db.Exec(
+		`INSERT INTO host_mappings (pattern, provider_type, api_url, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?)`,
+		m.Pattern, m.ProviderType, m.APIURL, now, now,
+	)
+	if err != nil {
+		return fmt.Errorf("HostMappingStore.Create: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("HostMappingStore.Create: last insert id: %w", err)
+	}
+
+	m.ID = id
+	m.CreatedAt = now
+	m.UpdatedAt = now
+
+	return nil
+}
+
+func (s *HostMappingStore) GetByID(id int64) (*models.HostMapping, error) {
+	m := &models.HostMapping{}
+
+	err := s.db.QueryRow(
+		`SELECT id, pattern, provider_type, api_url, created_at, updated_at
+		 FROM host_mappings WHERE id = ?`, id,
+	).Scan(&m.ID, &m.Pattern, &m.ProviderType, &m.APIURL, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("HostMappingStore.GetByID(%d): %w", id, err)
+	}
+
+	return m, nil
+}
+
+func (s *HostMappingStore) List() ([]models.HostMapping, error) {
+	rows, err := s.db.Query(
+		`SELECT id, pattern, provider_type, api_url, created_at, updated_at
+		 FROM host_mappings ORDER BY id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("HostMappingStore.List: %w", err)
+	}
+	defer rows.Close()
+
+	var mappings []models.HostMapping
+
+	for rows.Next() {
+		var m models.HostMapping
+
+		if err := rows.Scan(&m.ID, &m.Pattern, &m.ProviderType, &m.APIURL, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("HostMappingStore.List: scan: %w", err)
+		}
+
+		mappings = append(mappings, m)
+	}
+
+	return mappings, rows.Err()
+}
+
+func (s *HostMappingStore) Update(m *models.HostMapping) error {
+	now := time.Now().UTC()
+
+	result, err := s.db.Exec(
+		`UPDATE host_mappings SET pattern = ?, provider_type = ?, api_url = ?, updated_at = ?
+		 WHERE id = ?`,
+		m.Pattern, m.ProviderType, m.APIURL, now, m.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("HostMappingStore.Update(%d): %w", m.ID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("HostMappingStore.Update(%d): rows affected: %w", m.ID, err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("HostMappingStore.Update(%d): %w", m.ID, sql.ErrNoRows)
+	}
+
+	m.UpdatedAt = now
+
+	return nil
+}
+
+func (s *HostMappingStore) Delete(id int64) error {
+	result, err := s.db.Exec(`DELETE FROM host_mappings WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("HostMappingStore.Delete(%d): %w", id, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("HostMappingStore.Delete(%d): rows affected: %w", id, err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("HostMappingStore.Delete(%d): %w", id, sql.ErrNoRows)
+	}
+
+	return nil
+}
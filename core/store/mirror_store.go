@@ -0,0 +1,155 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"GitSyncer/core/models"
+)
+
+type MirrorStore struct {
+	db *sql.DB
+}
+
+func NewMirrorStore(db *sql.DB) *MirrorStore {
+	return &MirrorStore{db: db}
+}
+
+const mirrorColumns = `id, repository_id, mode, direction, target_provider_id, target_url, target_owner, credential_id, interval_seconds, only_protected_branches, mirror_submodules, native_id, created_at, updated_at`
+
+func (s *MirrorStore) Create(m *models.Mirror) error {
+	now := time.Now().UTC()
+
+	result, err := s.db.Exec(
+		`INSERT INTO mirrors (repository_id, mode, direction, target_provider_id, target_url, target_owner, credential_id, interval_seconds, only_protected_branches, mirror_submodules, native_id, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		m.RepositoryID, m.Mode, m.Direction, m.TargetProviderID, m.TargetURL, m.TargetOwner, m.CredentialID, m.IntervalSeconds, m.OnlyProtectedBranches, m.MirrorSubmodules, m.NativeID, now, now,
+	)
+	if err != nil {
+		return fmt.Errorf("MirrorStore.Create: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("MirrorStore.Create: last insert id: %w", err)
+	}
+
+	m.ID = id
+	m.CreatedAt = now
+	m.UpdatedAt = now
+
+	return nil
+}
+
+func (s *MirrorStore) GetByID(id int64) (*models.Mirror, error) {
+	m, err := scanMirror(s.db.QueryRow(`SELECT `+mirrorColumns+` FROM mirrors WHERE id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("MirrorStore.GetByID(%d): %w", id, err)
+	}
+
+	return m, nil
+}
+
+func (s *MirrorStore) List() ([]models.Mirror, error) {
+	mirrors, err := s.query(`SELECT ` + mirrorColumns + ` FROM mirrors ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("MirrorStore.List: %w", err)
+	}
+
+	return mirrors, nil
+}
+
+func (s *MirrorStore) ListByRepository(repositoryID int64) ([]models.Mirror, error) {
+	mirrors, err := s.query(`SELECT `+mirrorColumns+` FROM mirrors WHERE repository_id = ? ORDER BY id`, repositoryID)
+	if err != nil {
+		return nil, fmt.Errorf("MirrorStore.ListByRepository(%d): %w", repositoryID, err)
+	}
+
+	return mirrors, nil
+}
+
+func (s *MirrorStore) Update(m *models.Mirror) error {
+	now := time.Now().UTC()
+
+	result, err := s.db.Exec(
+		`UPDATE mirrors SET mode = ?, direction = ?, target_provider_id = ?, target_url = ?, target_owner = ?, credential_id = ?, interval_seconds = ?,
+		 only_protected_branches = ?, mirror_submodules = ?, native_id = ?, updated_at = ?
+		 WHERE id = ?`,
+		m.Mode, m.Direction, m.TargetProviderID, m.TargetURL, m.TargetOwner, m.CredentialID, m.IntervalSeconds,
+		m.OnlyProtectedBranches, m.MirrorSubmodules, m.NativeID, now, m.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("MirrorStore.Update(%d): %w", m.ID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("MirrorStore.Update(%d): rows affected: %w", m.ID, err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("MirrorStore.Update(%d): %w", m.ID, sql.ErrNoRows)
+	}
+
+	m.UpdatedAt = now
+
+	return nil
+}
+
+func (s *MirrorStore) Delete(id int64) error {
+	result, err := s.db.Exec(`DELETE FROM mirrors WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("MirrorStore.Delete(%d): %w", id, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("MirrorStore.Delete(%d): rows affected: %w", id, err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("MirrorStore.Delete(%d): %w", id, sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+func (s *MirrorStore) query(query string, args ...any) ([]models.Mirror, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mirrors []models.Mirror
+
+	for rows.Next() {
+		m, err := scanMirror(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan: %w", err)
+		}
+
+		mirrors = append(mirrors, *m)
+	}
+
+	return mirrors, rows.Err()
+}
+
+func scanMirror(row rowScanner) (*models.Mirror, error) {
+	m := &models.Mirror{}
+
+	var credentialID sql.NullInt64
+
+	err := row.Scan(&m.ID, &m.RepositoryID, &m.Mode, &m.Direction, &m.TargetProviderID, &m.TargetURL, &m.TargetOwner, &credentialID,
+		&m.IntervalSeconds, &m.OnlyProtectedBranches, &m.MirrorSubmodules, &m.NativeID, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if credentialID.Valid {
+		m.CredentialID = &credentialID.Int64
+	}
+
+	return m, nil
+}
@@ -2,10 +2,12 @@ package store
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
 )
 
 type ProviderStore struct {
@@ -16,13 +18,20 @@ func NewProviderStore(db *sql.DB) *ProviderStore {
 	return &ProviderStore{db: db}
 }
 
+const providerColumns = `id, name, type, base_url, options, created_at, updated_at`
+
 func (s *ProviderStore) Create(p *models.Provider) error {
 	now := time.Now().UTC()
 
+	options, err := encodeOptions(p.Options)
+	if err != nil {
+		return fmt.Errorf("ProviderStore.Create: %w", err)
+	}
+
 	result, err := s.db.Exec(
-		`INSERT INTO providers (name, type, base_url, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?)`,
-		p.Name, p.Type, p.BaseURL, now, now,
+		`INSERT INTO providers (name, type, base_url, options, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?)`,
+		p.Name, p.Type, p.BaseURL, options, now, now,
 	)
 	if err != nil {
 		return fmt.Errorf("ProviderStore.Create: %w", err)
@@ -41,12 +50,10 @@ func (s *ProviderStore) Create(p *models.Provider) error {
 }
 
 func (s *ProviderStore) GetByID(id int64) (*models.Provider, error) {
-	p := &models.Provider{}
-
-	err := s.db.QueryRow(
-		`SELECT id, name, type, base_url, created_at, updated_at
+	p, err := scanProvider(s.db.QueryRow(
+		`SELECT `+providerColumns+`
 		 FROM providers WHERE id = ?`, id,
-	).Scan(&p.ID, &p.Name, &p.Type, &p.BaseURL, &p.CreatedAt, &p.UpdatedAt)
+	))
 	if err != nil {
 		return nil, fmt.Errorf("ProviderStore.GetByID(%d): %w", id, err)
 	}
@@ -56,7 +63,7 @@ func (s *ProviderStore) GetByID(id int64) (*models.Provider, error) {
 
 func (s *ProviderStore) List() ([]models.Provider, error) {
 	rows, err := s.db.Query(
-		`SELECT id, name, type, base_url, created_at, updated_at
+		`SELECT ` + providerColumns + `
 		 FROM providers ORDER BY id`,
 	)
 	if err != nil {
@@ -67,25 +74,54 @@ func (s *ProviderStore) List() ([]models.Provider, error) {
 	var providers []models.Provider
 
 	for rows.Next() {
-		var p models.Provider
-
-		if err := rows.Scan(&p.ID, &p.Name, &p.Type, &p.BaseURL, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		p, err := scanProvider(rows)
+		if err != nil {
 			return nil, fmt.Errorf("ProviderStore.List: scan: %w", err)
 		}
 
-		providers = append(providers, p)
+		providers = append(providers, *p)
 	}
 
 	return providers, rows.Err()
 }
 
+// FindByURL returns the providers whose base URL is on the same service as rawURL, which is how
+// a repository URL is matched to the providers, and so the credentials, that can access it.
+// Providers without a base URL use their type's public host and match by detected type, as do
+// local providers for local paths. Callers confirm a match with the provider's ValidateURL.
+func (s *ProviderStore) FindByURL(rawURL string) ([]models.Provider, error) {
+	all, err := s.List()
+	if err != nil {
+		return nil, fmt.Errorf("ProviderStore.FindByURL: %w", err)
+	}
+
+	detected := provider.DetectProviderType(rawURL)
+
+	var matched []models.Provider
+
+	for _, p := range all {
+		pt := provider.ProviderType(p.Type)
+
+		if ((p.BaseURL == "" || pt == provider.ProviderLocal) && pt == detected) || provider.SameHost(p.BaseURL, rawURL) {
+			matched = append(matched, p)
+		}
+	}
+
+	return matched, nil
+}
+
 func (s *ProviderStore) Update(p *models.Provider) error {
 	now := time.Now().UTC()
 
+	options, err := encodeOptions(p.Options)
+	if err != nil {
+		return fmt.Errorf("ProviderStore.Update(%d): %w", p.ID, err)
+	}
+
 	result, err := s.db.Exec(
-		`UPDATE providers SET name = ?, type = ?, base_url = ?, updated_at = ?
+		`UPDATE providers SET name = ?, type = ?, base_url = ?, options = ?, updated_at = ?
 		 WHERE id = ?`,
-		p.Name, p.Type, p.BaseURL, now, p.ID,
+		p.Name, p.Type, p.BaseURL, options, now, p.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("ProviderStore.Update(%d): %w", p.ID, err)
@@ -122,3 +158,33 @@ func (s *ProviderStore) Delete(id int64) error {
 
 	return nil
 }
+
+func scanProvider(row rowScanner) (*models.Provider, error) {
+	p := &models.Provider{}
+
+	var options string
+
+	if err := row.Scan(&p.ID, &p.Name, &p.Type, &p.BaseURL, &options, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(options), &p.Options); err != nil {
+		return nil, fmt.Errorf("decode options: %w", err)
+	}
+
+	return p, nil
+}
+
+// encodeOptions stores provider options as a JSON object, {} when there are none.
+func encodeOptions(options map[string]string) (string, error) {
+	if options == nil {
+		return "{}", nil
+	}
+
+	data, err := json.Marshal(options)
+	if err != nil {
+		return "", fmt.Errorf("encode options: %w", err)
+	}
+
+	return string(data), nil
+}
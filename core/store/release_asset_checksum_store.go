@@ -0,0 +1,54 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"GitSyncer/core/models"
+)
+
+type ReleaseAssetChecksumStore struct {
+	db *sql.DB
+}
+
+func NewReleaseAssetChecksumStore(db *sql.DB) *ReleaseAssetChecksumStore {
+	return &ReleaseAssetChecksumStore{db: db}
+}
+
+const releaseAssetChecksumColumns = `provider_id, asset_id, download_url, size, sha256, updated_at`
+
+// Upsert records a checksum, replacing the one of the same provider and asset.
+func (s *ReleaseAssetChecksumStore) Upsert(c *models.ReleaseAssetChecksum) error {
+	now := time.Now().UTC()
+
+	_, err := s.db.Exec(
+		`INSERT INTO release_asset_checksums (provider_id, asset_id, download_url, size, sha256, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(provider_id, asset_id) DO UPDATE SET
+		 download_url = excluded.download_url, size = excluded.size, sha256 = excluded.sha256, updated_at = excluded.updated_at`,
+		c.ProviderID, c.AssetID, c.DownloadURL, c.Size, c.SHA256, now,
+	)
+	if err != nil {
+		return fmt.Errorf("ReleaseAssetChecksumStore.Upsert(%d, %s): %w", c.ProviderID, c.AssetID, err)
+	}
+
+	c.UpdatedAt = now
+
+	return nil
+}
+
+// Get returns the checksum recorded for an asset of a provider, or sql.ErrNoRows when there is none.
+func (s *ReleaseAssetChecksumStore) Get(providerID int64, assetID string) (*models.ReleaseAssetChecksum, error) {
+	var c models.ReleaseAssetChecksum
+
+	err := s.db.QueryRow(
+		`SELECT `+releaseAssetChecksumColumns+` FROM release_asset_checksums WHERE provider_id = ? AND asset_id = ?`,
+		providerID, assetID,
+	).Scan(&c.ProviderID, &c.AssetID, &c.DownloadURL, &c.Size, &c.SHA256, &c.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("ReleaseAssetChecksumStore.Get(%d, %s): %w", providerID, assetID, err)
+	}
+
+	return &c, nil
+}
@@ -3,9 +3,11 @@ package store
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
 )
 
 type RepositoryStore struct {
@@ -20,9 +22,9 @@ func (s *RepositoryStore) Create(r *models.Repository) error {
 	now := time.Now().UTC()
 
 	result, err := s.db.Exec(
-		`INSERT INTO repositories (provider_id, name, clone_url, description, is_mirror, default_branch, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
-		r.ProviderID, r.Name, r.CloneURL, r.Description, r.IsMirror, r.DefaultBranch, now, now,
+		`INSERT INTO repositories (provider_id, name, clone_url, canonical_url, description, homepage, topics, is_mirror, has_wiki, wiki_clone_url, default_branch, local_path, owner, visibility, is_archived, is_fork, pushed_at, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.ProviderID, r.Name, r.CloneURL, provider.CanonicalURL(r.CloneURL), r.Description, r.Homepage, joinTopics(r.Topics), r.IsMirror, r.HasWiki, r.WikiCloneURL, r.DefaultBranch, r.LocalPath, r.Owner, r.Visibility, r.IsArchived, r.IsFork, r.PushedAt, now, now,
 	)
 	if err != nil {
 		return fmt.Errorf("RepositoryStore.Create: %w", err)
@@ -43,16 +45,23 @@ func (s *RepositoryStore) Create(r *models.Repository) error {
 func (s *RepositoryStore) GetByID(id int64) (*models.Repository, error) {
 	r := &models.Repository{}
 
-	var lastSynced sql.NullTime
+	var topics string
+	var pushedAt, lastSynced sql.NullTime
 
 	err := s.db.QueryRow(
-		`SELECT id, provider_id, name, clone_url, description, is_mirror, default_branch, last_synced_at, created_at, updated_at
+		`SELECT id, provider_id, name, clone_url, description, homepage, topics, is_mirror, has_wiki, wiki_clone_url, default_branch, local_path, owner, visibility, is_archived, is_fork, pushed_at, last_synced_at, created_at, updated_at
 		 FROM repositories WHERE id = ?`, id,
-	).Scan(&r.ID, &r.ProviderID, &r.Name, &r.CloneURL, &r.Description, &r.IsMirror, &r.DefaultBranch, &lastSynced, &r.CreatedAt, &r.UpdatedAt)
+	).Scan(&r.ID, &r.ProviderID, &r.Name, &r.CloneURL, &r.Description, &r.Homepage, &topics, &r.IsMirror, &r.HasWiki, &r.WikiCloneURL, &r.DefaultBranch, &r.LocalPath, &r.Owner, &r.Visibility, &r.IsArchived, &r.IsFork, &pushedAt, &lastSynced, &r.CreatedAt, &r.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("RepositoryStore.GetByID(%d): %w", id, err)
 	}
 
+	r.Topics = splitTopics(topics)
+
+	if pushedAt.Valid {
+		r.PushedAt = &pushedAt.Time
+	}
+
 	if lastSynced.Valid {
 		r.LastSyncedAt = &lastSynced.Time
 	}
@@ -62,7 +71,7 @@ func (s *RepositoryStore) GetByID(id int64) (*models.Repository, error) {
 
 func (s *RepositoryStore) List() ([]models.Repository, error) {
 	rows, err := s.db.Query(
-		`SELECT id, provider_id, name, clone_url, description, is_mirror, default_branch, last_synced_at, created_at, updated_at
+		`SELECT id, provider_id, name, clone_url, description, homepage, topics, is_mirror, has_wiki, wiki_clone_url, default_branch, local_path, owner, visibility, is_archived, is_fork, pushed_at, last_synced_at, created_at, updated_at
 		 FROM repositories ORDER BY id`,
 	)
 	if err != nil {
@@ -74,12 +83,19 @@ func (s *RepositoryStore) List() ([]models.Repository, error) {
 
 	for rows.Next() {
 		var r models.Repository
-		var lastSynced sql.NullTime
+		var topics string
+		var pushedAt, lastSynced sql.NullTime
 
-		if err := rows.Scan(&r.ID, &r.ProviderID, &r.Name, &r.CloneURL, &r.Description, &r.IsMirror, &r.DefaultBranch, &lastSynced, &r.CreatedAt, &r.UpdatedAt); err != nil {
+		if err := rows.Scan(&r.ID, &r.ProviderID, &r.Name, &r.CloneURL, &r.Description, &r.Homepage, &topics, &r.IsMirror, &r.HasWiki, &r.WikiCloneURL, &r.DefaultBranch, &r.LocalPath, &r.Owner, &r.Visibility, &r.IsArchived, &r.IsFork, &pushedAt, &lastSynced, &r.CreatedAt, &r.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("RepositoryStore.List: scan: %w", err)
 		}
 
+		r.Topics = splitTopics(topics)
+
+		if pushedAt.Valid {
+			r.PushedAt = &pushedAt.Time
+		}
+
 		if lastSynced.Valid {
 			r.LastSyncedAt = &lastSynced.Time
 		}
@@ -90,13 +106,72 @@ func (s *RepositoryStore) List() ([]models.Repository, error) {
 	return repos, rows.Err()
 }
 
+// FindByCloneURL returns the repository whose clone URL addresses the same repository as rawURL,
+// comparing canonical forms so that SSH, HTTPS and credential-bearing URLs of one repository match.
+func (s *RepositoryStore) FindByCloneURL(rawURL string) (*models.Repository, error) {
+	canonical := provider.CanonicalURL(rawURL)
+	if canonical == "" {
+		return nil, fmt.Errorf("RepositoryStore.FindByCloneURL: unrecognised url %q", rawURL)
+	}
+
+	var id int64
+
+	err := s.db.QueryRow(`SELECT id FROM repositories WHERE canonical_url = ? ORDER BY id LIMIT 1`, canonical).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("RepositoryStore.FindByCloneURL(%s): %w", canonical, err)
+	}
+
+	return s.GetByID(id)
+}
+
+// BackfillCanonicalURLs sets the canonical URL of repositories stored before the column existed,
+// which migrations cannot compute.
+func (s *RepositoryStore) BackfillCanonicalURLs() error {
+	rows, err := s.db.Query(`SELECT id, clone_url FROM repositories WHERE canonical_url IS NULL`)
+	if err != nil {
+		return fmt.Errorf("RepositoryStore.BackfillCanonicalURLs: %w", err)
+	}
+
+	urls := make(map[int64]string)
+
+	for rows.Next() {
+		var (
+			id       int64
+			cloneURL string
+		)
+
+		if err := rows.Scan(&id, &cloneURL); err != nil {
+			rows.Close()
+			return fmt.Errorf("RepositoryStore.BackfillCanonicalURLs: scan: %w", err)
+		}
+
+		urls[id] = cloneURL
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("RepositoryStore.BackfillCanonicalURLs: %w", err)
+	}
+
+	for id, cloneURL := range urls {
+		if _, err := s.db.Exec(`UPDATE repositories SET canonical_url = ? WHERE id = ?`, provider.CanonicalURL(cloneURL), id); err != nil {
+			return fmt.Errorf("RepositoryStore.BackfillCanonicalURLs(%d): %w", id, err)
+		}
+	}
+
+	return nil
+}
+
 func (s *RepositoryStore) Update(r *models.Repository) error {
 	now := time.Now().UTC()
 
 	result, err := s.db.Exec(
-		`UPDATE repositories SET provider_id = ?, name = ?, clone_url = ?, description = ?, is_mirror = ?, default_branch = ?, last_synced_at = ?, updated_at = ?
+		`UPDATE repositories SET provider_id = ?, name = ?, clone_url = ?, canonical_url = ?, description = ?, homepage = ?, topics = ?, is_mirror = ?, has_wiki = ?, wiki_clone_url = ?,
+		 default_branch = ?, local_path = ?, owner = ?, visibility = ?, is_archived = ?, is_fork = ?, pushed_at = ?, last_synced_at = ?, updated_at = ?
 		 WHERE id = ?`,
-		r.ProviderID, r.Name, r.CloneURL, r.Description, r.IsMirror, r.DefaultBranch, r.LastSyncedAt, now, r.ID,
+		r.ProviderID, r.Name, r.CloneURL, provider.CanonicalURL(r.CloneURL), r.Description, r.Homepage, joinTopics(r.Topics), r.IsMirror, r.HasWiki, r.WikiCloneURL, r.DefaultBranch, r.LocalPath,
+		r.Owner, r.Visibility, r.IsArchived, r.IsFork, r.PushedAt, r.LastSyncedAt, now, r.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("RepositoryStore.Update(%d): %w", r.ID, err)
@@ -133,3 +208,16 @@ func (s *RepositoryStore) Delete(id int64) error {
 
 	return nil
 }
+
+// Topics are stored comma-separated; no provider allows commas in topic names.
+func joinTopics(topics []string) string {
+	return strings.Join(topics, ",")
+}
+
+func splitTopics(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, ",")
+}
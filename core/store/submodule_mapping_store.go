@@ -0,0 +1,71 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"GitSyncer/core/models"
+)
+
+type SubmoduleMappingStore struct {
+	db *sql.DB
+}
+
+func NewSubmoduleMappingStore(db *sql.DB) *SubmoduleMappingStore {
+	return &SubmoduleMappingStore{db: db}
+}
+
+const submoduleMappingColumns = `id, mirror_id, submodule_mirror_id, source_url, target_url, updated_at`
+
+// Upsert records a mapping, replacing the one of the same mirror and source URL. An unmapped
+// submodule has a nil SubmoduleMirrorID.
+func (s *SubmoduleMappingStore) Upsert(m *models.SubmoduleMapping) error {
+	now := time.Now().UTC()
+
+	err := s.db.QueryRow(
+		`INSERT INTO submodule_mappings (mirror_id, submodule_mirror_id, source_url, target_url, updated_at)
+		 VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(mirror_id, source_url) DO UPDATE SET
+		 submodule_mirror_id = excluded.submodule_mirror_id, target_url = excluded.target_url, updated_at = excluded.updated_at
+		 RETURNING id`,
+		m.MirrorID, m.SubmoduleMirrorID, m.SourceURL, m.TargetURL, now,
+	).Scan(&m.ID)
+	if err != nil {
+		return fmt.Errorf("SubmoduleMappingStore.Upsert(%d): %w", m.MirrorID, err)
+	}
+
+	m.UpdatedAt = now
+
+	return nil
+}
+
+// ListByMirror returns the mappings recorded for a mirror, ordered by source URL.
+func (s *SubmoduleMappingStore) ListByMirror(mirrorID int64) ([]models.SubmoduleMapping, error) {
+	rows, err := s.db.Query(`SELECT `+submoduleMappingColumns+` FROM submodule_mappings WHERE mirror_id = ? ORDER BY source_url`, mirrorID)
+	if err != nil {
+		return nil, fmt.Errorf("SubmoduleMappingStore.ListByMirror(%d): %w", mirrorID, err)
+	}
+	defer rows.Close()
+
+	var mappings []models.SubmoduleMapping
+
+	for rows.Next() {
+		var (
+			m                 models.SubmoduleMapping
+			submoduleMirrorID sql.NullInt64
+		)
+
+		if err := rows.Scan(&m.ID, &m.MirrorID, &submoduleMirrorID, &m.SourceURL, &m.TargetURL, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("SubmoduleMappingStore.ListByMirror(%d): scan: %w", mirrorID, err)
+		}
+
+		if submoduleMirrorID.Valid {
+			m.SubmoduleMirrorID = &submoduleMirrorID.Int64
+		}
+
+		mappings = append(mappings, m)
+	}
+
+	return mappings, rows.Err()
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+
+	"GitSyncer/core/models"
+)
+
+type SyncHistoryStore struct {
+	db *sql.DB
+}
+
+func NewSyncHistoryStore(db *sql.DB) *SyncHistoryStore {
+	return &SyncHistoryStore{db: db}
+}
+
+const syncHistoryColumns = `id, repository_id, mirror_id, status, started_at, finished_at, error_message, details`
+
+func (s *SyncHistoryStore) Create(h *models.SyncHistory) error {
+	result, err := s.db.Exec(
+		`INSERT INTO sync_history (repository_id, mirror_id, status, started_at, finished_at, error_message, details)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		h.RepositoryID, h.MirrorID, h.Status, h.StartedAt, h.FinishedAt, h.ErrorMessage, h.Details,
+	)
+	if err != nil {
+		return fmt.Errorf("SyncHistoryStore.Create: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("SyncHistoryStore.Create: last insert id: %w", err)
+	}
+
+	h.ID = id
+
+	return nil
+}
+
+// Update records the outcome of an entry: its status, finish time, error and details.
+func (s *SyncHistoryStore) Update(h *models.SyncHistory) error {
+	result, err := s.db.Exec(
+		`UPDATE sync_history SET status = ?, finished_at = ?, error_message = ?, details = ? WHERE id = ?`,
+		h.Status, h.FinishedAt, h.ErrorMessage, h.Details, h.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("SyncHistoryStore.Update(%d): %w", h.ID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("SyncHistoryStore.Update(%d): rows affected: %w", h.ID, err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("SyncHistoryStore.Update(%d): %w", h.ID, sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// ListByRepository returns up to limit entries of a repository, most recent first.
+func (s *SyncHistoryStore) ListByRepository(repositoryID int64, limit int) ([]models.SyncHistory, error) {
+	rows, err := s.db.Query(
+		`SELECT `+syncHistoryColumns+` FROM sync_history WHERE repository_id = ? ORDER BY started_at DESC, id DESC LIMIT ?`,
+		repositoryID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("SyncHistoryStore.ListByRepository(%d): %w", repositoryID, err)
+	}
+	defer rows.Close()
+
+	var entries []models.SyncHistory
+
+	for rows.Next() {
+		h, err := scanSyncHistory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("SyncHistoryStore.ListByRepository(%d): scan: %w", repositoryID, err)
+		}
+
+		entries = append(entries, *h)
+	}
+
+	return entries, rows.Err()
+}
+
+// LatestByMirror returns the most recent entry recorded for a mirror.
+func (s *SyncHistoryStore) LatestByMirror(mirrorID int64) (*models.SyncHistory, error) {
+	h, err := scanSyncHistory(s.db.QueryRow(
+		`SELECT `+syncHistoryColumns+` FROM sync_history WHERE mirror_id = ? ORDER BY started_at DESC, id DESC LIMIT 1`, mirrorID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("SyncHistoryStore.LatestByMirror(%d): %w", mirrorID, err)
+	}
+
+	return h, nil
+}
+
+func scanSyncHistory(row rowScanner) (*models.SyncHistory, error) {
+	h := &models.SyncHistory{}
+
+	var mirrorID sql.NullInt64
+	var finishedAt sql.NullTime
+
+	err := row.Scan(&h.ID, &h.RepositoryID, &mirrorID, &h.Status, &h.StartedAt, &finishedAt, &h.ErrorMessage, &h.Details)
+	if err != nil {
+		return nil, err
+	}
+
+	if mirrorID.Valid {
+		h.MirrorID = &mirrorID.Int64
+	}
+
+	if finishedAt.Valid {
+		h.FinishedAt = &finishedAt.Time
+	}
+
+	return h, nil
+}
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"GitSyncer/core/models"
+)
+
+type WebhookDeliveryStore struct {
+	db *sql.DB
+}
+
+func NewWebhookDeliveryStore(db *sql.DB) *WebhookDeliveryStore {
+	return &WebhookDeliveryStore{db: db}
+}
+
+const webhookDeliveryColumns = `id, provider_id, repository_id, delivery_id, event, status, verified, error_message, headers, payload, received_at, processed_at`
+
+func (s *WebhookDeliveryStore) Create(d *models.WebhookDelivery) error {
+	now := time.Now().UTC()
+
+	result, err := s.db.Exec(
+		`INSERT INTO webhook_deliveries (provider_id, repository_id, delivery_id, event, status, verified, error_message, headers, payload, received_at, processed_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.ProviderID, d.RepositoryID, d.DeliveryID, d.Event, d.Status, d.Verified, d.ErrorMessage, d.Headers, d.Payload, now, d.ProcessedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("WebhookDeliveryStore.Create: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("WebhookDeliveryStore.Create: last insert id: %w", err)
+	}
+
+	d.ID = id
+	d.ReceivedAt = now
+
+	return nil
+}
+
+func (s *WebhookDeliveryStore) GetByID(id int64) (*models.WebhookDelivery, error) {
+	d, err := scanWebhookDelivery(s.db.QueryRow(
+		`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries WHERE id = ?`, id,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("WebhookDeliveryStore.GetByID(%d): %w", id, err)
+	}
+
+	return d, nil
+}
+
+// List returns the most recent deliveries first. A limit of zero or less returns all of them.
+func (s *WebhookDeliveryStore) List(limit int) ([]models.WebhookDelivery, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+
+	rows, err := s.db.Query(
+		`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries ORDER BY id DESC LIMIT ?`, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookDeliveryStore.List: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []models.WebhookDelivery
+
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("WebhookDeliveryStore.List: scan: %w", err)
+		}
+
+		deliveries = append(deliveries, *d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// Update stores the outcome of processing a delivery.
+func (s *WebhookDeliveryStore) Update(d *models.WebhookDelivery) error {
+	result, err := s.db.Exec(
+		`UPDATE webhook_deliveries SET repository_id = ?, status = ?, verified = ?, error_message = ?, processed_at = ?
+		 WHERE id = ?`,
+		d.RepositoryID, d.Status, d.Verified, d.ErrorMessage, d.ProcessedAt, d.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("WebhookDeliveryStore.Update(%d): %w", d.ID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("WebhookDeliveryStore.Update(%d): rows affected: %w", d.ID, err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("WebhookDeliveryStore.Update(%d): %w", d.ID, sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// DeleteReceivedBefore removes deliveries received before t and returns how many were removed.
+func (s *WebhookDeliveryStore) DeleteReceivedBefore(t time.Time) (int64, error) {
+	result, err := s.db.Exec(`DELETE FROM webhook_deliveries WHERE received_at < ?`, t.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("WebhookDeliveryStore.DeleteReceivedBefore: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("WebhookDeliveryStore.DeleteReceivedBefore: rows affected: %w", err)
+	}
+
+	return rows, nil
+}
+
+// DeleteUnverifiedBeyond removes all but the n most recent deliveries that were not verified and
+// returns how many were removed.
+func (s *WebhookDeliveryStore) DeleteUnverifiedBeyond(n int) (int64, error) {
+	result, err := s.db.Exec(
+		`DELETE FROM webhook_deliveries WHERE verified = 0 AND id NOT IN
+		 (SELECT id FROM webhook_deliveries WHERE verified = 0 ORDER BY id DESC LIMIT ?)`, n,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("WebhookDeliveryStore.DeleteUnverifiedBeyond: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("WebhookDeliveryStore.DeleteUnverifiedBeyond: rows affected: %w", err)
+	}
+
+	return rows, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	d := &models.WebhookDelivery{}
+
+	var (
+		repositoryID sql.NullInt64
+		processedAt  sql.NullTime
+	)
+
+	err := row.Scan(&d.ID, &d.ProviderID, &repositoryID, &d.DeliveryID, &d.Event, &d.Status, &d.Verified, &d.ErrorMessage, &d.Headers, &d.Payload, &d.ReceivedAt, &processedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if repositoryID.Valid {
+		d.RepositoryID = &repositoryID.Int64
+	}
+
+	if processedAt.Valid {
+		d.ProcessedAt = &processedAt.Time
+	}
+
+	return d, nil
+}
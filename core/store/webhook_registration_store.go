@@ -0,0 +1,151 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"GitSyncer/core/models"
+)
+
+type WebhookRegistrationStore struct {
+	db *sql.DB
+}
+
+func NewWebhookRegistrationStore(db *sql.DB) *WebhookRegistrationStore {
+	return &WebhookRegistrationStore{db: db}
+}
+
+const webhookRegistrationColumns = `id, provider_id, repository_id, credential_id, clone_url, hook_id, url, created_at, updated_at`
+
+func (s *WebhookRegistrationStore) Create(r *models.WebhookRegistration) error {
+	now := time.Now().UTC()
+
+	result, err := s.db.Exec(
+		`INSERT INTO webhook_registrations (provider_id, repository_id, credential_id, clone_url, hook_id, url, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.ProviderID, r.RepositoryID, r.CredentialID, r.CloneURL, r.HookID, r.URL, now, now,
+	)
+	if err != nil {
+		return fmt.Errorf("WebhookRegistrationStore.Create: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("WebhookRegistrationStore.Create: last insert id: %w", err)
+	}
+
+	r.ID = id
+	r.CreatedAt = now
+	r.UpdatedAt = now
+
+	return nil
+}
+
+func (s *WebhookRegistrationStore) GetByID(id int64) (*models.WebhookRegistration, error) {
+	r, err := scanWebhookRegistration(s.db.QueryRow(
+		`SELECT `+webhookRegistrationColumns+` FROM webhook_registrations WHERE id = ?`, id,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("WebhookRegistrationStore.GetByID(%d): %w", id, err)
+	}
+
+	return r, nil
+}
+
+func (s *WebhookRegistrationStore) GetByRepositoryID(repositoryID int64) (*models.WebhookRegistration, error) {
+	r, err := scanWebhookRegistration(s.db.QueryRow(
+		`SELECT `+webhookRegistrationColumns+` FROM webhook_registrations WHERE repository_id = ?`, repositoryID,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("WebhookRegistrationStore.GetByRepositoryID(%d): %w", repositoryID, err)
+	}
+
+	return r, nil
+}
+
+func (s *WebhookRegistrationStore) List() ([]models.WebhookRegistration, error) {
+	rows, err := s.db.Query(`SELECT ` + webhookRegistrationColumns + ` FROM webhook_registrations ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookRegistrationStore.List: %w", err)
+	}
+	defer rows.Close()
+
+	var registrations []models.WebhookRegistration
+
+	for rows.Next() {
+		r, err := scanWebhookRegistration(rows)
+		if err != nil {
+			return nil, fmt.Errorf("WebhookRegistrationStore.List: scan: %w", err)
+		}
+
+		registrations = append(registrations, *r)
+	}
+
+	return registrations, rows.Err()
+}
+
+func (s *WebhookRegistrationStore) Update(r *models.WebhookRegistration) error {
+	now := time.Now().UTC()
+
+	result, err := s.db.Exec(
+		`UPDATE webhook_registrations SET repository_id = ?, credential_id = ?, clone_url = ?, hook_id = ?, url = ?, updated_at = ?
+		 WHERE id = ?`,
+		r.RepositoryID, r.CredentialID, r.CloneURL, r.HookID, r.URL, now, r.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("WebhookRegistrationStore.Update(%d): %w", r.ID, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("WebhookRegistrationStore.Update(%d): rows affected: %w", r.ID, err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("WebhookRegistrationStore.Update(%d): %w", r.ID, sql.ErrNoRows)
+	}
+
+	r.UpdatedAt = now
+
+	return nil
+}
+
+func (s *WebhookRegistrationStore) Delete(id int64) error {
+	result, err := s.db.Exec(`DELETE FROM webhook_registrations WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("WebhookRegistrationStore.Delete(%d): %w", id, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("WebhookRegistrationStore.Delete(%d): rows affected: %w", id, err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("WebhookRegistrationStore.Delete(%d): %w", id, sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+func scanWebhookRegistration(row rowScanner) (*models.WebhookRegistration, error) {
+	r := &models.WebhookRegistration{}
+
+	var repositoryID, credentialID sql.NullInt64
+
+	err := row.Scan(&r.ID, &r.ProviderID, &repositoryID, &credentialID, &r.CloneURL, &r.HookID, &r.URL, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if repositoryID.Valid {
+		r.RepositoryID = &repositoryID.Int64
+	}
+
+	if credentialID.Valid {
+		r.CredentialID = &credentialID.Int64
+	}
+
+	return r, nil
+}
@@ -0,0 +1,230 @@
+// Package webhook receives push notifications from GitHub, GitLab and Gitea. It verifies each
+// provider's signature scheme, extracts the repository and refs an event concerns, and hands
+// deliveries to a Handler through an embedded HTTP listener.
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+
+	"GitSyncer/core/provider"
+)
+
+// Event kinds.
+const (
+	// EventPush is any change to branches or tags, including their creation and deletion.
+	EventPush = "push"
+
+	// EventPing is the test delivery sent when a webhook is created.
+	EventPing = "ping"
+
+	// EventOther is an event GitSyncer does not act on.
+	EventOther = "other"
+)
+
+var (
+	ErrUnsupportedProvider = errors.New("webhooks are not supported for this provider")
+	ErrMissingSignature    = errors.New("webhook signature or token is missing")
+	ErrInvalidSignature    = errors.New("webhook signature or token does not match")
+	ErrNoSecret            = errors.New("no webhook secret is configured")
+)
+
+// Event is the part of a delivery GitSyncer acts on.
+type Event struct {
+	Provider provider.ProviderType `json:"provider"`
+
+	// Kind is EventPush, EventPing or EventOther; Name is the provider's own event name.
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+
+	DeliveryID string `json:"delivery_id"`
+
+	// CloneURLs lists every URL the payload gives for the repository, e.g. HTTPS and SSH.
+	CloneURLs []string `json:"clone_urls"`
+
+	Ref    string `json:"ref"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Supported reports whether deliveries from pt can be verified and parsed.
+func Supported(pt provider.ProviderType) bool {
+	switch pt {
+	case provider.ProviderGitHub, provider.ProviderGitLab, provider.ProviderGitea:
+		return true
+	}
+
+	return false
+}
+
+// EventName returns the provider's event header and delivery id header values.
+func EventName(pt provider.ProviderType, header http.Header) (name, deliveryID string) {
+	switch pt {
+	case provider.ProviderGitHub:
+		return header.Get("X-GitHub-Event"), header.Get("X-GitHub-Delivery")
+	case provider.ProviderGitLab:
+		deliveryID = header.Get("X-Gitlab-Webhook-UUID")
+		if deliveryID == "" {
+			deliveryID = header.Get("X-Gitlab-Event-UUID")
+		}
+
+		return header.Get("X-Gitlab-Event"), deliveryID
+	case provider.ProviderGitea:
+		// Forgejo and Gogs send their own prefixes alongside or instead of Gitea's.
+		for _, prefix := range []string{"X-Gitea-", "X-Forgejo-", "X-Gogs-"} {
+			if name = header.Get(prefix + "Event"); name != "" {
+				return name, header.Get(prefix + "Delivery")
+			}
+		}
+	}
+
+	return "", ""
+}
+
+// repositoryPayload covers the GitHub and Gitea repository object and GitLab's project object.
+type repositoryPayload struct {
+	CloneURL string `json:"clone_url"`
+	SSHURL   string `json:"ssh_url"`
+	GitURL   string `json:"git_url"`
+	HTMLURL  string `json:"html_url"`
+
+	GitHTTPURL string `json:"git_http_url"`
+	GitSSHURL  string `json:"git_ssh_url"`
+	WebURL     string `json:"web_url"`
+}
+
+func (r *repositoryPayload) urls() []string {
+	if r == nil {
+		return nil
+	}
+
+	var urls []string
+
+	for _, u := range []string{r.CloneURL, r.GitHTTPURL, r.SSHURL, r.GitSSHURL, r.GitURL, r.HTMLURL, r.WebURL} {
+		if u != "" {
+			urls = append(urls, u)
+		}
+	}
+
+	return urls
+}
+
+type payload struct {
+	Ref        string             `json:"ref"`
+	Before     string             `json:"before"`
+	After      string             `json:"after"`
+	ObjectKind string             `json:"object_kind"`
+	Repository *repositoryPayload `json:"repository"`
+	Project    *repositoryPayload `json:"project"`
+}
+
+// Parse decodes a delivery from pt. The body must already have been verified.
+func Parse(pt provider.ProviderType, header http.Header, body []byte) (*Event, error) {
+	if !Supported(pt) {
+		return nil, ErrUnsupportedProvider
+	}
+
+	name, deliveryID := EventName(pt, header)
+
+	data, err := jsonBody(header, body)
+	if err != nil {
+		return nil, fmt.Errorf("webhook.Parse: %w", err)
+	}
+
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("webhook.Parse: %w", err)
+	}
+
+	ev := &Event{
+		Provider:   pt,
+		Kind:       kind(pt, name, p.ObjectKind),
+		Name:       name,
+		DeliveryID: deliveryID,
+		Ref:        p.Ref,
+		Before:     p.Before,
+		After:      p.After,
+		CloneURLs:  append(p.Project.urls(), p.Repository.urls()...),
+	}
+
+	return ev, nil
+}
+
+func kind(pt provider.ProviderType, name, objectKind string) string {
+	switch pt {
+	case provider.ProviderGitLab:
+		// System hooks all arrive as "System Hook", so the payload's object_kind decides.
+		switch objectKind {
+		case "push", "tag_push":
+			return EventPush
+		}
+
+		return EventOther
+	case provider.ProviderGitHub, provider.ProviderGitea:
+		switch name {
+		case "push", "create", "delete":
+			return EventPush
+		case "ping":
+			return EventPing
+		}
+	}
+
+	return EventOther
+}
+
+// jsonBody returns the JSON document of a delivery, unwrapping GitHub's form-encoded variant.
+func jsonBody(header http.Header, body []byte) ([]byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mediaType != "application/x-www-form-urlencoded" {
+		return body, nil
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(form.Get("payload")), nil
+}
+
+// recordedHeaders are kept with a delivery so it can be inspected and re-verified on replay.
+// GitLab's X-Gitlab-Token is the secret itself and is deliberately absent.
+var recordedHeaders = []string{
+	"Content-Type",
+	"User-Agent",
+	"X-GitHub-Event",
+	"X-GitHub-Delivery",
+	"X-GitHub-Hook-ID",
+	"X-Hub-Signature",
+	"X-Hub-Signature-256",
+	"X-Gitlab-Event",
+	"X-Gitlab-Event-UUID",
+	"X-Gitlab-Webhook-UUID",
+	"X-Gitlab-Instance",
+	"X-Gitea-Event",
+	"X-Gitea-Delivery",
+	"X-Gitea-Signature",
+	"X-Forgejo-Event",
+	"X-Forgejo-Delivery",
+	"X-Forgejo-Signature",
+	"X-Gogs-Event",
+	"X-Gogs-Delivery",
+	"X-Gogs-Signature",
+}
+
+// RecordedHeaders returns the subset of header safe to store with a delivery.
+func RecordedHeaders(header http.Header) http.Header {
+	kept := http.Header{}
+
+	for _, name := range recordedHeaders {
+		if v := header.Values(name); len(v) > 0 {
+			kept[http.CanonicalHeaderKey(name)] = v
+		}
+	}
+
+	return kept
+}
@@ -0,0 +1,166 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"GitSyncer/core/models"
+)
+
+// PathPrefix is where deliveries are accepted; the provider record id follows it,
+// e.g. http://host:port/webhooks/3.
+const PathPrefix = "/webhooks/"
+
+// ReceiverURL returns the delivery URL for the provider record providerID under baseURL, the
+// public address the receiver is reachable at, e.g. https://sync.example.com/webhooks/3.
+func ReceiverURL(baseURL string, providerID int64) string {
+	return strings.TrimSuffix(baseURL, "/") + PathPrefix + strconv.FormatInt(providerID, 10)
+}
+
+// maxBodySize matches GitHub's 25 MB payload cap.
+const maxBodySize = 25 << 20
+
+// ErrUnknownProvider is returned by a Handler when no provider record has the id in the path.
+var ErrUnknownProvider = errors.New("unknown provider")
+
+// Handler processes a delivery addressed to the provider record providerID and returns the
+// recorded delivery. The delivery's status decides the HTTP response.
+type Handler interface {
+	HandleWebhook(ctx context.Context, providerID int64, header http.Header, body []byte) (*models.WebhookDelivery, error)
+}
+
+// Server is the embedded HTTP listener for webhook deliveries.
+type Server struct {
+	handler Handler
+	mux     *http.ServeMux
+
+	mu  sync.Mutex
+	srv *http.Server
+	ln  net.Listener
+}
+
+// NewServer creates a Server passing deliveries to handler. It does not listen until Start.
+func NewServer(handler Handler) *Server {
+	s := &Server{handler: handler, mux: http.NewServeMux()}
+	s.mux.HandleFunc("POST "+PathPrefix+"{providerID}", s.receive)
+
+	return s
+}
+
+// ServeHTTP lets the Server be mounted on another listener or used in tests.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Start listens on addr, e.g. ":8765", replacing any listener already running.
+func (s *Server) Start(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("webhook.Server.Start: %w", err)
+	}
+
+	srv := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+
+	s.mu.Lock()
+	old := s.srv
+	s.srv, s.ln = srv, ln
+	s.mu.Unlock()
+
+	if old != nil {
+		old.Close()
+	}
+
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("webhook listener on %s stopped: %v", ln.Addr(), err)
+		}
+	}()
+
+	return nil
+}
+
+// Addr returns the address being listened on, or "" when the Server is stopped.
+func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ln == nil {
+		return ""
+	}
+
+	return s.ln.Addr().String()
+}
+
+// Stop closes the listener, waiting for in-flight deliveries until ctx ends.
+func (s *Server) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	srv := s.srv
+	s.srv, s.ln = nil, nil
+	s.mu.Unlock()
+
+	if srv == nil {
+		return nil
+	}
+
+	return srv.Shutdown(ctx)
+}
+
+func (s *Server) receive(w http.ResponseWriter, r *http.Request) {
+	providerID, err := strconv.ParseInt(r.PathValue("providerID"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	delivery, err := s.handler.HandleWebhook(r.Context(), providerID, r.Header, body)
+
+	switch {
+	case errors.Is(err, ErrUnknownProvider), errors.Is(err, ErrUnsupportedProvider):
+		http.NotFound(w, r)
+		return
+	case err != nil:
+		log.Printf("webhook delivery for provider %d: %v", providerID, err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(StatusCode(delivery.Status))
+
+	_ = json.NewEncoder(w).Encode(struct {
+		ID     int64  `json:"id"`
+		Status string `json:"status"`
+	}{delivery.ID, delivery.Status})
+}
+
+// StatusCode is the HTTP status answered for a delivery with the given status. Failed
+// deliveries get 503 so that providers which retry will deliver them again.
+func StatusCode(status string) int {
+	switch status {
+	case models.WebhookStatusQueued:
+		return http.StatusAccepted
+	case models.WebhookStatusRejected:
+		return http.StatusUnauthorized
+	case models.WebhookStatusFailed:
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusOK
+}
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"hash"
+	"net/http"
+	"strings"
+
+	"GitSyncer/core/provider"
+)
+
+// Verify checks a delivery from pt against each of secrets, so that secrets can be rotated
+// without dropping deliveries. GitHub and Gitea sign the body with HMAC; GitLab sends the
+// secret token verbatim.
+func Verify(pt provider.ProviderType, header http.Header, body []byte, secrets []string) error {
+	if !Supported(pt) {
+		return ErrUnsupportedProvider
+	}
+
+	if len(secrets) == 0 {
+		return ErrNoSecret
+	}
+
+	if pt == provider.ProviderGitLab {
+		token := header.Get("X-Gitlab-Token")
+		if token == "" {
+			return ErrMissingSignature
+		}
+
+		for _, secret := range secrets {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1 {
+				return nil
+			}
+		}
+
+		return ErrInvalidSignature
+	}
+
+	sig, newHash := signature(pt, header)
+	if sig == "" {
+		return ErrMissingSignature
+	}
+
+	want, err := hex.DecodeString(sig)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	for _, secret := range secrets {
+		mac := hmac.New(newHash, []byte(secret))
+		mac.Write(body)
+
+		if hmac.Equal(mac.Sum(nil), want) {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+// signature returns the hex digest a GitHub or Gitea delivery carries and the hash it uses,
+// preferring SHA-256 over GitHub's legacy SHA-1 header.
+func signature(pt provider.ProviderType, header http.Header) (string, func() hash.Hash) {
+	if pt == provider.ProviderGitea {
+		for _, name := range []string{"X-Gitea-Signature", "X-Forgejo-Signature", "X-Gogs-Signature"} {
+			if v := header.Get(name); v != "" {
+				return v, sha256.New
+			}
+		}
+	}
+
+	if v, ok := strings.CutPrefix(header.Get("X-Hub-Signature-256"), "sha256="); ok {
+		return v, sha256.New
+	}
+
+	if v, ok := strings.CutPrefix(header.Get("X-Hub-Signature"), "sha1="); ok {
+		return v, sha1.New
+	}
+
+	return "", nil
+}
+
+// Sign returns the X-Hub-Signature-256 value for body, as GitHub computes it.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
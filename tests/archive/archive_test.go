@@ -0,0 +1,87 @@
+package archive_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"GitSyncer/core/archive"
+	"GitSyncer/core/provider"
+)
+
+func TestWriteRead(t *testing.T) {
+	exportedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	a := &archive.Archive{
+		Provider:   "github",
+		Repository: "octocat/hello",
+		ExportedAt: exportedAt,
+		Issues:     []provider.Issue{{Number: 1, Title: "Bug", Labels: []string{"bug"}, Assignees: []string{}}},
+	}
+
+	var buf bytes.Buffer
+	if err := archive.Write(&buf, a); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), `"comments": []`) || !strings.Contains(buf.String(), `"version": 1`) {
+		t.Errorf("Write() = %s, want the version and empty lists", buf.String())
+	}
+
+	got, err := archive.Read(&buf)
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+
+	if got.Version != archive.Version || !got.ExportedAt.Equal(exportedAt) || got.Since != nil || len(got.Issues) != 1 || got.Issues[0].Labels[0] != "bug" {
+		t.Errorf("Read() = %+v", got)
+	}
+
+	for _, data := range []string{`{"version": 99}`, `{}`, `not json`} {
+		if _, err := archive.Read(strings.NewReader(data)); err == nil {
+			t.Errorf("Read(%s) accepted an unsupported archive", data)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	first := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	second := first.Add(24 * time.Hour)
+
+	full := &archive.Archive{
+		ExportedAt: first,
+		Labels:     []provider.Label{{Name: "bug"}},
+		Issues: []provider.Issue{
+			{Number: 1, Title: "Bug", State: "open"},
+			{Number: 1, PullRequest: true, Title: "Fix"},
+		},
+		Comments: []provider.Comment{{ID: "10", IssueNumber: 1, Body: "seen"}},
+	}
+
+	incremental := &archive.Archive{
+		Since:      &first,
+		ExportedAt: second,
+		Labels:     []provider.Label{{Name: "bug"}, {Name: "docs"}},
+		Issues:     []provider.Issue{{Number: 1, Title: "Bug", State: "closed"}, {Number: 2, Title: "Docs"}},
+		Comments:   []provider.Comment{{ID: "10", IssueNumber: 1, Body: "seen, fixed"}, {ID: "11", IssueNumber: 2}},
+	}
+
+	merged := archive.Merge(full, incremental)
+
+	if merged.Since != nil || !merged.ExportedAt.Equal(second) || len(merged.Labels) != 2 {
+		t.Errorf("Merge() = %+v", merged)
+	}
+
+	if len(merged.Issues) != 3 || merged.Issues[0].State != "closed" || !merged.Issues[1].PullRequest || merged.Issues[2].Number != 2 {
+		t.Errorf("Merge() issues = %+v", merged.Issues)
+	}
+
+	if len(merged.Comments) != 2 || merged.Comments[0].Body != "seen, fixed" {
+		t.Errorf("Merge() comments = %+v", merged.Comments)
+	}
+
+	if archive.Merge() != nil {
+		t.Error("Merge() of nothing is not nil")
+	}
+}
@@ -0,0 +1,301 @@
+package lfs_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"GitSyncer/core/git"
+	"GitSyncer/core/lfs"
+)
+
+// lfsServer is an in-memory LFS server speaking the batch API with the basic transfer adapter.
+type lfsServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	objects  map[string][]byte
+	token    string
+	verified int
+}
+
+func newLFSServer(t *testing.T, token string, contents ...string) *lfsServer {
+	t.Helper()
+
+	s := &lfsServer{objects: make(map[string][]byte), token: token}
+	for _, c := range contents {
+		s.objects[pointerOf(c).OID] = []byte(c)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repo.git/info/lfs/objects/batch", s.batch)
+	mux.HandleFunc("GET /storage/{oid}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		_, _ = w.Write(s.objects[r.PathValue("oid")])
+	})
+	mux.HandleFunc("PUT /storage/{oid}", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.objects[r.PathValue("oid")] = data
+	})
+	mux.HandleFunc("POST /verify", func(w http.ResponseWriter, r *http.Request) {
+		var p lfs.Pointer
+		_ = json.NewDecoder(r.Body).Decode(&p)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if len(s.objects[p.OID]) != int(p.Size) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		s.verified++
+	})
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *lfsServer) batch(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+s.token {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"message":"Credentials needed"}`)
+
+		return
+	}
+
+	var req struct {
+		Operation string        `json:"operation"`
+		Objects   []lfs.Pointer `json:"objects"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	objects := []map[string]any{}
+
+	for _, p := range req.Objects {
+		obj := map[string]any{"oid": p.OID, "size": p.Size}
+		_, stored := s.objects[p.OID]
+
+		switch {
+		case req.Operation == lfs.OperationDownload && !stored:
+			obj["error"] = map[string]any{"code": 404, "message": "Object does not exist"}
+		case req.Operation == lfs.OperationDownload:
+			obj["actions"] = map[string]any{"download": map[string]any{"href": s.URL + "/storage/" + p.OID}}
+		case !stored:
+			obj["actions"] = map[string]any{
+				"upload": map[string]any{"href": s.URL + "/storage/" + p.OID},
+				"verify": map[string]any{"href": s.URL + "/verify"},
+			}
+		}
+
+		objects = append(objects, obj)
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.git-lfs+json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"transfer": "basic", "objects": objects})
+}
+
+func (s *lfsServer) endpoint() lfs.Endpoint {
+	return lfs.Endpoint{URL: s.URL + "/repo.git/info/lfs", Auth: &git.Auth{BearerToken: s.token}}
+}
+
+func pointerOf(content string) lfs.Pointer {
+	sum := sha256.Sum256([]byte(content))
+
+	return lfs.Pointer{OID: hex.EncodeToString(sum[:]), Size: int64(len(content))}
+}
+
+func pointerFile(content string) string {
+	p := pointerOf(content)
+
+	return fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n", p.OID, p.Size)
+}
+
+// seedRepo creates a bare repository whose main branch holds files, and returns its path.
+func seedRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	work := filepath.Join(dir, "work")
+
+	runGit(t, "", "init", "-q", "-b", "main", work)
+
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(work, name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(filepath.Join(work, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runGit(t, work, "add", "-A")
+	runGit(t, work, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial")
+	runGit(t, "", "clone", "-q", "--mirror", work, filepath.Join(dir, "repo.git"))
+
+	return filepath.Join(dir, "repo.git")
+}
+
+func TestParsePointer(t *testing.T) {
+	p, ok := lfs.ParsePointer([]byte(pointerFile("hello")))
+	if !ok || p != pointerOf("hello") {
+		t.Errorf("ParsePointer() = %+v, %v", p, ok)
+	}
+
+	for _, data := range []string{
+		"hello",
+		"version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize 5\n",
+		"version https://git-lfs.github.com/spec/v1\noid sha256:" + strings.Repeat("a", 64) + "\n",
+	} {
+		if _, ok := lfs.ParsePointer([]byte(data)); ok {
+			t.Errorf("ParsePointer(%q) accepted an invalid pointer", data)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	plain := seedRepo(t, map[string]string{"a.bin": pointerFile("a")})
+
+	if pointers, err := lfs.Scan(context.Background(), plain); err != nil || pointers != nil {
+		t.Errorf("Scan() without LFS attributes = %v, %v", pointers, err)
+	}
+
+	repo := seedRepo(t, map[string]string{
+		".gitattributes":       "*.bin filter=lfs diff=lfs merge=lfs -text\n",
+		"a.bin":                pointerFile("a"),
+		"assets/b.bin":         pointerFile("bb"),
+		"assets/copy-of-a.bin": pointerFile("a"),
+		"README.md":            "hello\n",
+	})
+
+	pointers, err := lfs.Scan(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+
+	if len(pointers) != 2 {
+		t.Errorf("Scan() = %+v, want the two distinct objects", pointers)
+	}
+}
+
+func TestTransferCopiesMissingObjects(t *testing.T) {
+	repo := seedRepo(t, map[string]string{"README.md": "hello\n"})
+	pointers := []lfs.Pointer{pointerOf("a"), pointerOf("bb"), pointerOf("ccc")}
+
+	source := newLFSServer(t, "source-token", "a", "bb", "ccc")
+	target := newLFSServer(t, "target-token", "a")
+
+	stats, err := lfs.Transfer(context.Background(), repo, pointers, source.endpoint(), target.endpoint())
+	if err != nil {
+		t.Fatalf("Transfer() error: %v", err)
+	}
+
+	want := lfs.Stats{Objects: 3, Bytes: 6, Downloaded: 2, DownloadedBytes: 5, Uploaded: 2, UploadedBytes: 5}
+	if stats != want {
+		t.Errorf("Transfer() = %+v, want %+v", stats, want)
+	}
+
+	if string(target.objects[pointerOf("ccc").OID]) != "ccc" || target.verified != 2 {
+		t.Errorf("target objects = %v, verified %d", target.objects, target.verified)
+	}
+
+	// Another target is served from the cache in the repository.
+	other := newLFSServer(t, "other-token")
+	source.Close()
+
+	stats, err = lfs.Transfer(context.Background(), repo, pointers[1:], source.endpoint(), other.endpoint())
+	if err != nil || stats.Downloaded != 0 || stats.Uploaded != 2 {
+		t.Errorf("Transfer() from the cache = %+v, %v", stats, err)
+	}
+}
+
+func TestTransferReportsFailures(t *testing.T) {
+	repo := seedRepo(t, map[string]string{"README.md": "hello\n"})
+	source := newLFSServer(t, "source-token", "a")
+	target := newLFSServer(t, "target-token")
+
+	stats, err := lfs.Transfer(context.Background(), repo, []lfs.Pointer{pointerOf("a"), pointerOf("missing")}, source.endpoint(), target.endpoint())
+	if err == nil || !strings.Contains(err.Error(), "Object does not exist") {
+		t.Errorf("Transfer() of a missing object = %v", err)
+	}
+
+	if stats.Uploaded != 1 {
+		t.Errorf("Transfer() = %+v, want the available object copied", stats)
+	}
+
+	denied := target.endpoint()
+	denied.Auth = nil
+
+	if _, err := lfs.Transfer(context.Background(), repo, []lfs.Pointer{pointerOf("a")}, source.endpoint(), denied); err == nil || !strings.Contains(err.Error(), "Credentials needed") {
+		t.Errorf("Transfer() without credentials = %v", err)
+	}
+}
+
+func TestTransferRejectsUnrequestedObjects(t *testing.T) {
+	repo := seedRepo(t, map[string]string{"README.md": "hello\n"})
+	p := pointerOf("a")
+
+	for _, bogus := range []map[string]any{
+		{"oid": "", "size": 0},
+		{"oid": "../../../etc", "size": p.Size},
+		{"oid": p.OID, "size": p.Size + 1},
+		{"oid": pointerOf("b").OID, "size": 1},
+	} {
+		uploads := 0
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /objects/batch", func(w http.ResponseWriter, r *http.Request) {
+			bogus["actions"] = map[string]any{"upload": map[string]any{"href": "http://" + r.Host + "/storage"}}
+			_ = json.NewEncoder(w).Encode(map[string]any{"objects": []any{bogus}})
+		})
+		mux.HandleFunc("PUT /storage", func(http.ResponseWriter, *http.Request) { uploads++ })
+
+		server := httptest.NewServer(mux)
+		ep := lfs.Endpoint{URL: server.URL}
+
+		_, err := lfs.Transfer(context.Background(), repo, []lfs.Pointer{p}, ep, ep)
+		if err == nil || !strings.Contains(err.Error(), "unrequested object") || uploads != 0 {
+			t.Errorf("Transfer() answered with %v = %v, %d uploads", bogus, err, uploads)
+		}
+
+		server.Close()
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
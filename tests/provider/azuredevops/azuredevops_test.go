@@ -0,0 +1,180 @@
+package azuredevops_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/azuredevops"
+)
+
+const testPAT = "azure-pat"
+
+// newTestServer returns an httptest stand-in for https://dev.azure.com/contoso with two pages of projects.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		user, pass, ok := r.BasicAuth()
+		if ok && user == "" && pass == testPAT {
+			return true
+		}
+
+		// Azure DevOps answers bad PATs with a sign-in page rather than 401.
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusNonAuthoritativeInfo)
+		fmt.Fprint(w, "<html>sign in</html>")
+
+		return false
+	}
+
+	mux.HandleFunc("/contoso/_apis/projects", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+
+		if r.URL.Query().Get("api-version") == "" {
+			t.Error("api-version query parameter is required")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Query().Get("continuationToken") == "" && r.URL.Query().Get("$top") != "1" {
+			w.Header().Set("x-ms-continuationtoken", "page2")
+			fmt.Fprint(w, `{"value":[{"name":"Web"}]}`)
+
+			return
+		}
+
+		fmt.Fprint(w, `{"value":[{"name":"Data Platform"}]}`)
+	})
+
+	mux.HandleFunc("/contoso/{project}/_apis/git/repositories", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+
+		switch r.PathValue("project") {
+		case "Web":
+			fmt.Fprint(w, `{"value":[
+				{"name":"portal","remoteUrl":"https://contoso@dev.azure.com/contoso/Web/_git/portal","defaultBranch":"refs/heads/main","project":{"name":"Web","description":"web apps"}},
+				{"name":"old","remoteUrl":"https://contoso@dev.azure.com/contoso/Web/_git/old","isDisabled":true,"project":{"name":"Web"}}
+			]}`)
+		case "Data Platform":
+			fmt.Fprint(w, `{"value":[{"name":"etl","remoteUrl":"https://dev.azure.com/contoso/Data%20Platform/_git/etl","defaultBranch":"refs/heads/develop","project":{"name":"Data Platform"}}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestListReposAcrossProjects(t *testing.T) {
+	srv := newTestServer(t)
+
+	p, err := azuredevops.New(provider.ProviderConfig{Type: provider.ProviderAzureDevOps, BaseURL: srv.URL + "/contoso"})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	ctx := context.Background()
+
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeToken, AuthData: testPAT}); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	repos, err := p.ListRepos(ctx)
+	if err != nil {
+		t.Fatalf("ListRepos() error: %v", err)
+	}
+
+	if len(repos) != 2 {
+		t.Fatalf("ListRepos() returned %d repos, want 2 (disabled repo skipped): %+v", len(repos), repos)
+	}
+
+	if repos[0].Name != "Web/portal" || repos[0].DefaultBranch != "main" || repos[0].CloneURL != "https://dev.azure.com/contoso/Web/_git/portal" {
+		t.Errorf("unexpected repo mapping: %+v", repos[0])
+	}
+
+	if repos[1].Name != "Data Platform/etl" || repos[1].DefaultBranch != "develop" {
+		t.Errorf("unexpected repo mapping: %+v", repos[1])
+	}
+}
+
+func TestAuthenticateRejectsSignInPage(t *testing.T) {
+	srv := newTestServer(t)
+
+	p, err := azuredevops.New(provider.ProviderConfig{Type: provider.ProviderAzureDevOps, BaseURL: srv.URL + "/contoso"})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	err = p.Authenticate(context.Background(), &models.Credential{AuthType: models.AuthTypeToken, AuthData: "expired"})
+
+	var authErr *provider.AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("Authenticate() = %v, want AuthError", err)
+	}
+}
+
+func TestNewRequiresOrganization(t *testing.T) {
+	if _, err := azuredevops.New(provider.ProviderConfig{Type: provider.ProviderAzureDevOps}); err == nil {
+		t.Fatal("New() without base url or organization should fail")
+	}
+
+	p, err := azuredevops.New(provider.ProviderConfig{
+		Type:    provider.ProviderAzureDevOps,
+		Options: map[string]string{azuredevops.OptionOrganization: "contoso"},
+	})
+	if err != nil {
+		t.Fatalf("New() with organization option error: %v", err)
+	}
+
+	if !p.ValidateURL("git@ssh.dev.azure.com:v3/contoso/Web/portal") {
+		t.Error("expected organization SSH URL to validate")
+	}
+
+	if p.ValidateURL("https://dev.azure.com/fabrikam/Web/_git/portal") {
+		t.Error("expected other organization to be rejected")
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	want := azuredevops.RepoRef{Organization: "contoso", Project: "Data Platform", Repository: "etl"}
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"https", "https://dev.azure.com/contoso/Data%20Platform/_git/etl"},
+		{"https with user", "https://contoso@dev.azure.com/contoso/Data%20Platform/_git/etl"},
+		{"ssh", "git@ssh.dev.azure.com:v3/contoso/Data%20Platform/etl"},
+		{"legacy https", "https://contoso.visualstudio.com/Data%20Platform/_git/etl"},
+		{"legacy collection", "https://contoso.visualstudio.com/DefaultCollection/Data%20Platform/_git/etl"},
+		{"legacy ssh", "contoso@vs-ssh.visualstudio.com:v3/contoso/Data%20Platform/etl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := azuredevops.ParseURL(tt.url)
+			if !ok || got != want {
+				t.Errorf("ParseURL(%q) = %+v, %v; want %+v", tt.url, got, ok, want)
+			}
+		})
+	}
+
+	if _, ok := azuredevops.ParseURL("https://github.com/contoso/etl.git"); ok {
+		t.Error("ParseURL() should reject non-Azure URLs")
+	}
+}
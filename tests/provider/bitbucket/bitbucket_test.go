@@ -0,0 +1,248 @@
+package bitbucket_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/bitbucket"
+)
+
+// newCloudServer returns an httptest stand-in for the Bitbucket Cloud 2.0 API.
+// Workspace listings use the JSON "next" link for pagination.
+func newCloudServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	var srv *httptest.Server
+
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		user, pass, ok := r.BasicAuth()
+		if (ok && user == "alice" && pass == "app-password") || r.Header.Get("Authorization") == "Bearer ws-token" {
+			return true
+		}
+
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return false
+	}
+
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if authorized(w, r) {
+			fmt.Fprint(w, `{"username":"alice"}`)
+		}
+	})
+
+	mux.HandleFunc("/user/permissions/workspaces", func(w http.ResponseWriter, r *http.Request) {
+		if authorized(w, r) {
+			fmt.Fprint(w, `{"values":[{"workspace":{"slug":"alice"}},{"workspace":{"slug":"acme"}}]}`)
+		}
+	})
+
+	mux.HandleFunc("/repositories/alice", func(w http.ResponseWriter, r *http.Request) {
+		if authorized(w, r) {
+			fmt.Fprint(w, `{"values":[{"full_name":"alice/dotfiles","mainbranch":{"name":"main"},"links":{"clone":[{"name":"https","href":"https://alice@bitbucket.org/alice/dotfiles.git"},{"name":"ssh","href":"git@bitbucket.org:alice/dotfiles.git"}]}}]}`)
+		}
+	})
+
+	mux.HandleFunc("/repositories/acme", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `{"values":[{"full_name":"acme/api","description":"API","links":{"clone":[{"name":"https","href":"https://bitbucket.org/acme/api.git"}]}}]}`)
+
+			return
+		}
+
+		fmt.Fprintf(w, `{"values":[{"full_name":"acme/web","links":{"clone":[{"name":"https","href":"https://bitbucket.org/acme/web.git"}]}}],"next":"%s/repositories/acme?pagelen=100&page=2"}`, srv.URL)
+	})
+
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestCloudListReposAcrossWorkspaces(t *testing.T) {
+	srv := newCloudServer(t)
+
+	p, err := bitbucket.NewCloud(provider.ProviderConfig{Type: provider.ProviderBitbucketCloud, BaseURL: srv.URL})
+	if err != nil {
+		t.Fatalf("NewCloud() error: %v", err)
+	}
+
+	ctx := context.Background()
+
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeBasic, AuthData: "alice:app-password"}); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	repos, err := p.ListRepos(ctx)
+	if err != nil {
+		t.Fatalf("ListRepos() error: %v", err)
+	}
+
+	if len(repos) != 3 {
+		t.Fatalf("ListRepos() returned %d repos, want 3", len(repos))
+	}
+
+	if repos[0].CloneURL != "https://bitbucket.org/alice/dotfiles.git" {
+		t.Errorf("clone URL should drop embedded username, got %q", repos[0].CloneURL)
+	}
+
+	if repos[0].DefaultBranch != "main" || repos[2].Description != "API" {
+		t.Errorf("unexpected repo mapping: %+v", repos)
+	}
+}
+
+func TestCloudWorkspaceToken(t *testing.T) {
+	srv := newCloudServer(t)
+
+	p, err := bitbucket.NewCloud(provider.ProviderConfig{
+		Type:    provider.ProviderBitbucketCloud,
+		BaseURL: srv.URL,
+		Options: map[string]string{bitbucket.OptionWorkspace: "acme"},
+	})
+	if err != nil {
+		t.Fatalf("NewCloud() error: %v", err)
+	}
+
+	ctx := context.Background()
+
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeToken, AuthData: "ws-token"}); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	repos, err := p.ListRepos(ctx)
+	if err != nil {
+		t.Fatalf("ListRepos() error: %v", err)
+	}
+
+	if len(repos) != 2 {
+		t.Fatalf("ListRepos() returned %d repos, want 2", len(repos))
+	}
+
+	var authErr *provider.AuthError
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeToken, AuthData: "bad"}); !errors.As(err, &authErr) {
+		t.Errorf("Authenticate() with bad token = %v, want AuthError", err)
+	}
+}
+
+// newDataCenterServer returns an httptest stand-in for the Bitbucket Data Center REST API.
+func newDataCenterServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/rest/api/1.0/repos", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer http-access-token" {
+			w.Header().Set("X-AUSERNAME", "svc-backup")
+		}
+
+		switch r.URL.Query().Get("start") {
+		case "0":
+			fmt.Fprint(w, `{"isLastPage":false,"nextPageStart":1,"values":[{"slug":"core","project":{"key":"PLAT"},"links":{"clone":[{"name":"ssh","href":"ssh://git@bb.corp:7999/plat/core.git"},{"name":"http","href":"https://svc@bb.corp/scm/plat/core.git"}]}}]}`)
+		default:
+			fmt.Fprint(w, `{"isLastPage":true,"values":[{"slug":"ui","project":{"key":"WEB"},"description":"frontend","links":{"clone":[{"name":"http","href":"https://bb.corp/scm/web/ui.git"}]}}]}`)
+		}
+	})
+
+	mux.HandleFunc("/rest/api/1.0/projects/WEB/repos", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-AUSERNAME", "svc-backup")
+		fmt.Fprint(w, `{"isLastPage":true,"values":[{"slug":"ui","project":{"key":"WEB"},"links":{"clone":[{"name":"http","href":"https://bb.corp/scm/web/ui.git"}]}}]}`)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestDataCenterListRepos(t *testing.T) {
+	srv := newDataCenterServer(t)
+
+	p, err := bitbucket.NewDataCenter(provider.ProviderConfig{Type: provider.ProviderBitbucketDataCenter, BaseURL: srv.URL})
+	if err != nil {
+		t.Fatalf("NewDataCenter() error: %v", err)
+	}
+
+	ctx := context.Background()
+
+	var authErr *provider.AuthError
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeToken, AuthData: "wrong"}); !errors.As(err, &authErr) {
+		t.Fatalf("Authenticate() with anonymous fallback = %v, want AuthError", err)
+	}
+
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeToken, AuthData: "http-access-token"}); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	repos, err := p.ListRepos(ctx)
+	if err != nil {
+		t.Fatalf("ListRepos() error: %v", err)
+	}
+
+	if len(repos) != 2 {
+		t.Fatalf("ListRepos() returned %d repos, want 2", len(repos))
+	}
+
+	if repos[0].Name != "PLAT/core" || repos[0].CloneURL != "https://bb.corp/scm/plat/core.git" {
+		t.Errorf("unexpected repo mapping: %+v", repos[0])
+	}
+}
+
+func TestDataCenterProjectOption(t *testing.T) {
+	srv := newDataCenterServer(t)
+
+	p, err := bitbucket.NewDataCenter(provider.ProviderConfig{
+		Type:    provider.ProviderBitbucketDataCenter,
+		BaseURL: srv.URL,
+		Options: map[string]string{bitbucket.OptionProject: "WEB"},
+	})
+	if err != nil {
+		t.Fatalf("NewDataCenter() error: %v", err)
+	}
+
+	ctx := context.Background()
+
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeBasic, AuthData: "svc:pw"}); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	repos, err := p.ListRepos(ctx)
+	if err != nil {
+		t.Fatalf("ListRepos() error: %v", err)
+	}
+
+	if len(repos) != 1 || repos[0].Name != "WEB/ui" {
+		t.Fatalf("ListRepos() = %+v, want only WEB/ui", repos)
+	}
+}
+
+func TestDataCenterRequiresBaseURL(t *testing.T) {
+	if _, err := bitbucket.NewDataCenter(provider.ProviderConfig{Type: provider.ProviderBitbucketDataCenter}); err == nil {
+		t.Fatal("NewDataCenter() without base url should fail")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	r := provider.NewProviderRegistry()
+
+	if err := bitbucket.Register(r); err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+
+	for _, pt := range []provider.ProviderType{provider.ProviderBitbucketCloud, provider.ProviderBitbucketDataCenter} {
+		if _, err := r.GetSourceControlProviderFactory(pt); err != nil {
+			t.Errorf("factory for %s not registered: %v", pt, err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package provider_test
+
+import (
+	"errors"
+	"testing"
+
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/gitea"
+	"GitSyncer/core/provider/local"
+)
+
+var testSchema = provider.ConfigSchema{
+	BaseURL: provider.OptionSpec{Type: provider.OptionTypeURL, Default: "https://git.example.com"},
+	Options: []provider.OptionSpec{
+		{Name: "group", Type: provider.OptionTypeString, Required: true},
+		{Name: "private", Type: provider.OptionTypeBool, Default: "true"},
+		{Name: "page_size", Type: provider.OptionTypeInt},
+		{Name: "token", Type: provider.OptionTypeString, Secret: true},
+	},
+}
+
+func TestConfigSchemaValidateAppliesDefaults(t *testing.T) {
+	in := provider.ProviderConfig{Type: provider.ProviderGitLab, Options: map[string]string{"group": "team", "page_size": "50"}}
+
+	cfg, err := testSchema.Validate(in)
+	if err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+
+	if cfg.BaseURL != "https://git.example.com" {
+		t.Errorf("BaseURL = %q, want default", cfg.BaseURL)
+	}
+
+	if cfg.Options["private"] != "true" || cfg.Options["group"] != "team" || cfg.Options["page_size"] != "50" {
+		t.Errorf("Options = %v", cfg.Options)
+	}
+
+	if _, ok := in.Options["private"]; ok {
+		t.Error("Validate() modified the input options")
+	}
+}
+
+func TestConfigSchemaValidateNormalisesBool(t *testing.T) {
+	cfg, err := testSchema.Validate(provider.ProviderConfig{Options: map[string]string{"group": "team", "private": "0"}})
+	if err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+
+	if cfg.Options["private"] != "false" {
+		t.Errorf("private = %q, want false", cfg.Options["private"])
+	}
+}
+
+func TestConfigSchemaValidateErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   provider.ProviderConfig
+		field string
+	}{
+		{"missing required", provider.ProviderConfig{}, "group"},
+		{"bad bool", provider.ProviderConfig{Options: map[string]string{"group": "g", "private": "maybe"}}, "private"},
+		{"bad int", provider.ProviderConfig{Options: map[string]string{"group": "g", "page_size": "lots"}}, "page_size"},
+		{"bad url", provider.ProviderConfig{BaseURL: "git.example.com", Options: map[string]string{"group": "g"}}, "base_url"},
+		{"unknown option", provider.ProviderConfig{Options: map[string]string{"group": "g", "colour": "red"}}, "colour"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testSchema.Validate(tt.cfg)
+
+			var cfgErr *provider.ConfigError
+			if !errors.As(err, &cfgErr) {
+				t.Fatalf("Validate() error = %v, want ConfigError", err)
+			}
+
+			if cfgErr.Field != tt.field {
+				t.Errorf("ConfigError.Field = %q, want %q", cfgErr.Field, tt.field)
+			}
+		})
+	}
+}
+
+func TestRegistryNewSourceControlProviderValidates(t *testing.T) {
+	r := provider.NewProviderRegistry()
+
+	var received provider.ProviderConfig
+
+	factory := func(cfg provider.ProviderConfig) (provider.SourceControlProvider, error) {
+		received = cfg
+		return &MockSourceControlProvider{Type: cfg.Type}, nil
+	}
+
+	if err := r.RegisterSourceControlProviderSchema(provider.ProviderGitLab, testSchema); err == nil {
+		t.Error("registering a schema before its factory should fail")
+	}
+
+	if err := r.RegisterSourceControlProviderFactory(provider.ProviderGitLab, factory); err != nil {
+		t.Fatalf("RegisterSourceControlProviderFactory failed: %v", err)
+	}
+
+	if err := r.RegisterSourceControlProviderSchema(provider.ProviderGitLab, testSchema); err != nil {
+		t.Fatalf("RegisterSourceControlProviderSchema failed: %v", err)
+	}
+
+	if _, err := r.NewSourceControlProvider(provider.ProviderConfig{Type: provider.ProviderGitLab}); err == nil {
+		t.Fatal("NewSourceControlProvider() should reject a config missing a required option")
+	}
+
+	if received.Type != "" {
+		t.Fatal("factory was called with an invalid config")
+	}
+
+	if _, err := r.NewSourceControlProvider(provider.ProviderConfig{Type: provider.ProviderGitLab, Options: map[string]string{"group": "team"}}); err != nil {
+		t.Fatalf("NewSourceControlProvider() error: %v", err)
+	}
+
+	if received.BaseURL != "https://git.example.com" || received.Options["private"] != "true" {
+		t.Errorf("factory received %+v, want defaults applied", received)
+	}
+}
+
+func TestBuiltinProviderSchemas(t *testing.T) {
+	r := provider.NewProviderRegistry()
+
+	if err := gitea.Register(r); err != nil {
+		t.Fatalf("gitea.Register() error: %v", err)
+	}
+
+	if err := local.Register(r); err != nil {
+		t.Fatalf("local.Register() error: %v", err)
+	}
+
+	if _, ok := r.GetSourceControlProviderSchema(provider.ProviderGitea); !ok {
+		t.Error("gitea schema not registered")
+	}
+
+	if _, err := r.NewSourceControlProvider(provider.ProviderConfig{Type: provider.ProviderLocal}); err == nil {
+		t.Error("local provider without a root should be rejected")
+	}
+
+	if _, err := r.NewSourceControlProvider(provider.ProviderConfig{Type: provider.ProviderGitea, Options: map[string]string{gitea.OptionPrivate: "false"}}); err != nil {
+		t.Errorf("gitea provider with defaults: %v", err)
+	}
+}
@@ -26,8 +26,38 @@ func TestDetectProviderType(t *testing.T) {
 		{"gitea https", "https://gitea.com/user/repo.git", provider.ProviderGitea},
 		{"gitea self-hosted", "https://gitea.myserver.com/user/repo.git", provider.ProviderGitea},
 
+		// Bitbucket
+		{"bitbucket cloud https", "https://bitbucket.org/workspace/repo.git", provider.ProviderBitbucketCloud},
+		{"bitbucket cloud ssh", "git@bitbucket.org:workspace/repo.git", provider.ProviderBitbucketCloud},
+		{"bitbucket data center", "https://bitbucket.example.com/scm/proj/repo.git", provider.ProviderBitbucketDataCenter},
+
+		// Azure DevOps
+		{"azure https", "https://dev.azure.com/contoso/Web/_git/portal", provider.ProviderAzureDevOps},
+		{"azure https with user", "https://contoso@dev.azure.com/contoso/Web/_git/portal", provider.ProviderAzureDevOps},
+		{"azure ssh", "git@ssh.dev.azure.com:v3/contoso/Web/portal", provider.ProviderAzureDevOps},
+		{"azure legacy https", "https://contoso.visualstudio.com/DefaultCollection/Web/_git/portal", provider.ProviderAzureDevOps},
+		{"azure legacy ssh", "contoso@vs-ssh.visualstudio.com:v3/contoso/Web/portal", provider.ProviderAzureDevOps},
+
+		// Gerrit
+		{"gerrit googlesource", "https://go.googlesource.com/tools", provider.ProviderGerrit},
+		{"gerrit self-hosted", "https://gerrit.example.org/a/project", provider.ProviderGerrit},
+
+		// SourceHut
+		{"sourcehut https", "https://git.sr.ht/~sircmpwn/scdoc", provider.ProviderSourceHut},
+		{"sourcehut ssh", "git@git.sr.ht:~sircmpwn/scdoc", provider.ProviderSourceHut},
+
+		// Local
+		{"local path", "/srv/git/team/repo.git", provider.ProviderLocal},
+		{"local file url", "file:///srv/git/team/repo.git", provider.ProviderLocal},
+
+		// Public hosts on other software
+		{"codeberg", "https://codeberg.org/forgejo/forgejo.git", provider.ProviderGitea},
+		{"framagit", "git@framagit.org:user/repo.git", provider.ProviderGitLab},
+
 		// Unknown
 		{"unknown provider", "https://example.com/user/repo.git", ""},
+		{"lookalike suffix", "https://notgithub.com/user/repo.git", ""},
+		{"lookalike prefix", "https://github.com.evil/user/repo.git", ""},
 		{"empty url", "", ""},
 		{"invalid url", "://invalid", ""},
 	}
@@ -41,3 +71,26 @@ func TestDetectProviderType(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveProviderType(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected provider.ProviderType
+	}{
+		{"known host", "https://github.com/user/repo.git", provider.ProviderGitHub},
+		{"unknown https host", "https://example.com/user/repo.git", provider.ProviderPlainGit},
+		{"unknown scp host", "git@git.example.com:tools/build.git", provider.ProviderPlainGit},
+		{"local path", "/srv/git/repo.git", provider.ProviderLocal},
+		{"empty url", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := provider.ResolveProviderType(tt.url)
+			if got != tt.expected {
+				t.Errorf("ResolveProviderType(%q) = %q, want %q", tt.url, got, tt.expected)
+			}
+		})
+	}
+}
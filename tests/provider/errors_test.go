@@ -0,0 +1,130 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"GitSyncer/core/git"
+	"GitSyncer/core/provider"
+)
+
+func TestErrorFromStatus(t *testing.T) {
+	tests := []struct {
+		status    int
+		check     func(error) bool
+		retryable bool
+		permanent bool
+	}{
+		{http.StatusUnauthorized, func(err error) bool { var e *provider.AuthError; return errors.As(err, &e) }, false, true},
+		{http.StatusForbidden, func(err error) bool { var e *provider.PermissionDeniedError; return errors.As(err, &e) }, false, true},
+		{http.StatusNotFound, provider.IsNotFound, false, true},
+		{http.StatusGone, provider.IsNotFound, false, true},
+		{http.StatusConflict, func(err error) bool { var e *provider.ConflictError; return errors.As(err, &e) }, false, true},
+		{http.StatusRequestEntityTooLarge, func(err error) bool { var e *provider.TooLargeError; return errors.As(err, &e) }, false, true},
+		{http.StatusTooManyRequests, func(err error) bool { var e *provider.RateLimitError; return errors.As(err, &e) }, true, false},
+		{http.StatusInternalServerError, func(err error) bool { var e *provider.UnavailableError; return errors.As(err, &e) }, true, false},
+		{http.StatusServiceUnavailable, func(err error) bool { var e *provider.UnavailableError; return errors.As(err, &e) }, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			err := provider.ErrorFromStatus(provider.ProviderGitHub, tt.status, "body", 5*time.Second)
+			if !tt.check(err) {
+				t.Fatalf("ErrorFromStatus(%d) = %T %v", tt.status, err, err)
+			}
+
+			// Wrapping must not hide the classification.
+			wrapped := fmt.Errorf("sync: %w", err)
+
+			if provider.IsRetryable(wrapped) != tt.retryable || provider.IsPermanent(wrapped) != tt.permanent {
+				t.Errorf("IsRetryable = %v, IsPermanent = %v, want %v and %v", provider.IsRetryable(wrapped), provider.IsPermanent(wrapped), tt.retryable, tt.permanent)
+			}
+
+			if tt.retryable && provider.RetryDelay(wrapped) != 5*time.Second {
+				t.Errorf("RetryDelay() = %v, want 5s", provider.RetryDelay(wrapped))
+			}
+		})
+	}
+
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnprocessableEntity} {
+		if err := provider.ErrorFromStatus(provider.ProviderGitHub, status, "", 0); err != nil {
+			t.Errorf("ErrorFromStatus(%d) = %v, want nil", status, err)
+		}
+	}
+}
+
+func TestClassifyGitError(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		check  func(error) bool
+	}{
+		{"ssh key rejected", "git@example.com: Permission denied (publickey).\nfatal: Could not read from remote repository.", func(err error) bool {
+			var e *provider.AuthError
+			return errors.As(err, &e)
+		}},
+		{"https auth", "remote: HTTP Basic: Access denied\nfatal: Authentication failed for 'https://gitlab.com/a/b.git/'", func(err error) bool {
+			var e *provider.AuthError
+			return errors.As(err, &e)
+		}},
+		{"no write access", "remote: Permission to octo/repo.git denied to bot.\nfatal: unable to access 'https://github.com/octo/repo.git/': The requested URL returned error: 403", func(err error) bool {
+			var e *provider.PermissionDeniedError
+			return errors.As(err, &e)
+		}},
+		{"protected branch", " ! [remote rejected] main -> main (pre-receive hook declined)\nremote: GitLab: You are not allowed to force push code to a protected branch on this project.", func(err error) bool {
+			var e *provider.PermissionDeniedError
+			return errors.As(err, &e)
+		}},
+		{"non-fast-forward", " ! [rejected]        main -> main (non-fast-forward)\nerror: failed to push some refs", func(err error) bool {
+			var e *provider.ConflictError
+			return errors.As(err, &e)
+		}},
+		{"missing repository", "remote: Repository not found.\nfatal: repository 'https://github.com/octo/missing.git/' not found", provider.IsNotFound},
+		{"missing local path", "fatal: '/srv/git/missing.git' does not appear to be a git repository", provider.IsNotFound},
+		{"missing local clone source", "fatal: repository '/srv/git/hello.wiki.git' does not exist", provider.IsNotFound},
+		{"large file", "remote: error: GH001: Large files detected. You may want to try Git Large File Storage.", func(err error) bool {
+			var e *provider.TooLargeError
+			return errors.As(err, &e)
+		}},
+		{"http 413", "error: RPC failed; HTTP 413 curl 22 The requested URL returned error: 413\nfatal: the remote end hung up unexpectedly", func(err error) bool {
+			var e *provider.TooLargeError
+			return errors.As(err, &e)
+		}},
+		{"bad gateway", "fatal: unable to access 'https://git.example.com/a.git/': The requested URL returned error: 502", func(err error) bool {
+			var e *provider.UnavailableError
+			return errors.As(err, &e)
+		}},
+		{"dns", "fatal: unable to access 'https://nowhere.invalid/a.git/': Could not resolve host: nowhere.invalid", func(err error) bool {
+			var e *provider.NetworkError
+			return errors.As(err, &e)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmdErr := &git.CommandError{Args: []string{"push", "--mirror"}, Stderr: tt.stderr, Err: errors.New("exit status 128")}
+
+			err := provider.ClassifyGitError(provider.ProviderGitHub, fmt.Errorf("mirror: %w", cmdErr))
+			if !tt.check(err) {
+				t.Fatalf("ClassifyGitError() = %T %v", err, err)
+			}
+
+			if !errors.Is(err, cmdErr.Err) {
+				t.Error("classified error does not wrap the command error")
+			}
+		})
+	}
+
+	unclear := &git.CommandError{Stderr: "fatal: something odd happened", Err: errors.New("exit status 1")}
+	if err := provider.ClassifyGitError(provider.ProviderGitHub, unclear); err != unclear {
+		t.Errorf("ClassifyGitError() with unknown stderr = %v, want it unchanged", err)
+	}
+
+	if err := provider.ClassifyGitError(provider.ProviderGitHub, context.Canceled); err != context.Canceled {
+		t.Errorf("ClassifyGitError(context.Canceled) = %v", err)
+	}
+}
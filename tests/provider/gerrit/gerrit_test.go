@@ -0,0 +1,162 @@
+package gerrit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/gerrit"
+)
+
+// newTestServer returns an httptest stand-in for a Gerrit instance under the /r context path.
+// Every JSON body carries the ")]}'" XSSI prefix, and authenticated calls live under /a/.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	writeJSON := func(w http.ResponseWriter, body string) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, ")]}'\n"+body)
+	}
+
+	mux.HandleFunc("/r/config/server/version", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, `"3.9.1"`)
+	})
+
+	mux.HandleFunc("/r/a/", func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "alice" || pass != "http-password" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		switch r.URL.Path {
+		case "/r/a/accounts/self":
+			writeJSON(w, `{"_account_id":1000,"username":"alice"}`)
+		case "/r/a/projects/":
+			if r.URL.Query().Get("n") == "" {
+				t.Error("projects request should set a page size")
+			}
+
+			if r.URL.Query().Get("S") != "0" {
+				writeJSON(w, `{}`)
+
+				return
+			}
+
+			writeJSON(w, `{
+				"tools/build":{"id":"tools%2Fbuild","state":"ACTIVE","description":"Build tooling"},
+				"All-Projects":{"id":"All-Projects","state":"ACTIVE"},
+				"All-Users":{"id":"All-Users","state":"ACTIVE"},
+				"secret":{"id":"secret","state":"HIDDEN"},
+				"app":{"id":"app","state":"READ_ONLY"}
+			}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestListReposStripsXSSIPrefix(t *testing.T) {
+	srv := newTestServer(t)
+
+	p, err := gerrit.New(provider.ProviderConfig{Type: provider.ProviderGerrit, BaseURL: srv.URL + "/r/"})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	ctx := context.Background()
+
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeBasic, AuthData: "alice:http-password"}); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	repos, err := p.ListRepos(ctx)
+	if err != nil {
+		t.Fatalf("ListRepos() error: %v", err)
+	}
+
+	if len(repos) != 2 {
+		t.Fatalf("ListRepos() = %+v, want 2 repos (system and hidden projects skipped)", repos)
+	}
+
+	if repos[0].Name != "app" || repos[1].Name != "tools/build" || repos[1].Description != "Build tooling" {
+		t.Errorf("unexpected repos: %+v", repos)
+	}
+
+	if want := srv.URL + "/r/a/tools/build"; repos[1].CloneURL != want {
+		t.Errorf("CloneURL = %q, want %q", repos[1].CloneURL, want)
+	}
+}
+
+func TestListReposStopsOnRepeatedPage(t *testing.T) {
+	requests := 0
+
+	// A server that ignores S and always claims there are more projects.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if requests > 10 {
+			t.Error("ListRepos() keeps requesting the same page")
+			fmt.Fprint(w, ")]}'\n{}")
+
+			return
+		}
+
+		fmt.Fprint(w, ")]}'\n"+`{"app":{"id":"app","state":"ACTIVE"},"lib":{"id":"lib","state":"ACTIVE","_more_projects":true}}`)
+	}))
+	defer srv.Close()
+
+	p, err := gerrit.New(provider.ProviderConfig{Type: provider.ProviderGerrit, BaseURL: srv.URL})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	repos, err := p.ListRepos(context.Background())
+	if err != nil {
+		t.Fatalf("ListRepos() error: %v", err)
+	}
+
+	if len(repos) != 2 || requests != 2 {
+		t.Errorf("ListRepos() = %+v after %d requests, want app and lib after 2", repos, requests)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	srv := newTestServer(t)
+
+	p, err := gerrit.New(provider.ProviderConfig{Type: provider.ProviderGerrit, BaseURL: srv.URL + "/r"})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	ctx := context.Background()
+
+	if err := p.Authenticate(ctx, nil); err != nil {
+		t.Errorf("anonymous Authenticate() error: %v", err)
+	}
+
+	err = p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeBasic, AuthData: "alice:wrong"})
+
+	var authErr *provider.AuthError
+	if !errors.As(err, &authErr) {
+		t.Errorf("Authenticate() with wrong password = %v, want AuthError", err)
+	}
+
+	err = p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeToken, AuthData: "token"})
+	if !errors.As(err, &authErr) {
+		t.Errorf("Authenticate() with token = %v, want AuthError", err)
+	}
+}
@@ -0,0 +1,797 @@
+package github_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/github"
+)
+
+const testToken = "ghp_test"
+
+// newTestServer returns an httptest stand-in for the GitHub API serving two pages of repositories.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	var srv *httptest.Server
+
+	mux.HandleFunc("/api/v3/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"message":"Bad credentials"}`)
+
+			return
+		}
+
+		fmt.Fprint(w, `{"login":"octocat"}`)
+	})
+
+	mux.HandleFunc("/api/v3/user/repos", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("affiliation") != "owner,collaborator,organization_member" {
+			t.Errorf("unexpected affiliation %q", r.URL.Query().Get("affiliation"))
+		}
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+		var repos []map[string]any
+
+		switch page {
+		case 0, 1:
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v3/user/repos?affiliation=owner%%2Ccollaborator%%2Corganization_member&per_page=100&page=2>; rel="next"`, srv.URL))
+			repos = []map[string]any{
+				{"full_name": "octocat/hello", "clone_url": "https://github.com/octocat/hello.git", "default_branch": "main"},
+				{"full_name": "org/tool", "clone_url": "https://github.com/org/tool.git", "description": "a tool", "default_branch": "master", "has_wiki": true},
+			}
+		case 2:
+			repos = []map[string]any{
+				{"full_name": "friend/shared", "clone_url": "https://github.com/friend/shared.git", "mirror_url": "https://example.com/x.git", "default_branch": "main"},
+			}
+		}
+
+		_ = json.NewEncoder(w).Encode(repos)
+	})
+
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func newProvider(t *testing.T, baseURL string) provider.SourceControlProvider {
+	t.Helper()
+
+	p, err := github.New(provider.ProviderConfig{Type: provider.ProviderGitHub, BaseURL: baseURL})
+	if err != nil {
+		t.Fatalf("github.New() error: %v", err)
+	}
+
+	return p
+}
+
+func TestAuthenticate(t *testing.T) {
+	srv := newTestServer(t)
+	p := newProvider(t, srv.URL)
+	ctx := context.Background()
+
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeToken, AuthData: testToken}); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeToken, AuthData: "wrong"})
+
+	var authErr *provider.AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("Authenticate() with bad token = %v, want AuthError", err)
+	}
+
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeSSHKey, AuthData: "key"}); !errors.As(err, &authErr) {
+		t.Fatalf("Authenticate() with ssh key = %v, want AuthError", err)
+	}
+}
+
+func TestListReposPaginates(t *testing.T) {
+	srv := newTestServer(t)
+	p := newProvider(t, srv.URL)
+	ctx := context.Background()
+
+	if _, err := p.ListRepos(ctx); err == nil {
+		t.Fatal("ListRepos() before Authenticate should fail")
+	}
+
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeToken, AuthData: testToken}); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	repos, err := p.ListRepos(ctx)
+	if err != nil {
+		t.Fatalf("ListRepos() error: %v", err)
+	}
+
+	if len(repos) != 3 {
+		t.Fatalf("ListRepos() returned %d repos, want 3", len(repos))
+	}
+
+	if repos[1].Name != "org/tool" || repos[1].Description != "a tool" || repos[1].DefaultBranch != "master" {
+		t.Errorf("unexpected repo mapping: %+v", repos[1])
+	}
+
+	if !repos[1].HasWiki || repos[1].WikiCloneURL != "https://github.com/org/tool.wiki.git" || repos[0].HasWiki {
+		t.Errorf("wiki mapping: %+v, %+v", repos[0], repos[1])
+	}
+
+	if !repos[2].IsMirror {
+		t.Error("repo with mirror_url should be marked as mirror")
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	reset := time.Now().Add(90 * time.Second).Unix()
+
+	tests := []struct {
+		name   string
+		status int
+		header map[string]string
+		check  func(error) bool
+	}{
+		{"rate limit 403", http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(reset, 10)}, func(err error) bool {
+			var rl *provider.RateLimitError
+			return errors.As(err, &rl) && rl.RetryAfter > 0
+		}},
+		{"secondary rate limit 403", http.StatusForbidden, map[string]string{"Retry-After": "60", "X-RateLimit-Remaining": "4000"}, func(err error) bool {
+			var rl *provider.RateLimitError
+			return errors.As(err, &rl) && rl.RetryAfter == time.Minute && provider.IsRetryable(err) && !provider.IsPermanent(err)
+		}},
+		{"rate limit 429", http.StatusTooManyRequests, map[string]string{"Retry-After": "30"}, func(err error) bool {
+			var rl *provider.RateLimitError
+			return errors.As(err, &rl) && rl.RetryAfter == 30*time.Second
+		}},
+		{"unauthorized", http.StatusUnauthorized, nil, func(err error) bool {
+			var ae *provider.AuthError
+			return errors.As(err, &ae) && provider.IsPermanent(err)
+		}},
+		{"forbidden", http.StatusForbidden, nil, func(err error) bool {
+			var pe *provider.PermissionDeniedError
+			return errors.As(err, &pe) && provider.IsPermanent(err)
+		}},
+		{"not found", http.StatusNotFound, nil, func(err error) bool {
+			return provider.IsNotFound(err) && provider.IsPermanent(err)
+		}},
+		{"conflict", http.StatusConflict, nil, func(err error) bool {
+			var ce *provider.ConflictError
+			return errors.As(err, &ce)
+		}},
+		{"too large", http.StatusRequestEntityTooLarge, nil, func(err error) bool {
+			var te *provider.TooLargeError
+			return errors.As(err, &te)
+		}},
+		{"server error", http.StatusBadGateway, nil, func(err error) bool {
+			var ue *provider.UnavailableError
+			return errors.As(err, &ue) && provider.IsRetryable(err) && !provider.IsPermanent(err)
+		}},
+		{"unexpected status", http.StatusUnprocessableEntity, nil, func(err error) bool {
+			return err != nil && !provider.IsRetryable(err) && !provider.IsPermanent(err)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.header {
+					w.Header().Set(k, v)
+				}
+
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			p := newProvider(t, srv.URL)
+
+			err := p.Authenticate(context.Background(), &models.Credential{AuthType: models.AuthTypeToken, AuthData: testToken})
+			if !tt.check(err) {
+				t.Errorf("Authenticate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRateLimitsAreReported(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4321")
+		w.Header().Set("X-RateLimit-Resource", "core")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		fmt.Fprint(w, `{"login":"octocat"}`)
+	}))
+	defer srv.Close()
+
+	p := newProvider(t, srv.URL)
+
+	reporter, ok := p.(provider.RateLimitReporter)
+	if !ok {
+		t.Fatal("github provider does not implement RateLimitReporter")
+	}
+
+	if got := reporter.RateLimits(); len(got) != 0 {
+		t.Fatalf("RateLimits() before any request = %+v", got)
+	}
+
+	if err := p.Authenticate(context.Background(), &models.Credential{AuthType: models.AuthTypeToken, AuthData: testToken}); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	got := reporter.RateLimits()
+	if len(got) != 1 || got[0].Resource != "core" || got[0].Remaining != 4321 || got[0].Limit != 5000 {
+		t.Errorf("RateLimits() = %+v", got)
+	}
+}
+
+func TestNetworkError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	p := newProvider(t, srv.URL)
+
+	err := p.Authenticate(context.Background(), &models.Credential{AuthType: models.AuthTypeToken, AuthData: testToken})
+
+	var ne *provider.NetworkError
+	if !errors.As(err, &ne) {
+		t.Fatalf("Authenticate() against closed server = %v, want NetworkError", err)
+	}
+}
+
+func TestCloneAndPushMirror(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source")
+	target := filepath.Join(dir, "target.git")
+
+	runGit(t, "", "init", "-q", "-b", "main", source)
+	runGit(t, source, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "initial")
+	runGit(t, "", "init", "-q", "--bare", target)
+
+	p := newProvider(t, "")
+	ctx := context.Background()
+
+	repo := &models.Repository{Name: "source", CloneURL: source}
+
+	if err := p.CloneRepo(ctx, repo, filepath.Join(dir, "cache", "source.git")); err != nil {
+		t.Fatalf("CloneRepo() error: %v", err)
+	}
+
+	if repo.LocalPath == "" {
+		t.Fatal("CloneRepo() should record LocalPath")
+	}
+
+	// A second clone into the same path updates in place.
+	if err := p.CloneRepo(ctx, repo, repo.LocalPath); err != nil {
+		t.Fatalf("CloneRepo() update error: %v", err)
+	}
+
+	if err := p.PushMirror(ctx, repo, target); err != nil {
+		t.Fatalf("PushMirror() error: %v", err)
+	}
+
+	out, err := exec.Command("git", "-C", target, "rev-parse", "refs/heads/main").CombinedOutput()
+	if err != nil {
+		t.Fatalf("target is missing main branch: %v: %s", err, out)
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	p := newProvider(t, "")
+
+	if !p.ValidateURL("git@github.com:user/repo.git") {
+		t.Error("expected github.com URL to validate")
+	}
+
+	if p.ValidateURL("https://gitlab.com/user/repo.git") {
+		t.Error("expected gitlab.com URL to be rejected")
+	}
+
+	ghe := newProvider(t, "https://ghe.example.com")
+	if !ghe.ValidateURL("https://ghe.example.com/team/repo.git") {
+		t.Error("expected Enterprise host URL to validate")
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
+
+func TestListReposWithOptions(t *testing.T) {
+	mux := http.NewServeMux()
+
+	queries := make(map[string]string)
+
+	mux.HandleFunc("/api/v3/user", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login":"octocat"}`)
+	})
+
+	mux.HandleFunc("/api/v3/orgs/{owner}/repos", func(w http.ResponseWriter, r *http.Request) {
+		queries[r.URL.Path] = r.URL.RawQuery
+
+		if r.PathValue("owner") != "acme" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+
+			return
+		}
+
+		// Sorted by push time: the listing must stop at the first repository older than the cutoff.
+		fmt.Fprint(w, `[
+			{"full_name":"acme/new","owner":{"login":"acme"},"visibility":"internal","fork":true,"pushed_at":"2026-05-01T00:00:00Z"},
+			{"full_name":"acme/archived","owner":{"login":"acme"},"visibility":"private","fork":true,"archived":true,"pushed_at":"2026-04-01T00:00:00Z"},
+			{"full_name":"acme/old","owner":{"login":"acme"},"private":true,"fork":true,"pushed_at":"2025-01-01T00:00:00Z"}
+		]`)
+	})
+
+	mux.HandleFunc("/api/v3/users/{owner}/repos", func(w http.ResponseWriter, r *http.Request) {
+		queries[r.URL.Path] = r.URL.RawQuery
+		fmt.Fprint(w, `[{"full_name":"hubot/tool","owner":{"login":"hubot"},"private":false}]`)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	p := newProvider(t, srv.URL)
+	ctx := context.Background()
+
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeToken, AuthData: testToken}); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	lister := p.(provider.RepoLister)
+	fork, archived := true, false
+
+	page, err := lister.ListReposWithOptions(ctx, provider.ListReposOptions{
+		Owner:       "acme",
+		Fork:        &fork,
+		Archived:    &archived,
+		PushedSince: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		PageSize:    20,
+	})
+	if err != nil {
+		t.Fatalf("ListReposWithOptions() error: %v", err)
+	}
+
+	if len(page.Repos) != 1 || page.Repos[0].Name != "acme/new" || page.Repos[0].Visibility != models.VisibilityInternal || page.NextCursor != "" {
+		t.Fatalf("ListReposWithOptions() = %+v", page)
+	}
+
+	if got := queries["/api/v3/orgs/acme/repos"]; got != "direction=desc&per_page=20&sort=pushed&type=forks" {
+		t.Errorf("org query = %q", got)
+	}
+
+	page, err = lister.ListReposWithOptions(ctx, provider.ListReposOptions{Owner: "hubot", Visibility: models.VisibilityPublic})
+	if err != nil {
+		t.Fatalf("ListReposWithOptions() for a user error: %v", err)
+	}
+
+	if len(page.Repos) != 1 || page.Repos[0].Owner != "hubot" || page.Repos[0].Visibility != models.VisibilityPublic {
+		t.Fatalf("ListReposWithOptions() for a user = %+v", page)
+	}
+
+	if got := queries["/api/v3/users/hubot/repos"]; got != "per_page=100" {
+		t.Errorf("user query = %q, want the org-only type filter dropped", got)
+	}
+
+	outside := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("cursor on another host was requested")
+	}))
+	t.Cleanup(outside.Close)
+
+	if _, err := lister.ListReposWithOptions(ctx, provider.ListReposOptions{Cursor: outside.URL + "/api/v3/orgs/acme/repos?page=2"}); err == nil {
+		t.Error("ListReposWithOptions() accepted a cursor on another host")
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	srv := newTestServer(t)
+
+	type hook struct {
+		ID     int64          `json:"id"`
+		Name   string         `json:"name,omitempty"`
+		Active bool           `json:"active"`
+		Events []string       `json:"events"`
+		Config map[string]any `json:"config"`
+	}
+
+	hooks := map[string]*hook{}
+
+	mux := srv.Config.Handler.(*http.ServeMux)
+	mux.HandleFunc("/api/v3/repos/octocat/hello/hooks", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			list := []*hook{}
+			for _, h := range hooks {
+				list = append(list, h)
+			}
+
+			_ = json.NewEncoder(w).Encode(list)
+		case http.MethodPost:
+			var h hook
+			_ = json.NewDecoder(r.Body).Decode(&h)
+
+			if h.Name != "web" || h.Config["content_type"] != "json" || h.Config["secret"] != "s3cret" {
+				t.Errorf("create webhook body = %+v", h)
+			}
+
+			h.ID = int64(len(hooks) + 1)
+			hooks[strconv.FormatInt(h.ID, 10)] = &h
+
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(h)
+		}
+	})
+	mux.HandleFunc("/api/v3/repos/octocat/hello/hooks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		h, ok := hooks[r.PathValue("id")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPatch:
+			_ = json.NewDecoder(r.Body).Decode(h)
+			_ = json.NewEncoder(w).Encode(h)
+		case http.MethodDelete:
+			delete(hooks, r.PathValue("id"))
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	p := newProvider(t, srv.URL)
+	ctx := context.Background()
+	repo := &models.Repository{Name: "octocat/hello", CloneURL: "https://github.com/octocat/hello.git"}
+
+	m, ok := p.(provider.WebhookManager)
+	if !ok {
+		t.Fatal("provider does not implement WebhookManager")
+	}
+
+	if _, err := m.ListWebhooks(ctx, repo); err == nil {
+		t.Fatal("ListWebhooks() before Authenticate should fail")
+	}
+
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeToken, AuthData: testToken}); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	created, err := m.CreateWebhook(ctx, repo, provider.WebhookConfig{URL: "https://sync.example.com/webhooks/1", Secret: "s3cret"})
+	if err != nil {
+		t.Fatalf("CreateWebhook() error: %v", err)
+	}
+
+	if created.ID != "1" || created.URL != "https://sync.example.com/webhooks/1" || !created.Active {
+		t.Errorf("CreateWebhook() = %+v", created)
+	}
+
+	updated, err := m.UpdateWebhook(ctx, repo, created.ID, provider.WebhookConfig{URL: "https://new.example.com/webhooks/1", Secret: "s3cret"})
+	if err != nil || updated.URL != "https://new.example.com/webhooks/1" {
+		t.Fatalf("UpdateWebhook() = %+v, %v", updated, err)
+	}
+
+	listed, err := m.ListWebhooks(ctx, repo)
+	if err != nil || len(listed) != 1 || listed[0].URL != updated.URL {
+		t.Fatalf("ListWebhooks() = %+v, %v", listed, err)
+	}
+
+	if err := m.DeleteWebhook(ctx, repo, created.ID); err != nil {
+		t.Fatalf("DeleteWebhook() error: %v", err)
+	}
+
+	if err := m.DeleteWebhook(ctx, repo, created.ID); !provider.IsNotFound(err) {
+		t.Errorf("DeleteWebhook() of a missing hook = %v, want NotFoundError", err)
+	}
+}
+
+func TestRepoManager(t *testing.T) {
+	srv := newTestServer(t)
+
+	repos := map[string]map[string]any{}
+	topics := map[string][]string{}
+
+	mux := srv.Config.Handler.(*http.ServeMux)
+	mux.HandleFunc("/api/v3/orgs/{owner}/repos", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		if body["private"] != true || body["auto_init"] != false || body["homepage"] != "https://hello.example.com" {
+			t.Errorf("create repository body = %+v", body)
+		}
+
+		name := r.PathValue("owner") + "/" + body["name"].(string)
+		repos[name] = map[string]any{
+			"full_name":   name,
+			"clone_url":   "https://github.com/" + name + ".git",
+			"description": body["description"],
+			"homepage":    body["homepage"],
+			"private":     body["private"],
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(repos[name])
+	})
+	mux.HandleFunc("/api/v3/repos/{owner}/{name}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("owner") + "/" + r.PathValue("name")
+
+		repo, ok := repos[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+
+			return
+		}
+
+		if r.Method == http.MethodPatch {
+			_ = json.NewDecoder(r.Body).Decode(&repo)
+		}
+
+		repo["topics"] = topics[name]
+		_ = json.NewEncoder(w).Encode(repo)
+	})
+	mux.HandleFunc("PUT /api/v3/repos/{owner}/{name}/topics", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Names []string `json:"names"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		topics[r.PathValue("owner")+"/"+r.PathValue("name")] = body.Names
+		_ = json.NewEncoder(w).Encode(body)
+	})
+
+	p := newProvider(t, srv.URL)
+	ctx := context.Background()
+
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeToken, AuthData: testToken}); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	m, ok := p.(provider.RepoManager)
+	if !ok {
+		t.Fatal("provider does not implement RepoManager")
+	}
+
+	meta := provider.RepoMetadata{Description: "Hello", Homepage: "https://hello.example.com", Topics: []string{"Demo", "go"}}
+
+	created, isNew, err := m.EnsureRepo(ctx, "backups", "hello", meta)
+	if err != nil || !isNew || created.CloneURL != "https://github.com/backups/hello.git" {
+		t.Fatalf("EnsureRepo() = %+v, %v, %v", created, isNew, err)
+	}
+
+	if got := topics["backups/hello"]; len(got) != 2 || got[0] != "demo" {
+		t.Errorf("topics after EnsureRepo() = %v, want them lowercased", got)
+	}
+
+	if _, isNew, err := m.EnsureRepo(ctx, "backups", "hello", meta); err != nil || isNew {
+		t.Errorf("second EnsureRepo() = %v, %v, want the existing repository", isNew, err)
+	}
+
+	meta.Description, meta.Visibility, meta.DefaultBranch, meta.Topics = "Hello, world", models.VisibilityPublic, "main", nil
+	if err := m.UpdateRepo(ctx, created, meta); err != nil {
+		t.Fatalf("UpdateRepo() error: %v", err)
+	}
+
+	got, err := m.GetRepo(ctx, created)
+	if err != nil {
+		t.Fatalf("GetRepo() error: %v", err)
+	}
+
+	if got.Description != "Hello, world" || got.Visibility != models.VisibilityPublic || got.DefaultBranch != "main" || len(got.Topics) != 0 {
+		t.Errorf("GetRepo() after UpdateRepo() = %+v", got)
+	}
+}
+
+func TestReleases(t *testing.T) {
+	srv := newTestServer(t)
+
+	releases := []map[string]any{}
+	assets := map[string][]byte{"1": []byte("binary")}
+
+	mux := srv.Config.Handler.(*http.ServeMux)
+	mux.HandleFunc("GET /api/v3/repos/octocat/hello/releases", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(releases)
+	})
+	mux.HandleFunc("POST /api/v3/repos/octocat/hello/releases", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		body["id"] = len(releases) + 1
+		body["assets"] = []any{}
+		releases = append(releases, body)
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(body)
+	})
+	mux.HandleFunc("PATCH /api/v3/repos/octocat/hello/releases/{id}", func(w http.ResponseWriter, r *http.Request) {
+		rel := releases[0]
+		_ = json.NewDecoder(r.Body).Decode(&rel)
+
+		_ = json.NewEncoder(w).Encode(rel)
+	})
+	mux.HandleFunc("POST /api/uploads/repos/octocat/hello/releases/{id}/assets", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/octet-stream" || r.ContentLength != 4 {
+			t.Errorf("upload Content-Type = %q, length %d", r.Header.Get("Content-Type"), r.ContentLength)
+		}
+
+		data, _ := io.ReadAll(r.Body)
+		id := strconv.Itoa(len(assets) + 1)
+		assets[id] = data
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":     len(assets),
+			"name":   r.URL.Query().Get("name"),
+			"size":   len(data),
+			"digest": "sha256:abc",
+			"url":    srv.URL + "/api/v3/repos/octocat/hello/releases/assets/" + id,
+		})
+	})
+	mux.HandleFunc("GET /api/v3/repos/octocat/hello/releases/assets/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "application/octet-stream" {
+			t.Errorf("download Accept = %q", r.Header.Get("Accept"))
+		}
+
+		_, _ = w.Write(assets[r.PathValue("id")])
+	})
+	mux.HandleFunc("DELETE /api/v3/repos/octocat/hello/releases/assets/{id}", func(w http.ResponseWriter, r *http.Request) {
+		delete(assets, r.PathValue("id"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	p := newProvider(t, srv.URL)
+	ctx := context.Background()
+
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeToken, AuthData: testToken}); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	m, ok := p.(provider.ReleaseManager)
+	if !ok {
+		t.Fatal("provider does not implement ReleaseManager")
+	}
+
+	repo := &models.Repository{Name: "octocat/hello", CloneURL: "https://github.com/octocat/hello.git"}
+
+	rel, err := m.CreateRelease(ctx, repo, provider.ReleaseConfig{TagName: "v1.0.0", Name: "One", Body: "notes", Prerelease: true})
+	if err != nil || rel.ID != "1" || rel.TagName != "v1.0.0" || !rel.Prerelease {
+		t.Fatalf("CreateRelease() = %+v, %v", rel, err)
+	}
+
+	if rel, err = m.UpdateRelease(ctx, repo, rel.ID, provider.ReleaseConfig{TagName: "v1.0.0", Name: "One", Body: "more notes"}); err != nil || rel.Body != "more notes" || rel.Prerelease {
+		t.Fatalf("UpdateRelease() = %+v, %v", rel, err)
+	}
+
+	asset, err := m.UploadReleaseAsset(ctx, repo, rel.ID, "tool.tar.gz", strings.NewReader("data"), 4)
+	if err != nil || asset.ID != "2" || asset.Name != "tool.tar.gz" || asset.Size != 4 || asset.SHA256 != "abc" {
+		t.Fatalf("UploadReleaseAsset() = %+v, %v", asset, err)
+	}
+
+	r, err := m.DownloadReleaseAsset(ctx, repo, *asset)
+	if err != nil {
+		t.Fatalf("DownloadReleaseAsset() error: %v", err)
+	}
+
+	data, _ := io.ReadAll(r)
+	r.Close()
+
+	if string(data) != "data" {
+		t.Errorf("DownloadReleaseAsset() = %q, want the uploaded contents", data)
+	}
+
+	if err := m.DeleteReleaseAsset(ctx, repo, rel.ID, *asset); err != nil || assets["2"] != nil {
+		t.Errorf("DeleteReleaseAsset() = %v, assets %v", err, assets)
+	}
+
+	listed, err := m.ListReleases(ctx, repo)
+	if err != nil || len(listed) != 1 || listed[0].Name != "One" {
+		t.Errorf("ListReleases() = %+v, %v", listed, err)
+	}
+}
+
+func TestDiscussions(t *testing.T) {
+	srv := newTestServer(t)
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	mux := srv.Config.Handler.(*http.ServeMux)
+	mux.HandleFunc("GET /api/v3/repos/octocat/hello/labels", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name":"bug","color":"d73a4a","description":"Something is wrong"}]`)
+	})
+	mux.HandleFunc("GET /api/v3/repos/octocat/hello/milestones", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("state") != "all" {
+			t.Errorf("milestones state = %q", r.URL.Query().Get("state"))
+		}
+
+		fmt.Fprint(w, `[{"title":"v1","state":"closed","due_on":"2026-04-30T07:00:00Z"}]`)
+	})
+	mux.HandleFunc("GET /api/v3/repos/octocat/hello/issues", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("state") != "all" || q.Get("since") != "2026-03-01T00:00:00Z" || q.Get("sort") != "updated" {
+			t.Errorf("issues query = %v", q)
+		}
+
+		fmt.Fprint(w, `[
+			{"number":1,"title":"Crash","state":"open","user":{"login":"alice"},"labels":[{"name":"bug"}],
+			 "milestone":{"title":"v1"},"assignees":[{"login":"bob"}],"created_at":"2026-02-01T00:00:00Z","updated_at":"2026-03-02T00:00:00Z"},
+			{"number":2,"title":"Fix crash","state":"closed","user":{"login":"bob"},"labels":[],"assignees":[],
+			 "pull_request":{"merged_at":"2026-03-03T00:00:00Z"},"created_at":"2026-03-02T00:00:00Z","updated_at":"2026-03-03T00:00:00Z"}
+		]`)
+	})
+	mux.HandleFunc("GET /api/v3/repos/octocat/hello/issues/comments", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"id":10,"body":"Same here","user":{"login":"carol"},"issue_url":"%s/api/v3/repos/octocat/hello/issues/1"}]`, srv.URL)
+	})
+	mux.HandleFunc("GET /api/v3/repos/octocat/hello/pulls/comments", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"id":20,"body":"Nit","user":{"login":"alice"},"pull_request_url":"%s/api/v3/repos/octocat/hello/pulls/2"}]`, srv.URL)
+	})
+
+	p := newProvider(t, srv.URL)
+	ctx := context.Background()
+
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeToken, AuthData: testToken}); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	e, ok := p.(provider.DiscussionExporter)
+	if !ok {
+		t.Fatal("provider does not implement DiscussionExporter")
+	}
+
+	repo := &models.Repository{Name: "octocat/hello", CloneURL: "https://github.com/octocat/hello.git"}
+
+	if labels, err := e.ListLabels(ctx, repo); err != nil || len(labels) != 1 || labels[0].Color != "d73a4a" {
+		t.Errorf("ListLabels() = %+v, %v", labels, err)
+	}
+
+	if milestones, err := e.ListMilestones(ctx, repo); err != nil || len(milestones) != 1 || milestones[0].DueOn != "2026-04-30" {
+		t.Errorf("ListMilestones() = %+v, %v", milestones, err)
+	}
+
+	issues, err := e.ListIssues(ctx, repo, since)
+	if err != nil || len(issues) != 2 {
+		t.Fatalf("ListIssues() = %+v, %v", issues, err)
+	}
+
+	if got := issues[0]; got.PullRequest || got.Author != "alice" || got.Milestone != "v1" || got.Labels[0] != "bug" || got.Assignees[0] != "bob" {
+		t.Errorf("issue = %+v", got)
+	}
+
+	if got := issues[1]; !got.PullRequest || got.MergedAt == nil {
+		t.Errorf("pull request = %+v", got)
+	}
+
+	comments, err := e.ListComments(ctx, repo, since)
+	if err != nil || len(comments) != 2 {
+		t.Fatalf("ListComments() = %+v, %v", comments, err)
+	}
+
+	if comments[0].IssueNumber != 1 || comments[0].PullRequest || comments[1].IssueNumber != 2 || !comments[1].PullRequest {
+		t.Errorf("ListComments() = %+v", comments)
+	}
+}
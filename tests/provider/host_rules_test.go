@@ -0,0 +1,98 @@
+package provider_test
+
+import (
+	"testing"
+
+	"GitSyncer/core/provider"
+)
+
+func TestHostRuleMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		host    string
+		matches bool
+	}{
+		{"git.example.com", "git.example.com", true},
+		{"git.example.com", "GIT.example.com", true},
+		{"git.example.com", "sub.git.example.com", false},
+		{"*.github.com", "github.com", true},
+		{"*.github.com", "ssh.github.com", true},
+		{"*.github.com", "notgithub.com", false},
+		{"*.github.com", "github.com.evil", false},
+		{"gitlab.*", "gitlab.example.com", true},
+		{"gitlab.*", "mygitlab.example.com", false},
+		{"gitlab.*", "gitlab", false},
+	}
+
+	for _, tt := range tests {
+		rule := provider.HostRule{Pattern: tt.pattern, Type: provider.ProviderGitLab}
+		if got := rule.Matches(tt.host); got != tt.matches {
+			t.Errorf("HostRule{%q}.Matches(%q) = %v, want %v", tt.pattern, tt.host, got, tt.matches)
+		}
+	}
+}
+
+func TestMatchHostRulePrefersSpecific(t *testing.T) {
+	rules := []provider.HostRule{
+		{Pattern: "gitlab.*", Type: provider.ProviderGitLab},
+		{Pattern: "*.example.com", Type: provider.ProviderGitea},
+		{Pattern: "*.corp.example.com", Type: provider.ProviderGerrit},
+		{Pattern: "gitlab.corp.example.com", Type: provider.ProviderPlainGit},
+	}
+
+	tests := []struct {
+		host     string
+		expected provider.ProviderType
+	}{
+		{"gitlab.corp.example.com", provider.ProviderPlainGit},
+		{"review.corp.example.com", provider.ProviderGerrit},
+		{"gitlab.example.com", provider.ProviderGitea},
+		{"gitlab.other.org", provider.ProviderGitLab},
+	}
+
+	for _, tt := range tests {
+		rule, ok := provider.MatchHostRule(tt.host, rules)
+		if !ok || rule.Type != tt.expected {
+			t.Errorf("MatchHostRule(%q) = %q, %v; want %q", tt.host, rule.Type, ok, tt.expected)
+		}
+	}
+
+	if _, ok := provider.MatchHostRule("git.other.org", rules); ok {
+		t.Error("MatchHostRule() matched an unrelated host")
+	}
+}
+
+func TestValidateHostPattern(t *testing.T) {
+	valid := []string{"git.example.com", "*.example.com", "gitlab.*", "localhost"}
+	invalid := []string{"", "*", "*.", ".*", "*.example.*", "git.*.com", "gitlab.example.*", "https://git.example.com", "git.example.com/path", "..com"}
+
+	for _, p := range valid {
+		if err := provider.ValidateHostPattern(p); err != nil {
+			t.Errorf("ValidateHostPattern(%q) error: %v", p, err)
+		}
+	}
+
+	for _, p := range invalid {
+		if err := provider.ValidateHostPattern(p); err == nil {
+			t.Errorf("ValidateHostPattern(%q) should fail", p)
+		}
+	}
+}
+
+func TestDetectProviderTypeWithRules(t *testing.T) {
+	rules := []provider.HostRule{{Pattern: "github.com", Type: provider.ProviderGitea, APIURL: "https://mirror.example/api/v1"}}
+
+	rule, ok := provider.DetectProviderTypeWithRules("git@github.com:user/repo.git", rules)
+	if !ok || rule.Type != provider.ProviderGitea || rule.APIURL != "https://mirror.example/api/v1" {
+		t.Errorf("custom rule not applied: %+v", rule)
+	}
+
+	rule, ok = provider.DetectProviderTypeWithRules("https://codeberg.org/user/repo", rules)
+	if !ok || rule.Type != provider.ProviderGitea {
+		t.Errorf("built-in rule not applied: %+v", rule)
+	}
+
+	if _, ok := provider.DetectProviderTypeWithRules("https://git.example.com/repo.git", rules); ok {
+		t.Error("unknown host should not match")
+	}
+}
@@ -0,0 +1,136 @@
+package provider_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+)
+
+func TestListReposOptionsMatches(t *testing.T) {
+	pushed := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	yes, no := true, false
+
+	repo := models.Repository{
+		Name:       "acme/platform/api",
+		Owner:      "acme/platform",
+		Visibility: models.VisibilityPrivate,
+		IsFork:     true,
+		PushedAt:   &pushed,
+	}
+
+	tests := []struct {
+		name string
+		opts provider.ListReposOptions
+		want bool
+	}{
+		{"no filters", provider.ListReposOptions{}, true},
+		{"exact owner", provider.ListReposOptions{Owner: "acme/platform"}, true},
+		{"parent namespace", provider.ListReposOptions{Owner: "ACME"}, true},
+		{"owner prefix is not a namespace", provider.ListReposOptions{Owner: "acm"}, false},
+		{"visibility", provider.ListReposOptions{Visibility: models.VisibilityPrivate}, true},
+		{"other visibility", provider.ListReposOptions{Visibility: models.VisibilityPublic}, false},
+		{"not archived", provider.ListReposOptions{Archived: &no}, true},
+		{"archived", provider.ListReposOptions{Archived: &yes}, false},
+		{"forks only", provider.ListReposOptions{Fork: &yes}, true},
+		{"sources only", provider.ListReposOptions{Fork: &no}, false},
+		{"pushed since earlier", provider.ListReposOptions{PushedSince: pushed.Add(-time.Hour)}, true},
+		{"pushed since later", provider.ListReposOptions{PushedSince: pushed.Add(time.Hour)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.Matches(repo); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if (provider.ListReposOptions{PushedSince: pushed}).Matches(models.Repository{}) {
+		t.Error("a repository without PushedAt should not match a PushedSince filter")
+	}
+}
+
+func TestListReposPageFiltersClientSide(t *testing.T) {
+	calls := 0
+
+	mock := &MockSourceControlProvider{
+		ListReposFn: func(ctx context.Context) ([]models.Repository, error) {
+			calls++
+
+			return []models.Repository{
+				{Name: "a", Visibility: models.VisibilityPublic},
+				{Name: "b", Visibility: models.VisibilityPrivate},
+				{Name: "c", Visibility: models.VisibilityPublic},
+				{Name: "d", Visibility: models.VisibilityPublic},
+			}, nil
+		},
+	}
+
+	ctx := context.Background()
+	opts := provider.ListReposOptions{Visibility: models.VisibilityPublic, PageSize: 2}
+
+	page, err := provider.ListReposPage(ctx, mock, opts)
+	if err != nil {
+		t.Fatalf("ListReposPage() error: %v", err)
+	}
+
+	if len(page.Repos) != 2 || page.Repos[0].Name != "a" || page.Repos[1].Name != "c" || page.NextCursor == "" {
+		t.Fatalf("first page = %+v", page)
+	}
+
+	opts.Cursor = page.NextCursor
+
+	page, err = provider.ListReposPage(ctx, mock, opts)
+	if err != nil {
+		t.Fatalf("ListReposPage() error: %v", err)
+	}
+
+	if len(page.Repos) != 1 || page.Repos[0].Name != "d" || page.NextCursor != "" {
+		t.Fatalf("second page = %+v", page)
+	}
+
+	all, err := provider.ListAllRepos(ctx, mock, provider.ListReposOptions{Visibility: models.VisibilityPublic, PageSize: 1})
+	if err != nil || len(all) != 3 {
+		t.Fatalf("ListAllRepos() = %+v, %v", all, err)
+	}
+
+	if _, err := provider.ListReposPage(ctx, mock, provider.ListReposOptions{Cursor: "next"}); err == nil {
+		t.Error("ListReposPage() should reject a malformed offset cursor")
+	}
+
+	if calls == 0 {
+		t.Error("expected the fallback to call ListRepos")
+	}
+}
+
+type pagedMock struct {
+	MockSourceControlProvider
+	opts []provider.ListReposOptions
+}
+
+func (m *pagedMock) ListReposWithOptions(ctx context.Context, opts provider.ListReposOptions) (*provider.RepoPage, error) {
+	m.opts = append(m.opts, opts)
+
+	return &provider.RepoPage{Repos: []models.Repository{{Name: "server-side"}}}, nil
+}
+
+func TestListReposPageUsesRepoLister(t *testing.T) {
+	mock := &pagedMock{}
+	mock.ListReposFn = func(ctx context.Context) ([]models.Repository, error) {
+		t.Error("ListRepos should not be called for a RepoLister")
+
+		return nil, nil
+	}
+
+	page, err := provider.ListReposPage(context.Background(), mock, provider.ListReposOptions{Owner: "acme"})
+	if err != nil || len(page.Repos) != 1 || page.Repos[0].Name != "server-side" {
+		t.Fatalf("ListReposPage() = %+v, %v", page, err)
+	}
+
+	if len(mock.opts) != 1 || mock.opts[0].Owner != "acme" {
+		t.Errorf("options not passed through: %+v", mock.opts)
+	}
+}
@@ -0,0 +1,194 @@
+package local_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/local"
+)
+
+// seedBareRepo creates a bare repository at path with a single commit on main.
+func seedBareRepo(t *testing.T, path, description string) {
+	t.Helper()
+
+	runGit(t, "", "init", "--bare", "-b", "main", path)
+
+	if description != "" {
+		if err := os.WriteFile(filepath.Join(path, "description"), []byte(description+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	work := t.TempDir()
+	runGit(t, work, "clone", path, ".")
+	runGit(t, work, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "--allow-empty", "-m", "initial")
+	runGit(t, work, "push", "origin", "HEAD:main")
+}
+
+func newProvider(t *testing.T, baseURL string) provider.SourceControlProvider {
+	t.Helper()
+
+	p, err := local.New(provider.ProviderConfig{Type: provider.ProviderLocal, BaseURL: baseURL})
+	if err != nil {
+		t.Fatalf("New(%q) error: %v", baseURL, err)
+	}
+
+	if err := p.Authenticate(context.Background(), nil); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	return p
+}
+
+func TestListReposScansTree(t *testing.T) {
+	root := t.TempDir()
+
+	seedBareRepo(t, filepath.Join(root, "team", "api.git"), "Public API")
+	seedBareRepo(t, filepath.Join(root, "tools.git"), "")
+	seedBareRepo(t, filepath.Join(root, "tools.wiki.git"), "")
+
+	// A working tree must not be reported, nor its .git directory.
+	runGit(t, "", "init", filepath.Join(root, "checkout"))
+
+	repos, err := newProvider(t, "file://"+filepath.ToSlash(root)).ListRepos(context.Background())
+	if err != nil {
+		t.Fatalf("ListRepos() error: %v", err)
+	}
+
+	if len(repos) != 2 {
+		t.Fatalf("ListRepos() = %+v, want 2 repos", repos)
+	}
+
+	if repos[0].Name != "team/api" || repos[0].Description != "Public API" || repos[0].DefaultBranch != "main" {
+		t.Errorf("unexpected repo mapping: %+v", repos[0])
+	}
+
+	if repos[1].Name != "tools" || repos[1].Description != "" || repos[1].CloneURL != filepath.Join(root, "tools.git") {
+		t.Errorf("unexpected repo mapping: %+v", repos[1])
+	}
+
+	// The wiki next to tools.git belongs to it rather than being a repository of its own.
+	if repos[0].HasWiki || !repos[1].HasWiki || repos[1].WikiCloneURL != filepath.Join(root, "tools.wiki.git") {
+		t.Errorf("wiki mapping: %+v, %+v", repos[0], repos[1])
+	}
+}
+
+func TestListReposMissingRoot(t *testing.T) {
+	repos, err := newProvider(t, filepath.Join(t.TempDir(), "absent")).ListRepos(context.Background())
+	if err != nil || len(repos) != 0 {
+		t.Fatalf("ListRepos() = %+v, %v; want no repos and no error", repos, err)
+	}
+}
+
+// TestMirrorBetweenLocalRoots is the offline reference flow: list a source, clone each repository
+// into a work directory and push it to a target root that does not exist yet.
+func TestMirrorBetweenLocalRoots(t *testing.T) {
+	ctx := context.Background()
+	sourceRoot := t.TempDir()
+	targetRoot := filepath.Join(t.TempDir(), "backup")
+	workDir := t.TempDir()
+
+	seedBareRepo(t, filepath.Join(sourceRoot, "team", "api.git"), "")
+
+	source := newProvider(t, sourceRoot)
+	target := newProvider(t, targetRoot)
+
+	repos, err := source.ListRepos(ctx)
+	if err != nil {
+		t.Fatalf("ListRepos() error: %v", err)
+	}
+
+	for i := range repos {
+		repo := &repos[i]
+
+		if err := source.CloneRepo(ctx, repo, filepath.Join(workDir, repo.Name+".git")); err != nil {
+			t.Fatalf("CloneRepo() error: %v", err)
+		}
+
+		if !target.ValidateURL(filepath.Join(targetRoot, repo.Name+".git")) {
+			t.Errorf("ValidateURL() rejected a path below the target root")
+		}
+
+		if err := target.PushMirror(ctx, repo, repo.Name+".git"); err != nil {
+			t.Fatalf("PushMirror() error: %v", err)
+		}
+	}
+
+	mirrored, err := target.ListRepos(ctx)
+	if err != nil || len(mirrored) != 1 || mirrored[0].Name != "team/api" {
+		t.Fatalf("target ListRepos() = %+v, %v", mirrored, err)
+	}
+
+	out, err := exec.Command("git", "--git-dir", mirrored[0].CloneURL, "log", "--format=%s", "main").CombinedOutput()
+	if err != nil || strings.TrimSpace(string(out)) != "initial" {
+		t.Fatalf("mirrored main = %q, %v", out, err)
+	}
+}
+
+func TestPushMirrorWithoutCreateMissing(t *testing.T) {
+	ctx := context.Background()
+	sourceRoot := t.TempDir()
+	targetRoot := t.TempDir()
+
+	seedBareRepo(t, filepath.Join(sourceRoot, "api.git"), "")
+
+	p, err := local.New(provider.ProviderConfig{
+		Type:    provider.ProviderLocal,
+		BaseURL: targetRoot,
+		Options: map[string]string{local.OptionCreateMissing: "false"},
+	})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	repos, err := newProvider(t, sourceRoot).ListRepos(ctx)
+	if err != nil || len(repos) != 1 {
+		t.Fatalf("ListRepos() = %+v, %v", repos, err)
+	}
+
+	if err := p.PushMirror(ctx, &repos[0], "api.git"); err == nil {
+		t.Fatal("PushMirror() to a missing repository should fail when create_missing is false")
+	}
+}
+
+func TestNewAndValidateURL(t *testing.T) {
+	if _, err := local.New(provider.ProviderConfig{Type: provider.ProviderLocal, BaseURL: "https://example.com/git"}); err == nil {
+		t.Error("New() should reject a remote base url")
+	}
+
+	root := t.TempDir()
+	p := newProvider(t, root)
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{filepath.Join(root, "team", "api.git"), true},
+		{"file://" + filepath.ToSlash(filepath.Join(root, "api.git")), true},
+		{root, false},
+		{filepath.Join(filepath.Dir(root), "elsewhere.git"), false},
+		{"git@example.com:team/api.git", false},
+	}
+
+	for _, tt := range tests {
+		if got := p.ValidateURL(tt.url); got != tt.want {
+			t.Errorf("ValidateURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
@@ -0,0 +1,201 @@
+package plaingit_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/plaingit"
+)
+
+const gitoliteInfo = "hello alice, this is git@git.example.com running gitolite3 v3.6.12 on git 2.39.5\n\n" +
+	" R W\ttools/build\n" +
+	" R  \tdocs\n" +
+	" R W C\tusers/CREATOR/..*\n" +
+	"    W\twrite-only\n"
+
+// fakeSSH puts an ssh stand-in on PATH that records its arguments and runs script.
+func fakeSSH(t *testing.T, script string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+
+	body := "#!/bin/sh\necho \"$@\" > " + argsFile + "\n" + script
+	if err := os.WriteFile(filepath.Join(dir, "ssh"), []byte(body), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return argsFile
+}
+
+func TestListReposFromGitolite(t *testing.T) {
+	argsFile := fakeSSH(t, "cat <<'INFO'\n"+gitoliteInfo+"INFO\n")
+
+	p, err := plaingit.New(provider.ProviderConfig{
+		Type:    provider.ProviderPlainGit,
+		BaseURL: "ssh://git@git.example.com:2222",
+		Options: map[string]string{plaingit.OptionGitolite: "true"},
+	})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	ctx := context.Background()
+
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeSSHKey, AuthData: "not-a-real-key"}); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	repos, err := p.ListRepos(ctx)
+	if err != nil {
+		t.Fatalf("ListRepos() error: %v", err)
+	}
+
+	want := []models.Repository{
+		{Name: "tools/build", CloneURL: "ssh://git@git.example.com:2222/tools/build.git"},
+		{Name: "docs", CloneURL: "ssh://git@git.example.com:2222/docs.git"},
+	}
+
+	if len(repos) != len(want) {
+		t.Fatalf("ListRepos() = %+v, want %+v", repos, want)
+	}
+
+	for i := range want {
+		if !reflect.DeepEqual(repos[i], want[i]) {
+			t.Errorf("repos[%d] = %+v, want %+v", i, repos[i], want[i])
+		}
+	}
+
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(args); !strings.Contains(got, "-p 2222") || !strings.Contains(got, "git@git.example.com info") {
+		t.Errorf("ssh invoked with %q", got)
+	}
+}
+
+func TestAuthenticateRejectedBySSH(t *testing.T) {
+	fakeSSH(t, "echo 'git@git.example.com: Permission denied (publickey).' >&2\nexit 255\n")
+
+	p, err := plaingit.New(provider.ProviderConfig{
+		Type:    provider.ProviderPlainGit,
+		BaseURL: "git@git.example.com",
+		Options: map[string]string{plaingit.OptionGitolite: "true"},
+	})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	err = p.Authenticate(context.Background(), &models.Credential{AuthType: models.AuthTypeSSHKey, AuthData: "not-a-real-key"})
+
+	var authErr *provider.AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("Authenticate() = %v, want AuthError", err)
+	}
+}
+
+func TestNewRejectsInvalidGitoliteAddress(t *testing.T) {
+	for _, baseURL := range []string{
+		"https://git.example.com",
+		// Would be parsed by ssh as an option that runs a local command.
+		"-oProxyCommand=touch /tmp/pwned@x",
+		"ssh://-oProxyCommand=sh@git.example.com",
+	} {
+		_, err := plaingit.New(provider.ProviderConfig{
+			Type:    provider.ProviderPlainGit,
+			BaseURL: baseURL,
+			Options: map[string]string{plaingit.OptionGitolite: "true"},
+		})
+		if err == nil {
+			t.Errorf("New() should reject the gitolite address %q", baseURL)
+		}
+	}
+}
+
+func TestCloneAndPushConfiguredList(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "source.git")
+	target := filepath.Join(dir, "target.git")
+
+	runGit(t, dir, "init", "--bare", "-b", "main", source)
+	runGit(t, dir, "init", "--bare", target)
+
+	work := filepath.Join(dir, "work")
+	runGit(t, dir, "clone", source, work)
+	runGit(t, work, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "--allow-empty", "-m", "initial")
+	runGit(t, work, "push", "origin", "HEAD:main")
+
+	p, err := plaingit.New(provider.ProviderConfig{
+		Type:    provider.ProviderPlainGit,
+		Options: map[string]string{plaingit.OptionRepos: source + "\n"},
+	})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	ctx := context.Background()
+
+	if err := p.Authenticate(ctx, nil); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	repos, err := p.ListRepos(ctx)
+	if err != nil || len(repos) != 1 || repos[0].CloneURL != source {
+		t.Fatalf("ListRepos() = %+v, %v", repos, err)
+	}
+
+	repo := repos[0]
+
+	if err := p.CloneRepo(ctx, &repo, filepath.Join(dir, "mirror.git")); err != nil {
+		t.Fatalf("CloneRepo() error: %v", err)
+	}
+
+	if err := p.PushMirror(ctx, &repo, target); err != nil {
+		t.Fatalf("PushMirror() error: %v", err)
+	}
+
+	out, err := exec.Command("git", "--git-dir", target, "rev-parse", "refs/heads/main").CombinedOutput()
+	if err != nil {
+		t.Fatalf("target is missing main: %v: %s", err, out)
+	}
+}
+
+func TestCapabilitiesReportNoAPI(t *testing.T) {
+	p, err := plaingit.New(provider.ProviderConfig{Type: provider.ProviderPlainGit})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	for _, c := range p.Capabilities() {
+		if c == provider.CapabilityAPI || c == provider.CapabilityWebhooks || c == provider.CapabilityMirror {
+			t.Errorf("plain git provider should not report %q", c)
+		}
+	}
+
+	if !p.ValidateURL("git@git.example.com:tools/build.git") || !p.ValidateURL("https://cgit.example.org/repo.git") {
+		t.Error("ValidateURL() should accept any git URL with a host")
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
@@ -0,0 +1,370 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/github"
+	"GitSyncer/core/provider/plugin"
+)
+
+// envPluginType makes the test binary serve the fake plugin under the given provider type.
+const envPluginType = "GITSYNCER_TEST_PLUGIN"
+
+const forgeType provider.ProviderType = "forge"
+
+func TestMain(m *testing.M) {
+	if pt := os.Getenv(envPluginType); pt != "" {
+		if err := plugin.Serve(fakePlugin(provider.ProviderType(pt)), os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+func fakePlugin(pt provider.ProviderType) plugin.Plugin {
+	return plugin.Plugin{
+		Type: pt,
+		SourceControl: func(cfg provider.ProviderConfig) (provider.SourceControlProvider, error) {
+			return &fakeForge{pt: pt, cfg: cfg}, nil
+		},
+		SourceControlSchema: &provider.ConfigSchema{
+			BaseURL: provider.OptionSpec{Type: provider.OptionTypeURL, Required: true},
+			Options: []provider.OptionSpec{
+				{Name: "repos", Type: provider.OptionTypeList, Required: true},
+				{Name: "block", Type: provider.OptionTypeBool, Default: "false"},
+			},
+		},
+		Storage: func(cfg provider.ProviderConfig) (provider.StorageProvider, error) {
+			return &fakeStorage{root: cfg.BaseURL}, nil
+		},
+	}
+}
+
+type fakeForge struct {
+	pt  provider.ProviderType
+	cfg provider.ProviderConfig
+}
+
+func (f *fakeForge) Authenticate(ctx context.Context, cred *models.Credential) error {
+	if cred == nil || cred.AuthData != "secret" {
+		return &provider.AuthError{Provider: f.pt, Message: "bad token"}
+	}
+
+	return nil
+}
+
+func (f *fakeForge) ListRepos(ctx context.Context) ([]models.Repository, error) {
+	if f.cfg.Options["block"] == "true" {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	var repos []models.Repository
+	for _, name := range strings.Split(f.cfg.Options["repos"], ",") {
+		repos = append(repos, models.Repository{Name: name, CloneURL: f.cfg.BaseURL + "/" + name + ".git"})
+	}
+
+	return repos, nil
+}
+
+func (f *fakeForge) CloneRepo(ctx context.Context, repo *models.Repository, destPath string) error {
+	repo.LocalPath = destPath
+	return nil
+}
+
+func (f *fakeForge) PushMirror(ctx context.Context, repo *models.Repository, remoteURL string) error {
+	return &provider.RateLimitError{Provider: f.pt, Message: "slow down", RetryAfter: 30 * time.Second}
+}
+
+func (f *fakeForge) ValidateURL(rawURL string) bool {
+	return strings.HasPrefix(rawURL, f.cfg.BaseURL)
+}
+
+func (f *fakeForge) GetProviderType() provider.ProviderType {
+	return f.pt
+}
+
+func (f *fakeForge) Capabilities() []provider.SourceControlProviderCapability {
+	return []provider.SourceControlProviderCapability{provider.CapabilityAPI, provider.CapabilityTokenAuth}
+}
+
+type fakeStorage struct {
+	root string
+}
+
+func (s *fakeStorage) Authenticate(ctx context.Context, cred *models.Credential) error {
+	return nil
+}
+
+func (s *fakeStorage) Upload(ctx context.Context, localPath string, remotePath string) error {
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(s.root, remotePath), data, 0o644)
+}
+
+func (s *fakeStorage) Download(ctx context.Context, remotePath string, localPath string) error {
+	data, err := os.ReadFile(filepath.Join(s.root, remotePath))
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(localPath, data, 0o644)
+}
+
+func (s *fakeStorage) List(ctx context.Context, prefix string) ([]provider.StorageObject, error) {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []provider.StorageObject
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), prefix) {
+			info, _ := e.Info()
+			objects = append(objects, provider.StorageObject{Path: e.Name(), Size: info.Size()})
+		}
+	}
+
+	return objects, nil
+}
+
+func (s *fakeStorage) Delete(ctx context.Context, remotePath string) error {
+	return os.Remove(filepath.Join(s.root, remotePath))
+}
+
+func (s *fakeStorage) GetQuota(ctx context.Context) (*provider.QuotaInfo, error) {
+	return &provider.QuotaInfo{TotalBytes: 100, UsedBytes: 40, FreeBytes: 60}, nil
+}
+
+// writePlugin writes an executable to dir that runs the test binary as a plugin serving pt.
+func writePlugin(t *testing.T, dir, name string, pt provider.ProviderType) {
+	t.Helper()
+
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable: %v", err)
+	}
+
+	script := fmt.Sprintf("#!/bin/sh\n%s=%s exec %q\n", envPluginType, pt, exe)
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+		t.Fatalf("write plugin: %v", err)
+	}
+}
+
+func loadForge(t *testing.T) *provider.ProviderRegistry {
+	t.Helper()
+
+	dir := t.TempDir()
+	writePlugin(t, dir, "forge", forgeType)
+
+	r := provider.NewProviderRegistry()
+
+	manifests, err := plugin.Load(context.Background(), r, dir)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if len(manifests) != 1 || manifests[0].Type != forgeType {
+		t.Fatalf("Load() = %+v", manifests)
+	}
+
+	return r
+}
+
+func newForge(t *testing.T, r *provider.ProviderRegistry, options map[string]string) *plugin.SourceControl {
+	t.Helper()
+
+	p, err := r.NewSourceControlProvider(provider.ProviderConfig{Type: forgeType, BaseURL: "https://forge.internal", Options: options})
+	if err != nil {
+		t.Fatalf("NewSourceControlProvider() error: %v", err)
+	}
+
+	sc := p.(*plugin.SourceControl)
+	t.Cleanup(func() { sc.Close() })
+
+	return sc
+}
+
+func TestPluginSourceControl(t *testing.T) {
+	r := loadForge(t)
+
+	if _, ok := r.GetSourceControlProviderSchema(forgeType); !ok {
+		t.Fatal("plugin schema was not registered")
+	}
+
+	if _, err := r.NewSourceControlProvider(provider.ProviderConfig{Type: forgeType, BaseURL: "https://forge.internal"}); err == nil {
+		t.Fatal("config missing a required option should be rejected before launching the plugin")
+	}
+
+	p := newForge(t, r, map[string]string{"repos": "api,web"})
+	ctx := context.Background()
+
+	var authErr *provider.AuthError
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeToken, AuthData: "wrong"}); !errors.As(err, &authErr) || authErr.Provider != forgeType {
+		t.Fatalf("Authenticate() error = %v, want AuthError", err)
+	}
+
+	if err := p.Authenticate(ctx, &models.Credential{AuthType: models.AuthTypeToken, AuthData: "secret"}); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	repos, err := p.ListRepos(ctx)
+	if err != nil {
+		t.Fatalf("ListRepos() error: %v", err)
+	}
+
+	if len(repos) != 2 || repos[1].CloneURL != "https://forge.internal/web.git" {
+		t.Errorf("ListRepos() = %+v", repos)
+	}
+
+	repo := repos[0]
+	if err := p.CloneRepo(ctx, &repo, "/tmp/mirrors/api.git"); err != nil {
+		t.Fatalf("CloneRepo() error: %v", err)
+	}
+
+	if repo.LocalPath != "/tmp/mirrors/api.git" || repo.Name != "api" {
+		t.Errorf("CloneRepo() left repo as %+v", repo)
+	}
+
+	var rateErr *provider.RateLimitError
+	if err := p.PushMirror(ctx, &repo, "https://forge.internal/api.git"); !errors.As(err, &rateErr) || rateErr.RetryAfter != 30*time.Second {
+		t.Errorf("PushMirror() error = %v, want RateLimitError with RetryAfter", err)
+	}
+
+	if !p.ValidateURL("https://forge.internal/api.git") || p.ValidateURL("https://github.com/a/b") {
+		t.Error("ValidateURL() gave the wrong answer")
+	}
+
+	if p.GetProviderType() != forgeType || len(p.Capabilities()) != 2 {
+		t.Errorf("type %q, capabilities %v", p.GetProviderType(), p.Capabilities())
+	}
+}
+
+func TestPluginCancel(t *testing.T) {
+	p := newForge(t, loadForge(t), map[string]string{"repos": "api", "block": "true"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	if _, err := p.ListRepos(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ListRepos() error = %v, want deadline exceeded", err)
+	}
+
+	if time.Since(start) > 5*time.Second {
+		t.Error("cancelled call did not return promptly")
+	}
+
+	// The process stays usable after a cancelled call.
+	if !p.ValidateURL("https://forge.internal/x") {
+		t.Error("ValidateURL() after cancel failed")
+	}
+}
+
+func TestPluginStorage(t *testing.T) {
+	r := loadForge(t)
+	root := t.TempDir()
+
+	sp, err := r.NewStorageProvider(provider.ProviderConfig{Type: forgeType, BaseURL: root})
+	if err != nil {
+		t.Fatalf("NewStorageProvider() error: %v", err)
+	}
+	defer sp.(*plugin.Storage).Close()
+
+	ctx := context.Background()
+	local := filepath.Join(t.TempDir(), "bundle")
+
+	if err := os.WriteFile(local, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sp.Upload(ctx, local, "backup-1.bundle"); err != nil {
+		t.Fatalf("Upload() error: %v", err)
+	}
+
+	objects, err := sp.List(ctx, "backup-")
+	if err != nil || len(objects) != 1 || objects[0].Size != 4 {
+		t.Fatalf("List() = %+v, %v", objects, err)
+	}
+
+	restored := filepath.Join(t.TempDir(), "restored")
+	if err := sp.Download(ctx, "backup-1.bundle", restored); err != nil {
+		t.Fatalf("Download() error: %v", err)
+	}
+
+	if data, _ := os.ReadFile(restored); string(data) != "data" {
+		t.Errorf("Download() wrote %q", data)
+	}
+
+	quota, err := sp.GetQuota(ctx)
+	if err != nil || quota.FreeBytes != 60 {
+		t.Errorf("GetQuota() = %+v, %v", quota, err)
+	}
+
+	if err := sp.Delete(ctx, "backup-1.bundle"); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+
+	if err := sp.Delete(ctx, "backup-1.bundle"); err == nil {
+		t.Error("Delete() of a missing object should fail")
+	}
+}
+
+func TestLoadSkipsBrokenAndConflictingPlugins(t *testing.T) {
+	dir := t.TempDir()
+
+	writePlugin(t, dir, "forge", forgeType)
+	writePlugin(t, dir, "shadow-github", provider.ProviderGitHub)
+
+	if err := os.WriteFile(filepath.Join(dir, "broken"), []byte("#!/bin/sh\necho not json\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("not executable"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := provider.NewProviderRegistry()
+	if err := github.Register(r); err != nil {
+		t.Fatalf("github.Register() error: %v", err)
+	}
+
+	manifests, err := plugin.Load(context.Background(), r, dir)
+	if err == nil {
+		t.Fatal("Load() should report the broken and conflicting plugins")
+	}
+
+	for _, name := range []string{"broken", "shadow-github"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Load() error does not mention %s: %v", name, err)
+		}
+	}
+
+	if len(manifests) != 1 || manifests[0].Type != forgeType {
+		t.Errorf("Load() registered %+v, want only forge", manifests)
+	}
+}
+
+func TestDiscoverMissingDir(t *testing.T) {
+	manifests, err := plugin.Discover(context.Background(), filepath.Join(t.TempDir(), "absent"))
+	if err != nil || manifests != nil {
+		t.Errorf("Discover() = %v, %v; want nothing", manifests, err)
+	}
+}
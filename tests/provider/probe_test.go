@@ -0,0 +1,85 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"GitSyncer/core/provider"
+)
+
+func TestProbeProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		status   int
+		body     string
+		expected provider.ProviderType
+		api      string
+		version  string
+	}{
+		{"gitlab anonymous", "/api/v4/version", http.StatusUnauthorized, `{"message":"401 Unauthorized"}`, provider.ProviderGitLab, "/api/v4", ""},
+		{"gitlab open", "/api/v4/version", http.StatusOK, `{"version":"17.2.1","revision":"abc"}`, provider.ProviderGitLab, "/api/v4", "17.2.1"},
+		{"gitea", "/api/v1/version", http.StatusOK, `{"version":"1.22.0"}`, provider.ProviderGitea, "/api/v1", "1.22.0"},
+		{"github enterprise", "/api/v3/meta", http.StatusOK, `{"installed_version":"3.12.0","verifiable_password_authentication":false}`, provider.ProviderGitHub, "/api/v3", "3.12.0"},
+		{"bitbucket data center", "/rest/api/1.0/application-properties", http.StatusOK, `{"version":"8.19.1","displayName":"Bitbucket"}`, provider.ProviderBitbucketDataCenter, "/rest/api/1.0", "8.19.1"},
+		{"gerrit", "/config/server/version", http.StatusOK, ")]}'\n\"3.10.0\"", provider.ProviderGerrit, "", "3.10.0"},
+		{"unrelated json", "/api/v1/version", http.StatusOK, `{"name":"x"}`, "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path {
+					http.NotFound(w, r)
+					return
+				}
+
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			result, err := provider.ProbeProvider(context.Background(), srv.Client(), srv.URL+"/group/repo.git")
+			if err != nil {
+				t.Fatalf("ProbeProvider() error: %v", err)
+			}
+
+			if tt.expected == "" {
+				if result != nil {
+					t.Fatalf("ProbeProvider() = %+v, want nil", result)
+				}
+
+				return
+			}
+
+			if result == nil {
+				t.Fatal("ProbeProvider() = nil")
+			}
+
+			if result.Type != tt.expected || result.BaseURL != srv.URL || result.APIURL != srv.URL+tt.api || result.Version != tt.version {
+				t.Errorf("ProbeProvider() = %+v", result)
+			}
+		})
+	}
+}
+
+func TestProbeProviderUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	if _, err := provider.ProbeProvider(context.Background(), nil, srv.URL+"/repo.git"); !errors.Is(err, provider.ErrProbeUnreachable) {
+		t.Errorf("ProbeProvider() error = %v, want ErrProbeUnreachable", err)
+	}
+}
+
+func TestProbeProviderCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := provider.ProbeProvider(ctx, nil, "https://git.example.com/repo.git"); err == nil {
+		t.Error("ProbeProvider() with cancelled context should fail")
+	}
+}
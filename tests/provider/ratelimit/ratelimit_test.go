@@ -0,0 +1,243 @@
+package ratelimit_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"GitSyncer/core/provider/ratelimit"
+)
+
+var fastPolicy = ratelimit.Policy{
+	MaxRetries:  3,
+	MaxWait:     time.Second,
+	BaseBackoff: time.Millisecond,
+	MaxBackoff:  10 * time.Millisecond,
+}
+
+func get(t *testing.T, client *http.Client, url, token string) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	return resp, string(body)
+}
+
+func TestParseStatus(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	reset := now.Add(time.Hour).Unix()
+
+	tests := []struct {
+		name     string
+		header   map[string]string
+		want     int
+		resource string
+	}{
+		{"github", map[string]string{"X-RateLimit-Limit": "5000", "X-RateLimit-Remaining": "4999", "X-RateLimit-Reset": strconv.FormatInt(reset, 10), "X-RateLimit-Resource": "core"}, 4999, "core"},
+		{"gitlab", map[string]string{"RateLimit-Limit": "2000", "RateLimit-Remaining": "1999", "RateLimit-Reset": strconv.FormatInt(reset, 10)}, 1999, ""},
+		{"gitea", map[string]string{"X-RateLimit-Limit": "100", "X-RateLimit-Remaining": "42", "X-RateLimit-Reset": "3600"}, 42, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tt.header {
+				h.Set(k, v)
+			}
+
+			status, ok := ratelimit.ParseStatus(h, now)
+			if !ok {
+				t.Fatal("ParseStatus() found no rate-limit headers")
+			}
+
+			if status.Remaining != tt.want || status.Resource != tt.resource || status.Limit == 0 {
+				t.Errorf("ParseStatus() = %+v", status)
+			}
+
+			if !status.Reset.Equal(time.Unix(reset, 0)) {
+				t.Errorf("Reset = %v, want %v", status.Reset, time.Unix(reset, 0))
+			}
+		})
+	}
+
+	if _, ok := ratelimit.ParseStatus(http.Header{}, now); ok {
+		t.Error("ParseStatus() without headers reported a status")
+	}
+}
+
+func TestTransportRetriesThrottledRequests(t *testing.T) {
+	var hits atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "57")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	rl := ratelimit.New(nil, fastPolicy)
+	resp, body := get(t, &http.Client{Transport: rl}, srv.URL, "")
+
+	if resp.StatusCode != http.StatusOK || body != "ok" || hits.Load() != 3 {
+		t.Fatalf("status %d body %q after %d requests, want 200 ok after 3", resp.StatusCode, body, hits.Load())
+	}
+
+	statuses := rl.Statuses()
+	if len(statuses) != 1 || statuses[0].Remaining != 57 || statuses[0].Limit != 60 {
+		t.Errorf("Statuses() = %+v", statuses)
+	}
+}
+
+func TestTransportGivesUpOnLongWaits(t *testing.T) {
+	var hits atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	resp, _ := get(t, &http.Client{Transport: ratelimit.New(nil, fastPolicy)}, srv.URL, "")
+
+	if resp.StatusCode != http.StatusForbidden || hits.Load() != 1 {
+		t.Fatalf("status %d after %d requests, want 403 after 1", resp.StatusCode, hits.Load())
+	}
+
+	if d := ratelimit.RetryAfter(resp); d < 59*time.Minute {
+		t.Errorf("RetryAfter() = %v, want about an hour", d)
+	}
+}
+
+func TestTransportRetriesPostWithBody(t *testing.T) {
+	var hits atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("attempt %d body = %q", hits.Load()+1, body)
+		}
+
+		if hits.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: ratelimit.New(nil, fastPolicy)}
+
+	resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated || hits.Load() != 2 {
+		t.Errorf("status %d after %d requests, want 201 after 2", resp.StatusCode, hits.Load())
+	}
+}
+
+func TestTransportStopsWaitingOnCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	policy := fastPolicy
+	policy.BaseBackoff, policy.MaxBackoff = 500*time.Millisecond, 500*time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+
+	start := time.Now()
+	if _, err := (&http.Client{Transport: ratelimit.New(nil, policy)}).Do(req); err == nil {
+		t.Fatal("Do() after cancel succeeded")
+	}
+
+	if time.Since(start) > 400*time.Millisecond {
+		t.Errorf("cancelled request took %v", time.Since(start))
+	}
+}
+
+func TestTransportRevalidatesWithETag(t *testing.T) {
+	var full, notModified atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"v1-` + r.Header.Get("Authorization") + `"`
+
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.Header().Set("X-RateLimit-Remaining", "10")
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		full.Add(1)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Remaining", "11")
+		io.WriteString(w, `{"login":"octocat"}`)
+	}))
+	defer srv.Close()
+
+	rl := ratelimit.New(nil, fastPolicy)
+	client := &http.Client{Transport: rl}
+
+	get(t, client, srv.URL, "a")
+
+	resp, body := get(t, client, srv.URL, "a")
+	if resp.StatusCode != http.StatusOK || body != `{"login":"octocat"}` || resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("revalidated response = %d %q %v", resp.StatusCode, body, resp.Header)
+	}
+
+	if full.Load() != 1 || notModified.Load() != 1 {
+		t.Errorf("full=%d notModified=%d, want 1 and 1", full.Load(), notModified.Load())
+	}
+
+	if s := rl.Statuses(); len(s) != 1 || s[0].Remaining != 10 {
+		t.Errorf("Statuses() = %+v, want remaining from the 304", s)
+	}
+
+	// A different credential must not reuse the cached response.
+	get(t, client, srv.URL, "b")
+
+	if full.Load() != 2 {
+		t.Errorf("full = %d after a request with another credential, want 2", full.Load())
+	}
+}
@@ -0,0 +1,133 @@
+package sourcehut_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/sourcehut"
+)
+
+// newTestServer returns an httptest stand-in for the git.sr.ht GraphQL endpoint.
+// Repository listings are split over two cursor pages.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /query", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer srht-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"errors":[{"message":"Invalid authorization"}]}`)
+
+			return
+		}
+
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.Contains(req.Query, "me {"):
+			fmt.Fprint(w, `{"data":{"me":{"canonicalName":"~alice"}}}`)
+		case strings.Contains(req.Query, "user(username"):
+			if req.Variables["username"] != "bob" {
+				fmt.Fprint(w, `{"data":{"user":null}}`)
+
+				return
+			}
+
+			fmt.Fprint(w, `{"data":{"user":{"repositories":{"results":[{"name":"lib","owner":{"canonicalName":"~bob"}}],"cursor":null}}}}`)
+		case req.Variables["cursor"] == nil:
+			fmt.Fprint(w, `{"data":{"repositories":{"results":[{"name":"dotfiles","description":"configs","HEAD":{"name":"refs/heads/master"},"owner":{"canonicalName":"~alice"}}],"cursor":"c2"}}}`)
+		case req.Variables["cursor"] == "c2":
+			fmt.Fprint(w, `{"data":{"repositories":{"results":[{"name":"site","HEAD":null,"owner":{"canonicalName":"~alice"}}],"cursor":null}}}`)
+		default:
+			fmt.Fprint(w, `{"errors":[{"message":"unexpected query"}]}`)
+		}
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func newProvider(t *testing.T, baseURL string, options map[string]string) provider.SourceControlProvider {
+	t.Helper()
+
+	p, err := sourcehut.New(provider.ProviderConfig{Type: provider.ProviderSourceHut, BaseURL: baseURL, Options: options})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	if err := p.Authenticate(context.Background(), &models.Credential{AuthType: models.AuthTypeToken, AuthData: "srht-token"}); err != nil {
+		t.Fatalf("Authenticate() error: %v", err)
+	}
+
+	return p
+}
+
+func TestListReposFollowsCursor(t *testing.T) {
+	srv := newTestServer(t)
+
+	repos, err := newProvider(t, srv.URL, nil).ListRepos(context.Background())
+	if err != nil {
+		t.Fatalf("ListRepos() error: %v", err)
+	}
+
+	if len(repos) != 2 {
+		t.Fatalf("ListRepos() = %+v, want 2 repos", repos)
+	}
+
+	if repos[0].Name != "~alice/dotfiles" || repos[0].DefaultBranch != "master" || repos[0].CloneURL != srv.URL+"/~alice/dotfiles" {
+		t.Errorf("unexpected repo mapping: %+v", repos[0])
+	}
+
+	if repos[1].Name != "~alice/site" || repos[1].DefaultBranch != "" {
+		t.Errorf("unexpected repo mapping: %+v", repos[1])
+	}
+}
+
+func TestListReposForOwner(t *testing.T) {
+	srv := newTestServer(t)
+
+	repos, err := newProvider(t, srv.URL, map[string]string{sourcehut.OptionOwner: "bob"}).ListRepos(context.Background())
+	if err != nil || len(repos) != 1 || repos[0].Name != "~bob/lib" {
+		t.Fatalf("ListRepos() = %+v, %v", repos, err)
+	}
+
+	if _, err := newProvider(t, srv.URL, map[string]string{sourcehut.OptionOwner: "~nobody"}).ListRepos(context.Background()); err == nil {
+		t.Error("ListRepos() for a missing owner should fail")
+	}
+}
+
+func TestAuthenticateRejectsInvalidToken(t *testing.T) {
+	srv := newTestServer(t)
+
+	p, err := sourcehut.New(provider.ProviderConfig{Type: provider.ProviderSourceHut, BaseURL: srv.URL})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	err = p.Authenticate(context.Background(), &models.Credential{AuthType: models.AuthTypeToken, AuthData: "revoked"})
+
+	var authErr *provider.AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("Authenticate() = %v, want AuthError", err)
+	}
+}
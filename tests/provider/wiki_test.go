@@ -0,0 +1,51 @@
+package provider_test
+
+import (
+	"testing"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+)
+
+func TestWikiCloneURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://github.com/octo/hello.git", "https://github.com/octo/hello.wiki.git"},
+		{"https://gitlab.example.com/group/sub/tool", "https://gitlab.example.com/group/sub/tool.wiki.git"},
+		{"git@gitea.example.com:team/app.git/", "git@gitea.example.com:team/app.wiki.git"},
+		{"/srv/git/hello.git", "/srv/git/hello.wiki.git"},
+	}
+
+	for _, tt := range tests {
+		got := provider.WikiCloneURL(tt.url)
+		if got != tt.want {
+			t.Errorf("WikiCloneURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+
+		if provider.IsWikiURL(tt.url) || !provider.IsWikiURL(got) {
+			t.Errorf("IsWikiURL() misclassifies %q or %q", tt.url, got)
+		}
+	}
+}
+
+func TestWikiOf(t *testing.T) {
+	repo := &models.Repository{ID: 3, ProviderID: 1, Name: "octo/hello", CloneURL: "https://github.com/octo/hello.git"}
+
+	if provider.WikiOf(repo) != nil {
+		t.Fatal("WikiOf() of a repository without a wiki is not nil")
+	}
+
+	repo.HasWiki = true
+
+	wiki := provider.WikiOf(repo)
+	if wiki == nil || wiki.Name != "octo/hello.wiki" || wiki.CloneURL != "https://github.com/octo/hello.wiki.git" || wiki.ID != 3 {
+		t.Errorf("WikiOf() = %+v", wiki)
+	}
+
+	repo.WikiCloneURL = "https://github.com/octo/hello-docs.git"
+	if wiki := provider.WikiOf(repo); wiki.CloneURL != repo.WikiCloneURL {
+		t.Errorf("WikiOf() ignores the reported clone URL: %+v", wiki)
+	}
+}
@@ -0,0 +1,303 @@
+package service_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"GitSyncer/core/database"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/gitlab"
+	"GitSyncer/core/service"
+	"GitSyncer/core/store"
+)
+
+func newDetectionService(t *testing.T, client *http.Client) *service.DetectionService {
+	t.Helper()
+
+	db, err := database.Open(":memory:")
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	return service.NewDetectionService(store.NewSettingStore(db), store.NewHostMappingStore(db), detectionRegistry(t), client)
+}
+
+// detectionRegistry returns a registry holding the GitLab provider, the only type the tests map hosts to.
+func detectionRegistry(t *testing.T) *provider.ProviderRegistry {
+	t.Helper()
+
+	registry := provider.NewProviderRegistry()
+	if err := gitlab.Register(registry); err != nil {
+		t.Fatalf("register gitlab provider: %v", err)
+	}
+
+	return registry
+}
+
+func TestDetectKnownHostSkipsProbe(t *testing.T) {
+	svc := newDetectionService(t, nil)
+
+	result, err := svc.Detect(context.Background(), "git@github.com:user/repo.git")
+	if err != nil {
+		t.Fatalf("Detect() error: %v", err)
+	}
+
+	if result == nil || result.Type != provider.ProviderGitHub || result.APIURL != "" {
+		t.Errorf("Detect() = %+v", result)
+	}
+}
+
+func TestDetectCachesProbePerHost(t *testing.T) {
+	var hits atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+
+		if r.URL.Path != "/api/v1/version" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte(`{"version":"1.22.0"}`))
+	}))
+	defer srv.Close()
+
+	svc := newDetectionService(t, srv.Client())
+
+	for _, repo := range []string{"/team/one.git", "/team/two.git"} {
+		result, err := svc.Detect(context.Background(), srv.URL+repo)
+		if err != nil {
+			t.Fatalf("Detect(%s) error: %v", repo, err)
+		}
+
+		if result == nil || result.Type != provider.ProviderGitea || result.APIURL != srv.URL+"/api/v1" {
+			t.Fatalf("Detect(%s) = %+v", repo, result)
+		}
+	}
+
+	probed := hits.Load()
+	if probed == 0 {
+		t.Fatal("server was never probed")
+	}
+
+	// Each probe endpoint is requested once; the second repository is answered from settings.
+	if probed != 5 {
+		t.Errorf("server hit %d times, want 5", probed)
+	}
+
+	u, _ := url.Parse(srv.URL)
+	if err := svc.Forget(u.Hostname()); err != nil {
+		t.Fatalf("Forget() error: %v", err)
+	}
+
+	if _, err := svc.Detect(context.Background(), srv.URL+"/team/one.git"); err != nil {
+		t.Fatalf("Detect() after Forget error: %v", err)
+	}
+
+	if hits.Load() == probed {
+		t.Error("Forget() did not clear the cached probe")
+	}
+}
+
+func TestDetectCachesNegativeResult(t *testing.T) {
+	var hits atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	svc := newDetectionService(t, srv.Client())
+
+	for range 2 {
+		result, err := svc.Detect(context.Background(), srv.URL+"/repo.git")
+		if err != nil {
+			t.Fatalf("Detect() error: %v", err)
+		}
+
+		if result == nil || result.Type != provider.ProviderPlainGit {
+			t.Fatalf("Detect() = %+v, want plain git", result)
+		}
+	}
+
+	if n := hits.Load(); n != 5 {
+		t.Errorf("server hit %d times, want one probe per endpoint", n)
+	}
+}
+
+func TestDetectDoesNotCacheUnreachableHost(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	rawURL := srv.URL + "/repo.git"
+	srv.Close()
+
+	svc := newDetectionService(t, nil)
+
+	result, err := svc.Detect(context.Background(), rawURL)
+	if err != nil || result == nil || result.Type != provider.ProviderPlainGit {
+		t.Fatalf("Detect() = %+v, %v, want plain git", result, err)
+	}
+
+	var hits atomic.Int32
+
+	// The host comes back on the same address as a Gitea instance.
+	u, _ := url.Parse(rawURL)
+
+	listener, err := net.Listen("tcp", u.Host)
+	if err != nil {
+		t.Skipf("cannot reuse %s: %v", u.Host, err)
+	}
+
+	srv = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+
+		if r.URL.Path != "/api/v1/version" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Write([]byte(`{"version":"1.22.0"}`))
+	}))
+	srv.Listener.Close()
+	srv.Listener = listener
+	srv.Start()
+	defer srv.Close()
+
+	result, err = svc.Detect(context.Background(), rawURL)
+	if err != nil {
+		t.Fatalf("Detect() error: %v", err)
+	}
+
+	if hits.Load() == 0 || result == nil || result.Type != provider.ProviderGitea {
+		t.Errorf("Detect() after the host came back = %+v", result)
+	}
+}
+
+func TestDetectExpiresCachedProbe(t *testing.T) {
+	var hits atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	db, err := database.Open(":memory:")
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	defer db.Close()
+
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+
+	settings := store.NewSettingStore(db)
+	svc := service.NewDetectionService(settings, store.NewHostMappingStore(db), detectionRegistry(t), srv.Client())
+
+	u, _ := url.Parse(srv.URL)
+	stale := `{"result":{"type":"gitea","base_url":"` + srv.URL + `"},"probed_at":"` + time.Now().AddDate(0, -1, 0).UTC().Format(time.RFC3339) + `"}`
+
+	if err := settings.Set("provider_probe:"+u.Hostname(), stale); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+
+	result, err := svc.Detect(context.Background(), srv.URL+"/repo.git")
+	if err != nil {
+		t.Fatalf("Detect() error: %v", err)
+	}
+
+	if result == nil || result.Type != provider.ProviderPlainGit || hits.Load() == 0 {
+		t.Errorf("Detect() = %+v after %d requests, want a fresh probe", result, hits.Load())
+	}
+}
+
+func TestDetectUsesHostMappings(t *testing.T) {
+	svc := newDetectionService(t, nil)
+
+	mapping := &models.HostMapping{Pattern: "*.Corp.Example", ProviderType: string(provider.ProviderGitLab), APIURL: "https://code.corp.example/gitlab/api/v4"}
+	if err := svc.SaveMapping(mapping); err != nil {
+		t.Fatalf("SaveMapping() error: %v", err)
+	}
+
+	if mapping.ID == 0 || mapping.Pattern != "*.corp.example" {
+		t.Fatalf("SaveMapping() stored %+v", mapping)
+	}
+
+	result, err := svc.Detect(context.Background(), "git@code.corp.example:team/app.git")
+	if err != nil {
+		t.Fatalf("Detect() error: %v", err)
+	}
+
+	if result == nil || result.Type != provider.ProviderGitLab || result.APIURL != mapping.APIURL {
+		t.Errorf("Detect() = %+v", result)
+	}
+
+	// A mapping overrides the built-in rules.
+	override := &models.HostMapping{Pattern: "gitea.internal.example", ProviderType: string(provider.ProviderGitLab)}
+	if err := svc.SaveMapping(override); err != nil {
+		t.Fatalf("SaveMapping() error: %v", err)
+	}
+
+	result, err = svc.Detect(context.Background(), "https://gitea.internal.example/team/app")
+	if err != nil {
+		t.Fatalf("Detect() error: %v", err)
+	}
+
+	if result == nil || result.Type != provider.ProviderGitLab {
+		t.Errorf("Detect() = %+v, want gitlab", result)
+	}
+
+	if err := svc.DeleteMapping(override.ID); err != nil {
+		t.Fatalf("DeleteMapping() error: %v", err)
+	}
+
+	mappings, err := svc.ListMappings()
+	if err != nil {
+		t.Fatalf("ListMappings() error: %v", err)
+	}
+
+	if len(mappings) != 1 {
+		t.Errorf("ListMappings() returned %d mappings, want 1", len(mappings))
+	}
+}
+
+func TestSaveMappingValidation(t *testing.T) {
+	svc := newDetectionService(t, nil)
+
+	tests := []struct {
+		name    string
+		mapping models.HostMapping
+	}{
+		{"empty pattern", models.HostMapping{ProviderType: "gitlab"}},
+		{"inner wildcard", models.HostMapping{Pattern: "git.*.example", ProviderType: "gitlab"}},
+		{"url instead of host", models.HostMapping{Pattern: "https://git.example.com", ProviderType: "gitlab"}},
+		{"missing type", models.HostMapping{Pattern: "git.example.com"}},
+		{"unknown type", models.HostMapping{Pattern: "git.example.com", ProviderType: "gitlabb"}},
+		{"unregistered type", models.HostMapping{Pattern: "git.example.com", ProviderType: string(provider.ProviderGitea)}},
+		{"bad api url", models.HostMapping{Pattern: "git.example.com", ProviderType: "gitlab", APIURL: "git.example.com/api"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := svc.SaveMapping(&tt.mapping); err == nil {
+				t.Error("SaveMapping() should fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"GitSyncer/core/archive"
+	"GitSyncer/core/database"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/service"
+	"GitSyncer/core/store"
+)
+
+// fakeExporter is a GitHub provider whose discussions live in memory. ListIssues and
+// ListComments return the items updated at or after since, and record the since they got.
+type fakeExporter struct {
+	fakeHookManager
+
+	issues   []provider.Issue
+	comments []provider.Comment
+	since    []time.Time
+}
+
+func (e *fakeExporter) Capabilities() []provider.SourceControlProviderCapability {
+	return []provider.SourceControlProviderCapability{provider.CapabilityAPI, provider.CapabilityIssues}
+}
+
+func (e *fakeExporter) ListLabels(context.Context, *models.Repository) ([]provider.Label, error) {
+	return []provider.Label{{Name: "bug", Color: "d73a4a"}}, nil
+}
+
+func (e *fakeExporter) ListMilestones(context.Context, *models.Repository) ([]provider.Milestone, error) {
+	return nil, nil
+}
+
+func (e *fakeExporter) ListIssues(_ context.Context, _ *models.Repository, since time.Time) ([]provider.Issue, error) {
+	e.since = append(e.since, since)
+
+	var issues []provider.Issue
+	for _, i := range e.issues {
+		if !i.UpdatedAt.Before(since) {
+			issues = append(issues, i)
+		}
+	}
+
+	return issues, nil
+}
+
+func (e *fakeExporter) ListComments(_ context.Context, _ *models.Repository, since time.Time) ([]provider.Comment, error) {
+	var comments []provider.Comment
+	for _, c := range e.comments {
+		if !c.UpdatedAt.Before(since) {
+			comments = append(comments, c)
+		}
+	}
+
+	return comments, nil
+}
+
+// memoryStorage is a storage provider keeping uploaded files in memory.
+type memoryStorage struct {
+	cred    *models.Credential
+	objects map[string][]byte
+}
+
+func (s *memoryStorage) Authenticate(_ context.Context, cred *models.Credential) error {
+	s.cred = cred
+	return nil
+}
+
+func (s *memoryStorage) Upload(_ context.Context, localPath, remotePath string) error {
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+
+	s.objects[remotePath] = data
+
+	return nil
+}
+
+func (s *memoryStorage) Download(context.Context, string, string) error { return nil }
+
+func (s *memoryStorage) List(context.Context, string) ([]provider.StorageObject, error) {
+	return nil, nil
+}
+
+func (s *memoryStorage) Delete(context.Context, string) error { return nil }
+
+func (s *memoryStorage) GetQuota(context.Context) (*provider.QuotaInfo, error) { return nil, nil }
+
+func TestExportDiscussionsIncrementally(t *testing.T) {
+	db, err := database.Open(":memory:")
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	providers := store.NewProviderStore(db)
+	repos := store.NewRepositoryStore(db)
+	creds := service.NewCredentialService(db, store.NewCredentialStore(db), store.NewSettingStore(db))
+
+	if err := creds.SetupMasterPassword(webhookPassword); err != nil {
+		t.Fatalf("SetupMasterPassword() error: %v", err)
+	}
+
+	github := &models.Provider{Name: "GitHub", Type: "github", BaseURL: "https://api.github.com"}
+	bucket := &models.Provider{Name: "Archive", Type: "memory", BaseURL: "memory://archive", Options: map[string]string{"prefix": "backups"}}
+
+	for _, p := range []*models.Provider{github, bucket} {
+		if err := providers.Create(p); err != nil {
+			t.Fatalf("create provider: %v", err)
+		}
+	}
+
+	if err := creds.Store(&models.Credential{ProviderID: bucket.ID, Label: "key", AuthType: models.AuthTypeToken, AuthData: "secret"}); err != nil {
+		t.Fatalf("store credential: %v", err)
+	}
+
+	repo := &models.Repository{ProviderID: github.ID, Name: "octocat/hello", CloneURL: "https://github.com/octocat/hello.git"}
+	if err := repos.Create(repo); err != nil {
+		t.Fatalf("create repository: %v", err)
+	}
+
+	old := time.Now().UTC().Add(-time.Hour)
+	exporter := &fakeExporter{
+		issues:   []provider.Issue{{Number: 1, Title: "Crash", UpdatedAt: old}, {Number: 2, PullRequest: true, Title: "Fix", UpdatedAt: old}},
+		comments: []provider.Comment{{ID: "10", IssueNumber: 1, Body: "Same here", UpdatedAt: old}},
+	}
+
+	storage := &memoryStorage{objects: make(map[string][]byte)}
+
+	var storageCfg provider.ProviderConfig
+
+	registry := provider.NewProviderRegistry()
+	if err := registry.RegisterStorageProviderFactory("memory", func(cfg provider.ProviderConfig) (provider.StorageProvider, error) {
+		storageCfg = cfg
+		return storage, nil
+	}); err != nil {
+		t.Fatalf("register storage provider: %v", err)
+	}
+
+	exports := service.NewExportService(providers, repos, store.NewDiscussionExportStore(db), creds,
+		func(context.Context, *models.Provider) (provider.SourceControlProvider, error) { return exporter, nil },
+		service.RegistryStorageConnector(registry, creds), t.TempDir())
+
+	ctx := context.Background()
+
+	first, err := exports.Export(ctx, repo.ID, bucket.ID)
+	if err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+
+	if first.Since != nil || first.Issues != 2 || first.Comments != 1 || !strings.HasPrefix(first.Path, "discussions/octocat/hello/") {
+		t.Errorf("first Export() = %+v", first)
+	}
+
+	if storage.cred == nil || storage.cred.AuthData != "secret" {
+		t.Errorf("storage authenticated with %+v", storage.cred)
+	}
+
+	if storageCfg.BaseURL != bucket.BaseURL || storageCfg.Options["prefix"] != "backups" {
+		t.Errorf("storage created with %+v, want the stored base URL and options", storageCfg)
+	}
+
+	// A comment edited after the first export reappears in the next one, alone.
+	exporter.comments[0].Body, exporter.comments[0].UpdatedAt = "Fixed by #2", time.Now().UTC()
+
+	second, err := exports.Export(ctx, repo.ID, bucket.ID)
+	if err != nil {
+		t.Fatalf("second Export() error: %v", err)
+	}
+
+	if second.Since == nil || !second.Since.Equal(first.ExportedAt) || !exporter.since[1].Equal(first.ExportedAt) {
+		t.Errorf("second Export() since = %v, fetched since %v, want %v", second.Since, exporter.since, first.ExportedAt)
+	}
+
+	a, err := archive.Read(bytes.NewReader(storage.objects[second.Path]))
+	if err != nil {
+		t.Fatalf("read archive: %v", err)
+	}
+
+	if a.Repository != "octocat/hello" || a.Provider != "github" || len(a.Issues) != 0 || len(a.Comments) != 1 || a.Comments[0].Body != "Fixed by #2" || len(a.Labels) != 1 {
+		t.Errorf("incremental archive = %+v", a)
+	}
+
+	if list, err := exports.Exports(repo.ID); err != nil || len(list) != 2 || list[0].ID != second.ID {
+		t.Errorf("Exports() = %+v, %v", list, err)
+	}
+}
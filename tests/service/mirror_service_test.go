@@ -0,0 +1,377 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"GitSyncer/core/database"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/service"
+	"GitSyncer/core/store"
+)
+
+// fakeMirrorManager is a GitLab provider whose push mirrors, and pull mirrors when directions
+// allows them, live in memory.
+type fakeMirrorManager struct {
+	fakeHookManager
+
+	directions []string
+	mirrors    map[string]provider.NativeMirror
+	configs    map[string]provider.NativeMirrorConfig
+	synced     []string
+	nextID     int
+}
+
+func (m *fakeMirrorManager) GetProviderType() provider.ProviderType { return provider.ProviderGitLab }
+
+func (m *fakeMirrorManager) Capabilities() []provider.SourceControlProviderCapability {
+	return []provider.SourceControlProviderCapability{provider.CapabilityAPI, provider.CapabilityMirror}
+}
+
+func (m *fakeMirrorManager) MirrorDirections() []string {
+	if m.directions == nil {
+		return []string{provider.MirrorPush}
+	}
+
+	return m.directions
+}
+
+func (m *fakeMirrorManager) ListMirrors(context.Context, *models.Repository) ([]provider.NativeMirror, error) {
+	var mirrors []provider.NativeMirror
+	for _, n := range m.mirrors {
+		mirrors = append(mirrors, n)
+	}
+
+	return mirrors, nil
+}
+
+func (m *fakeMirrorManager) CreateMirror(_ context.Context, _ *models.Repository, cfg provider.NativeMirrorConfig) (*provider.NativeMirror, error) {
+	m.nextID++
+
+	n := provider.NativeMirror{ID: strconv.Itoa(m.nextID), Direction: cfg.Direction, RemoteURL: cfg.RemoteURL, Enabled: true, Status: provider.MirrorStatusPending}
+	m.mirrors[n.ID] = n
+	m.configs[n.ID] = cfg
+
+	return &n, nil
+}
+
+func (m *fakeMirrorManager) UpdateMirror(_ context.Context, _ *models.Repository, id string, cfg provider.NativeMirrorConfig) (*provider.NativeMirror, error) {
+	n, ok := m.mirrors[id]
+	if !ok {
+		return nil, &provider.NotFoundError{Provider: provider.ProviderGitLab, Message: "mirror " + id}
+	}
+
+	n.OnlyProtectedBranches = cfg.OnlyProtectedBranches
+	m.mirrors[id] = n
+
+	return &n, nil
+}
+
+func (m *fakeMirrorManager) DeleteMirror(_ context.Context, _ *models.Repository, id string) error {
+	if _, ok := m.mirrors[id]; !ok {
+		return &provider.NotFoundError{Provider: provider.ProviderGitLab, Message: "mirror " + id}
+	}
+
+	delete(m.mirrors, id)
+
+	return nil
+}
+
+func (m *fakeMirrorManager) SyncMirror(_ context.Context, _ *models.Repository, id string) error {
+	m.synced = append(m.synced, id)
+
+	return nil
+}
+
+type mirrorFixture struct {
+	mirrors    *service.MirrorService
+	sync       *service.SyncService
+	manager    *fakeMirrorManager
+	creds      *service.CredentialService
+	history    *store.SyncHistoryStore
+	sourceID   int64
+	targetID   int64
+	repository int64
+}
+
+func newMirrorFixture(t *testing.T) *mirrorFixture {
+	t.Helper()
+
+	db, err := database.Open(":memory:")
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	providers := store.NewProviderStore(db)
+	repos := store.NewRepositoryStore(db)
+	creds := service.NewCredentialService(db, store.NewCredentialStore(db), store.NewSettingStore(db))
+
+	if err := creds.SetupMasterPassword(webhookPassword); err != nil {
+		t.Fatalf("SetupMasterPassword() error: %v", err)
+	}
+
+	gitlab := &models.Provider{Name: "GitLab", Type: "gitlab", BaseURL: "https://gitlab.com"}
+	github := &models.Provider{Name: "GitHub", Type: "github", BaseURL: "https://api.github.com"}
+
+	for _, p := range []*models.Provider{gitlab, github} {
+		if err := providers.Create(p); err != nil {
+			t.Fatalf("create provider: %v", err)
+		}
+	}
+
+	if err := creds.Store(&models.Credential{ProviderID: github.ID, Label: "token", AuthType: models.AuthTypeToken, AuthData: "ghp_target"}); err != nil {
+		t.Fatalf("store credential: %v", err)
+	}
+
+	repo := &models.Repository{ProviderID: gitlab.ID, Name: "lib", CloneURL: "https://gitlab.com/team/lib.git"}
+	if err := repos.Create(repo); err != nil {
+		t.Fatalf("create repository: %v", err)
+	}
+
+	f := &mirrorFixture{
+		manager: &fakeMirrorManager{
+			mirrors: make(map[string]provider.NativeMirror),
+			configs: make(map[string]provider.NativeMirrorConfig),
+		},
+		creds:      creds,
+		history:    store.NewSyncHistoryStore(db),
+		sourceID:   gitlab.ID,
+		targetID:   github.ID,
+		repository: repo.ID,
+	}
+
+	connect := func(ctx context.Context, p *models.Provider) (provider.SourceControlProvider, error) {
+		return f.manager, nil
+	}
+
+	mirrorStore := store.NewMirrorStore(db)
+	f.mirrors = service.NewMirrorService(providers, repos, mirrorStore, store.NewSubmoduleMappingStore(db), f.history, creds, connect)
+	f.sync = service.NewSyncService(providers, repos, mirrorStore, f.history, store.NewReleaseAssetChecksumStore(db), creds, f.mirrors, connect, t.TempDir())
+
+	return f
+}
+
+func (f *mirrorFixture) createPushMirror(t *testing.T) *models.Mirror {
+	t.Helper()
+
+	m := &models.Mirror{
+		RepositoryID:     f.repository,
+		Mode:             models.MirrorModeNative,
+		Direction:        provider.MirrorPush,
+		TargetProviderID: f.targetID,
+		TargetURL:        "https://github.com/octo/lib.git",
+	}
+
+	if err := f.mirrors.Create(context.Background(), m); err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+
+	return m
+}
+
+func TestCreateNativePushMirror(t *testing.T) {
+	f := newMirrorFixture(t)
+	m := f.createPushMirror(t)
+
+	if m.ID == 0 || m.NativeID == "" {
+		t.Fatalf("Create() = %+v, want a stored mirror with a native ID", m)
+	}
+
+	cfg := f.manager.configs[m.NativeID]
+	if cfg.RemoteURL != m.TargetURL || cfg.Username != "x-access-token" || cfg.Password != "ghp_target" {
+		t.Errorf("native mirror config = %+v, want the target's token", cfg)
+	}
+
+	mirrors, err := f.mirrors.List(f.repository)
+	if err != nil || len(mirrors) != 1 || mirrors[0].NativeID != m.NativeID {
+		t.Fatalf("List() = %+v, %v", mirrors, err)
+	}
+
+	// The fake only hosts push mirrors.
+	pull := &models.Mirror{RepositoryID: f.repository, Mode: models.MirrorModeNative, Direction: provider.MirrorPull, TargetProviderID: f.targetID, TargetURL: m.TargetURL}
+	if err := f.mirrors.Create(context.Background(), pull); !errors.Is(err, provider.ErrUnsupportedMirror) {
+		t.Errorf("Create() of a pull mirror = %v, want ErrUnsupportedMirror", err)
+	}
+}
+
+func TestUpdateNativeMirror(t *testing.T) {
+	f := newMirrorFixture(t)
+	ctx := context.Background()
+	m := f.createPushMirror(t)
+	nativeID := m.NativeID
+
+	m.OnlyProtectedBranches = true
+	if err := f.mirrors.Update(ctx, m, false); err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+
+	if m.NativeID != nativeID || !f.manager.mirrors[nativeID].OnlyProtectedBranches {
+		t.Errorf("Update() of protected branches replaced or missed the native mirror: %+v", f.manager.mirrors)
+	}
+
+	// A new target replaces the native mirror.
+	m.TargetURL = "https://github.com/octo/lib-backup.git"
+	if err := f.mirrors.Update(ctx, m, false); err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+
+	if _, ok := f.manager.mirrors[nativeID]; ok || len(f.manager.mirrors) != 1 || m.NativeID == nativeID {
+		t.Errorf("Update() of the target = %+v, native mirrors %+v", m, f.manager.mirrors)
+	}
+
+	// Switching to a GitSyncer-driven mirror removes the native one.
+	m.Mode = models.MirrorModeGitSyncer
+	if err := f.mirrors.Update(ctx, m, false); err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+
+	if len(f.manager.mirrors) != 0 || m.NativeID != "" || m.Direction != "" {
+		t.Errorf("Update() to GitSyncer mode = %+v, native mirrors %+v", m, f.manager.mirrors)
+	}
+}
+
+func TestDeleteNativeMirror(t *testing.T) {
+	f := newMirrorFixture(t)
+	ctx := context.Background()
+
+	m := f.createPushMirror(t)
+	if err := f.mirrors.Delete(ctx, m.ID, false); err != nil || len(f.manager.mirrors) != 0 {
+		t.Fatalf("Delete() = %v, native mirrors %+v", err, f.manager.mirrors)
+	}
+
+	// A native mirror already removed on the provider does not block the deletion.
+	m = f.createPushMirror(t)
+	delete(f.manager.mirrors, m.NativeID)
+
+	if err := f.mirrors.Delete(ctx, m.ID, false); err != nil {
+		t.Fatalf("Delete() of a mirror gone from the provider: %v", err)
+	}
+
+	if mirrors, _ := f.mirrors.List(f.repository); len(mirrors) != 0 {
+		t.Errorf("List() after Delete() = %+v", mirrors)
+	}
+}
+
+func TestPullMirrorRemovalNeedsConfirmation(t *testing.T) {
+	f := newMirrorFixture(t)
+	ctx := context.Background()
+	f.manager.directions = []string{provider.MirrorPush, provider.MirrorPull}
+
+	m := &models.Mirror{RepositoryID: f.repository, Mode: models.MirrorModeNative, Direction: provider.MirrorPull, TargetProviderID: f.targetID, TargetURL: "https://github.com/octo/lib.git"}
+	if err := f.mirrors.Create(ctx, m); err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+
+	nativeID := m.NativeID
+
+	m.TargetURL = "https://github.com/octo/lib-backup.git"
+	if err := f.mirrors.Update(ctx, m, false); !errors.Is(err, service.ErrPullMirrorDeletion) {
+		t.Errorf("unconfirmed Update() of the target = %v, want ErrPullMirrorDeletion", err)
+	}
+
+	if err := f.mirrors.Delete(ctx, m.ID, false); !errors.Is(err, service.ErrPullMirrorDeletion) {
+		t.Errorf("unconfirmed Delete() = %v, want ErrPullMirrorDeletion", err)
+	}
+
+	// Switching mode is refused even when confirmed: nothing would be left to push to.
+	switched := *m
+	switched.Mode = models.MirrorModeGitSyncer
+
+	if err := f.mirrors.Update(ctx, &switched, true); err == nil {
+		t.Error("Update() of a pull mirror to GitSyncer mode returned no error")
+	}
+
+	if _, ok := f.manager.mirrors[nativeID]; !ok || len(f.manager.mirrors) != 1 {
+		t.Fatalf("native mirrors after refused changes = %+v", f.manager.mirrors)
+	}
+
+	if err := f.mirrors.Update(ctx, m, true); err != nil || m.NativeID == nativeID {
+		t.Errorf("confirmed Update() = %+v, %v, want the pull mirror replaced", m, err)
+	}
+
+	if err := f.mirrors.Delete(ctx, m.ID, true); err != nil || len(f.manager.mirrors) != 0 {
+		t.Errorf("confirmed Delete() = %v, native mirrors %+v", err, f.manager.mirrors)
+	}
+}
+
+func TestRecordNativeMirrorStatus(t *testing.T) {
+	f := newMirrorFixture(t)
+	ctx := context.Background()
+	m := f.createPushMirror(t)
+
+	if h, err := f.mirrors.RecordStatus(ctx, m); err != nil || h != nil {
+		t.Fatalf("RecordStatus() of a pending mirror = %+v, %v", h, err)
+	}
+
+	updated := time.Date(2026, 5, 2, 10, 0, 0, 0, time.UTC)
+	n := f.manager.mirrors[m.NativeID]
+	n.Status, n.LastUpdateAt = provider.MirrorStatusOK, &updated
+	f.manager.mirrors[m.NativeID] = n
+
+	h, err := f.mirrors.RecordStatus(ctx, m)
+	if err != nil || h == nil || h.Status != models.SyncStatusSuccess || *h.MirrorID != m.ID {
+		t.Fatalf("RecordStatus() of an updated mirror = %+v, %v", h, err)
+	}
+
+	var details service.SyncDetails
+	if err := json.Unmarshal([]byte(h.Details), &details); err != nil || details.Mode != models.MirrorModeNative || details.NativeStatus != provider.MirrorStatusOK {
+		t.Errorf("details = %s, %v", h.Details, err)
+	}
+
+	// The same update is recorded once.
+	if h, err := f.mirrors.RecordStatus(ctx, m); err != nil || h != nil {
+		t.Fatalf("RecordStatus() of an already recorded update = %+v, %v", h, err)
+	}
+
+	failed := updated.Add(time.Hour)
+	n.Status, n.LastUpdateAt, n.LastError = provider.MirrorStatusFailed, &failed, "remote rejected"
+	f.manager.mirrors[m.NativeID] = n
+
+	if err := f.mirrors.RecordAllStatuses(ctx); err != nil {
+		t.Fatalf("RecordAllStatuses() error: %v", err)
+	}
+
+	entries, err := f.sync.History(f.repository, 10)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("History() = %+v, %v", entries, err)
+	}
+
+	if e := entries[0]; e.Status != models.SyncStatusFailed || e.ErrorMessage != "remote rejected" || !e.StartedAt.Equal(failed) {
+		t.Errorf("latest entry = %+v", e)
+	}
+}
+
+func TestSyncTriggersNativeMirrors(t *testing.T) {
+	f := newMirrorFixture(t)
+	m := f.createPushMirror(t)
+
+	if err := f.sync.Sync(context.Background(), f.repository); err != nil {
+		t.Fatalf("Sync() error: %v", err)
+	}
+
+	if len(f.manager.synced) != 1 || f.manager.synced[0] != m.NativeID {
+		t.Errorf("synced native mirrors = %v", f.manager.synced)
+	}
+
+	// The outcome is recorded once the provider reports it, not when triggering.
+	if entries, _ := f.sync.History(f.repository, 10); len(entries) != 0 {
+		t.Errorf("History() after triggering = %+v", entries)
+	}
+
+	f.creds.Lock()
+
+	if err := f.sync.Sync(context.Background(), f.repository); !errors.Is(err, service.ErrLocked) {
+		t.Errorf("Sync() while locked = %v, want ErrLocked", err)
+	}
+}
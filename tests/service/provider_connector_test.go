@@ -0,0 +1,84 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/provider/gitea"
+	"GitSyncer/core/service"
+)
+
+func TestRegistryConnectorSharesTransportPerRecord(t *testing.T) {
+	var revalidated atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+
+		if r.URL.Path != "/api/v1/user" {
+			http.NotFound(w, r)
+			return
+		}
+
+		if r.Header.Get("If-None-Match") == `"me"` {
+			revalidated.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("ETag", `"me"`)
+		w.Write([]byte(`{"login":"octo"}`))
+	}))
+	defer srv.Close()
+
+	f := newSyncFixture(t)
+
+	p := &models.Provider{Name: "forge", Type: string(provider.ProviderGitea), BaseURL: srv.URL}
+	if err := f.providers.Create(p); err != nil {
+		t.Fatalf("create provider: %v", err)
+	}
+
+	if err := f.creds.Store(&models.Credential{ProviderID: p.ID, Label: "token", AuthType: models.AuthTypeToken, AuthData: "secret"}); err != nil {
+		t.Fatalf("Store() error: %v", err)
+	}
+
+	registry := provider.NewProviderRegistry()
+	if err := gitea.Register(registry); err != nil {
+		t.Fatalf("register gitea provider: %v", err)
+	}
+
+	transports := service.NewProviderTransports()
+	connect := service.RegistryConnector(registry, f.creds, transports)
+
+	if limits := transports.RateLimits(p.ID); len(limits) != 0 {
+		t.Fatalf("RateLimits() before any request = %+v", limits)
+	}
+
+	// Each connection authenticates with GET /user and is discarded, like a sync does.
+	for range 2 {
+		scp, err := connect(context.Background(), p)
+		if err != nil {
+			t.Fatalf("connect() error: %v", err)
+		}
+
+		if c, ok := scp.(io.Closer); ok {
+			c.Close()
+		}
+	}
+
+	if n := revalidated.Load(); n != 1 {
+		t.Errorf("revalidated %d times, want the second connection to reuse the first's ETag", n)
+	}
+
+	limits := transports.RateLimits(p.ID)
+	if len(limits) != 1 || limits[0].Limit != 5000 || limits[0].Remaining != 4999 {
+		t.Errorf("RateLimits() = %+v, want the quota of the last response", limits)
+	}
+}
@@ -0,0 +1,257 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/service"
+	"GitSyncer/core/webhook"
+)
+
+const publicURL = "https://sync.example.com"
+
+// fakeHookManager is a GitHub provider whose webhooks live in memory.
+type fakeHookManager struct {
+	hooks   map[string]provider.Webhook
+	secrets map[string]string
+	nextID  int
+}
+
+func (m *fakeHookManager) Authenticate(context.Context, *models.Credential) error { return nil }
+
+func (m *fakeHookManager) ListRepos(context.Context) ([]models.Repository, error) { return nil, nil }
+
+func (m *fakeHookManager) CloneRepo(context.Context, *models.Repository, string) error { return nil }
+
+func (m *fakeHookManager) PushMirror(context.Context, *models.Repository, string) error { return nil }
+
+func (m *fakeHookManager) ValidateURL(string) bool { return true }
+
+func (m *fakeHookManager) GetProviderType() provider.ProviderType { return provider.ProviderGitHub }
+
+func (m *fakeHookManager) Capabilities() []provider.SourceControlProviderCapability {
+	return []provider.SourceControlProviderCapability{provider.CapabilityAPI, provider.CapabilityWebhooks}
+}
+
+func (m *fakeHookManager) ListWebhooks(context.Context, *models.Repository) ([]provider.Webhook, error) {
+	var hooks []provider.Webhook
+	for _, h := range m.hooks {
+		hooks = append(hooks, h)
+	}
+
+	return hooks, nil
+}
+
+func (m *fakeHookManager) CreateWebhook(_ context.Context, _ *models.Repository, cfg provider.WebhookConfig) (*provider.Webhook, error) {
+	m.nextID++
+
+	h := provider.Webhook{ID: strconv.Itoa(m.nextID), URL: cfg.URL, Events: []string{"push"}, Active: true}
+	m.hooks[h.ID] = h
+
+	if m.secrets == nil {
+		m.secrets = make(map[string]string)
+	}
+
+	m.secrets[h.ID] = cfg.Secret
+
+	return &h, nil
+}
+
+func (m *fakeHookManager) UpdateWebhook(_ context.Context, _ *models.Repository, id string, cfg provider.WebhookConfig) (*provider.Webhook, error) {
+	h, ok := m.hooks[id]
+	if !ok {
+		return nil, &provider.NotFoundError{Provider: provider.ProviderGitHub, Message: "hook " + id}
+	}
+
+	h.URL, h.Active = cfg.URL, true
+	m.hooks[id] = h
+	m.secrets[id] = cfg.Secret
+
+	return &h, nil
+}
+
+func (m *fakeHookManager) DeleteWebhook(_ context.Context, _ *models.Repository, id string) error {
+	if _, ok := m.hooks[id]; !ok {
+		return &provider.NotFoundError{Provider: provider.ProviderGitHub, Message: "hook " + id}
+	}
+
+	delete(m.hooks, id)
+
+	return nil
+}
+
+func TestRegisterWebhookRequiresPublicURL(t *testing.T) {
+	f := newWebhookFixture(t)
+
+	if _, err := f.hooks.Register(context.Background(), f.repository); !errors.Is(err, service.ErrNoPublicURL) {
+		t.Fatalf("Register() without a public URL = %v, want ErrNoPublicURL", err)
+	}
+
+	if err := f.hooks.SetPublicURL("sync.example.com"); err == nil {
+		t.Error("SetPublicURL() accepted a URL without a scheme")
+	}
+}
+
+func TestRegisterWebhookUsesPerRepositorySecret(t *testing.T) {
+	f := newWebhookFixture(t)
+	ctx := context.Background()
+
+	if err := f.hooks.SetPublicURL(publicURL + "/"); err != nil {
+		t.Fatalf("SetPublicURL() error: %v", err)
+	}
+
+	reg, err := f.hooks.Register(ctx, f.repository)
+	if err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+
+	hook := f.manager.hooks[reg.HookID]
+	if want := webhook.ReceiverURL(publicURL, f.githubID); hook.URL != want || reg.URL != want {
+		t.Errorf("hook URL = %q, registration URL = %q, want %q", hook.URL, reg.URL, want)
+	}
+
+	secret := f.manager.secrets[reg.HookID]
+	if secret == "" || secret == webhookSecret || reg.CredentialID == nil {
+		t.Fatalf("hook secret = %q, credential = %v", secret, reg.CredentialID)
+	}
+
+	cred, err := f.creds.GetByID(*reg.CredentialID)
+	if err != nil || cred.AuthData != secret || cred.AuthType != models.AuthTypeWebhookSecret {
+		t.Fatalf("stored secret = %+v, %v", cred, err)
+	}
+
+	// Registering again leaves the hook alone.
+	again, err := f.hooks.Register(ctx, f.repository)
+	if err != nil || again.ID != reg.ID || len(f.manager.hooks) != 1 {
+		t.Fatalf("second Register() = %+v, %v with %d hooks", again, err, len(f.manager.hooks))
+	}
+
+	// Deliveries signed with the repository's secret verify.
+	h := githubHeader(githubPushBody)
+	h.Set("X-Hub-Signature-256", webhook.Sign(secret, []byte(githubPushBody)))
+
+	if rec := f.deliver(t, f.githubID, h, githubPushBody); rec.Code != 202 {
+		t.Errorf("delivery signed with the hook secret: status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	// The repository's secret does not vouch for pushes to other repositories.
+	other := `{"ref":"refs/heads/main","repository":{"clone_url":"https://github.com/octo/other.git"}}`
+	h = githubHeader(other)
+	h.Set("X-Hub-Signature-256", webhook.Sign(secret, []byte(other)))
+
+	if rec := f.deliver(t, f.githubID, h, other); rec.Code != 401 {
+		t.Errorf("delivery for another repository signed with the hook secret: status = %d", rec.Code)
+	}
+}
+
+func TestReconcileWebhooks(t *testing.T) {
+	f := newWebhookFixture(t)
+	ctx := context.Background()
+
+	if err := f.hooks.SetPublicURL(publicURL); err != nil {
+		t.Fatalf("SetPublicURL() error: %v", err)
+	}
+
+	res, err := f.hooks.Reconcile(ctx)
+	if err != nil || res.Created != 1 || len(res.Errors) != 0 {
+		t.Fatalf("first Reconcile() = %+v, %v", res, err)
+	}
+
+	regs, _ := f.hooks.List()
+	if len(regs) != 1 {
+		t.Fatalf("List() = %+v", regs)
+	}
+
+	// A hook disabled on the provider is re-enabled, one deleted there is recreated.
+	hook := f.manager.hooks[regs[0].HookID]
+	hook.Active = false
+	f.manager.hooks[hook.ID] = hook
+
+	if res, err := f.hooks.Reconcile(ctx); err != nil || res.Updated != 1 {
+		t.Fatalf("Reconcile() of a disabled hook = %+v, %v", res, err)
+	}
+
+	delete(f.manager.hooks, hook.ID)
+
+	if res, err := f.hooks.Reconcile(ctx); err != nil || res.Created != 1 {
+		t.Fatalf("Reconcile() of a deleted hook = %+v, %v", res, err)
+	}
+
+	if res, err := f.hooks.Reconcile(ctx); err != nil || res.Unchanged != 1 || res.Created+res.Updated+res.Deleted != 0 {
+		t.Fatalf("Reconcile() of a healthy hook = %+v, %v", res, err)
+	}
+
+	// A moved receiver updates the hook URL.
+	if err := f.hooks.SetPublicURL("https://new.example.com"); err != nil {
+		t.Fatalf("SetPublicURL() error: %v", err)
+	}
+
+	if res, err := f.hooks.Reconcile(ctx); err != nil || res.Updated != 1 {
+		t.Fatalf("Reconcile() after moving the receiver = %+v, %v", res, err)
+	}
+
+	regs, _ = f.hooks.List()
+	if got := f.manager.hooks[regs[0].HookID].URL; got != webhook.ReceiverURL("https://new.example.com", f.githubID) {
+		t.Errorf("hook URL after moving the receiver = %q", got)
+	}
+
+	// The hook of a repository removed behind the service's back goes on the next run.
+	if err := f.repos.Delete(f.repository); err != nil {
+		t.Fatalf("delete repository: %v", err)
+	}
+
+	if res, err := f.hooks.Reconcile(ctx); err != nil || res.Deleted != 1 || len(f.manager.hooks) != 0 {
+		t.Fatalf("Reconcile() after removing the repository = %+v, %v, hooks %+v", res, err, f.manager.hooks)
+	}
+
+	if regs, _ := f.hooks.List(); len(regs) != 0 {
+		t.Errorf("List() after removing the repository = %+v", regs)
+	}
+}
+
+func TestUnregisterWebhookDeletesHookAndSecret(t *testing.T) {
+	f := newWebhookFixture(t)
+	ctx := context.Background()
+
+	if err := f.hooks.SetPublicURL(publicURL); err != nil {
+		t.Fatalf("SetPublicURL() error: %v", err)
+	}
+
+	reg, err := f.hooks.Register(ctx, f.repository)
+	if err != nil {
+		t.Fatalf("Register() error: %v", err)
+	}
+
+	if err := f.hooks.Unregister(ctx, f.repository); err != nil {
+		t.Fatalf("Unregister() error: %v", err)
+	}
+
+	if len(f.manager.hooks) != 0 {
+		t.Errorf("hooks after Unregister() = %+v", f.manager.hooks)
+	}
+
+	if _, err := f.creds.GetByID(*reg.CredentialID); err == nil {
+		t.Error("the hook secret survived Unregister()")
+	}
+
+	if regs, _ := f.hooks.List(); len(regs) != 0 {
+		t.Errorf("List() after Unregister() = %+v", regs)
+	}
+
+	if err := f.hooks.Unregister(ctx, f.repository); err != nil {
+		t.Errorf("Unregister() without a registration = %v", err)
+	}
+}
+
+func TestReconcileRequiresUnlockedVault(t *testing.T) {
+	f := newWebhookFixture(t)
+	f.creds.Lock()
+
+	if _, err := f.hooks.Reconcile(context.Background()); !errors.Is(err, service.ErrLocked) {
+		t.Fatalf("Reconcile() while locked = %v, want ErrLocked", err)
+	}
+}
@@ -0,0 +1,372 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"GitSyncer/core/database"
+	"GitSyncer/core/models"
+	"GitSyncer/core/provider"
+	"GitSyncer/core/service"
+	"GitSyncer/core/store"
+	"GitSyncer/core/webhook"
+)
+
+const (
+	webhookSecret   = "hook-secret"
+	webhookPassword = "master-password"
+)
+
+type webhookFixture struct {
+	svc        *service.WebhookService
+	hooks      *service.WebhookRegistrationService
+	manager    *fakeHookManager
+	creds      *service.CredentialService
+	repos      *store.RepositoryStore
+	queue      *service.SyncQueue
+	server     *webhook.Server
+	githubID   int64
+	gitlabID   int64
+	repository int64
+}
+
+func newWebhookFixture(t *testing.T) *webhookFixture {
+	t.Helper()
+
+	db, err := database.Open(":memory:")
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+
+	if err := database.RunMigrations(db); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	providers := store.NewProviderStore(db)
+	repos := store.NewRepositoryStore(db)
+	settings := store.NewSettingStore(db)
+	creds := service.NewCredentialService(db, store.NewCredentialStore(db), settings)
+
+	if err := creds.SetupMasterPassword(webhookPassword); err != nil {
+		t.Fatalf("SetupMasterPassword() error: %v", err)
+	}
+
+	f := &webhookFixture{creds: creds, queue: service.NewSyncQueue()}
+
+	github := &models.Provider{Name: "GitHub", Type: "github", BaseURL: "https://api.github.com"}
+	gitlab := &models.Provider{Name: "GitLab", Type: "gitlab", BaseURL: "https://gitlab.com"}
+
+	for _, p := range []*models.Provider{github, gitlab} {
+		if err := providers.Create(p); err != nil {
+			t.Fatalf("create provider: %v", err)
+		}
+
+		if err := creds.Store(&models.Credential{ProviderID: p.ID, Label: "webhook", AuthType: models.AuthTypeWebhookSecret, AuthData: webhookSecret}); err != nil {
+			t.Fatalf("store webhook secret: %v", err)
+		}
+	}
+
+	f.githubID, f.gitlabID = github.ID, gitlab.ID
+	f.repos = repos
+
+	repo := &models.Repository{ProviderID: f.githubID, Name: "hello", CloneURL: "git@github.com:Octo/Hello.git", DefaultBranch: "main"}
+	if err := repos.Create(repo); err != nil {
+		t.Fatalf("create repository: %v", err)
+	}
+
+	f.repository = repo.ID
+	registrations := store.NewWebhookRegistrationStore(db)
+
+	f.manager = &fakeHookManager{hooks: make(map[string]provider.Webhook)}
+	connect := func(ctx context.Context, p *models.Provider) (provider.SourceControlProvider, error) {
+		return f.manager, nil
+	}
+
+	f.svc = service.NewWebhookService(providers, repos, store.NewWebhookDeliveryStore(db), registrations, settings, creds, f.queue)
+	f.hooks = service.NewWebhookRegistrationService(providers, repos, registrations, settings, creds, connect)
+	f.server = webhook.NewServer(f.svc)
+
+	return f
+}
+
+func (f *webhookFixture) deliver(t *testing.T, providerID int64, header http.Header, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, webhook.PathPrefix+strconv.FormatInt(providerID, 10), bytes.NewBufferString(body))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	rec := httptest.NewRecorder()
+	f.server.ServeHTTP(rec, req)
+
+	return rec
+}
+
+const githubPushBody = `{"ref":"refs/heads/main","after":"abc","repository":{"clone_url":"https://github.com/octo/hello.git","ssh_url":"git@github.com:octo/hello.git"}}`
+
+func githubHeader(body string) http.Header {
+	h := http.Header{}
+	h.Set("Content-Type", "application/json")
+	h.Set("X-GitHub-Event", "push")
+	h.Set("X-GitHub-Delivery", "delivery-1")
+	h.Set("X-Hub-Signature-256", webhook.Sign(webhookSecret, []byte(body)))
+
+	return h
+}
+
+func TestWebhookQueuesSyncForMatchedRepository(t *testing.T) {
+	f := newWebhookFixture(t)
+
+	rec := f.deliver(t, f.githubID, githubHeader(githubPushBody), githubPushBody)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	pending := f.queue.Pending()
+	if len(pending) != 1 || pending[0].RepositoryID != f.repository || pending[0].Reason != service.SyncReasonWebhook {
+		t.Fatalf("Pending() = %+v", pending)
+	}
+
+	// A second push while the first sync is pending is coalesced.
+	f.deliver(t, f.githubID, githubHeader(githubPushBody), githubPushBody)
+
+	if len(f.queue.Pending()) != 1 {
+		t.Errorf("Pending() after a second push = %+v", f.queue.Pending())
+	}
+
+	deliveries, err := f.svc.ListDeliveries(10)
+	if err != nil {
+		t.Fatalf("ListDeliveries() error: %v", err)
+	}
+
+	if len(deliveries) != 2 {
+		t.Fatalf("ListDeliveries() = %d deliveries, want 2", len(deliveries))
+	}
+
+	d := deliveries[0]
+	if d.Status != models.WebhookStatusQueued || !d.Verified || d.DeliveryID != "delivery-1" || d.Event != "push" ||
+		d.RepositoryID == nil || *d.RepositoryID != f.repository || d.Payload != githubPushBody {
+		t.Errorf("recorded delivery = %+v", d)
+	}
+}
+
+func TestWebhookRejectsBadSignature(t *testing.T) {
+	f := newWebhookFixture(t)
+
+	h := githubHeader(githubPushBody)
+	h.Set("X-Hub-Signature-256", webhook.Sign("wrong", []byte(githubPushBody)))
+
+	if rec := f.deliver(t, f.githubID, h, githubPushBody); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", rec.Code)
+	}
+
+	if len(f.queue.Pending()) != 0 {
+		t.Error("a rejected delivery enqueued a sync")
+	}
+
+	deliveries, _ := f.svc.ListDeliveries(0)
+	if len(deliveries) != 1 || deliveries[0].Status != models.WebhookStatusRejected || deliveries[0].Verified {
+		t.Errorf("ListDeliveries() = %+v", deliveries)
+	}
+}
+
+func TestWebhookBoundsRejectedDeliveries(t *testing.T) {
+	f := newWebhookFixture(t)
+
+	large := `{"ref":"refs/heads/main","padding":"` + strings.Repeat("x", 64<<10) + `"}`
+
+	h := githubHeader(large)
+	h.Set("X-Hub-Signature-256", webhook.Sign("wrong", []byte(large)))
+
+	if rec := f.deliver(t, f.githubID, h, large); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", rec.Code)
+	}
+
+	deliveries, _ := f.svc.ListDeliveries(1)
+	if len(deliveries) != 1 || deliveries[0].Payload != "" {
+		t.Fatalf("rejected delivery kept a %d byte payload", len(deliveries[0].Payload))
+	}
+
+	if _, err := f.svc.Replay(deliveries[0].ID); err == nil {
+		t.Error("Replay() of a delivery without its payload should fail")
+	}
+
+	// A verified delivery is never evicted by unverifiable ones, here failing on the locked vault.
+	f.deliver(t, f.githubID, githubHeader(githubPushBody), githubPushBody)
+	f.creds.Lock()
+
+	for range 250 {
+		f.deliver(t, f.githubID, githubHeader(githubPushBody), githubPushBody)
+	}
+
+	deliveries, _ = f.svc.ListDeliveries(0)
+
+	var verified, unverified int
+
+	for _, d := range deliveries {
+		if d.Verified {
+			verified++
+		} else {
+			unverified++
+		}
+	}
+
+	if verified != 1 || unverified != 200 {
+		t.Errorf("kept %d verified and %d unverified deliveries, want 1 and 200", verified, unverified)
+	}
+}
+
+func TestWebhookStatuses(t *testing.T) {
+	f := newWebhookFixture(t)
+
+	ping := `{"zen":"hi"}`
+	h := githubHeader(ping)
+	h.Set("X-GitHub-Event", "ping")
+
+	if rec := f.deliver(t, f.githubID, h, ping); rec.Code != http.StatusOK {
+		t.Errorf("ping status = %d, want 200", rec.Code)
+	}
+
+	unknown := `{"ref":"refs/heads/main","repository":{"clone_url":"https://github.com/octo/other.git"}}`
+	if rec := f.deliver(t, f.githubID, githubHeader(unknown), unknown); rec.Code != http.StatusOK {
+		t.Errorf("unmatched status = %d, want 200", rec.Code)
+	}
+
+	if rec := f.deliver(t, 99, githubHeader(githubPushBody), githubPushBody); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown provider status = %d, want 404", rec.Code)
+	}
+
+	deliveries, _ := f.svc.ListDeliveries(0)
+	if len(deliveries) != 2 || deliveries[0].Status != models.WebhookStatusUnmatched || deliveries[1].Status != models.WebhookStatusIgnored {
+		t.Errorf("ListDeliveries() = %+v", deliveries)
+	}
+
+	if len(f.queue.Pending()) != 0 {
+		t.Errorf("Pending() = %+v, want none", f.queue.Pending())
+	}
+}
+
+func TestWebhookReplayAfterUnlock(t *testing.T) {
+	f := newWebhookFixture(t)
+	f.creds.Lock()
+
+	rec := f.deliver(t, f.githubID, githubHeader(githubPushBody), githubPushBody)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status while locked = %d, want 503", rec.Code)
+	}
+
+	deliveries, _ := f.svc.ListDeliveries(1)
+	if len(deliveries) != 1 || deliveries[0].Status != models.WebhookStatusFailed {
+		t.Fatalf("ListDeliveries() = %+v", deliveries)
+	}
+
+	if err := f.creds.Unlock(webhookPassword); err != nil {
+		t.Fatalf("Unlock() error: %v", err)
+	}
+
+	d, err := f.svc.Replay(deliveries[0].ID)
+	if err != nil {
+		t.Fatalf("Replay() error: %v", err)
+	}
+
+	if d.Status != models.WebhookStatusQueued || !d.Verified || d.ErrorMessage != "" {
+		t.Errorf("Replay() = %+v", d)
+	}
+
+	stored, _ := f.svc.GetDelivery(d.ID)
+	if stored.Status != models.WebhookStatusQueued || stored.RepositoryID == nil {
+		t.Errorf("stored delivery after replay = %+v", stored)
+	}
+
+	if len(f.queue.Pending()) != 1 {
+		t.Errorf("Pending() after replay = %+v", f.queue.Pending())
+	}
+}
+
+func TestWebhookGitLabTokenIsNotRecorded(t *testing.T) {
+	f := newWebhookFixture(t)
+
+	body := `{"object_kind":"push","ref":"refs/heads/main","project":{"git_http_url":"https://gitlab.com/group/app.git"}}`
+
+	h := http.Header{}
+	h.Set("X-Gitlab-Event", "Push Hook")
+	h.Set("X-Gitlab-Token", webhookSecret)
+
+	if rec := f.deliver(t, f.gitlabID, h, body); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (unmatched)", rec.Code)
+	}
+
+	deliveries, _ := f.svc.ListDeliveries(1)
+	if len(deliveries) != 1 || bytes.Contains([]byte(deliveries[0].Headers), []byte(webhookSecret)) {
+		t.Fatalf("recorded delivery = %+v", deliveries)
+	}
+
+	// Verified deliveries replay without the token.
+	if d, err := f.svc.Replay(deliveries[0].ID); err != nil || d.Status != models.WebhookStatusUnmatched {
+		t.Errorf("Replay() = %+v, %v", d, err)
+	}
+}
+
+func TestSyncQueueNext(t *testing.T) {
+	q := service.NewSyncQueue()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := q.Next(ctx); err == nil {
+		t.Fatal("Next() on an empty queue returned before the context ended")
+	}
+
+	go func() {
+		q.Enqueue(7, service.SyncReasonManual)
+		q.Enqueue(8, service.SyncReasonManual)
+	}()
+
+	for _, want := range []int64{7, 8} {
+		req, err := q.Next(context.Background())
+		if err != nil || req.RepositoryID != want {
+			t.Fatalf("Next() = %+v, %v, want repository %d", req, err, want)
+		}
+	}
+
+	if !q.Enqueue(7, service.SyncReasonManual) {
+		t.Error("Enqueue() of a repository no longer pending reported a duplicate")
+	}
+}
+
+func TestWebhookListenAddress(t *testing.T) {
+	f := newWebhookFixture(t)
+
+	if addr, err := f.svc.ListenAddress(); err != nil || addr != "" {
+		t.Fatalf("ListenAddress() = %q, %v, want disabled", addr, err)
+	}
+
+	if err := f.svc.SetListenAddress("not an address"); err == nil {
+		t.Error("SetListenAddress() accepted an invalid address")
+	}
+
+	if err := f.svc.SetListenAddress(":8765"); err != nil {
+		t.Fatalf("SetListenAddress() error: %v", err)
+	}
+
+	if addr, _ := f.svc.ListenAddress(); addr != ":8765" {
+		t.Errorf("ListenAddress() = %q", addr)
+	}
+
+	if err := f.svc.SetListenAddress(""); err != nil {
+		t.Fatalf("SetListenAddress(\"\") error: %v", err)
+	}
+
+	if addr, _ := f.svc.ListenAddress(); addr != "" {
+		t.Errorf("ListenAddress() after disabling = %q", addr)
+	}
+}
@@ -0,0 +1,115 @@
+package webhook_test
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+
+	"GitSyncer/core/provider"
+	"GitSyncer/core/webhook"
+)
+
+const secret = "s3cret"
+
+func header(kv ...string) http.Header {
+	h := http.Header{}
+	for i := 0; i < len(kv); i += 2 {
+		h.Set(kv[i], kv[i+1])
+	}
+
+	return h
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	sig := webhook.Sign(secret, body)
+	giteaSig := sig[len("sha256="):]
+
+	tests := []struct {
+		name    string
+		pt      provider.ProviderType
+		header  http.Header
+		secrets []string
+		want    error
+	}{
+		{"github valid", provider.ProviderGitHub, header("X-Hub-Signature-256", sig), []string{secret}, nil},
+		{"github rotated secret", provider.ProviderGitHub, header("X-Hub-Signature-256", sig), []string{"new", secret}, nil},
+		{"github wrong secret", provider.ProviderGitHub, header("X-Hub-Signature-256", sig), []string{"other"}, webhook.ErrInvalidSignature},
+		{"github garbage", provider.ProviderGitHub, header("X-Hub-Signature-256", "sha256=zz"), []string{secret}, webhook.ErrInvalidSignature},
+		{"github unsigned", provider.ProviderGitHub, header(), []string{secret}, webhook.ErrMissingSignature},
+		{"no secret configured", provider.ProviderGitHub, header("X-Hub-Signature-256", sig), nil, webhook.ErrNoSecret},
+		{"gitea valid", provider.ProviderGitea, header("X-Gitea-Signature", giteaSig), []string{secret}, nil},
+		{"forgejo valid", provider.ProviderGitea, header("X-Forgejo-Signature", giteaSig), []string{secret}, nil},
+		{"gitea wrong", provider.ProviderGitea, header("X-Gitea-Signature", giteaSig), []string{"other"}, webhook.ErrInvalidSignature},
+		{"gitlab token", provider.ProviderGitLab, header("X-Gitlab-Token", secret), []string{secret}, nil},
+		{"gitlab wrong token", provider.ProviderGitLab, header("X-Gitlab-Token", "guess"), []string{secret}, webhook.ErrInvalidSignature},
+		{"gitlab missing token", provider.ProviderGitLab, header(), []string{secret}, webhook.ErrMissingSignature},
+		{"unsupported", provider.ProviderBitbucketCloud, header(), []string{secret}, webhook.ErrUnsupportedProvider},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := webhook.Verify(tt.pt, tt.header, body, tt.secrets); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	githubPush := `{"ref":"refs/heads/main","before":"a1","after":"b2","repository":{"clone_url":"https://github.com/octo/hello.git","ssh_url":"git@github.com:octo/hello.git","html_url":"https://github.com/octo/hello"}}`
+
+	tests := []struct {
+		name   string
+		pt     provider.ProviderType
+		header http.Header
+		body   string
+		kind   string
+		url    string
+	}{
+		{"github push", provider.ProviderGitHub, header("X-GitHub-Event", "push", "X-GitHub-Delivery", "d-1"), githubPush, webhook.EventPush, "git@github.com:octo/hello.git"},
+		{"github form encoded", provider.ProviderGitHub, header("X-GitHub-Event", "push", "Content-Type", "application/x-www-form-urlencoded"), "payload=" + url.QueryEscape(githubPush), webhook.EventPush, "https://github.com/octo/hello.git"},
+		{"github ping", provider.ProviderGitHub, header("X-GitHub-Event", "ping"), `{"zen":"Keep it logically awesome.","hook_id":1}`, webhook.EventPing, ""},
+		{"github issue", provider.ProviderGitHub, header("X-GitHub-Event", "issues"), githubPush, webhook.EventOther, "https://github.com/octo/hello.git"},
+		{"gitlab push", provider.ProviderGitLab, header("X-Gitlab-Event", "Push Hook"), `{"object_kind":"push","ref":"refs/heads/main","project":{"git_http_url":"https://gitlab.com/group/app.git","git_ssh_url":"git@gitlab.com:group/app.git"}}`, webhook.EventPush, "https://gitlab.com/group/app.git"},
+		{"gitlab system tag push", provider.ProviderGitLab, header("X-Gitlab-Event", "System Hook"), `{"object_kind":"tag_push","ref":"refs/tags/v1","project":{"git_http_url":"https://gitlab.com/group/app.git"}}`, webhook.EventPush, "https://gitlab.com/group/app.git"},
+		{"gitlab merge request", provider.ProviderGitLab, header("X-Gitlab-Event", "Merge Request Hook"), `{"object_kind":"merge_request"}`, webhook.EventOther, ""},
+		{"gitea push", provider.ProviderGitea, header("X-Gitea-Event", "push", "X-Gitea-Delivery", "d-2"), `{"ref":"refs/heads/main","repository":{"clone_url":"https://codeberg.org/me/tool.git"}}`, webhook.EventPush, "https://codeberg.org/me/tool.git"},
+		{"forgejo create", provider.ProviderGitea, header("X-Forgejo-Event", "create"), `{"ref":"v2","repository":{"clone_url":"https://codeberg.org/me/tool.git"}}`, webhook.EventPush, "https://codeberg.org/me/tool.git"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev, err := webhook.Parse(tt.pt, tt.header, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("Parse() error: %v", err)
+			}
+
+			if ev.Kind != tt.kind {
+				t.Errorf("Kind = %q, want %q", ev.Kind, tt.kind)
+			}
+
+			if tt.url != "" && !slices.Contains(ev.CloneURLs, tt.url) {
+				t.Errorf("CloneURLs = %v, want %q among them", ev.CloneURLs, tt.url)
+			}
+		})
+	}
+
+	if _, err := webhook.Parse(provider.ProviderGitHub, header("X-GitHub-Event", "push"), []byte("not json")); err == nil {
+		t.Error("Parse() of a malformed body succeeded")
+	}
+}
+
+func TestRecordedHeadersOmitGitLabToken(t *testing.T) {
+	h := webhook.RecordedHeaders(header("X-Gitlab-Token", secret, "X-Gitlab-Event", "Push Hook", "Authorization", "Bearer x"))
+
+	if h.Get("X-Gitlab-Token") != "" || h.Get("Authorization") != "" {
+		t.Errorf("RecordedHeaders() kept a secret: %v", h)
+	}
+
+	if h.Get("X-Gitlab-Event") != "Push Hook" {
+		t.Errorf("RecordedHeaders() dropped the event header: %v", h)
+	}
+}
@@ -1,15 +1,45 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {models} from '../models';
+import {provider} from '../models';
+import {service} from '../models';
+
+export function AddRepository(arg1:number,arg2:string,arg3:string):Promise<number>;
 
 export function ChangeMasterPassword(arg1:string,arg2:string):Promise<void>;
 
+export function CreateHostMapping(arg1:string,arg2:string,arg3:string):Promise<number>;
+
+export function CreateMirror(arg1:models.Mirror):Promise<number>;
+
 export function DeleteCredential(arg1:number):Promise<void>;
 
+export function DeleteHostMapping(arg1:number):Promise<void>;
+
+export function DeleteMirror(arg1:number,arg2:boolean):Promise<void>;
+
+export function DetectProvider(arg1:string):Promise<provider.ProbeResult>;
+
+export function ExportDiscussions(arg1:number,arg2:number):Promise<models.DiscussionExport>;
+
+export function ForgetProbe(arg1:string):Promise<void>;
+
 export function GetCredential(arg1:number):Promise<models.Credential>;
 
 export function GetCredentialsByProvider(arg1:number):Promise<Array<models.Credential>>;
 
+export function GetMirrorCloneCommand(arg1:number):Promise<string>;
+
+export function GetNativeMirrorStatus(arg1:number):Promise<provider.NativeMirror>;
+
+export function GetProviderRateLimits(arg1:number):Promise<Array<provider.RateLimitStatus>>;
+
+export function GetWebhookDelivery(arg1:number):Promise<models.WebhookDelivery>;
+
+export function GetWebhookListenAddress():Promise<string>;
+
+export function GetWebhookPublicURL():Promise<string>;
+
 export function Greet(arg1:string):Promise<string>;
 
 export function IsMasterPasswordSetup():Promise<boolean>;
@@ -18,12 +48,44 @@ export function IsVaultLocked():Promise<boolean>;
 
 export function ListCredentials():Promise<Array<models.Credential>>;
 
+export function ListDiscussionExports(arg1:number):Promise<Array<models.DiscussionExport>>;
+
+export function ListHostMappings():Promise<Array<models.HostMapping>>;
+
+export function ListMirrors(arg1:number):Promise<Array<models.Mirror>>;
+
+export function ListProviderSchemas():Promise<{[key: string]: provider.ConfigSchema}>;
+
+export function ListSubmoduleMappings(arg1:number):Promise<Array<models.SubmoduleMapping>>;
+
+export function ListSyncHistory(arg1:number,arg2:number):Promise<Array<models.SyncHistory>>;
+
+export function ListWebhookDeliveries(arg1:number):Promise<Array<models.WebhookDelivery>>;
+
+export function ListWebhookRegistrations():Promise<Array<models.WebhookRegistration>>;
+
 export function LockVault():Promise<void>;
 
+export function ReconcileWebhooks():Promise<service.WebhookReconcileResult>;
+
+export function RemoveRepository(arg1:number):Promise<void>;
+
+export function ReplayWebhookDelivery(arg1:number):Promise<models.WebhookDelivery>;
+
+export function SetWebhookListenAddress(arg1:string):Promise<void>;
+
+export function SetWebhookPublicURL(arg1:string):Promise<void>;
+
 export function SetupMasterPassword(arg1:string):Promise<void>;
 
 export function StoreCredential(arg1:number,arg2:string,arg3:string,arg4:string):Promise<number>;
 
+export function SyncRepository(arg1:number):Promise<boolean>;
+
 export function UnlockVault(arg1:string):Promise<void>;
 
 export function UpdateCredential(arg1:number,arg2:number,arg3:string,arg4:string,arg5:string):Promise<void>;
+
+export function UpdateHostMapping(arg1:number,arg2:string,arg3:string,arg4:string):Promise<void>;
+
+export function UpdateMirror(arg1:models.Mirror,arg2:boolean):Promise<void>;
@@ -2,14 +2,46 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddRepository(arg1, arg2, arg3) {
+  return window['go']['main']['App']['AddRepository'](arg1, arg2, arg3);
+}
+
 export function ChangeMasterPassword(arg1, arg2) {
   return window['go']['main']['App']['ChangeMasterPassword'](arg1, arg2);
 }
 
+export function CreateHostMapping(arg1, arg2, arg3) {
+  return window['go']['main']['App']['CreateHostMapping'](arg1, arg2, arg3);
+}
+
+export function CreateMirror(arg1) {
+  return window['go']['main']['App']['CreateMirror'](arg1);
+}
+
 export function DeleteCredential(arg1) {
   return window['go']['main']['App']['DeleteCredential'](arg1);
 }
 
+export function DeleteHostMapping(arg1) {
+  return window['go']['main']['App']['DeleteHostMapping'](arg1);
+}
+
+export function DeleteMirror(arg1, arg2) {
+  return window['go']['main']['App']['DeleteMirror'](arg1, arg2);
+}
+
+export function DetectProvider(arg1) {
+  return window['go']['main']['App']['DetectProvider'](arg1);
+}
+
+export function ExportDiscussions(arg1, arg2) {
+  return window['go']['main']['App']['ExportDiscussions'](arg1, arg2);
+}
+
+export function ForgetProbe(arg1) {
+  return window['go']['main']['App']['ForgetProbe'](arg1);
+}
+
 export function GetCredential(arg1) {
   return window['go']['main']['App']['GetCredential'](arg1);
 }
@@ -18,6 +50,30 @@ export function GetCredentialsByProvider(arg1) {
   return window['go']['main']['App']['GetCredentialsByProvider'](arg1);
 }
 
+export function GetMirrorCloneCommand(arg1) {
+  return window['go']['main']['App']['GetMirrorCloneCommand'](arg1);
+}
+
+export function GetNativeMirrorStatus(arg1) {
+  return window['go']['main']['App']['GetNativeMirrorStatus'](arg1);
+}
+
+export function GetProviderRateLimits(arg1) {
+  return window['go']['main']['App']['GetProviderRateLimits'](arg1);
+}
+
+export function GetWebhookDelivery(arg1) {
+  return window['go']['main']['App']['GetWebhookDelivery'](arg1);
+}
+
+export function GetWebhookListenAddress() {
+  return window['go']['main']['App']['GetWebhookListenAddress']();
+}
+
+export function GetWebhookPublicURL() {
+  return window['go']['main']['App']['GetWebhookPublicURL']();
+}
+
 export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
@@ -34,10 +90,62 @@ export function ListCredentials() {
   return window['go']['main']['App']['ListCredentials']();
 }
 
+export function ListDiscussionExports(arg1) {
+  return window['go']['main']['App']['ListDiscussionExports'](arg1);
+}
+
+export function ListHostMappings() {
+  return window['go']['main']['App']['ListHostMappings']();
+}
+
+export function ListMirrors(arg1) {
+  return window['go']['main']['App']['ListMirrors'](arg1);
+}
+
+export function ListProviderSchemas() {
+  return window['go']['main']['App']['ListProviderSchemas']();
+}
+
+export function ListSubmoduleMappings(arg1) {
+  return window['go']['main']['App']['ListSubmoduleMappings'](arg1);
+}
+
+export function ListSyncHistory(arg1, arg2) {
+  return window['go']['main']['App']['ListSyncHistory'](arg1, arg2);
+}
+
+export function ListWebhookDeliveries(arg1) {
+  return window['go']['main']['App']['ListWebhookDeliveries'](arg1);
+}
+
+export function ListWebhookRegistrations() {
+  return window['go']['main']['App']['ListWebhookRegistrations']();
+}
+
 export function LockVault() {
   return window['go']['main']['App']['LockVault']();
 }
 
+export function ReconcileWebhooks() {
+  return window['go']['main']['App']['ReconcileWebhooks']();
+}
+
+export function RemoveRepository(arg1) {
+  return window['go']['main']['App']['RemoveRepository'](arg1);
+}
+
+export function ReplayWebhookDelivery(arg1) {
+  return window['go']['main']['App']['ReplayWebhookDelivery'](arg1);
+}
+
+export function SetWebhookListenAddress(arg1) {
+  return window['go']['main']['App']['SetWebhookListenAddress'](arg1);
+}
+
+export function SetWebhookPublicURL(arg1) {
+  return window['go']['main']['App']['SetWebhookPublicURL'](arg1);
+}
+
 export function SetupMasterPassword(arg1) {
   return window['go']['main']['App']['SetupMasterPassword'](arg1);
 }
@@ -46,6 +154,10 @@ export function StoreCredential(arg1, arg2, arg3, arg4) {
   return window['go']['main']['App']['StoreCredential'](arg1, arg2, arg3, arg4);
 }
 
+export function SyncRepository(arg1) {
+  return window['go']['main']['App']['SyncRepository'](arg1);
+}
+
 export function UnlockVault(arg1) {
   return window['go']['main']['App']['UnlockVault'](arg1);
 }
@@ -53,3 +165,11 @@ export function UnlockVault(arg1) {
 export function UpdateCredential(arg1, arg2, arg3, arg4, arg5) {
   return window['go']['main']['App']['UpdateCredential'](arg1, arg2, arg3, arg4, arg5);
 }
+
+export function UpdateHostMapping(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['UpdateHostMapping'](arg1, arg2, arg3, arg4);
+}
+
+export function UpdateMirror(arg1, arg2) {
+  return window['go']['main']['App']['UpdateMirror'](arg1, arg2);
+}
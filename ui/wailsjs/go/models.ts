@@ -44,6 +44,533 @@ export namespace models {
 		    return a;
 		}
 	}
+	export class DiscussionExport {
+	    id: number;
+	    repository_id: number;
+	    storage_provider_id: number;
+	    path: string;
+	    // Go type: time
+	    since?: any;
+	    // Go type: time
+	    exported_at: any;
+	    issues: number;
+	    comments: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiscussionExport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.repository_id = source["repository_id"];
+	        this.storage_provider_id = source["storage_provider_id"];
+	        this.path = source["path"];
+	        this.since = this.convertValues(source["since"], null);
+	        this.exported_at = this.convertValues(source["exported_at"], null);
+	        this.issues = source["issues"];
+	        this.comments = source["comments"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class HostMapping {
+	    id: number;
+	    pattern: string;
+	    provider_type: string;
+	    api_url: string;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new HostMapping(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.pattern = source["pattern"];
+	        this.provider_type = source["provider_type"];
+	        this.api_url = source["api_url"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Mirror {
+	    id: number;
+	    repository_id: number;
+	    mode: string;
+	    direction: string;
+	    target_provider_id: number;
+	    target_url: string;
+	    target_owner: string;
+	    credential_id?: number;
+	    interval_seconds: number;
+	    only_protected_branches: boolean;
+	    mirror_submodules: boolean;
+	    native_id: string;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Mirror(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.repository_id = source["repository_id"];
+	        this.mode = source["mode"];
+	        this.direction = source["direction"];
+	        this.target_provider_id = source["target_provider_id"];
+	        this.target_url = source["target_url"];
+	        this.target_owner = source["target_owner"];
+	        this.credential_id = source["credential_id"];
+	        this.interval_seconds = source["interval_seconds"];
+	        this.only_protected_branches = source["only_protected_branches"];
+	        this.mirror_submodules = source["mirror_submodules"];
+	        this.native_id = source["native_id"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SubmoduleMapping {
+	    id: number;
+	    mirror_id: number;
+	    submodule_mirror_id?: number;
+	    source_url: string;
+	    target_url: string;
+	    // Go type: time
+	    updated_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new SubmoduleMapping(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.mirror_id = source["mirror_id"];
+	        this.submodule_mirror_id = source["submodule_mirror_id"];
+	        this.source_url = source["source_url"];
+	        this.target_url = source["target_url"];
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SyncHistory {
+	    id: number;
+	    repository_id: number;
+	    mirror_id?: number;
+	    status: string;
+	    // Go type: time
+	    started_at: any;
+	    // Go type: time
+	    finished_at?: any;
+	    error_message: string;
+	    details: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SyncHistory(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.repository_id = source["repository_id"];
+	        this.mirror_id = source["mirror_id"];
+	        this.status = source["status"];
+	        this.started_at = this.convertValues(source["started_at"], null);
+	        this.finished_at = this.convertValues(source["finished_at"], null);
+	        this.error_message = source["error_message"];
+	        this.details = source["details"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WebhookDelivery {
+	    id: number;
+	    provider_id: number;
+	    repository_id?: number;
+	    delivery_id: string;
+	    event: string;
+	    status: string;
+	    verified: boolean;
+	    error_message: string;
+	    headers: string;
+	    payload: string;
+	    // Go type: time
+	    received_at: any;
+	    // Go type: time
+	    processed_at?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new WebhookDelivery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.provider_id = source["provider_id"];
+	        this.repository_id = source["repository_id"];
+	        this.delivery_id = source["delivery_id"];
+	        this.event = source["event"];
+	        this.status = source["status"];
+	        this.verified = source["verified"];
+	        this.error_message = source["error_message"];
+	        this.headers = source["headers"];
+	        this.payload = source["payload"];
+	        this.received_at = this.convertValues(source["received_at"], null);
+	        this.processed_at = this.convertValues(source["processed_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class WebhookRegistration {
+	    id: number;
+	    provider_id: number;
+	    repository_id?: number;
+	    credential_id?: number;
+	    clone_url: string;
+	    hook_id: string;
+	    url: string;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    updated_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new WebhookRegistration(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.provider_id = source["provider_id"];
+	        this.repository_id = source["repository_id"];
+	        this.credential_id = source["credential_id"];
+	        this.clone_url = source["clone_url"];
+	        this.hook_id = source["hook_id"];
+	        this.url = source["url"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace provider {
+	
+	export class OptionSpec {
+	    name: string;
+	    type: string;
+	    required: boolean;
+	    default?: string;
+	    secret: boolean;
+	    help: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new OptionSpec(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.required = source["required"];
+	        this.default = source["default"];
+	        this.secret = source["secret"];
+	        this.help = source["help"];
+	    }
+	}
+	export class ConfigSchema {
+	    base_url: OptionSpec;
+	    options: OptionSpec[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ConfigSchema(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.base_url = this.convertValues(source["base_url"], OptionSpec);
+	        this.options = this.convertValues(source["options"], OptionSpec);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class NativeMirror {
+	    id: string;
+	    direction: string;
+	    remote_url: string;
+	    interval: number;
+	    only_protected_branches: boolean;
+	    enabled: boolean;
+	    status: string;
+	    last_error: string;
+	    // Go type: time
+	    last_update_at?: any;
+	    // Go type: time
+	    last_success_at?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new NativeMirror(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.direction = source["direction"];
+	        this.remote_url = source["remote_url"];
+	        this.interval = source["interval"];
+	        this.only_protected_branches = source["only_protected_branches"];
+	        this.enabled = source["enabled"];
+	        this.status = source["status"];
+	        this.last_error = source["last_error"];
+	        this.last_update_at = this.convertValues(source["last_update_at"], null);
+	        this.last_success_at = this.convertValues(source["last_success_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProbeResult {
+	    type: string;
+	    base_url: string;
+	    api_url: string;
+	    version?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProbeResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.base_url = source["base_url"];
+	        this.api_url = source["api_url"];
+	        this.version = source["version"];
+	    }
+	}
+	export class RateLimitStatus {
+	    resource?: string;
+	    limit: number;
+	    remaining: number;
+	    // Go type: time
+	    reset: any;
+	    // Go type: time
+	    updated_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new RateLimitStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.resource = source["resource"];
+	        this.limit = source["limit"];
+	        this.remaining = source["remaining"];
+	        this.reset = this.convertValues(source["reset"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace service {
+	
+	export class WebhookReconcileResult {
+	    created: number;
+	    updated: number;
+	    deleted: number;
+	    unchanged: number;
+	    errors: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new WebhookReconcileResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.created = source["created"];
+	        this.updated = source["updated"];
+	        this.deleted = source["deleted"];
+	        this.unchanged = source["unchanged"];
+	        this.errors = source["errors"];
+	    }
+	}
 
 }
 